@@ -0,0 +1,166 @@
+// Package amortization calculates the repayment schedules of loans.
+package amortization
+
+import (
+	"math"
+	"time"
+)
+
+// Loan schedule
+const (
+	ScheduleAnnuity string = "Annuity"
+	ScheduleLinear         = "Linear"
+)
+
+var Schedules = []string{
+	ScheduleAnnuity,
+	ScheduleLinear,
+}
+
+// Loan is repaid in monthly payments, the first of which is due on the first
+// payment date. An annuity loan has equal payments of principal and interest,
+// a linear loan has equal repayments of principal and decreasing interest.
+type Loan struct {
+	Principal float64
+	// Rate is the yearly interest rate in percent
+	Rate         float64
+	Term         int
+	Schedule     string
+	FirstPayment time.Time
+}
+
+// Repayment is an extra repayment of principal. It is deducted together with
+// the next payment that is due on or after its date, so interest is charged
+// over it until then.
+type Repayment struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Payment is a monthly payment, with the balance that is left after it.
+type Payment struct {
+	Number    int
+	Date      time.Time
+	Principal float64
+	Interest  float64
+	Extra     float64
+	Total     float64
+	Balance   float64
+}
+
+type Schedule struct {
+	Payments      []Payment
+	TotalInterest float64
+	TotalPaid     float64
+	PayoffDate    time.Time
+}
+
+// Amortize calculates the payments of a loan. Extra repayments keep the
+// regular payment the same, so they shorten the term rather than lower the
+// payments. Repayments after the loan is paid off are ignored.
+func Amortize(loan Loan, repayments []Repayment) Schedule {
+	schedule := Schedule{}
+	if loan.Principal <= 0 || loan.Term <= 0 {
+		return schedule
+	}
+
+	rate := loan.Rate / 100 / 12
+	annuity := loan.Principal / float64(loan.Term)
+	if rate != 0 {
+		annuity = loan.Principal * rate / (1 - math.Pow(1+rate, -float64(loan.Term)))
+	}
+	linear := loan.Principal / float64(loan.Term)
+
+	balance := loan.Principal
+	var previous time.Time
+	for number := 1; number <= loan.Term && balance > 0; number++ {
+		date := addMonths(loan.FirstPayment, number-1)
+
+		interest := round(balance * rate)
+		var principal float64
+		switch loan.Schedule {
+		case ScheduleLinear:
+			principal = linear
+		default:
+			principal = annuity - interest
+		}
+		// The last payment repays whatever is left after rounding
+		if number == loan.Term {
+			principal = balance
+		}
+		principal = round(min(principal, balance))
+
+		var extra float64
+		for _, repayment := range repayments {
+			if (number == 1 || repayment.Date.After(previous)) && !repayment.Date.After(date) {
+				extra += repayment.Amount
+			}
+		}
+		extra = round(min(max(extra, 0), balance-principal))
+
+		balance = round(balance - principal - extra)
+		payment := Payment{
+			Number:    number,
+			Date:      date,
+			Principal: principal,
+			Interest:  interest,
+			Extra:     extra,
+			Total:     round(principal + interest + extra),
+			Balance:   balance,
+		}
+		schedule.Payments = append(schedule.Payments, payment)
+		schedule.TotalInterest += interest
+		schedule.TotalPaid += payment.Total
+		schedule.PayoffDate = date
+		previous = date
+	}
+
+	schedule.TotalInterest = round(schedule.TotalInterest)
+	schedule.TotalPaid = round(schedule.TotalPaid)
+
+	return schedule
+}
+
+// BalanceAt returns the balance after the payments that are due on or before
+// a day, which is the principal before the first payment.
+func (schedule Schedule) BalanceAt(loan Loan, date time.Time) float64 {
+	balance := loan.Principal
+	for _, payment := range schedule.Payments {
+		if payment.Date.After(date) {
+			break
+		}
+		balance = payment.Balance
+	}
+
+	return balance
+}
+
+// MonthlyRepayments returns an extra repayment of the amount with each payment
+// of the term that is due after a day.
+func MonthlyRepayments(loan Loan, after time.Time, amount float64) []Repayment {
+	var repayments []Repayment
+	for number := 1; number <= loan.Term; number++ {
+		date := addMonths(loan.FirstPayment, number-1)
+		if date.After(after) {
+			repayments = append(repayments, Repayment{
+				Date:   date,
+				Amount: amount,
+			})
+		}
+	}
+
+	return repayments
+}
+
+// addMonths adds months to a date, keeping the day within the month, so a
+// loan paid on the 31st is paid on the last day of shorter months.
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,120 @@
+package amortization
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAmortizeAnnuity(t *testing.T) {
+	loan := Loan{
+		Principal:    100000,
+		Rate:         6,
+		Term:         360,
+		Schedule:     ScheduleAnnuity,
+		FirstPayment: date(2024, time.January, 31),
+	}
+
+	schedule := Amortize(loan, nil)
+
+	if len(schedule.Payments) != 360 {
+		t.Fatalf("expected 360 payments, got %d", len(schedule.Payments))
+	}
+	first := schedule.Payments[0]
+	if first.Interest != 500 || first.Principal != 99.55 || first.Total != 599.55 {
+		t.Errorf("expected a first payment of 99.55 principal and 500 interest, got %+v", first)
+	}
+	if date := schedule.Payments[1].Date; !date.Equal(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the second payment at the end of February, got %s", date.Format(time.DateOnly))
+	}
+	last := schedule.Payments[359]
+	if last.Balance != 0 {
+		t.Errorf("expected the loan to be paid off, got a balance of %v", last.Balance)
+	}
+	if schedule.TotalInterest < 115838 || schedule.TotalInterest > 115839 {
+		t.Errorf("expected about 115838.19 interest, got %v", schedule.TotalInterest)
+	}
+}
+
+func TestAmortizeLinear(t *testing.T) {
+	loan := Loan{
+		Principal:    1200,
+		Rate:         12,
+		Term:         12,
+		Schedule:     ScheduleLinear,
+		FirstPayment: date(2024, time.January, 1),
+	}
+
+	schedule := Amortize(loan, nil)
+
+	if len(schedule.Payments) != 12 {
+		t.Fatalf("expected 12 payments, got %d", len(schedule.Payments))
+	}
+	for _, payment := range schedule.Payments {
+		if payment.Principal != 100 {
+			t.Errorf("expected 100 principal in payment %d, got %v", payment.Number, payment.Principal)
+		}
+	}
+	if schedule.Payments[0].Interest != 12 || schedule.Payments[11].Interest != 1 {
+		t.Errorf("expected the interest to decrease from 12 to 1, got %v and %v", schedule.Payments[0].Interest, schedule.Payments[11].Interest)
+	}
+	if schedule.TotalInterest != 78 {
+		t.Errorf("expected 78 interest, got %v", schedule.TotalInterest)
+	}
+}
+
+func TestAmortizeRepayments(t *testing.T) {
+	loan := Loan{
+		Principal:    1200,
+		Rate:         0,
+		Term:         12,
+		Schedule:     ScheduleAnnuity,
+		FirstPayment: date(2024, time.January, 1),
+	}
+
+	schedule := Amortize(loan, []Repayment{
+		{Date: date(2023, time.December, 15), Amount: 150},
+		{Date: date(2024, time.March, 1), Amount: 250},
+		// After the loan is paid off
+		{Date: date(2025, time.January, 1), Amount: 1000},
+	})
+
+	if len(schedule.Payments) != 8 {
+		t.Fatalf("expected 8 payments, got %d", len(schedule.Payments))
+	}
+	if schedule.Payments[0].Extra != 150 || schedule.Payments[2].Extra != 250 {
+		t.Errorf("expected the repayments with the first and third payment, got %+v", schedule.Payments[:3])
+	}
+	if last := schedule.Payments[7]; last.Balance != 0 {
+		t.Errorf("expected the loan to be paid off, got %+v", last)
+	}
+	if schedule.TotalPaid != 1200 {
+		t.Errorf("expected 1200 paid, got %v", schedule.TotalPaid)
+	}
+	if balance := schedule.BalanceAt(loan, date(2024, time.March, 15)); balance != 500 {
+		t.Errorf("expected a balance of 500, got %v", balance)
+	}
+}
+
+func TestMonthlyRepayments(t *testing.T) {
+	loan := Loan{
+		Principal:    1200,
+		Rate:         0,
+		Term:         12,
+		Schedule:     ScheduleLinear,
+		FirstPayment: date(2024, time.January, 1),
+	}
+
+	repayments := MonthlyRepayments(loan, date(2024, time.June, 1), 100)
+	if len(repayments) != 6 || !repayments[0].Date.Equal(date(2024, time.July, 1)) {
+		t.Fatalf("expected 6 repayments from July, got %+v", repayments)
+	}
+
+	schedule := Amortize(loan, repayments)
+	if len(schedule.Payments) != 9 {
+		t.Errorf("expected 9 payments, got %d", len(schedule.Payments))
+	}
+}
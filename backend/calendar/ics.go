@@ -0,0 +1,83 @@
+// Package calendar writes iCalendar (RFC 5545) feeds.
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength is the number of octets after which lines are folded
+const maxLineLength = 75
+
+// Event is an all-day event.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+// WriteICS writes a calendar with the events to w.
+func WriteICS(w io.Writer, name string, events []Event, now time.Time) error {
+	writer := bufio.NewWriter(w)
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	writeLine(writer, "BEGIN:VCALENDAR")
+	writeLine(writer, "VERSION:2.0")
+	writeLine(writer, "PRODID:-//Fiscora//Fiscora//EN")
+	writeLine(writer, "CALSCALE:GREGORIAN")
+	writeLine(writer, "METHOD:PUBLISH")
+	writeLine(writer, "X-WR-CALNAME:"+escape(name))
+
+	for _, event := range events {
+		writeLine(writer, "BEGIN:VEVENT")
+		writeLine(writer, "UID:"+event.UID)
+		writeLine(writer, "DTSTAMP:"+stamp)
+		writeLine(writer, "DTSTART;VALUE=DATE:"+event.Date.Format("20060102"))
+		writeLine(writer, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine(writer, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			writeLine(writer, "DESCRIPTION:"+escape(event.Description))
+		}
+		writeLine(writer, "TRANSP:TRANSPARENT")
+		writeLine(writer, "END:VEVENT")
+	}
+
+	writeLine(writer, "END:VCALENDAR")
+
+	return writer.Flush()
+}
+
+// writeLine writes a content line, folding it into lines of at most 75 octets
+// without splitting characters.
+func writeLine(writer *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		writer.WriteString(line[:cut])
+		writer.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = maxLineLength - 1
+	}
+	writer.WriteString(line)
+	writer.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/gommon/log"
@@ -18,6 +20,14 @@ type Environment struct {
 	GoogleID           string
 	GoogleSecret       string
 	GoogleCallback     string
+	TrashRetentionDays int
+	IdempotencyKeyTTL  time.Duration
+	RecurringHorizon   time.Duration
+	SMTPHost           string
+	SMTPPort           string
+	SMTPUsername       string
+	SMTPPassword       string
+	SMTPFrom           string
 }
 
 var Envs = getEnvironment()
@@ -40,6 +50,14 @@ func getEnvironment() Environment {
 		GoogleID:           getEnv("GOOGLE_ID", ""),
 		GoogleSecret:       getEnv("GOOGLE_SECRET", ""),
 		GoogleCallback:     getEnv("GOOGLE_CALLBACK", ""),
+		TrashRetentionDays: getIntEnv("TRASH_RETENTION_DAYS", 30),
+		IdempotencyKeyTTL:  time.Duration(getIntEnv("IDEMPOTENCY_KEY_TTL_HOURS", 24)) * time.Hour,
+		RecurringHorizon:   time.Duration(getIntEnv("RECURRING_HORIZON_DAYS", 90)) * 24 * time.Hour,
+		SMTPHost:           getEnv("SMTP_HOST", ""),
+		SMTPPort:           getEnv("SMTP_PORT", "587"),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:           getEnv("SMTP_FROM", "noreply@fiscora.app"),
 	}
 }
 
@@ -58,3 +76,15 @@ func getBoolEnv(key string, fallback bool) bool {
 	log.Infof("%s not found in environment, defaulting to: %v", key, fallback)
 	return fallback
 }
+
+func getIntEnv(key string, fallback int) int {
+	if value := getEnv(key, ""); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+		log.Errorf("%s is not a valid integer, defaulting to: %d", key, fallback)
+		return fallback
+	}
+	log.Infof("%s not found in environment, defaulting to: %d", key, fallback)
+	return fallback
+}
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id INT NOT NULL,
+    tag VARCHAR(32) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    PRIMARY KEY(transaction_id, tag),
+    FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+DROP VIEW full_transaction;
+
+CREATE VIEW full_transaction AS (
+    SELECT t.*, rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, b.name as budget_name, be.name as budget_expense_name,
+        COALESCE((SELECT array_agg(tt.tag ORDER BY tt.tag) FROM transaction_tags tt WHERE tt.transaction_id = t.id), '{}')::text[] as tags
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id 
+        LEFT JOIN budgets b ON be.budget_id = b.id
+);
+
+-- +goose Down
+DROP VIEW full_transaction;
+
+CREATE VIEW full_transaction AS (
+    SELECT t.*, rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, b.name as budget_name, be.name as budget_expense_name
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id 
+        LEFT JOIN budgets b ON be.budget_id = b.id
+);
+
+DROP TABLE transaction_tags;
@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN deleted TIMESTAMP DEFAULT NULL;
+ALTER TABLE recurring_transactions ADD COLUMN deleted TIMESTAMP DEFAULT NULL;
+ALTER TABLE budgets ADD COLUMN deleted TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX transactions_deleted_idx ON transactions(deleted) WHERE deleted IS NOT NULL;
+CREATE INDEX recurring_transactions_deleted_idx ON recurring_transactions(deleted) WHERE deleted IS NOT NULL;
+CREATE INDEX budgets_deleted_idx ON budgets(deleted) WHERE deleted IS NOT NULL;
+
+DROP VIEW full_transaction;
+
+-- Transactions keep their budget while the budget is in the trash so restoring
+-- the budget restores the assignments, but they show up as unassigned meanwhile
+CREATE VIEW full_transaction AS (
+    SELECT t.id, t.user_id, b.id as budget_id, be.id as budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted,
+        rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, rt.deleted as recurring_deleted, b.name as budget_name, be.name as budget_expense_name,
+        COALESCE((SELECT array_agg(tt.tag ORDER BY tt.tag) FROM transaction_tags tt WHERE tt.transaction_id = t.id), '{}')::text[] as tags
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budgets b ON t.budget_id = b.id AND b.deleted IS NULL
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id AND be.budget_id = b.id
+);
+
+-- +goose Down
+DROP VIEW full_transaction;
+
+CREATE VIEW full_transaction AS (
+    SELECT t.*, rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, b.name as budget_name, be.name as budget_expense_name,
+        COALESCE((SELECT array_agg(tt.tag ORDER BY tt.tag) FROM transaction_tags tt WHERE tt.transaction_id = t.id), '{}')::text[] as tags
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id 
+        LEFT JOIN budgets b ON be.budget_id = b.id
+);
+
+DROP INDEX budgets_deleted_idx;
+DROP INDEX recurring_transactions_deleted_idx;
+DROP INDEX transactions_deleted_idx;
+
+ALTER TABLE budgets DROP COLUMN deleted;
+ALTER TABLE recurring_transactions DROP COLUMN deleted;
+ALTER TABLE transactions DROP COLUMN deleted;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    request_id VARCHAR(64),
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(32) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc')
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log(user_id, entity_type, entity_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose Down
+DROP TRIGGER audit_log_append_only ON audit_log;
+DROP FUNCTION audit_log_append_only;
+DROP TABLE audit_log;
@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE budgets ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE budget_expenses ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+DROP VIEW full_transaction;
+
+CREATE VIEW full_transaction AS (
+    SELECT t.id, t.user_id, b.id as budget_id, be.id as budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted, t.version,
+        rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, rt.deleted as recurring_deleted, b.name as budget_name, be.name as budget_expense_name,
+        COALESCE((SELECT array_agg(tt.tag ORDER BY tt.tag) FROM transaction_tags tt WHERE tt.transaction_id = t.id), '{}')::text[] as tags
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budgets b ON t.budget_id = b.id AND b.deleted IS NULL
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id AND be.budget_id = b.id
+);
+
+-- +goose Down
+DROP VIEW full_transaction;
+
+CREATE VIEW full_transaction AS (
+    SELECT t.id, t.user_id, b.id as budget_id, be.id as budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted,
+        rt.start_date, rt.end_date, rt.interval, rt.days_interval, rt.created as recurring_created, rt.updated as recurring_updated, rt.deleted as recurring_deleted, b.name as budget_name, be.name as budget_expense_name,
+        COALESCE((SELECT array_agg(tt.tag ORDER BY tt.tag) FROM transaction_tags tt WHERE tt.transaction_id = t.id), '{}')::text[] as tags
+    FROM transactions t 
+        LEFT OUTER JOIN recurring_transactions rt ON t.recurring_transaction_id = rt.id 
+        LEFT OUTER JOIN budgets b ON t.budget_id = b.id AND b.deleted IS NULL
+        LEFT OUTER JOIN budget_expenses be ON t.budget_expense_id = be.id AND be.budget_id = b.id
+);
+
+ALTER TABLE budget_expenses DROP COLUMN version;
+ALTER TABLE budgets DROP COLUMN version;
+ALTER TABLE transactions DROP COLUMN version;
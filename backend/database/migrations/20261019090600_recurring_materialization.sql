@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE recurring_transactions ALTER COLUMN end_date DROP NOT NULL;
+
+ALTER TABLE recurring_transactions ADD COLUMN description VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE recurring_transactions ADD COLUMN amount DECIMAL(19, 4) NOT NULL DEFAULT 0;
+ALTER TABLE recurring_transactions ADD COLUMN type VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE recurring_transactions ADD COLUMN materialized_until TIMESTAMP;
+
+UPDATE recurring_transactions rt
+SET description = t.description, amount = t.amount, type = t.type
+FROM (
+    SELECT DISTINCT ON (recurring_transaction_id) recurring_transaction_id, description, amount, type
+    FROM transactions
+    WHERE recurring_transaction_id IS NOT NULL
+    ORDER BY recurring_transaction_id, date
+) t
+WHERE t.recurring_transaction_id = rt.id;
+
+UPDATE recurring_transactions SET materialized_until = end_date;
+
+ALTER TABLE recurring_transactions ALTER COLUMN materialized_until SET NOT NULL;
+
+CREATE INDEX recurring_transactions_materialized_until_idx ON recurring_transactions(materialized_until) WHERE deleted IS NULL;
+
+-- +goose Down
+DROP INDEX recurring_transactions_materialized_until_idx;
+
+ALTER TABLE recurring_transactions DROP COLUMN materialized_until;
+ALTER TABLE recurring_transactions DROP COLUMN type;
+ALTER TABLE recurring_transactions DROP COLUMN amount;
+ALTER TABLE recurring_transactions DROP COLUMN description;
+
+UPDATE recurring_transactions
+SET end_date = (SELECT MAX(t.date) FROM transactions t WHERE t.recurring_transaction_id = recurring_transactions.id)
+WHERE end_date IS NULL;
+DELETE FROM recurring_transactions WHERE end_date IS NULL;
+
+ALTER TABLE recurring_transactions ALTER COLUMN end_date SET NOT NULL;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS recurring_transaction_exceptions (
+    recurring_transaction_id INT NOT NULL,
+    date TIMESTAMP NOT NULL,
+    transaction_id INT,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    PRIMARY KEY (recurring_transaction_id, date),
+    FOREIGN KEY (recurring_transaction_id) REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX recurring_transaction_exceptions_transaction_id_idx ON recurring_transaction_exceptions(transaction_id);
+
+-- +goose Down
+DROP TABLE recurring_transaction_exceptions;
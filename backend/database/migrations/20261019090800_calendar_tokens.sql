@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_id UUID PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE calendar_tokens;
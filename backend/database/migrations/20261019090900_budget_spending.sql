@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE budget_expenses DROP COLUMN current_amount;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = be.id AND t.budget_id = b.id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id
+);
+
+-- +goose Down
+DROP VIEW full_budget_expense;
+
+ALTER TABLE budget_expenses ADD COLUMN current_amount DECIMAL(19, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE budgets ADD COLUMN type VARCHAR(16) NOT NULL DEFAULT 'Custom';
+
+CREATE TABLE IF NOT EXISTS budget_periods (
+    id SERIAL PRIMARY KEY,
+    budget_id VARCHAR(16) NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    UNIQUE (budget_id, start_date),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS budget_period_expenses (
+    id SERIAL PRIMARY KEY,
+    period_id INT NOT NULL,
+    budget_expense_id INT,
+    name VARCHAR(64) NOT NULL,
+    allocated_amount DECIMAL(19, 2) NOT NULL,
+    FOREIGN KEY (period_id) REFERENCES budget_periods(id) ON DELETE CASCADE,
+    FOREIGN KEY (budget_expense_id) REFERENCES budget_expenses(id) ON DELETE SET NULL
+);
+
+CREATE INDEX budget_period_expenses_period_id_idx ON budget_period_expenses(period_id);
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = pe.budget_expense_id AND t.budget_id = p.budget_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id
+);
+
+-- +goose Down
+DROP VIEW full_budget_period_expense;
+DROP TABLE budget_period_expenses;
+DROP TABLE budget_periods;
+
+ALTER TABLE budgets DROP COLUMN type;
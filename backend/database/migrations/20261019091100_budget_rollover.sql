@@ -0,0 +1,69 @@
+-- +goose Up
+ALTER TABLE budget_expenses ADD COLUMN rollover VARCHAR(16) NOT NULL DEFAULT 'Reset';
+ALTER TABLE budget_expenses ADD COLUMN rollover_amount DECIMAL(19, 2) NOT NULL DEFAULT 0;
+ALTER TABLE budget_expenses ADD COLUMN rollover_period_id INT REFERENCES budget_periods(id) ON DELETE SET NULL;
+
+ALTER TABLE budget_period_expenses ADD COLUMN rollover VARCHAR(16) NOT NULL DEFAULT 'Reset';
+ALTER TABLE budget_period_expenses ADD COLUMN rollover_amount DECIMAL(19, 2) NOT NULL DEFAULT 0;
+ALTER TABLE budget_period_expenses ADD COLUMN rollover_period_id INT REFERENCES budget_periods(id) ON DELETE SET NULL;
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = be.id AND t.budget_id = b.id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount,
+        pe.rollover, pe.rollover_amount, pe.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN budget_periods rp ON pe.rollover_period_id = rp.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = pe.budget_expense_id AND t.budget_id = p.budget_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id, rp.id
+);
+
+-- +goose Down
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = pe.budget_expense_id AND t.budget_id = p.budget_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id
+);
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = be.id AND t.budget_id = b.id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id
+);
+
+ALTER TABLE budget_period_expenses DROP COLUMN rollover_period_id;
+ALTER TABLE budget_period_expenses DROP COLUMN rollover_amount;
+ALTER TABLE budget_period_expenses DROP COLUMN rollover;
+
+ALTER TABLE budget_expenses DROP COLUMN rollover_period_id;
+ALTER TABLE budget_expenses DROP COLUMN rollover_amount;
+ALTER TABLE budget_expenses DROP COLUMN rollover;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE budgets ADD COLUMN zero_based BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS budget_envelope_movements (
+    id SERIAL PRIMARY KEY,
+    budget_id VARCHAR(16) NOT NULL,
+    from_expense_id INT,
+    to_expense_id INT,
+    amount DECIMAL(19, 2) NOT NULL CHECK (amount > 0),
+    description VARCHAR(256) NOT NULL DEFAULT '',
+    date TIMESTAMP NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    CHECK (from_expense_id IS NOT NULL OR to_expense_id IS NOT NULL),
+    CHECK (from_expense_id IS DISTINCT FROM to_expense_id),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_expense_id) REFERENCES budget_expenses(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_expense_id) REFERENCES budget_expenses(id) ON DELETE CASCADE
+);
+
+CREATE INDEX budget_envelope_movements_budget_id_idx ON budget_envelope_movements(budget_id, date);
+
+-- +goose Down
+DROP TABLE budget_envelope_movements;
+
+ALTER TABLE budgets DROP COLUMN zero_based;
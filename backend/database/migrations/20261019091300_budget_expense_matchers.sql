@@ -0,0 +1,115 @@
+-- +goose Up
+ALTER TABLE budget_expenses ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE budget_expenses ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE budget_expenses ADD COLUMN payees TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS budget_excluded_transactions (
+    budget_id VARCHAR(16) NOT NULL,
+    transaction_id INT NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    PRIMARY KEY(budget_id, transaction_id),
+    FOREIGN KEY(budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+    FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- A transaction counts against the expense it is assigned to. A transaction
+-- without a budget counts against the expense of each budget it matches
+-- within one of the periods of the budget, unless it is excluded from the
+-- budget. A payee match wins over a tag match, which wins over a category
+-- match.
+CREATE VIEW budget_expense_transaction AS (
+    SELECT t.id as transaction_id, t.budget_id, t.budget_expense_id, false as matched
+    FROM transactions t
+    WHERE t.budget_id IS NOT NULL AND t.budget_expense_id IS NOT NULL
+    UNION ALL
+    (
+        SELECT DISTINCT ON (m.budget_id, m.transaction_id) m.transaction_id, m.budget_id, m.budget_expense_id, true as matched
+        FROM (
+            SELECT t.id as transaction_id, b.id as budget_id, be.id as budget_expense_id,
+                CASE
+                    WHEN EXISTS (SELECT 1 FROM unnest(be.payees) p WHERE t.description ILIKE '%' || p || '%') THEN 3
+                    WHEN EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = ANY(be.tags)) THEN 2
+                    WHEN t.type = ANY(be.categories) THEN 1
+                    ELSE 0
+                END as precedence
+            FROM transactions t
+                JOIN budgets b ON b.user_id = t.user_id
+                JOIN budget_expenses be ON be.budget_id = b.id
+            WHERE t.budget_id IS NULL
+                AND (t.date >= b.start_date AND t.date <= b.end_date
+                    OR EXISTS (SELECT 1 FROM budget_periods p WHERE p.budget_id = b.id AND t.date >= p.start_date AND t.date <= p.end_date))
+                AND NOT EXISTS (SELECT 1 FROM budget_excluded_transactions x WHERE x.budget_id = b.id AND x.transaction_id = t.id)
+        ) m
+        WHERE m.precedence > 0
+        ORDER BY m.budget_id, m.transaction_id, m.precedence DESC, m.budget_expense_id
+    )
+);
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date,
+        be.categories, be.tags, be.payees
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = be.id AND bet.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount,
+        pe.rollover, pe.rollover_amount, pe.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN budget_periods rp ON pe.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = pe.budget_expense_id AND bet.budget_id = p.budget_id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id, rp.id
+);
+
+-- +goose Down
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount,
+        pe.rollover, pe.rollover_amount, pe.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN budget_periods rp ON pe.rollover_period_id = rp.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = pe.budget_expense_id AND t.budget_id = p.budget_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id, rp.id
+);
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN transactions t ON t.budget_expense_id = be.id AND t.budget_id = b.id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP VIEW budget_expense_transaction;
+
+DROP TABLE budget_excluded_transactions;
+
+ALTER TABLE budget_expenses DROP COLUMN payees;
+ALTER TABLE budget_expenses DROP COLUMN tags;
+ALTER TABLE budget_expenses DROP COLUMN categories;
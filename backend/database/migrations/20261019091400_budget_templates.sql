@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS budget_templates (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(256) NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    zero_based BOOLEAN NOT NULL DEFAULT false,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS budget_template_expenses (
+    id SERIAL PRIMARY KEY,
+    template_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    allocated_amount DECIMAL(19, 2) NOT NULL,
+    rollover VARCHAR(16) NOT NULL DEFAULT 'Reset',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    payees TEXT[] NOT NULL DEFAULT '{}',
+
+    FOREIGN KEY(template_id) REFERENCES budget_templates(id) ON DELETE CASCADE
+);
+
+CREATE INDEX budget_template_expenses_template_id_idx ON budget_template_expenses(template_id);
+
+-- +goose Down
+DROP TABLE budget_template_expenses;
+
+DROP TABLE budget_templates;
@@ -0,0 +1,66 @@
+-- +goose Up
+ALTER TABLE budget_expenses ADD COLUMN thresholds INT[] NOT NULL DEFAULT '{}';
+ALTER TABLE budget_template_expenses ADD COLUMN thresholds INT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id SERIAL PRIMARY KEY,
+    budget_expense_id INT NOT NULL,
+    threshold INT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    percentage_used DECIMAL(9, 2) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    UNIQUE (budget_expense_id, threshold, period_start),
+    FOREIGN KEY (budget_expense_id) REFERENCES budget_expenses(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    title VARCHAR(256) NOT NULL,
+    message TEXT NOT NULL,
+    read TIMESTAMP,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications(user_id, created);
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date,
+        be.categories, be.tags, be.payees, be.thresholds
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = be.id AND bet.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+-- +goose Down
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date,
+        be.categories, be.tags, be.payees
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = be.id AND bet.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP TABLE notifications;
+
+DROP TABLE budget_alerts;
+
+ALTER TABLE budget_template_expenses DROP COLUMN thresholds;
+ALTER TABLE budget_expenses DROP COLUMN thresholds;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS goals (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(256) NOT NULL,
+    target_amount DECIMAL(19, 2) NOT NULL,
+    initial_amount DECIMAL(19, 2) NOT NULL DEFAULT 0,
+    start_date TIMESTAMP NOT NULL,
+    target_date TIMESTAMP NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    updated TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    version INT NOT NULL DEFAULT 1,
+
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX goals_user_id_idx ON goals(user_id);
+
+-- +goose Down
+DROP TABLE goals;
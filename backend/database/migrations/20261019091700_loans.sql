@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS loans (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(256) NOT NULL,
+    principal DECIMAL(19, 2) NOT NULL,
+    interest_rate DECIMAL(7, 4) NOT NULL,
+    term INT NOT NULL,
+    schedule VARCHAR(16) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    first_payment_date TIMESTAMP NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    updated TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    version INT NOT NULL DEFAULT 1,
+
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX loans_user_id_idx ON loans(user_id);
+
+CREATE TABLE IF NOT EXISTS loan_repayments (
+    id SERIAL PRIMARY KEY,
+    loan_id INT NOT NULL,
+    transaction_id INT,
+    date TIMESTAMP NOT NULL,
+    amount DECIMAL(19, 2) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    FOREIGN KEY(loan_id) REFERENCES loans(id) ON DELETE CASCADE,
+    FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX loan_repayments_loan_id_idx ON loan_repayments(loan_id);
+
+-- The scheduled payments that were posted as transactions
+CREATE TABLE IF NOT EXISTS loan_payments (
+    loan_id INT NOT NULL,
+    number INT NOT NULL,
+    date TIMESTAMP NOT NULL,
+    principal_transaction_id INT,
+    interest_transaction_id INT,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    PRIMARY KEY(loan_id, number),
+    FOREIGN KEY(loan_id) REFERENCES loans(id) ON DELETE CASCADE,
+    FOREIGN KEY(principal_transaction_id) REFERENCES transactions(id) ON DELETE SET NULL,
+    FOREIGN KEY(interest_transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE loan_payments;
+
+DROP TABLE loan_repayments;
+
+DROP TABLE loans;
@@ -0,0 +1,49 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS investment_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(256) NOT NULL,
+    cost_basis_method VARCHAR(16) NOT NULL DEFAULT 'FIFO',
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    updated TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+    version INT NOT NULL DEFAULT 1,
+
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX investment_accounts_user_id_idx ON investment_accounts(user_id);
+
+CREATE TABLE IF NOT EXISTS investment_trades (
+    id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    quantity DECIMAL(19, 6) NOT NULL,
+    price DECIMAL(19, 6) NOT NULL,
+    fees DECIMAL(19, 2) NOT NULL DEFAULT 0,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    FOREIGN KEY(account_id) REFERENCES investment_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX investment_trades_account_id_idx ON investment_trades(account_id, date);
+
+CREATE TABLE IF NOT EXISTS investment_prices (
+    user_id UUID NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    price DECIMAL(19, 6) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT (now() at time zone 'utc'),
+
+    PRIMARY KEY(user_id, symbol, date),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE investment_prices;
+
+DROP TABLE investment_trades;
+
+DROP TABLE investment_accounts;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Summaries aggregate the transactions of a user over a date range
+CREATE INDEX transactions_user_id_date_idx ON transactions(user_id, date) WHERE deleted IS NULL;
+
+-- +goose Down
+DROP INDEX transactions_user_id_date_idx;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- The week start is a day of the week from 0 (Sunday) to 6 (Saturday) and the
+-- fiscal year start a month from 1 (January) to 12 (December)
+ALTER TABLE users ADD COLUMN week_start SMALLINT NOT NULL DEFAULT 1 CHECK (week_start BETWEEN 0 AND 6);
+ALTER TABLE users ADD COLUMN fiscal_year_start SMALLINT NOT NULL DEFAULT 1 CHECK (fiscal_year_start BETWEEN 1 AND 12);
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN fiscal_year_start;
+ALTER TABLE users DROP COLUMN week_start;
@@ -0,0 +1,147 @@
+-- +goose Up
+-- Transactions assigned to a budget are looked up per budget
+CREATE INDEX transactions_budget_id_idx ON transactions(budget_id) WHERE budget_id IS NOT NULL;
+
+-- The budget_expense_transaction view matched the transactions of all users
+-- against all budgets before a budget could be filtered on. The function
+-- matches the transactions of a single budget. It is a single SELECT, so it
+-- is inlined when it is joined laterally and can use the indexes on
+-- transactions.
+--
+-- A transaction counts against the expense it is assigned to. A transaction
+-- without a budget counts against the expense of the budget it matches
+-- within one of the periods of the budget, unless it is excluded from the
+-- budget. Deleted transactions and income are never matched. A payee match
+-- wins over a tag match, which wins over a category match. Payees match
+-- literally, % and _ in a payee are escaped.
+-- +goose StatementBegin
+CREATE FUNCTION budget_expense_transactions(for_budget_id TEXT)
+RETURNS TABLE (transaction_id INT, budget_id VARCHAR(16), budget_expense_id INT, matched BOOLEAN)
+AS $$
+    SELECT t.id, t.budget_id, t.budget_expense_id, false
+    FROM transactions t
+    WHERE t.budget_id = for_budget_id AND t.budget_expense_id IS NOT NULL
+    UNION ALL
+    (
+        SELECT DISTINCT ON (m.transaction_id) m.transaction_id, m.budget_id, m.budget_expense_id, true
+        FROM (
+            SELECT t.id as transaction_id, b.id as budget_id, be.id as budget_expense_id,
+                CASE
+                    WHEN EXISTS (
+                        SELECT 1 FROM unnest(be.payees) p
+                        WHERE t.description ILIKE '%' || replace(replace(replace(p, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+                    ) THEN 3
+                    WHEN EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = ANY(be.tags)) THEN 2
+                    WHEN t.type = ANY(be.categories) THEN 1
+                    ELSE 0
+                END as precedence
+            FROM budgets b
+                JOIN budget_expenses be ON be.budget_id = b.id
+                JOIN transactions t ON t.user_id = b.user_id
+            WHERE b.id = for_budget_id AND t.budget_id IS NULL AND t.deleted IS NULL AND t.amount < 0
+                AND (t.date >= b.start_date AND t.date <= b.end_date
+                    OR EXISTS (SELECT 1 FROM budget_periods p WHERE p.budget_id = b.id AND t.date >= p.start_date AND t.date <= p.end_date))
+                AND NOT EXISTS (SELECT 1 FROM budget_excluded_transactions x WHERE x.budget_id = b.id AND x.transaction_id = t.id)
+        ) m
+        WHERE m.precedence > 0
+        ORDER BY m.transaction_id, m.precedence DESC, m.budget_expense_id
+    )
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date,
+        be.categories, be.tags, be.payees, be.thresholds
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN LATERAL budget_expense_transactions(b.id) bet ON bet.budget_expense_id = be.id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount,
+        pe.rollover, pe.rollover_amount, pe.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN budget_periods rp ON pe.rollover_period_id = rp.id
+        LEFT OUTER JOIN LATERAL budget_expense_transactions(p.budget_id) bet ON bet.budget_expense_id = pe.budget_expense_id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id, rp.id
+);
+
+DROP VIEW budget_expense_transaction;
+
+-- +goose Down
+CREATE VIEW budget_expense_transaction AS (
+    SELECT t.id as transaction_id, t.budget_id, t.budget_expense_id, false as matched
+    FROM transactions t
+    WHERE t.budget_id IS NOT NULL AND t.budget_expense_id IS NOT NULL
+    UNION ALL
+    (
+        SELECT DISTINCT ON (m.budget_id, m.transaction_id) m.transaction_id, m.budget_id, m.budget_expense_id, true as matched
+        FROM (
+            SELECT t.id as transaction_id, b.id as budget_id, be.id as budget_expense_id,
+                CASE
+                    WHEN EXISTS (SELECT 1 FROM unnest(be.payees) p WHERE t.description ILIKE '%' || p || '%') THEN 3
+                    WHEN EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = ANY(be.tags)) THEN 2
+                    WHEN t.type = ANY(be.categories) THEN 1
+                    ELSE 0
+                END as precedence
+            FROM transactions t
+                JOIN budgets b ON b.user_id = t.user_id
+                JOIN budget_expenses be ON be.budget_id = b.id
+            WHERE t.budget_id IS NULL
+                AND (t.date >= b.start_date AND t.date <= b.end_date
+                    OR EXISTS (SELECT 1 FROM budget_periods p WHERE p.budget_id = b.id AND t.date >= p.start_date AND t.date <= p.end_date))
+                AND NOT EXISTS (SELECT 1 FROM budget_excluded_transactions x WHERE x.budget_id = b.id AND x.transaction_id = t.id)
+        ) m
+        WHERE m.precedence > 0
+        ORDER BY m.budget_id, m.transaction_id, m.precedence DESC, m.budget_expense_id
+    )
+);
+
+DROP VIEW full_budget_period_expense;
+
+CREATE VIEW full_budget_period_expense AS (
+    SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as spent_amount,
+        pe.rollover, pe.rollover_amount, pe.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date
+    FROM budget_period_expenses pe
+        JOIN budget_periods p ON pe.period_id = p.id
+        LEFT OUTER JOIN budget_periods rp ON pe.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = pe.budget_expense_id AND bet.budget_id = p.budget_id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= p.start_date AND t.date <= p.end_date
+    GROUP BY pe.id, rp.id
+);
+
+DROP VIEW full_budget_expense;
+
+CREATE VIEW full_budget_expense AS (
+    SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version,
+        COALESCE(-SUM(t.amount), 0)::DECIMAL(19, 2) as current_amount,
+        be.rollover, be.rollover_amount, be.rollover_period_id, rp.start_date as rollover_start_date, rp.end_date as rollover_end_date,
+        be.categories, be.tags, be.payees, be.thresholds
+    FROM budget_expenses be
+        JOIN budgets b ON be.budget_id = b.id
+        LEFT OUTER JOIN budget_periods rp ON be.rollover_period_id = rp.id
+        LEFT OUTER JOIN budget_expense_transaction bet ON bet.budget_expense_id = be.id AND bet.budget_id = b.id
+        LEFT OUTER JOIN transactions t ON t.id = bet.transaction_id AND t.deleted IS NULL
+            AND t.date >= b.start_date AND t.date <= b.end_date
+    GROUP BY be.id, rp.id
+);
+
+DROP FUNCTION budget_expense_transactions;
+
+DROP INDEX transactions_budget_id_idx;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The value of a linked investment account counts as saved towards a goal
+ALTER TABLE goals ADD COLUMN investment_account_id INT;
+ALTER TABLE goals ADD FOREIGN KEY(investment_account_id) REFERENCES investment_accounts(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE goals DROP COLUMN investment_account_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Monthly and yearly periods are counted from the start of the first period,
+-- so a budget that starts on the 31st returns to the 31st after a shorter
+-- month. Closed periods already drifted, the first of them is the anchor.
+ALTER TABLE budgets ADD COLUMN anchor_date TIMESTAMP;
+UPDATE budgets SET anchor_date = COALESCE((SELECT MIN(p.start_date) FROM budget_periods p WHERE p.budget_id = budgets.id), start_date);
+ALTER TABLE budgets ALTER COLUMN anchor_date SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE budgets DROP COLUMN anchor_date;
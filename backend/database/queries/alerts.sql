@@ -0,0 +1,11 @@
+-- name: GetBudgetExpensesWithThresholds :many
+SELECT sqlc.embed(be), b.name as budget_name, b.start_date as period_start FROM full_budget_expense be
+JOIN budgets b ON be.budget_id = b.id
+WHERE b.user_id = $1 AND b.deleted IS NULL AND cardinality(be.thresholds) > 0
+ORDER BY b.id, be.id;
+
+-- name: CreateBudgetAlert :one
+INSERT INTO budget_alerts (budget_expense_id, threshold, period_start, percentage_used)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING *;
@@ -0,0 +1,10 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_log (user_id, actor_id, request_id, entity_type, entity_id, action, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetAuditLogForEntity :many
+SELECT * FROM audit_log
+WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+ORDER BY created DESC, id DESC
+LIMIT $4
+OFFSET $5;
@@ -0,0 +1,35 @@
+-- name: CreateBudgetTemplate :one
+INSERT INTO budget_templates (user_id, name, description, amount, type, zero_based)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CreateBudgetTemplateExpenses :exec
+INSERT INTO budget_template_expenses (template_id, name, allocated_amount, rollover, categories, tags, payees, thresholds)
+SELECT sqlc.arg(template_id)::int, be.name, be.allocated_amount, be.rollover, be.categories, be.tags, be.payees, be.thresholds
+FROM budget_expenses be
+WHERE be.budget_id = sqlc.arg(budget_id)::text
+ORDER BY be.id;
+
+-- name: GetBudgetTemplates :many
+SELECT * FROM budget_templates
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetBudgetTemplate :one
+SELECT * FROM budget_templates
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetBudgetTemplatesExpenses :many
+SELECT te.* FROM budget_template_expenses te
+JOIN budget_templates t ON te.template_id = t.id
+WHERE t.user_id = $1
+ORDER BY te.id;
+
+-- name: GetBudgetTemplateExpenses :many
+SELECT * FROM budget_template_expenses
+WHERE template_id = $1
+ORDER BY id;
+
+-- name: DeleteBudgetTemplate :execrows
+DELETE FROM budget_templates
+WHERE id = $1 AND user_id = $2;
@@ -1,51 +1,186 @@
 -- name: CreateBudget :one
-INSERT INTO budgets (id, user_id, name, description, amount, start_date, end_date)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO budgets (id, user_id, name, description, amount, start_date, end_date, type, zero_based, anchor_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $6)
 RETURNING *;
 
 -- name: UpdateBudget :one
 UPDATE budgets
-SET name = $3, description = $4, amount = $5, start_date = $6, end_date = $7, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2
+SET name = $3, description = $4, amount = $5, start_date = $6, end_date = $7, type = $8, zero_based = $9,
+    anchor_date = CASE WHEN start_date = $6 THEN anchor_date ELSE $6 END, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
 RETURNING *;
 
 -- name: GetBudgets :many
 SELECT * FROM budgets
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted IS NULL
 ORDER BY created DESC
 LIMIT $2
 OFFSET $3;
 
 -- name: GetBudget :one
 SELECT * FROM budgets
-WHERE id = $1 AND user_id = $2;
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL;
+
+-- name: GetBudgetUserId :one
+SELECT user_id FROM budgets
+WHERE id = $1;
 
 -- name: GetBudgetsExpenses :many
-SELECT sqlc.embed(be) FROM budgets b JOIN budget_expenses be ON b.id = be.budget_id
-WHERE b.user_id = $1
+SELECT sqlc.embed(be) FROM budgets b JOIN full_budget_expense be ON b.id = be.budget_id
+WHERE b.user_id = $1 AND b.deleted IS NULL
+LIMIT $2
+OFFSET $3;
+
+-- name: DeleteBudget :execrows
+UPDATE budgets
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+-- name: RestoreBudget :execrows
+UPDATE budgets
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL;
+
+-- name: GetDeletedBudgets :many
+SELECT * FROM budgets
+WHERE user_id = $1 AND deleted IS NOT NULL
+ORDER BY deleted DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetDeletedBudgetsExpenses :many
+SELECT sqlc.embed(be) FROM budgets b JOIN full_budget_expense be ON b.id = be.budget_id
+WHERE b.user_id = $1 AND b.deleted IS NOT NULL
 LIMIT $2
 OFFSET $3;
 
--- name: DeleteBudget :exec
-DELETE FROM budgets 
-WHERE id = $1 AND user_id = $2;
+-- name: GetTransactionsForPurgedBudgets :many
+SELECT * FROM transactions
+WHERE budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted < sqlc.arg(before)::timestamp)
+ORDER BY id
+FOR UPDATE;
+
+-- name: RemoveTransactionBudgetIdForPurgedBudgets :many
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted < sqlc.arg(before)::timestamp)
+RETURNING *;
+
+-- name: PurgeDeletedBudgets :many
+DELETE FROM budgets
+WHERE deleted < sqlc.arg(before)::timestamp
+RETURNING *;
 
 
 -- name: CreateBudgetExpense :one
-INSERT INTO budget_expenses (budget_id, name, allocated_amount)
-VALUES ($1, $2, $3)
+INSERT INTO budget_expenses (budget_id, name, allocated_amount, rollover, categories, tags, payees, thresholds)
+VALUES ($1, $2, $3, $4, COALESCE(sqlc.narg(categories)::text[], '{}'), COALESCE(sqlc.narg(tags)::text[], '{}'), COALESCE(sqlc.narg(payees)::text[], '{}'),
+    COALESCE(sqlc.narg(thresholds)::int[], '{}'))
 RETURNING *;
 
 -- name: UpdateBudgetExpense :one
 UPDATE budget_expenses 
-SET name = $2, allocated_amount = $3
-WHERE id = $1
+SET name = $2, allocated_amount = $3, rollover = $4, categories = COALESCE(sqlc.narg(categories)::text[], '{}'), tags = COALESCE(sqlc.narg(tags)::text[], '{}'), payees = COALESCE(sqlc.narg(payees)::text[], '{}'),
+    thresholds = COALESCE(sqlc.narg(thresholds)::int[], '{}'), updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
 RETURNING *;
 
+-- name: GetBudgetExpense :one
+SELECT * FROM budget_expenses
+WHERE id = $1 AND budget_id = $2;
+
+-- name: GetBudgetExpenseById :one
+SELECT * FROM budget_expenses
+WHERE id = $1;
+
 -- name: GetBudgetExpenses :many
 SELECT * FROM budget_expenses
 WHERE budget_id = $1;
 
--- name: DeleteBudgetExpense :exec
+-- name: GetFullBudgetExpenses :many
+SELECT * FROM full_budget_expense
+WHERE budget_id = $1
+ORDER BY id;
+
+-- name: DeleteBudgetExpense :execrows
 DELETE FROM budget_expenses
-WHERE id = $1 AND budget_id = $2;
+WHERE id = $1 AND budget_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+
+-- name: GetBudgetsWithEndedPeriod :many
+SELECT * FROM budgets
+WHERE type != 'Custom' AND deleted IS NULL AND end_date < sqlc.arg(before)::timestamp
+ORDER BY id;
+
+-- name: GetBudgetForUpdate :one
+SELECT * FROM budgets
+WHERE id = $1 AND deleted IS NULL
+FOR UPDATE;
+
+-- name: AdvanceBudgetPeriod :one
+UPDATE budgets
+SET start_date = $2, end_date = $3, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateBudgetPeriod :one
+INSERT INTO budget_periods (budget_id, start_date, end_date, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreateBudgetPeriodExpenses :exec
+INSERT INTO budget_period_expenses (period_id, budget_expense_id, name, allocated_amount, rollover, rollover_amount, rollover_period_id)
+SELECT sqlc.arg(period_id)::int, be.id, be.name, be.allocated_amount, be.rollover, be.rollover_amount, be.rollover_period_id
+FROM budget_expenses be
+WHERE be.budget_id = sqlc.arg(budget_id)::text;
+
+-- name: GetBudgetPeriods :many
+SELECT * FROM budget_periods
+WHERE budget_id = $1
+ORDER BY start_date;
+
+-- name: GetBudgetPeriodExpenses :many
+SELECT pe.* FROM full_budget_period_expense pe
+JOIN budget_periods p ON pe.period_id = p.id
+WHERE p.budget_id = $1
+ORDER BY pe.period_id, pe.id;
+
+-- name: SetBudgetExpenseRollover :exec
+UPDATE budget_expenses
+SET rollover_amount = $2, rollover_period_id = $3
+WHERE id = $1;
+
+-- name: GetBudgetMatchedTransactions :many
+SELECT sqlc.embed(t), be.id as matched_expense_id, be.name as matched_expense_name FROM budgets b
+JOIN LATERAL budget_expense_transactions(b.id) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON bet.matched
+JOIN full_transaction t ON bet.transaction_id = t.id
+JOIN budget_expenses be ON bet.budget_expense_id = be.id
+WHERE b.id = $1 AND b.user_id = $2 AND t.deleted IS NULL AND t.date >= b.start_date AND t.date <= b.end_date
+ORDER BY t.date;
+
+-- name: GetBudgetUnmatchedTransactions :many
+SELECT sqlc.embed(t) FROM full_transaction t
+JOIN budgets b ON b.user_id = t.user_id
+WHERE b.id = $1 AND b.user_id = $2 AND t.deleted IS NULL AND t.amount < 0 AND t.budget_id IS NULL
+    AND t.date >= b.start_date AND t.date <= b.end_date
+    AND NOT EXISTS (SELECT 1 FROM budget_expense_transactions(b.id) bet WHERE bet.transaction_id = t.id)
+    AND NOT EXISTS (SELECT 1 FROM budget_excluded_transactions x WHERE x.transaction_id = t.id AND x.budget_id = b.id)
+ORDER BY t.date;
+
+-- name: ExcludeBudgetTransactions :execrows
+INSERT INTO budget_excluded_transactions (budget_id, transaction_id)
+SELECT sqlc.arg(budget_id)::text, t.id FROM transactions t
+WHERE t.user_id = $1 AND t.id = ANY(sqlc.arg(ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: IncludeBudgetTransactions :execrows
+DELETE FROM budget_excluded_transactions
+WHERE budget_id = sqlc.arg(budget_id)::text AND transaction_id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: GetBudgetSpendingBetweenDates :many
+SELECT b.id as budget_id, bet.budget_expense_id::int as budget_expense_id, t.date, (-t.amount)::DECIMAL(19, 2) as amount FROM budgets b
+JOIN LATERAL budget_expense_transactions(b.id) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON true
+JOIN transactions t ON bet.transaction_id = t.id
+WHERE b.user_id = $1 AND b.deleted IS NULL AND t.deleted IS NULL
+    AND t.date >= sqlc.arg(start_date)::timestamp AND t.date <= sqlc.arg(end_date)::timestamp
+ORDER BY t.date;
@@ -0,0 +1,13 @@
+-- name: UpsertCalendarToken :exec
+INSERT INTO calendar_tokens (user_id, token_hash)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created = (now() at time zone 'utc');
+
+-- name: GetCalendarTokenUserId :one
+SELECT user_id FROM calendar_tokens
+WHERE token_hash = $1;
+
+-- name: DeleteCalendarToken :exec
+DELETE FROM calendar_tokens
+WHERE user_id = $1;
@@ -0,0 +1,28 @@
+-- name: CreateEnvelopeMovement :one
+INSERT INTO budget_envelope_movements (budget_id, from_expense_id, to_expense_id, amount, description, date)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetEnvelopeMovements :many
+SELECT * FROM budget_envelope_movements
+WHERE budget_id = $1
+ORDER BY date, id;
+
+-- name: GetEnvelopeTransactions :many
+SELECT t.id, t.description, t.amount, t.date, bet.budget_expense_id::int as budget_expense_id FROM transactions t
+JOIN budget_expense_transactions(sqlc.arg(budget_id)::text) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON bet.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL
+    AND t.date <= sqlc.arg(until)::timestamp
+ORDER BY t.date, t.id;
+
+-- name: GetEnvelopeIncome :many
+SELECT * FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0
+    AND NOT EXISTS (SELECT 1 FROM budget_expense_transactions(sqlc.arg(budget_id)::text) bet WHERE bet.transaction_id = transactions.id)
+    AND date >= sqlc.arg(start_date)::timestamp AND date <= sqlc.arg(end_date)::timestamp
+ORDER BY date, id;
+
+-- name: GetBudgetFirstStartDate :one
+SELECT COALESCE((SELECT MIN(p.start_date) FROM budget_periods p WHERE p.budget_id = b.id), b.start_date)::timestamp AS start_date
+FROM budgets b
+WHERE b.id = $1;
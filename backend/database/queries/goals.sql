@@ -0,0 +1,37 @@
+-- name: CreateGoal :one
+INSERT INTO goals (user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, investment_account_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(sqlc.narg(tags)::text[], '{}'), $8)
+RETURNING *;
+
+-- name: UpdateGoal :one
+UPDATE goals
+SET name = $3, description = $4, target_amount = $5, initial_amount = $6, start_date = $7, target_date = $8, tags = COALESCE(sqlc.narg(tags)::text[], '{}'),
+    investment_account_id = $9, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
+RETURNING *;
+
+-- name: GetGoals :many
+SELECT * FROM goals
+WHERE user_id = $1
+ORDER BY target_date, id;
+
+-- name: GetGoal :one
+SELECT * FROM goals
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteGoal :execrows
+DELETE FROM goals
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+-- name: GetGoalContributions :many
+SELECT g.id as goal_id, t.date, (-t.amount)::DECIMAL(19, 2) as amount FROM goals g
+JOIN transactions t ON t.user_id = g.user_id
+WHERE g.user_id = $1 AND t.deleted IS NULL AND t.date >= g.start_date
+    AND EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = ANY(g.tags))
+ORDER BY t.date;
+
+-- name: GetGoalTransactions :many
+SELECT sqlc.embed(t) FROM full_transaction t
+JOIN goals g ON g.user_id = t.user_id
+WHERE g.id = $1 AND g.user_id = $2 AND t.deleted IS NULL AND t.date >= g.start_date AND t.tags && g.tags
+ORDER BY t.date;
@@ -0,0 +1,27 @@
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, request_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = NULL,
+    response_body = NULL,
+    created = (now() at time zone 'utc')
+WHERE idempotency_keys.created < sqlc.arg(expired_before)::timestamp;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created < sqlc.arg(before)::timestamp;
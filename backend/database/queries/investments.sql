@@ -0,0 +1,59 @@
+-- name: CreateInvestmentAccount :one
+INSERT INTO investment_accounts (user_id, name, description, cost_basis_method)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateInvestmentAccount :one
+UPDATE investment_accounts
+SET name = $3, description = $4, cost_basis_method = $5, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
+RETURNING *;
+
+-- name: GetInvestmentAccounts :many
+SELECT * FROM investment_accounts
+WHERE user_id = $1
+ORDER BY created, id;
+
+-- name: GetInvestmentAccount :one
+SELECT * FROM investment_accounts
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteInvestmentAccount :execrows
+DELETE FROM investment_accounts
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+-- name: CreateInvestmentTrade :one
+INSERT INTO investment_trades (account_id, symbol, kind, date, quantity, price, fees)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetInvestmentTrades :many
+SELECT * FROM investment_trades
+WHERE account_id = $1
+ORDER BY date, id;
+
+-- name: GetInvestmentAccountsTrades :many
+SELECT it.* FROM investment_trades it
+JOIN investment_accounts ia ON it.account_id = ia.id
+WHERE ia.user_id = $1
+ORDER BY it.date, it.id;
+
+-- name: DeleteInvestmentTrade :one
+DELETE FROM investment_trades
+WHERE id = $1 AND account_id = $2
+RETURNING *;
+
+-- name: UpsertInvestmentPrice :exec
+INSERT INTO investment_prices (user_id, symbol, date, price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, symbol, date) DO UPDATE SET price = EXCLUDED.price, created = (now() at time zone 'utc');
+
+-- name: GetInvestmentPrices :many
+SELECT * FROM investment_prices
+WHERE user_id = $1 AND date <= sqlc.arg(until)::timestamp
+ORDER BY symbol, date;
+
+-- name: GetLatestInvestmentPrices :many
+SELECT DISTINCT ON (symbol) * FROM investment_prices
+WHERE user_id = $1
+ORDER BY symbol, date DESC;
@@ -0,0 +1,66 @@
+-- name: CreateLoan :one
+INSERT INTO loans (user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: UpdateLoan :one
+UPDATE loans
+SET name = $3, description = $4, principal = $5, interest_rate = $6, term = $7, schedule = $8, type = $9, first_payment_date = $10,
+    updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
+RETURNING *;
+
+-- name: GetLoans :many
+SELECT * FROM loans
+WHERE user_id = $1
+ORDER BY created, id;
+
+-- name: GetLoan :one
+SELECT * FROM loans
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetLoansWithDuePayments :many
+SELECT l.* FROM loans l
+WHERE l.first_payment_date <= sqlc.arg(until)::timestamp
+    AND l.term > COALESCE((SELECT MAX(lp.number) FROM loan_payments lp WHERE lp.loan_id = l.id), 0)
+ORDER BY l.id;
+
+-- name: DeleteLoan :execrows
+DELETE FROM loans
+WHERE id = $1 AND user_id = $2 AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+-- name: CreateLoanRepayment :one
+INSERT INTO loan_repayments (loan_id, transaction_id, date, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetLoanRepayments :many
+SELECT * FROM loan_repayments
+WHERE loan_id = $1
+ORDER BY date, id;
+
+-- name: GetLoansRepayments :many
+SELECT lr.* FROM loan_repayments lr
+JOIN loans l ON lr.loan_id = l.id
+WHERE l.user_id = $1
+ORDER BY lr.date, lr.id;
+
+-- name: DeleteLoanRepayment :one
+DELETE FROM loan_repayments
+WHERE id = $1 AND loan_id = $2
+RETURNING *;
+
+-- name: CreateLoanPayment :exec
+INSERT INTO loan_payments (loan_id, number, date, principal_transaction_id, interest_transaction_id)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetLoanPayments :many
+SELECT * FROM loan_payments
+WHERE loan_id = $1
+ORDER BY number;
+
+-- name: GetLoansPayments :many
+SELECT lp.* FROM loan_payments lp
+JOIN loans l ON lp.loan_id = l.id
+WHERE l.user_id = $1
+ORDER BY lp.loan_id, lp.number;
@@ -0,0 +1,21 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (user_id, title, message)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetNotifications :many
+SELECT * FROM notifications
+WHERE user_id = $1
+ORDER BY created DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read = COALESCE(read, (now() at time zone 'utc'))
+WHERE id = $1 AND user_id = $2;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read = (now() at time zone 'utc')
+WHERE user_id = $1 AND read IS NULL;
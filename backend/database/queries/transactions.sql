@@ -3,121 +3,403 @@ INSERT INTO transactions (user_id, recurring_transaction_id, amount, description
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
--- name: UpdateTransaction :exec
+-- name: UpdateTransaction :execrows
 UPDATE transactions
-SET amount = $3, description = $4, type = $5, date = $6, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2;
+SET amount = $3, description = $4, type = $5, date = $6, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
 
--- name: UpdateTransactionBudgetId :exec
+-- name: UpdateTransactionBudgetId :execrows
 UPDATE transactions
-SET budget_id = sqlc.arg(budget_id)::text, budget_expense_id = sqlc.arg(budget_expense_id)::int, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2;
+SET budget_id = sqlc.arg(budget_id)::text, budget_expense_id = sqlc.arg(budget_expense_id)::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
 
--- name: RemoveTransactionBudgetId :exec
+-- name: RemoveTransactionBudgetId :execrows
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2;
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
 
 -- name: RemoveTransactionBudgetIdForBudget :exec
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
 WHERE budget_id = sqlc.arg(budget_id)::text AND user_id = $1;
 
--- name: RemoveTransactionBudgetIdOutsideDates :exec
+-- name: GetTransactionsOutsideBudgetDates :many
+SELECT * FROM transactions
+WHERE user_id = $1 AND budget_id = sqlc.arg(budget_id)::text AND (date < sqlc.arg(start_date) OR date > sqlc.arg(end_date))
+ORDER BY id
+FOR UPDATE;
+
+-- name: RemoveTransactionBudgetIdOutsideDates :many
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
-WHERE user_id = $1 AND budget_id = sqlc.arg(budget_id)::text AND (date < sqlc.arg(start_date) OR date > sqlc.arg(end_date));
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND budget_id = sqlc.arg(budget_id)::text AND (date < sqlc.arg(start_date) OR date > sqlc.arg(end_date))
+RETURNING *;
+
+-- name: LockTransactionVersion :one
+SELECT id FROM transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int)
+FOR UPDATE;
 
 -- name: GetTransactionById :one
 SELECT * FROM transactions
-WHERE id = $1 AND user_id = $2
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1;
+
+-- name: GetFullTransactionById :one
+SELECT sqlc.embed(full_transaction) FROM full_transaction
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1;
+
+-- name: GetDeletedTransactionById :one
+SELECT * FROM transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL
 LIMIT 1;
 
+-- name: GetTransactionsByIds :many
+SELECT * FROM transactions
+WHERE user_id = $1 AND id = ANY(sqlc.arg(ids)::int[])
+ORDER BY id;
+
 -- name: GetTransactionsByRecurringTransactionId :many
 SELECT * FROM transactions
-WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND deleted IS NULL
 ORDER BY date;
 
 -- name: GetTransactionsByBudgetId :many
 SELECT sqlc.embed(full_transaction) FROM full_transaction
-WHERE budget_id = sqlc.arg(budget_id)::text AND user_id = $1
+WHERE budget_id = sqlc.arg(budget_id)::text AND user_id = $1 AND deleted IS NULL
 ORDER BY date;
 
 -- name: GetBaseTransactionsBetweenDates :many
 SELECT * FROM transactions
-WHERE user_id = $1 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
 ORDER BY date
 LIMIT $2
 OFFSET $3;
 
 -- name: GetUnassignedTransactionsBetweenDates :many
 SELECT * FROM transactions
-WHERE user_id = $1 AND budget_id IS NULL AND budget_expense_id IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+WHERE transactions.user_id = $1 AND transactions.deleted IS NULL
+    AND (transactions.budget_id IS NULL OR transactions.budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted IS NOT NULL))
+    AND transactions.date >= sqlc.arg(start_date) AND transactions.date <= sqlc.arg(end_date)
 ORDER BY date
 LIMIT $2
 OFFSET $3;
 
 -- name: GetTransactionsBetweenDates :many
 SELECT sqlc.embed(full_transaction) FROM full_transaction
-WHERE user_id = $1 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
-ORDER BY date
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+ORDER BY date, id
 LIMIT $2
 OFFSET $3;
 
+-- name: GetAllTransactions :many
+SELECT sqlc.embed(full_transaction) FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL
+ORDER BY date, id;
+
 -- name: GetIncomeTransactionsBetweenDates :many
 SELECT sqlc.embed(full_transaction) FROM full_transaction
-WHERE user_id = $1 AND amount > 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
 ORDER BY date
 LIMIT $2
 OFFSET $3;
 
 -- name: GetExpenseTransactionsBetweenDates :many
 SELECT sqlc.embed(full_transaction) FROM full_transaction
-WHERE user_id = $1 AND amount < 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+WHERE user_id = $1 AND deleted IS NULL AND amount < 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
 ORDER BY date
 LIMIT $2
 OFFSET $3;
 
 -- name: GetTransactionAmountsBetweenDates :many
 SELECT amount FROM transactions
-WHERE user_id = $1 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
 
 -- name: GetIncomeTransactionAmountsBetweenDates :many
 SELECT amount, type FROM transactions
-WHERE user_id = $1 AND amount > 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
 
 -- name: GetExpenseTransactionAmountsBetweenDates :many
 SELECT amount, type FROM transactions
-WHERE user_id = $1 AND amount < 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
+WHERE user_id = $1 AND deleted IS NULL AND amount < 0 AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date);
+
+-- name: GetMonthlyTransactionAmountsBetweenDates :many
+SELECT
+    date_trunc('month', date)::timestamp AS month,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+GROUP BY month
+ORDER BY month;
 
--- name: DeleteTransaction :exec
-DELETE FROM transactions 
-WHERE id = $1 AND user_id = $2;
+-- name: GetMonthlyTypeTransactionAmountsBetweenDates :many
+SELECT
+    date_trunc('month', date)::timestamp AS month,
+    type,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+GROUP BY month, type
+ORDER BY month, type;
 
--- name: DeleteTransactionsByRecurringTransactionId :execrows
-DELETE FROM transactions 
-WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1;
+-- name: GetDailyTransactionAmounts :many
+SELECT
+    date_trunc('day', date)::timestamp AS day,
+    (CASE sqlc.arg(group_by)::text
+        WHEN 'payee' THEN description
+        WHEN 'budget' THEN COALESCE(budget_name, '')
+        ELSE type
+    END)::text AS key,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL AND date >= sqlc.arg(start_date) AND date <= sqlc.arg(end_date)
+GROUP BY day, key
+ORDER BY day, key;
 
--- name: DeleteTransactionsByRecurringTransactionIdAndWhereDate :execrows
-DELETE FROM transactions 
-WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND date > $2;
+-- name: GetDailyTagTransactionAmounts :many
+SELECT
+    date_trunc('day', t.date)::timestamp AS day,
+    COALESCE(tt.tag, '')::text AS key,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.amount > 0), 0)::text AS income,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.amount < 0), 0)::text AS expense
+FROM transactions t
+    LEFT OUTER JOIN transaction_tags tt ON tt.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL AND t.date >= sqlc.arg(start_date) AND t.date <= sqlc.arg(end_date)
+GROUP BY day, key
+ORDER BY day, key;
+
+-- name: DeleteTransaction :execrows
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND (sqlc.narg(version)::int IS NULL OR version = sqlc.narg(version)::int);
+
+-- name: RestoreTransaction :one
+UPDATE transactions
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteTransactionsByRecurringTransactionId :many
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND deleted IS NULL
+RETURNING *;
+
+-- name: DeleteTransactionsByRecurringTransactionIdAndWhereDate :many
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND date >= $2 AND deleted IS NULL
+RETURNING *;
 
 
 -- name: CreateRecurringTransaction :one
-INSERT INTO recurring_transactions (user_id, start_date, end_date, interval, days_interval)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO recurring_transactions (user_id, start_date, end_date, interval, days_interval, rrule, description, amount, type, materialized_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $2)
 RETURNING *;
 
 -- name: UpdateRecurringTransaction :exec
 UPDATE recurring_transactions 
-SET start_date = $3, end_date = $4, interval = $5, days_interval = $6, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2;
+SET start_date = $3, end_date = $4, interval = $5, days_interval = $6, rrule = $7, description = $8, amount = $9, type = $10, updated = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL;
+
+-- name: SetRecurringTransactionMaterializedUntil :exec
+UPDATE recurring_transactions
+SET materialized_until = $2
+WHERE id = $1;
+
+-- name: GetUnmaterializedRecurringTransactions :many
+SELECT * FROM recurring_transactions
+WHERE deleted IS NULL
+    AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+    AND materialized_until < sqlc.arg(until)::timestamp
+    AND (end_date IS NULL OR materialized_until < end_date)
+ORDER BY id;
 
 -- name: GetRecurringTransactionById :one
 SELECT * FROM recurring_transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1;
+
+-- name: GetDeletedRecurringTransactionById :one
+SELECT * FROM recurring_transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL
+LIMIT 1;
+
+-- name: DeleteRecurringTransaction :one
+UPDATE recurring_transactions
+SET deleted = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+RETURNING deleted;
+
+-- name: DeleteTransactionsByRecurringTransactionIdSoft :many
+UPDATE transactions
+SET deleted = sqlc.arg(deleted)::timestamp, version = version + 1
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND deleted IS NULL
+RETURNING *;
+
+-- name: RestoreRecurringTransaction :one
+UPDATE recurring_transactions
+SET deleted = NULL, updated = (now() at time zone 'utc')
 WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RestoreTransactionsByRecurringTransactionId :many
+UPDATE transactions
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND deleted = sqlc.arg(deleted)::timestamp
+RETURNING *;
+
+
+-- name: GetDeletedTransactions :many
+SELECT sqlc.embed(full_transaction) FROM full_transaction
+WHERE full_transaction.user_id = $1 AND full_transaction.deleted IS NOT NULL
+    AND (full_transaction.recurring_deleted IS NULL OR full_transaction.id = (SELECT MIN(t.id) FROM transactions t WHERE t.recurring_transaction_id = full_transaction.recurring_transaction_id))
+ORDER BY full_transaction.deleted DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: PurgeDeletedTransactions :many
+DELETE FROM transactions
+WHERE deleted < sqlc.arg(before)::timestamp
+RETURNING *;
+
+-- name: PurgeDeletedRecurringTransactions :many
+DELETE FROM recurring_transactions
+WHERE deleted < sqlc.arg(before)::timestamp
+RETURNING *;
+
+
+-- name: GetTransactionIdsByIds :many
+SELECT id FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND id = ANY(sqlc.arg(ids)::int[])
+ORDER BY id;
+
+-- name: GetTransactionIdsByFilter :many
+SELECT t.id FROM transactions t
+WHERE t.user_id = $1 AND t.deleted IS NULL
+    AND (sqlc.narg(start_date)::timestamp IS NULL OR t.date >= sqlc.narg(start_date)::timestamp)
+    AND (sqlc.narg(end_date)::timestamp IS NULL OR t.date <= sqlc.narg(end_date)::timestamp)
+    AND (sqlc.narg(type)::text IS NULL OR t.type = sqlc.narg(type)::text)
+    AND (sqlc.narg(description)::text IS NULL OR t.description ILIKE '%' || sqlc.narg(description)::text || '%')
+    AND (sqlc.narg(budget_id)::text IS NULL OR t.budget_id = sqlc.narg(budget_id)::text)
+    AND (sqlc.narg(income)::boolean IS NULL OR (t.amount > 0) = sqlc.narg(income)::boolean)
+    AND (sqlc.narg(tag)::text IS NULL OR EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = sqlc.narg(tag)::text))
+    AND t.id > sqlc.arg(after_id)::int
+ORDER BY t.id
+LIMIT $2;
+
+-- name: BulkUpdateTransactionType :execrows
+UPDATE transactions
+SET type = sqlc.arg(type)::text, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: BulkUpdateTransactionBudgetId :execrows
+UPDATE transactions
+SET budget_id = sqlc.arg(budget_id)::text, budget_expense_id = sqlc.arg(budget_expense_id)::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE transactions.user_id = $1 AND transactions.id = ANY(sqlc.arg(ids)::int[])
+    AND EXISTS (
+        SELECT 1 FROM budget_expenses be JOIN budgets b ON be.budget_id = b.id
+        WHERE be.id = sqlc.arg(budget_expense_id)::int AND b.id = sqlc.arg(budget_id)::text AND b.user_id = $1 AND b.deleted IS NULL
+    );
+
+-- name: BulkRemoveTransactionBudgetId :execrows
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: BulkDeleteTransactions :execrows
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY(sqlc.arg(ids)::int[]) AND deleted IS NULL;
+
+
+-- name: AddTransactionTags :one
+WITH added AS (
+    INSERT INTO transaction_tags (transaction_id, tag)
+    SELECT t.id, tag FROM transactions t, unnest(sqlc.arg(tags)::text[]) AS tag
+    WHERE t.user_id = $1 AND t.id = ANY(sqlc.arg(ids)::int[])
+    ON CONFLICT DO NOTHING
+    RETURNING transaction_id
+)
+SELECT count(DISTINCT transaction_id) FROM added;
+
+-- name: RemoveTransactionTags :one
+WITH removed AS (
+    DELETE FROM transaction_tags tt
+    USING transactions t
+    WHERE tt.transaction_id = t.id AND t.user_id = $1 AND t.id = ANY(sqlc.arg(ids)::int[]) AND tt.tag = ANY(sqlc.arg(tags)::text[])
+    RETURNING tt.transaction_id
+)
+SELECT count(DISTINCT transaction_id) FROM removed;
+
+-- name: GetTags :many
+SELECT DISTINCT tt.tag FROM transaction_tags tt JOIN transactions t ON tt.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL
+ORDER BY tt.tag;
+
+-- name: GetTransactionByRecurringTransactionIdAndDate :one
+SELECT * FROM transactions
+WHERE recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND user_id = $1 AND date = $2
+LIMIT 1;
+
+-- name: EndRecurringTransaction :exec
+UPDATE recurring_transactions
+SET end_date = sqlc.arg(end_date)::timestamp, materialized_until = LEAST(materialized_until, sqlc.arg(end_date)::timestamp), updated = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL;
+
+-- name: CreateRecurringOccurrence :execrows
+INSERT INTO transactions (user_id, recurring_transaction_id, amount, description, type, date)
+SELECT sqlc.arg(user_id)::uuid, sqlc.arg(recurring_transaction_id)::int, CAST(sqlc.arg(amount)::text AS DECIMAL(19, 4)), sqlc.arg(description)::text, sqlc.arg(type)::text, sqlc.arg(date)::timestamp
+WHERE NOT EXISTS (
+    SELECT 1 FROM recurring_transaction_exceptions e
+    WHERE e.recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int AND e.date = sqlc.arg(date)::timestamp
+);
+
+-- name: GetRecurringTransactionForUpdate :one
+SELECT * FROM recurring_transactions
+WHERE id = $1 AND deleted IS NULL
+FOR UPDATE;
+
+-- name: GetRecurringTransactionExceptionsByIds :many
+SELECT * FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = ANY(sqlc.arg(ids)::int[])
+ORDER BY recurring_transaction_id, date;
+
+-- name: CreateRecurringTransactionException :exec
+INSERT INTO recurring_transaction_exceptions (recurring_transaction_id, date, transaction_id)
+SELECT sqlc.arg(recurring_transaction_id)::int, sqlc.arg(date)::timestamp, sqlc.arg(transaction_id)::int
+WHERE NOT EXISTS (SELECT 1 FROM recurring_transaction_exceptions e WHERE e.transaction_id = sqlc.arg(transaction_id)::int)
+ON CONFLICT (recurring_transaction_id, date) DO NOTHING;
+
+-- name: GetRecurringTransactionExceptionOnDay :one
+SELECT * FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1 AND date >= sqlc.arg(day)::timestamp AND date < sqlc.arg(day)::timestamp + INTERVAL '1 day'
 LIMIT 1;
 
--- name: DeleteRecurringTransaction :exec
-DELETE FROM recurring_transactions 
-WHERE id = $1 AND user_id = $2;
+-- name: GetRecurringTransactionExceptions :many
+SELECT * FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1
+ORDER BY date;
+
+-- name: DeleteRecurringTransactionExceptions :exec
+DELETE FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1 AND date >= sqlc.arg(from_date)::timestamp;
+
+-- name: GetNonRecurringTransactionsSince :many
+SELECT * FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND recurring_transaction_id IS NULL AND date >= sqlc.arg(since)::timestamp
+ORDER BY date;
+
+-- name: SetTransactionsRecurringTransactionId :many
+UPDATE transactions
+SET recurring_transaction_id = sqlc.arg(recurring_transaction_id)::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY(sqlc.arg(ids)::int[]) AND recurring_transaction_id IS NULL AND deleted IS NULL
+RETURNING *;
+
+-- name: GetBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::text AS balance FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date < sqlc.arg(before)::timestamp;
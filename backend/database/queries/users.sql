@@ -14,3 +14,9 @@ SELECT * FROM users WHERE provider = $1 AND provider_id = $2;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1;
+
+-- name: UpdateUserSettings :one
+UPDATE users
+SET week_start = $2, fiscal_year_start = $3, updated = (now() at time zone 'utc')
+WHERE id = $1
+RETURNING *;
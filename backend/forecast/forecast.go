@@ -0,0 +1,224 @@
+// Package forecast projects balances forward from scheduled transactions and
+// an estimate of variable spending per category.
+package forecast
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// The optimistic and pessimistic bands use these quantiles of the monthly
+	// totals per category
+	lowerQuantile = 0.25
+	upperQuantile = 0.75
+)
+
+// Transaction is a scheduled or past transaction.
+type Transaction struct {
+	Date     time.Time
+	Amount   float64
+	Category string
+}
+
+type Params struct {
+	// Start is the last day with a known balance, usually today
+	Start time.Time
+	End   time.Time
+	// Balance is the balance at the end of Start
+	Balance float64
+	// Scheduled are the transactions after Start that are known in advance,
+	// such as occurrences of recurring series
+	Scheduled []Transaction
+	// History are the variable transactions from the first day of the month
+	// HistoryMonths months before the month of Start, up to and including
+	// Start
+	History       []Transaction
+	HistoryMonths int
+}
+
+// Estimate is the variable amount of a category per month.
+type Estimate struct {
+	Category    string
+	Optimistic  float64
+	Expected    float64
+	Pessimistic float64
+}
+
+// Point is the projected balance at the end of a day.
+type Point struct {
+	Date        time.Time
+	Optimistic  float64
+	Expected    float64
+	Pessimistic float64
+}
+
+type Forecast struct {
+	Estimates []Estimate
+	Points    []Point
+}
+
+// Project estimates the variable amount per category as the median of its
+// monthly totals over the history, with the quartiles as the optimistic and
+// pessimistic bands. The estimates are spread evenly over the days of a
+// month, and in the month of Start only the part that was not spent yet is
+// spread over the remaining days.
+func Project(params Params) Forecast {
+	start := truncateDay(params.Start)
+	end := truncateDay(params.End)
+
+	estimates := estimate(params.History, start, params.HistoryMonths)
+	remaining := remainingThisMonth(params.History, start, estimates)
+
+	scheduled := make(map[time.Time]float64)
+	for _, transaction := range params.Scheduled {
+		scheduled[truncateDay(transaction.Date)] += transaction.Amount
+	}
+
+	point := Point{
+		Date:        start,
+		Optimistic:  params.Balance,
+		Expected:    params.Balance,
+		Pessimistic: params.Balance,
+	}
+	points := []Point{point}
+
+	monthEnd := endOfMonth(start)
+	daysLeft := float64(monthEnd.Sub(start).Hours() / 24)
+
+	for date := start.AddDate(0, 0, 1); !date.After(end); date = date.AddDate(0, 0, 1) {
+		point.Date = date
+		point.Optimistic += scheduled[date]
+		point.Expected += scheduled[date]
+		point.Pessimistic += scheduled[date]
+
+		if !date.After(monthEnd) {
+			point.Optimistic += remaining.Optimistic / daysLeft
+			point.Expected += remaining.Expected / daysLeft
+			point.Pessimistic += remaining.Pessimistic / daysLeft
+		} else {
+			days := float64(endOfMonth(date).Day())
+			for _, estimate := range estimates {
+				point.Optimistic += estimate.Optimistic / days
+				point.Expected += estimate.Expected / days
+				point.Pessimistic += estimate.Pessimistic / days
+			}
+		}
+
+		points = append(points, Point{
+			Date:        date,
+			Optimistic:  round(point.Optimistic),
+			Expected:    round(point.Expected),
+			Pessimistic: round(point.Pessimistic),
+		})
+	}
+
+	return Forecast{
+		Estimates: estimates,
+		Points:    points,
+	}
+}
+
+// estimate calculates the monthly estimates from the full months before the
+// month of start. Months without transactions in a category count as zero.
+func estimate(history []Transaction, start time.Time, months int) []Estimate {
+	if months < 1 {
+		return nil
+	}
+
+	firstMonth := startOfMonth(start).AddDate(0, -months, 0)
+	totals := make(map[string][]float64)
+	for _, transaction := range history {
+		month := monthsBetween(firstMonth, transaction.Date)
+		if month < 0 || month >= months {
+			continue
+		}
+		if _, ok := totals[transaction.Category]; !ok {
+			totals[transaction.Category] = make([]float64, months)
+		}
+		totals[transaction.Category][month] += transaction.Amount
+	}
+
+	estimates := make([]Estimate, 0, len(totals))
+	for category, amounts := range totals {
+		slices.Sort(amounts)
+		estimates = append(estimates, Estimate{
+			Category:    category,
+			Optimistic:  round(quantile(amounts, upperQuantile)),
+			Expected:    round(quantile(amounts, 0.5)),
+			Pessimistic: round(quantile(amounts, lowerQuantile)),
+		})
+	}
+
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Category < estimates[j].Category
+	})
+
+	return estimates
+}
+
+// remainingThisMonth returns what is left of the estimates in the month of
+// start, given what was already spent or received. A category that already
+// went past its estimate adds nothing more.
+func remainingThisMonth(history []Transaction, start time.Time, estimates []Estimate) Estimate {
+	monthStart := startOfMonth(start)
+	actual := make(map[string]float64)
+	for _, transaction := range history {
+		if transaction.Date.Before(monthStart) || transaction.Date.After(start) {
+			continue
+		}
+		actual[transaction.Category] += transaction.Amount
+	}
+
+	var result Estimate
+	for _, estimate := range estimates {
+		result.Optimistic += remainder(estimate.Optimistic, actual[estimate.Category])
+		result.Expected += remainder(estimate.Expected, actual[estimate.Category])
+		result.Pessimistic += remainder(estimate.Pessimistic, actual[estimate.Category])
+	}
+
+	return result
+}
+
+func remainder(estimate float64, actual float64) float64 {
+	remaining := estimate - actual
+	if remaining*estimate <= 0 {
+		return 0
+	}
+	return remaining
+}
+
+// quantile interpolates linearly between the closest ranks of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func monthsBetween(from time.Time, date time.Time) int {
+	return (date.Year()-from.Year())*12 + int(date.Month()) - int(from.Month())
+}
+
+func truncateDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func endOfMonth(date time.Time) time.Time {
+	return startOfMonth(date).AddDate(0, 1, -1)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
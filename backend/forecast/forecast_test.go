@@ -0,0 +1,63 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProject(t *testing.T) {
+	var history []Transaction
+	// Groceries cost 300, 400, 500 and 600 in the four months before May
+	for idx, amount := range []float64{-300, -400, -500, -600} {
+		history = append(history, Transaction{
+			Date:     date(2024, time.January+time.Month(idx), 10),
+			Amount:   amount,
+			Category: "Groceries",
+		})
+	}
+	// 100 of the groceries of May are already spent
+	history = append(history, Transaction{Date: date(2024, time.May, 5), Amount: -100, Category: "Groceries"})
+
+	forecast := Project(Params{
+		Start:         date(2024, time.May, 21),
+		End:           date(2024, time.June, 30),
+		Balance:       1000,
+		HistoryMonths: 4,
+		History:       history,
+		Scheduled: []Transaction{
+			{Date: date(2024, time.May, 25), Amount: 2000, Category: "Salary"},
+			{Date: date(2024, time.June, 1), Amount: -800, Category: "Rent"},
+		},
+	})
+
+	if len(forecast.Estimates) != 1 {
+		t.Fatalf("got %d estimates, want 1", len(forecast.Estimates))
+	}
+	estimate := forecast.Estimates[0]
+	if estimate.Expected != -450 || estimate.Optimistic != -375 || estimate.Pessimistic != -525 {
+		t.Fatalf("unexpected estimate %+v", estimate)
+	}
+
+	if len(forecast.Points) != 41 {
+		t.Fatalf("got %d points, want 41", len(forecast.Points))
+	}
+
+	endOfMay := forecast.Points[10]
+	if !endOfMay.Date.Equal(date(2024, time.May, 31)) {
+		t.Fatalf("got %s, want 2024-05-31", endOfMay.Date)
+	}
+	// 1000 + 2000 salary - (450 - 100) remaining groceries
+	if endOfMay.Expected != 2650 {
+		t.Fatalf("got expected balance %v at the end of May, want 2650", endOfMay.Expected)
+	}
+
+	endOfJune := forecast.Points[40]
+	// Rent and a full month of groceries
+	if endOfJune.Expected != 1400 || endOfJune.Optimistic != 1550 || endOfJune.Pessimistic != 1250 {
+		t.Fatalf("unexpected balance at the end of June %+v", endOfJune)
+	}
+}
@@ -9,6 +9,8 @@ require (
 	github.com/labstack/echo/v4 v4.12.0
 	github.com/labstack/gommon v0.4.2
 	github.com/lib/pq v1.10.9
+	github.com/sqlc-dev/pqtype v0.3.0
+	github.com/teambition/rrule-go v1.8.2
 	golang.org/x/oauth2 v0.21.0
 )
 
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleGetAuditLog(c echo.Context) error {
+	userId := getUserId(c)
+	entityType := c.Param("entity")
+	if !slices.Contains(repository.AuditEntities, entityType) {
+		return c.String(http.StatusBadRequest, "Invalid entity type")
+	}
+	entityId := c.Param("id")
+	if entityId == "" {
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	auditLogs, err := h.AuditRepository.GetForEntity(c.Request().Context(), userId, entityType, entityId)
+	if err != nil {
+		log.Errorf("Error getting audit log from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToAuditLogReturns(auditLogs))
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
@@ -11,6 +16,10 @@ import (
 	"github.com/tvgelderen/fiscora/types"
 )
 
+// maxBudgetThreshold is the highest percentage of a budget expense an alert
+// can be set at
+const maxBudgetThreshold = 1000
+
 func (h *APIHandler) HandleGetBudgets(c echo.Context) error {
 	userId := getUserId(c)
 	budgets, err := h.BudgetRepository.Get(c.Request().Context(), userId)
@@ -49,6 +58,7 @@ func (h *APIHandler) HandleGetBudget(c echo.Context) error {
 	if budget.UserID != userId {
 		return c.NoContent(http.StatusForbidden)
 	}
+	setETag(c, budget.Version)
 
 	transactions, err := h.TransactionRepository.GetByBudgetId(c.Request().Context(), userId, budgetId)
 	if err != nil {
@@ -61,6 +71,29 @@ func (h *APIHandler) HandleGetBudget(c echo.Context) error {
 		returnBudget.Transactions = types.ToTransactionReturns(transactions)
 	}
 
+	matched, err := h.BudgetRepository.GetMatchedTransactions(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting matched budget transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	if len(*matched) != 0 {
+		matchedTransactions := types.ToBudgetMatchedTransactionReturns(matched, budget.ID, budget.Name)
+		if returnBudget.Transactions != nil {
+			matchedTransactions = append(*returnBudget.Transactions, matchedTransactions...)
+			sort.SliceStable(matchedTransactions, func(i, j int) bool {
+				return matchedTransactions[i].Date.Before(matchedTransactions[j].Date)
+			})
+		}
+		returnBudget.Transactions = &matchedTransactions
+	}
+
+	unmatched, err := h.BudgetRepository.GetUnmatchedTransactions(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting unmatched budget transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	returnBudget.UnmatchedTransactions = types.ToTransactionReturns(unmatched)
+
 	return c.JSON(http.StatusOK, returnBudget)
 }
 
@@ -73,6 +106,10 @@ func (h *APIHandler) HandleCreateBudget(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "Error decoding request body")
 	}
 
+	if !normalizeBudgetForm(&budgetForm) {
+		return c.String(http.StatusBadRequest, "Invalid budget type, rollover, category or threshold")
+	}
+
 	userId := getUserId(c)
 	budgetId := generateRandomString(16)
 
@@ -84,31 +121,39 @@ func (h *APIHandler) HandleCreateBudget(c echo.Context) error {
 		Amount:      strconv.FormatFloat(budgetForm.Amount, 'f', -1, 64),
 		StartDate:   budgetForm.StartDate,
 		EndDate:     budgetForm.EndDate,
+		Type:        budgetForm.Type,
+		ZeroBased:   budgetForm.ZeroBased,
 	})
 	if err != nil {
 		log.Errorf("Error creating budget: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
-	expenses := make([]repository.BudgetExpense, len(budgetForm.Expenses))
-	for idx, expense := range budgetForm.Expenses {
-		budgetExpense, err := h.BudgetRepository.AddExpense(c.Request().Context(), repository.CreateBudgetExpenseParams{
+	for _, expense := range budgetForm.Expenses {
+		_, err := h.BudgetRepository.AddExpense(c.Request().Context(), repository.CreateBudgetExpenseParams{
 			BudgetID:        budgetId,
 			Name:            expense.Name,
 			AllocatedAmount: strconv.FormatFloat(expense.AllocatedAmount, 'f', -1, 64),
+			Rollover:        expense.Rollover,
+			Categories:      expense.Categories,
+			Tags:            expense.Tags,
+			Payees:          expense.Payees,
+			Thresholds:      expense.Thresholds,
 		})
 		if err != nil {
 			log.Errorf("Error creating budget expense: %v", err.Error())
 			return c.String(http.StatusInternalServerError, "Something went wrong")
 		}
+	}
 
-		expenses[idx] = *budgetExpense
+	budgetWithExpenses, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budget.ID)
+	if err != nil {
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
+	setETag(c, budgetWithExpenses.Version)
 
-	returnBudget := types.ToBudgetReturn(&repository.BudgetWithExpenses{
-		Budget:   *budget,
-		Expenses: expenses,
-	})
+	returnBudget := types.ToBudgetReturn(budgetWithExpenses)
 
 	return c.JSON(http.StatusCreated, returnBudget)
 }
@@ -121,13 +166,21 @@ func (h *APIHandler) HandleUpdateBudget(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "Error decoding request body")
 	}
 
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
 	decoder := json.NewDecoder(c.Request().Body)
 	budgetForm := types.BudgetForm{}
-	err := decoder.Decode(&budgetForm)
+	err = decoder.Decode(&budgetForm)
 	if err != nil {
 		log.Errorf("Error decoding request body: %v", err.Error())
 		return c.String(http.StatusBadRequest, "Error decoding request body")
 	}
+	if !normalizeBudgetForm(&budgetForm) {
+		return c.String(http.StatusBadRequest, "Invalid budget type, rollover, category or threshold")
+	}
 
 	err = h.BudgetRepository.Update(c.Request().Context(), repository.UpdateBudgetParams{
 		ID:          budgetId,
@@ -137,11 +190,17 @@ func (h *APIHandler) HandleUpdateBudget(c echo.Context) error {
 		Amount:      strconv.FormatFloat(budgetForm.Amount, 'f', -1, 64),
 		StartDate:   budgetForm.StartDate,
 		EndDate:     budgetForm.EndDate,
+		Type:        budgetForm.Type,
+		ZeroBased:   budgetForm.ZeroBased,
+		Version:     version,
 	})
 	if err != nil {
 		if repository.NoRowsFound(err) {
 			return c.NoContent(http.StatusNotFound)
 		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Budget was changed by someone else")
+		}
 		log.Errorf("Error updating budget: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
@@ -161,6 +220,11 @@ func (h *APIHandler) HandleUpdateBudget(c echo.Context) error {
 				BudgetID:        budgetId,
 				Name:            expense.Name,
 				AllocatedAmount: strconv.FormatFloat(expense.AllocatedAmount, 'f', -1, 64),
+				Rollover:        expense.Rollover,
+				Categories:      expense.Categories,
+				Tags:            expense.Tags,
+				Payees:          expense.Payees,
+				Thresholds:      expense.Thresholds,
 			})
 			if err != nil {
 				log.Errorf("Error creating budget expense: %v", err.Error())
@@ -172,13 +236,24 @@ func (h *APIHandler) HandleUpdateBudget(c echo.Context) error {
 		for _, budgetExpense := range *budgetExpenses {
 			allocatedAmount := strconv.FormatFloat(expense.AllocatedAmount, 'f', -1, 64)
 			if expense.ID == budgetExpense.ID &&
-				(expense.Name != budgetExpense.Name || allocatedAmount != budgetExpense.AllocatedAmount) {
+				(expense.Name != budgetExpense.Name || allocatedAmount != budgetExpense.AllocatedAmount || expense.Rollover != budgetExpense.Rollover ||
+					!slices.Equal(expense.Categories, budgetExpense.Categories) || !slices.Equal(expense.Tags, budgetExpense.Tags) || !slices.Equal(expense.Payees, budgetExpense.Payees) ||
+					!slices.Equal(expense.Thresholds, budgetExpense.Thresholds)) {
 				err := h.BudgetRepository.UpdateExpense(c.Request().Context(), repository.UpdateBudgetExpenseParams{
 					ID:              expense.ID,
 					Name:            expense.Name,
 					AllocatedAmount: allocatedAmount,
+					Rollover:        expense.Rollover,
+					Categories:      expense.Categories,
+					Tags:            expense.Tags,
+					Payees:          expense.Payees,
+					Thresholds:      expense.Thresholds,
+					Version:         sql.NullInt32{Int32: expense.Version, Valid: expense.Version > 0},
 				})
 				if err != nil {
+					if errors.Is(err, repository.ErrVersionConflict) {
+						return c.String(http.StatusPreconditionFailed, "Budget expense was changed by someone else")
+					}
 					log.Errorf("Error updating budget expense: %v", err.Error())
 					return c.String(http.StatusInternalServerError, "Something went wrong")
 				}
@@ -187,12 +262,45 @@ func (h *APIHandler) HandleUpdateBudget(c echo.Context) error {
 	}
 
 	budget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, budget.Version)
 
 	returnBudget := types.ToBudgetReturn(budget)
 
 	return c.JSON(http.StatusCreated, returnBudget)
 }
 
+// HandleGetBudgetPeriods returns the performance of a budget in each of its
+// periods, ending with the current one.
+func (h *APIHandler) HandleGetBudgetPeriods(c echo.Context) error {
+	userId := getUserId(c)
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	budget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	periods, err := h.BudgetRepository.GetPeriods(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting budget periods from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToBudgetPeriodReturns(periods, budget))
+}
+
 func (h *APIHandler) HandleAddBudgetTransactions(c echo.Context) error {
 	userId := getUserId(c)
 	budgetId := c.Param("id")
@@ -217,20 +325,37 @@ func (h *APIHandler) HandleAddBudgetTransactions(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "Error decoding request body")
 	}
 
-	for _, transactionId := range transactionIds {
-		err := h.TransactionRepository.UpdateBudgetId(c.Request().Context(), repository.UpdateTransactionBudgetIdParams{
-			UserID:          userId,
-			ID:              transactionId,
-			BudgetID:        budgetId,
-			BudgetExpenseID: int32(budgetExpenseId),
-		})
-		if err != nil {
-			if repository.NoRowsFound(err) {
-				return c.NoContent(http.StatusNotFound)
-			}
-			log.Errorf("Error updating budget id for transaction: %v", err.Error())
-			return c.String(http.StatusInternalServerError, "Something went wrong")
+	budget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	if !slices.ContainsFunc(budget.Expenses, func(expense repository.FullBudgetExpense) bool {
+		return expense.ID == int32(budgetExpenseId)
+	}) {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	_, err = h.TransactionRepository.Bulk(c.Request().Context(), repository.BulkParams{
+		UserID:          userId,
+		Operation:       repository.BulkOperationAssignBudget,
+		Ids:             transactionIds,
+		BudgetID:        budgetId,
+		BudgetExpenseID: int32(budgetExpenseId),
+		RequireAll:      true,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrBulkNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusConflict, "Transactions were changed by someone else")
 		}
+		log.Errorf("Error updating budget id for transactions: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
 	transactions, err := h.TransactionRepository.GetByBudgetId(c.Request().Context(), userId, budgetId)
@@ -242,6 +367,56 @@ func (h *APIHandler) HandleAddBudgetTransactions(c echo.Context) error {
 	return c.JSON(http.StatusOK, types.ToTransactionReturns(transactions))
 }
 
+// HandleExcludeBudgetTransactions stops transactions from counting against
+// the budget through the categories, tags and payees of its expenses.
+func (h *APIHandler) HandleExcludeBudgetTransactions(c echo.Context) error {
+	return h.setBudgetTransactionsExcluded(c, true)
+}
+
+// HandleIncludeBudgetTransactions lets excluded transactions count against the
+// budget again.
+func (h *APIHandler) HandleIncludeBudgetTransactions(c echo.Context) error {
+	return h.setBudgetTransactionsExcluded(c, false)
+}
+
+func (h *APIHandler) setBudgetTransactionsExcluded(c echo.Context, excluded bool) error {
+	userId := getUserId(c)
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	var transactionIds []int32
+	err := decoder.Decode(&transactionIds)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	if excluded {
+		_, err = h.BudgetRepository.ExcludeTransactions(c.Request().Context(), userId, budgetId, transactionIds)
+	} else {
+		_, err = h.BudgetRepository.IncludeTransactions(c.Request().Context(), userId, budgetId, transactionIds)
+	}
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error updating excluded budget transactions: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	budget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToBudgetReturn(budget))
+}
+
 func (h *APIHandler) HandleDeleteBudget(c echo.Context) error {
 	userId := getUserId(c)
 	budgetId := c.Param("id")
@@ -250,11 +425,19 @@ func (h *APIHandler) HandleDeleteBudget(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "Invalid url parameter")
 	}
 
-	err := h.BudgetRepository.Remove(c.Request().Context(), userId, budgetId)
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	err = h.BudgetRepository.Remove(c.Request().Context(), userId, budgetId, version)
 	if err != nil {
 		if repository.NoRowsFound(err) {
 			return c.NoContent(http.StatusNotFound)
 		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Budget was changed by someone else")
+		}
 		log.Errorf("Error deleting budget: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
@@ -277,20 +460,83 @@ func (h *APIHandler) HandleDeleteBudgetExpense(c echo.Context) error {
 	if budgetExpenseId == -1 {
 		return c.NoContent(http.StatusOK)
 	}
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
 
 	dbBudget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
 	if dbBudget.UserID != userId {
 		return c.NoContent(http.StatusForbidden)
 	}
 
-	err = h.BudgetRepository.RemoveExpense(c.Request().Context(), int32(budgetExpenseId), budgetId)
+	err = h.BudgetRepository.RemoveExpense(c.Request().Context(), int32(budgetExpenseId), budgetId, version)
 	if err != nil {
 		if repository.NoRowsFound(err) {
 			return c.NoContent(http.StatusNotFound)
 		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Budget expense was changed by someone else")
+		}
 		log.Errorf("Error deleting budget expense: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
 	return c.String(http.StatusOK, "Budget deleted successfully")
 }
+
+// normalizeBudgetForm defaults the type of a budget to custom and the
+// rollover of its expenses to reset, cleans up the tags, payees and thresholds
+// of the expenses, and sets the end of a periodic budget to the end of its
+// first period. It reports whether the type, rollovers, categories and
+// thresholds are valid.
+func normalizeBudgetForm(budgetForm *types.BudgetForm) bool {
+	if budgetForm.Type == "" {
+		budgetForm.Type = types.BudgetTypeCustom
+	}
+	if !slices.Contains(types.BudgetTypes, budgetForm.Type) {
+		return false
+	}
+
+	for idx := range budgetForm.Expenses {
+		if budgetForm.Expenses[idx].Rollover == "" {
+			budgetForm.Expenses[idx].Rollover = repository.BudgetRolloverReset
+		}
+		if !slices.Contains(repository.BudgetRollovers, budgetForm.Expenses[idx].Rollover) {
+			return false
+		}
+		for _, category := range budgetForm.Expenses[idx].Categories {
+			if !slices.Contains(repository.ExpenseTypes, category) && !slices.Contains(repository.IncomeTypes, category) {
+				return false
+			}
+		}
+		budgetForm.Expenses[idx].Tags = normalizeMatchers(budgetForm.Expenses[idx].Tags)
+		budgetForm.Expenses[idx].Payees = normalizeMatchers(budgetForm.Expenses[idx].Payees)
+
+		thresholds := budgetForm.Expenses[idx].Thresholds
+		for _, threshold := range thresholds {
+			if threshold < 1 || threshold > maxBudgetThreshold {
+				return false
+			}
+		}
+		slices.Sort(thresholds)
+		budgetForm.Expenses[idx].Thresholds = slices.Compact(thresholds)
+	}
+
+	budgetForm.EndDate = repository.BudgetPeriodEnd(budgetForm.Type, budgetForm.StartDate, budgetForm.EndDate)
+	return true
+}
+
+// normalizeMatchers trims the values an expense matches on and drops empty
+// values, which would match everything.
+func normalizeMatchers(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// HandleGetEnvelopes returns the envelopes of a zero-based budget and what is
+// left to be budgeted.
+func (h *APIHandler) HandleGetEnvelopes(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	summary, err := h.BudgetRepository.GetEnvelopes(c.Request().Context(), getUserId(c), budgetId)
+	if err != nil {
+		return envelopeError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, types.ToEnvelopeSummaryReturn(summary))
+}
+
+// HandleGetEnvelopeHistory returns the ledger of a zero-based budget with the
+// balances after each entry.
+func (h *APIHandler) HandleGetEnvelopeHistory(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	entries, err := h.BudgetRepository.GetEnvelopeHistory(c.Request().Context(), getUserId(c), budgetId)
+	if err != nil {
+		return envelopeError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, types.ToEnvelopeLedgerEntryReturns(entries))
+}
+
+func (h *APIHandler) HandleAssignEnvelope(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	form := types.EnvelopeAssignForm{}
+	err := decoder.Decode(&form)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	return h.moveEnvelopeMoney(c, repository.MoveEnvelopeMoneyParams{
+		UserID:      getUserId(c),
+		BudgetID:    budgetId,
+		ToExpenseID: sql.NullInt32{Int32: form.ExpenseID, Valid: true},
+		Amount:      form.Amount,
+		Description: form.Description,
+	})
+}
+
+func (h *APIHandler) HandleMoveEnvelope(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	form := types.EnvelopeMoveForm{}
+	err := decoder.Decode(&form)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+	if !form.FromExpenseID.Valid {
+		return c.String(http.StatusBadRequest, "Missing envelope to move from")
+	}
+	if form.ToExpenseID.Valid && form.ToExpenseID.Int32 == form.FromExpenseID.Int32 {
+		return c.String(http.StatusBadRequest, "Cannot move money to the same envelope")
+	}
+
+	return h.moveEnvelopeMoney(c, repository.MoveEnvelopeMoneyParams{
+		UserID:        getUserId(c),
+		BudgetID:      budgetId,
+		FromExpenseID: form.FromExpenseID.NullInt32,
+		ToExpenseID:   form.ToExpenseID.NullInt32,
+		Amount:        form.Amount,
+		Description:   form.Description,
+	})
+}
+
+// moveEnvelopeMoney records the movement and returns the envelopes as they are
+// after it.
+func (h *APIHandler) moveEnvelopeMoney(c echo.Context, params repository.MoveEnvelopeMoneyParams) error {
+	if params.Amount <= 0 {
+		return c.String(http.StatusBadRequest, "Amount must be positive")
+	}
+
+	_, err := h.BudgetRepository.MoveEnvelopeMoney(c.Request().Context(), params)
+	if err != nil {
+		return envelopeError(c, err)
+	}
+
+	summary, err := h.BudgetRepository.GetEnvelopes(c.Request().Context(), params.UserID, params.BudgetID)
+	if err != nil {
+		return envelopeError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, types.ToEnvelopeSummaryReturn(summary))
+}
+
+func envelopeError(c echo.Context, err error) error {
+	if repository.NoRowsFound(err) {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if errors.Is(err, repository.ErrNotZeroBased) {
+		return c.String(http.StatusConflict, "Budget is not zero-based")
+	}
+	if errors.Is(err, repository.ErrUnknownEnvelope) {
+		return c.String(http.StatusBadRequest, "Envelope does not belong to the budget")
+	}
+	log.Errorf("Error handling budget envelopes: %v", err.Error())
+	return c.String(http.StatusInternalServerError, "Something went wrong")
+}
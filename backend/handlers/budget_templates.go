@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleGetBudgetTemplates(c echo.Context) error {
+	templates, err := h.BudgetRepository.GetTemplates(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting budget templates from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToBudgetTemplateReturns(templates))
+}
+
+// HandleCreateBudgetTemplate saves the structure of a budget as a template.
+func (h *APIHandler) HandleCreateBudgetTemplate(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	templateForm := types.BudgetTemplateForm{}
+	err := decoder.Decode(&templateForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	template, err := h.BudgetRepository.AddTemplate(c.Request().Context(), getUserId(c), budgetId, templateForm.Name)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error creating budget template: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusCreated, types.ToBudgetTemplateReturn(template))
+}
+
+func (h *APIHandler) HandleDeleteBudgetTemplate(c echo.Context) error {
+	templateId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing budget template id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	err = h.BudgetRepository.RemoveTemplate(c.Request().Context(), getUserId(c), int32(templateId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error deleting budget template: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.String(http.StatusOK, "Budget template deleted successfully")
+}
+
+// HandleCreateBudgetFromTemplate creates a budget for new dates from a
+// template. A custom budget needs both dates, a periodic budget only the
+// start of its first period.
+func (h *APIHandler) HandleCreateBudgetFromTemplate(c echo.Context) error {
+	templateId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing budget template id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	copyForm, ok := decodeBudgetCopyForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+	if !copyForm.StartDate.Valid {
+		return c.String(http.StatusBadRequest, "Missing start date")
+	}
+
+	userId := getUserId(c)
+	budget, err := h.BudgetRepository.CreateFromTemplate(c.Request().Context(), repository.CreateFromTemplateParams{
+		UserID:     userId,
+		TemplateID: int32(templateId),
+		BudgetID:   generateRandomString(16),
+		Name:       copyForm.Name,
+		StartDate:  copyForm.StartDate.Time,
+		EndDate:    copyForm.EndDate.Time,
+		Percentage: copyForm.Percentage,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrInvalidBudgetDates) {
+			return c.String(http.StatusBadRequest, "Budget ends before it starts")
+		}
+		log.Errorf("Error creating budget from template: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnCreatedBudget(c, userId, budget.ID)
+}
+
+// HandleCloneBudget copies a budget and its expenses, optionally for new
+// dates and with the allocated amounts scaled by a percentage.
+func (h *APIHandler) HandleCloneBudget(c echo.Context) error {
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	copyForm, ok := decodeBudgetCopyForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	userId := getUserId(c)
+	budget, err := h.BudgetRepository.Clone(c.Request().Context(), repository.CloneBudgetParams{
+		UserID:     userId,
+		SourceID:   budgetId,
+		BudgetID:   generateRandomString(16),
+		Name:       copyForm.Name,
+		StartDate:  copyForm.StartDate.Time,
+		EndDate:    copyForm.EndDate.Time,
+		Percentage: copyForm.Percentage,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrInvalidBudgetDates) {
+			return c.String(http.StatusBadRequest, "Budget ends before it starts")
+		}
+		log.Errorf("Error cloning budget: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnCreatedBudget(c, userId, budget.ID)
+}
+
+// decodeBudgetCopyForm decodes the form to copy a budget with, which may be
+// empty, and defaults the percentage to 100.
+func decodeBudgetCopyForm(c echo.Context) (types.BudgetCopyForm, bool) {
+	copyForm := types.BudgetCopyForm{}
+	if c.Request().ContentLength != 0 {
+		decoder := json.NewDecoder(c.Request().Body)
+		err := decoder.Decode(&copyForm)
+		if err != nil {
+			log.Errorf("Error decoding request body: %v", err.Error())
+			return copyForm, false
+		}
+	}
+
+	if copyForm.Percentage == 0 {
+		copyForm.Percentage = 100
+	}
+	if copyForm.Percentage < 0 {
+		return copyForm, false
+	}
+	if copyForm.StartDate.Valid && copyForm.EndDate.Valid && copyForm.EndDate.Time.Before(copyForm.StartDate.Time) {
+		return copyForm, false
+	}
+
+	return copyForm, true
+}
+
+func (h *APIHandler) returnCreatedBudget(c echo.Context, userId uuid.UUID, budgetId string) error {
+	budget, err := h.BudgetRepository.GetById(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		log.Errorf("Error getting budget from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, budget.Version)
+
+	return c.JSON(http.StatusCreated, types.ToBudgetReturn(budget))
+}
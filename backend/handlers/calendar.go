@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/calendar"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+const (
+	defaultCalendarDays = 30
+	maxCalendarDays     = 366
+
+	// The feed keeps the last month so bills that were just paid stay visible
+	calendarFeedDaysBack  = 31
+	calendarFeedDaysAhead = 365
+)
+
+func (h *APIHandler) HandleGetCalendar(c echo.Context) error {
+	days := defaultCalendarDays
+	if daysParam := c.QueryParam("days"); daysParam != "" {
+		parsed, err := strconv.ParseInt(daysParam, 10, 16)
+		if err != nil || parsed < 1 || parsed > maxCalendarDays {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("Days must be between 1 and %d", maxCalendarDays))
+		}
+		days = int(parsed)
+	}
+
+	userId := getUserId(c)
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, days-1)
+
+	balance, err := h.TransactionRepository.GetBalanceBefore(c.Request().Context(), userId, start)
+	if err != nil {
+		log.Errorf("Error getting balance from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	transactions, err := h.TransactionRepository.GetAllBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: userId,
+		Start:  start,
+		End:    end,
+	})
+	if err != nil {
+		log.Errorf("Error getting transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.GetCalendar(start, end, balance, *transactions))
+}
+
+// HandleCreateCalendarToken creates the token for the calendar feed of the
+// user. Creating a new token revokes the previous one.
+func (h *APIHandler) HandleCreateCalendarToken(c echo.Context) error {
+	userId := getUserId(c)
+	token, err := h.CalendarRepository.CreateToken(c.Request().Context(), userId)
+	if err != nil {
+		log.Errorf("Error creating calendar token: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusCreated, types.CalendarTokenReturn{
+		Token: token,
+		Path:  fmt.Sprintf("/api/calendar/feed/%s.ics", token),
+	})
+}
+
+func (h *APIHandler) HandleDeleteCalendarToken(c echo.Context) error {
+	userId := getUserId(c)
+	err := h.CalendarRepository.RemoveToken(c.Request().Context(), userId)
+	if err != nil {
+		log.Errorf("Error deleting calendar token: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// HandleGetCalendarFeed serves the calendar as an iCalendar feed. Calendar
+// apps can not log in, so the token in the url is the authorization.
+func (h *APIHandler) HandleGetCalendarFeed(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	userId, err := h.CalendarRepository.GetUserIdByToken(c.Request().Context(), token)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting calendar token from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	transactions, err := h.TransactionRepository.GetAllBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: userId,
+		Start:  today.AddDate(0, 0, -calendarFeedDaysBack),
+		End:    today.AddDate(0, 0, calendarFeedDaysAhead),
+	})
+	if err != nil {
+		log.Errorf("Error getting transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	events := make([]calendar.Event, len(*transactions))
+	for idx, transaction := range *transactions {
+		events[idx] = toCalendarEvent(userId, transaction)
+	}
+
+	var feed bytes.Buffer
+	err = calendar.WriteICS(&feed, "Fiscora", events, now)
+	if err != nil {
+		log.Errorf("Error writing calendar feed: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", feed.Bytes())
+}
+
+// toCalendarEvent identifies occurrences of a series by their date, so an
+// occurrence keeps its event once it is materialized.
+func toCalendarEvent(userId uuid.UUID, transaction repository.FullTransaction) calendar.Event {
+	uid := fmt.Sprintf("transaction-%d@%s.fiscora", transaction.ID, userId)
+	if transaction.RecurringTransactionID.Valid {
+		uid = fmt.Sprintf("recurring-%d-%s@%s.fiscora", transaction.RecurringTransactionID.Int32, transaction.Date.Format("20060102"), userId)
+	}
+
+	return calendar.Event{
+		UID:         uid,
+		Date:        transaction.Date,
+		Summary:     fmt.Sprintf("%s (%s)", transaction.Description, transaction.Amount),
+		Description: transaction.Type,
+	}
+}
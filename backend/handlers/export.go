@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// HandleExport downloads the transactions, budgets and goals of the user as a
+// single JSON document.
+func (h *APIHandler) HandleExport(c echo.Context) error {
+	userId := getUserId(c)
+	ctx := c.Request().Context()
+
+	user, err := h.UserRepository.GetById(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting user from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	transactions, err := h.TransactionRepository.GetAll(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	budgets, err := h.BudgetRepository.Get(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting budgets from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	goals, err := h.GoalRepository.Get(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting goals from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	returnBudgets := make([]types.BudgetReturn, len(*budgets))
+	for idx, budget := range *budgets {
+		returnBudgets[idx] = types.ToBudgetReturn(&budget)
+	}
+
+	now := time.Now().UTC()
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="fiscora-%s.json"`, now.Format("2006-01-02")))
+
+	return c.JSON(http.StatusOK, types.ExportReturn{
+		Exported:     now,
+		User:         types.ToUser(user),
+		Transactions: *types.ToTransactionReturns(transactions),
+		Budgets:      returnBudgets,
+		Goals:        types.ToGoalReturns(goals),
+	})
+}
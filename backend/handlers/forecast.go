@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/forecast"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+const (
+	// forecastHistoryMonths is the number of full months variable spending is
+	// estimated from
+	forecastHistoryMonths = 6
+	maxForecastDays       = 366
+)
+
+// HandleGetForecast projects the balance from today up to the end of the
+// month, or over the given number of days.
+func (h *APIHandler) HandleGetForecast(c echo.Context) error {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	if daysParam := c.QueryParam("days"); daysParam != "" {
+		days, err := strconv.ParseInt(daysParam, 10, 16)
+		if err != nil || days < 1 || days > maxForecastDays {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("Days must be between 1 and %d", maxForecastDays))
+		}
+		end = start.AddDate(0, 0, int(days))
+	}
+
+	userId := getUserId(c)
+	tomorrow := start.AddDate(0, 0, 1)
+
+	balance, err := h.TransactionRepository.GetBalanceBefore(c.Request().Context(), userId, tomorrow)
+	if err != nil {
+		log.Errorf("Error getting balance from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	scheduled, err := h.TransactionRepository.GetBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: userId,
+		Start:  tomorrow,
+		End:    end,
+	})
+	if err != nil {
+		log.Errorf("Error getting transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	historyStart := time.Date(now.Year(), now.Month()-forecastHistoryMonths, 1, 0, 0, 0, 0, time.UTC)
+	history, err := h.TransactionRepository.GetNonRecurringSince(c.Request().Context(), userId, historyStart)
+	if err != nil {
+		log.Errorf("Error getting transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	params := forecast.Params{
+		Start:         start,
+		End:           end,
+		Balance:       balance,
+		HistoryMonths: forecastHistoryMonths,
+	}
+	for _, transaction := range *scheduled {
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		params.Scheduled = append(params.Scheduled, forecast.Transaction{
+			Date:     transaction.Date,
+			Amount:   amount,
+			Category: transaction.Type,
+		})
+	}
+	for _, transaction := range *history {
+		// Transactions after today are scheduled one-offs, not history
+		if !transaction.Date.Before(tomorrow) {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		params.History = append(params.History, forecast.Transaction{
+			Date:     transaction.Date,
+			Amount:   amount,
+			Category: transaction.Type,
+		})
+	}
+
+	return c.JSON(http.StatusOK, types.ToForecastReturn(start, end, forecast.Project(params)))
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleGetGoals(c echo.Context) error {
+	goals, err := h.GoalRepository.Get(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting goals from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToGoalReturns(goals))
+}
+
+func (h *APIHandler) HandleGetGoal(c echo.Context) error {
+	userId := getUserId(c)
+	goalId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing goal id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	goal, err := h.GoalRepository.GetById(c.Request().Context(), userId, int32(goalId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting goal from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, goal.Version)
+
+	transactions, err := h.GoalRepository.GetTransactions(c.Request().Context(), userId, goal.ID)
+	if err != nil {
+		log.Errorf("Error getting goal transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	returnGoal := types.ToGoalReturn(goal)
+	returnGoal.Transactions = types.ToTransactionReturns(transactions)
+
+	return c.JSON(http.StatusOK, returnGoal)
+}
+
+func (h *APIHandler) HandleCreateGoal(c echo.Context) error {
+	goalForm, ok := decodeGoalForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid goal amount or dates")
+	}
+
+	userId := getUserId(c)
+	goal, err := h.GoalRepository.Add(c.Request().Context(), repository.CreateGoalParams{
+		UserID:              userId,
+		Name:                goalForm.Name,
+		Description:         goalForm.Description,
+		TargetAmount:        strconv.FormatFloat(goalForm.TargetAmount, 'f', -1, 64),
+		InitialAmount:       strconv.FormatFloat(goalForm.InitialAmount, 'f', -1, 64),
+		StartDate:           goalForm.StartDate,
+		TargetDate:          goalForm.TargetDate,
+		Tags:                goalForm.Tags,
+		InvestmentAccountID: goalForm.InvestmentAccountID.NullInt32,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrGoalAccountNotFound) {
+			return c.String(http.StatusBadRequest, "Investment account not found")
+		}
+		log.Errorf("Error creating goal: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnGoal(c, http.StatusCreated, goal.ID)
+}
+
+func (h *APIHandler) HandleUpdateGoal(c echo.Context) error {
+	goalId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing goal id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	goalForm, ok := decodeGoalForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid goal amount or dates")
+	}
+
+	goal, err := h.GoalRepository.Update(c.Request().Context(), repository.UpdateGoalParams{
+		ID:                  int32(goalId),
+		UserID:              getUserId(c),
+		Name:                goalForm.Name,
+		Description:         goalForm.Description,
+		TargetAmount:        strconv.FormatFloat(goalForm.TargetAmount, 'f', -1, 64),
+		InitialAmount:       strconv.FormatFloat(goalForm.InitialAmount, 'f', -1, 64),
+		StartDate:           goalForm.StartDate,
+		TargetDate:          goalForm.TargetDate,
+		Tags:                goalForm.Tags,
+		InvestmentAccountID: goalForm.InvestmentAccountID.NullInt32,
+		Version:             version,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Goal was changed by someone else")
+		}
+		if errors.Is(err, repository.ErrGoalAccountNotFound) {
+			return c.String(http.StatusBadRequest, "Investment account not found")
+		}
+		log.Errorf("Error updating goal: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnGoal(c, http.StatusOK, goal.ID)
+}
+
+func (h *APIHandler) HandleDeleteGoal(c echo.Context) error {
+	goalId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing goal id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	err = h.GoalRepository.Remove(c.Request().Context(), getUserId(c), int32(goalId), version)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Goal was changed by someone else")
+		}
+		log.Errorf("Error deleting goal: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.String(http.StatusOK, "Goal deleted successfully")
+}
+
+// decodeGoalForm decodes and checks a goal. Without a start date the goal
+// starts today.
+func decodeGoalForm(c echo.Context) (*types.GoalForm, bool) {
+	decoder := json.NewDecoder(c.Request().Body)
+	goalForm := types.GoalForm{}
+	err := decoder.Decode(&goalForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return nil, false
+	}
+
+	if goalForm.StartDate.IsZero() {
+		now := time.Now().UTC()
+		goalForm.StartDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if goalForm.TargetAmount <= 0 || goalForm.InitialAmount < 0 || !goalForm.TargetDate.After(goalForm.StartDate) {
+		return nil, false
+	}
+	goalForm.Tags = normalizeMatchers(goalForm.Tags)
+
+	return &goalForm, true
+}
+
+func (h *APIHandler) returnGoal(c echo.Context, status int, id int32) error {
+	goal, err := h.GoalRepository.GetById(c.Request().Context(), getUserId(c), id)
+	if err != nil {
+		log.Errorf("Error getting goal from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, goal.Version)
+
+	return c.JSON(status, types.ToGoalReturn(goal))
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/portfolio"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// maxSymbolLength is the longest symbol of an investment
+const maxSymbolLength = 32
+
+func (h *APIHandler) HandleGetInvestmentAccounts(c echo.Context) error {
+	accounts, err := h.InvestmentRepository.Get(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting investment accounts from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToInvestmentAccountReturns(accounts))
+}
+
+func (h *APIHandler) HandleGetInvestmentAccount(c echo.Context) error {
+	accountId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment account id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	return h.returnInvestmentAccount(c, http.StatusOK, int32(accountId))
+}
+
+func (h *APIHandler) HandleCreateInvestmentAccount(c echo.Context) error {
+	accountForm, ok := decodeInvestmentAccountForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid cost basis method")
+	}
+
+	account, err := h.InvestmentRepository.Add(c.Request().Context(), repository.CreateInvestmentAccountParams{
+		UserID:          getUserId(c),
+		Name:            accountForm.Name,
+		Description:     accountForm.Description,
+		CostBasisMethod: accountForm.CostBasisMethod,
+	})
+	if err != nil {
+		log.Errorf("Error creating investment account: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnInvestmentAccount(c, http.StatusCreated, account.ID)
+}
+
+func (h *APIHandler) HandleUpdateInvestmentAccount(c echo.Context) error {
+	accountId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment account id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	accountForm, ok := decodeInvestmentAccountForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid cost basis method")
+	}
+
+	account, err := h.InvestmentRepository.Update(c.Request().Context(), repository.UpdateInvestmentAccountParams{
+		ID:              int32(accountId),
+		UserID:          getUserId(c),
+		Name:            accountForm.Name,
+		Description:     accountForm.Description,
+		CostBasisMethod: accountForm.CostBasisMethod,
+		Version:         version,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Investment account was changed by someone else")
+		}
+		log.Errorf("Error updating investment account: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnInvestmentAccount(c, http.StatusOK, account.ID)
+}
+
+func (h *APIHandler) HandleDeleteInvestmentAccount(c echo.Context) error {
+	accountId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment account id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	err = h.InvestmentRepository.Remove(c.Request().Context(), getUserId(c), int32(accountId), version)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Investment account was changed by someone else")
+		}
+		log.Errorf("Error deleting investment account: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.String(http.StatusOK, "Investment account deleted successfully")
+}
+
+func (h *APIHandler) HandleCreateInvestmentTrade(c echo.Context) error {
+	accountId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment account id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	tradeForm := types.InvestmentTradeForm{}
+	err = decoder.Decode(&tradeForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+	tradeForm.Symbol = normalizeSymbol(tradeForm.Symbol)
+	if tradeForm.Symbol == "" || len(tradeForm.Symbol) > maxSymbolLength || !slices.Contains(portfolio.TradeKinds, tradeForm.Kind) {
+		return c.String(http.StatusBadRequest, "Invalid symbol or trade kind")
+	}
+	if tradeForm.Date.IsZero() || tradeForm.Quantity <= 0 || tradeForm.Price < 0 || tradeForm.Fees < 0 {
+		return c.String(http.StatusBadRequest, "Invalid trade date, quantity, price or fees")
+	}
+
+	_, err = h.InvestmentRepository.AddTrade(c.Request().Context(), getUserId(c), repository.CreateInvestmentTradeParams{
+		AccountID: int32(accountId),
+		Symbol:    tradeForm.Symbol,
+		Kind:      tradeForm.Kind,
+		Date:      tradeForm.Date,
+		Quantity:  strconv.FormatFloat(tradeForm.Quantity, 'f', -1, 64),
+		Price:     strconv.FormatFloat(tradeForm.Price, 'f', -1, 64),
+		Fees:      strconv.FormatFloat(tradeForm.Fees, 'f', -1, 64),
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, portfolio.ErrInsufficientQuantity) {
+			return c.String(http.StatusConflict, "Sells more than is held")
+		}
+		log.Errorf("Error creating investment trade: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnInvestmentAccount(c, http.StatusCreated, int32(accountId))
+}
+
+func (h *APIHandler) HandleDeleteInvestmentTrade(c echo.Context) error {
+	accountId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment account id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+	tradeId, err := strconv.ParseInt(c.Param("trade_id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing investment trade id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	err = h.InvestmentRepository.RemoveTrade(c.Request().Context(), getUserId(c), int32(accountId), int32(tradeId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, portfolio.ErrInsufficientQuantity) {
+			return c.String(http.StatusConflict, "A later sell depends on this trade")
+		}
+		log.Errorf("Error deleting investment trade: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnInvestmentAccount(c, http.StatusOK, int32(accountId))
+}
+
+func (h *APIHandler) HandleGetInvestmentPrices(c echo.Context) error {
+	prices, err := h.InvestmentRepository.GetPrices(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting investment prices from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToInvestmentPriceReturns(prices))
+}
+
+func (h *APIHandler) HandleCreateInvestmentPrices(c echo.Context) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	priceForms := []types.InvestmentPriceForm{}
+	err := decoder.Decode(&priceForms)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	prices := make([]portfolio.Price, len(priceForms))
+	for idx, priceForm := range priceForms {
+		price, ok := toPortfolioPrice(priceForm.Symbol, priceForm.Date, priceForm.Price)
+		if !ok {
+			return c.String(http.StatusBadRequest, "Invalid symbol, date or price")
+		}
+		prices[idx] = price
+	}
+
+	return h.addInvestmentPrices(c, prices)
+}
+
+// HandleImportInvestmentPrices loads prices from a CSV file with the symbol,
+// the date as 2006-01-02 and the price on each line. A header line is
+// skipped.
+func (h *APIHandler) HandleImportInvestmentPrices(c echo.Context) error {
+	reader := csv.NewReader(c.Request().Body)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var prices []portfolio.Price
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("Invalid CSV: %v", err.Error()))
+		}
+
+		date, dateErr := time.Parse("2006-01-02", strings.TrimSpace(record[1]))
+		value, priceErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if line == 1 && (dateErr != nil || priceErr != nil) {
+			continue
+		}
+		if dateErr != nil || priceErr != nil {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("Invalid date or price on line %d", line))
+		}
+
+		price, ok := toPortfolioPrice(record[0], date, value)
+		if !ok {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("Invalid symbol, date or price on line %d", line))
+		}
+		prices = append(prices, price)
+	}
+
+	return h.addInvestmentPrices(c, prices)
+}
+
+func (h *APIHandler) addInvestmentPrices(c echo.Context, prices []portfolio.Price) error {
+	err := h.InvestmentRepository.AddPrices(c.Request().Context(), getUserId(c), prices)
+	if err != nil {
+		log.Errorf("Error creating investment prices: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{"imported": len(prices)})
+}
+
+func toPortfolioPrice(symbol string, date time.Time, price float64) (portfolio.Price, bool) {
+	symbol = normalizeSymbol(symbol)
+	if symbol == "" || len(symbol) > maxSymbolLength || date.IsZero() || price < 0 {
+		return portfolio.Price{}, false
+	}
+
+	return portfolio.Price{
+		Symbol: symbol,
+		Date:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		Price:  price,
+	}, true
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// decodeInvestmentAccountForm decodes and checks an investment account.
+// Without a method the cost basis is FIFO.
+func decodeInvestmentAccountForm(c echo.Context) (*types.InvestmentAccountForm, bool) {
+	decoder := json.NewDecoder(c.Request().Body)
+	accountForm := types.InvestmentAccountForm{}
+	err := decoder.Decode(&accountForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return nil, false
+	}
+
+	if accountForm.CostBasisMethod == "" {
+		accountForm.CostBasisMethod = portfolio.CostBasisFIFO
+	}
+	if !slices.Contains(portfolio.CostBasisMethods, accountForm.CostBasisMethod) {
+		return nil, false
+	}
+
+	return &accountForm, true
+}
+
+// returnInvestmentAccount returns an investment account with its trades.
+func (h *APIHandler) returnInvestmentAccount(c echo.Context, status int, id int32) error {
+	account, err := h.InvestmentRepository.GetById(c.Request().Context(), getUserId(c), id)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting investment account from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, account.Version)
+
+	trades := types.ToInvestmentTradeReturns(account.Trades)
+	returnAccount := types.ToInvestmentAccountReturn(account)
+	returnAccount.Trades = &trades
+
+	return c.JSON(status, returnAccount)
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/amortization"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// maxLoanTerm is the longest term of a loan in months
+const maxLoanTerm = 1200
+
+func (h *APIHandler) HandleGetLoans(c echo.Context) error {
+	loans, err := h.LoanRepository.Get(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting loans from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToLoanReturns(loans))
+}
+
+func (h *APIHandler) HandleGetLoan(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	return h.returnLoan(c, http.StatusOK, int32(loanId))
+}
+
+func (h *APIHandler) HandleCreateLoan(c echo.Context) error {
+	loanForm, ok := decodeLoanForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid loan amount, rate, term, schedule or type")
+	}
+
+	loan, err := h.LoanRepository.Add(c.Request().Context(), repository.CreateLoanParams{
+		UserID:           getUserId(c),
+		Name:             loanForm.Name,
+		Description:      loanForm.Description,
+		Principal:        strconv.FormatFloat(loanForm.Principal, 'f', -1, 64),
+		InterestRate:     strconv.FormatFloat(loanForm.InterestRate, 'f', -1, 64),
+		Term:             loanForm.Term,
+		Schedule:         loanForm.Schedule,
+		Type:             loanForm.Type,
+		FirstPaymentDate: loanForm.FirstPaymentDate,
+	})
+	if err != nil {
+		log.Errorf("Error creating loan: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnLoan(c, http.StatusCreated, loan.ID)
+}
+
+func (h *APIHandler) HandleUpdateLoan(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	loanForm, ok := decodeLoanForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid loan amount, rate, term, schedule or type")
+	}
+
+	loan, err := h.LoanRepository.Update(c.Request().Context(), repository.UpdateLoanParams{
+		ID:               int32(loanId),
+		UserID:           getUserId(c),
+		Name:             loanForm.Name,
+		Description:      loanForm.Description,
+		Principal:        strconv.FormatFloat(loanForm.Principal, 'f', -1, 64),
+		InterestRate:     strconv.FormatFloat(loanForm.InterestRate, 'f', -1, 64),
+		Term:             loanForm.Term,
+		Schedule:         loanForm.Schedule,
+		Type:             loanForm.Type,
+		FirstPaymentDate: loanForm.FirstPaymentDate,
+		Version:          version,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Loan was changed by someone else")
+		}
+		log.Errorf("Error updating loan: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnLoan(c, http.StatusOK, loan.ID)
+}
+
+func (h *APIHandler) HandleDeleteLoan(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	err = h.LoanRepository.Remove(c.Request().Context(), getUserId(c), int32(loanId), version)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Loan was changed by someone else")
+		}
+		log.Errorf("Error deleting loan: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.String(http.StatusOK, "Loan deleted successfully")
+}
+
+func (h *APIHandler) HandleCreateLoanRepayment(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	repaymentForm := types.LoanRepaymentForm{}
+	err = decoder.Decode(&repaymentForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+	if repaymentForm.Amount <= 0 || repaymentForm.Date.IsZero() {
+		return c.String(http.StatusBadRequest, "Invalid repayment amount or date")
+	}
+
+	_, err = h.LoanRepository.AddRepayment(c.Request().Context(), repository.AddLoanRepaymentParams{
+		UserID: getUserId(c),
+		LoanID: int32(loanId),
+		Date:   repaymentForm.Date,
+		Amount: repaymentForm.Amount,
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error creating loan repayment: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnLoan(c, http.StatusCreated, int32(loanId))
+}
+
+func (h *APIHandler) HandleDeleteLoanRepayment(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+	repaymentId, err := strconv.ParseInt(c.Param("repayment_id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan repayment id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	err = h.LoanRepository.RemoveRepayment(c.Request().Context(), getUserId(c), int32(loanId), int32(repaymentId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error deleting loan repayment: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.returnLoan(c, http.StatusOK, int32(loanId))
+}
+
+// HandleSimulateLoan calculates what extra repayments would save on a loan,
+// without making them.
+func (h *APIHandler) HandleSimulateLoan(c echo.Context) error {
+	loanId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing loan id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	decoder := json.NewDecoder(c.Request().Body)
+	simulationForm := types.LoanSimulationForm{}
+	err = decoder.Decode(&simulationForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+	if simulationForm.MonthlyExtra < 0 {
+		return c.String(http.StatusBadRequest, "Invalid repayment amount or date")
+	}
+	for _, repayment := range simulationForm.Repayments {
+		if repayment.Amount <= 0 || repayment.Date.IsZero() {
+			return c.String(http.StatusBadRequest, "Invalid repayment amount or date")
+		}
+	}
+
+	loan, err := h.LoanRepository.GetById(c.Request().Context(), getUserId(c), int32(loanId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting loan from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	amortizationLoan := repository.ToAmortizationLoan(loan.Loan)
+	repayments := repository.ToAmortizationRepayments(loan.Repayments)
+	for _, repayment := range simulationForm.Repayments {
+		repayments = append(repayments, amortization.Repayment{
+			Date:   repayment.Date,
+			Amount: repayment.Amount,
+		})
+	}
+	if simulationForm.MonthlyExtra > 0 {
+		repayments = append(repayments, amortization.MonthlyRepayments(amortizationLoan, time.Now().UTC(), simulationForm.MonthlyExtra)...)
+	}
+	simulated := amortization.Amortize(amortizationLoan, repayments)
+
+	var posted int32
+	if len(loan.Payments) != 0 {
+		posted = loan.Payments[len(loan.Payments)-1].Number
+	}
+
+	current := types.ToLoanScheduleSummaryReturn(loan.Amortization)
+	simulatedSummary := types.ToLoanScheduleSummaryReturn(simulated)
+
+	return c.JSON(http.StatusOK, types.LoanSimulationReturn{
+		Current:       current,
+		Simulated:     simulatedSummary,
+		InterestSaved: math.Round((current.TotalInterest-simulatedSummary.TotalInterest)*100) / 100,
+		PaymentsSaved: current.Payments - simulatedSummary.Payments,
+		Payments:      types.ToLoanPaymentReturns(simulated, posted),
+	})
+}
+
+// decodeLoanForm decodes and checks a loan. Without a schedule the loan is an
+// annuity, and without a type the principal is booked as Mortgage.
+func decodeLoanForm(c echo.Context) (*types.LoanForm, bool) {
+	decoder := json.NewDecoder(c.Request().Body)
+	loanForm := types.LoanForm{}
+	err := decoder.Decode(&loanForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return nil, false
+	}
+
+	if loanForm.Schedule == "" {
+		loanForm.Schedule = amortization.ScheduleAnnuity
+	}
+	if loanForm.Type == "" {
+		loanForm.Type = repository.ExpenseTypeMortgage
+	}
+	if !slices.Contains(amortization.Schedules, loanForm.Schedule) || !slices.Contains(repository.ExpenseTypes, loanForm.Type) {
+		return nil, false
+	}
+	if loanForm.Principal <= 0 || loanForm.InterestRate < 0 || loanForm.InterestRate >= 100 {
+		return nil, false
+	}
+	if loanForm.Term < 1 || loanForm.Term > maxLoanTerm || loanForm.FirstPaymentDate.IsZero() {
+		return nil, false
+	}
+
+	return &loanForm, true
+}
+
+// returnLoan returns a loan with its amortization table.
+func (h *APIHandler) returnLoan(c echo.Context, status int, id int32) error {
+	loan, err := h.LoanRepository.GetById(c.Request().Context(), getUserId(c), id)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting loan from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	setETag(c, loan.Version)
+
+	var posted int32
+	if len(loan.Payments) != 0 {
+		posted = loan.Payments[len(loan.Payments)-1].Number
+	}
+	payments := types.ToLoanPaymentReturns(loan.Amortization, posted)
+
+	returnLoan := types.ToLoanReturn(loan)
+	returnLoan.Payments = &payments
+
+	return c.JSON(status, returnLoan)
+}
@@ -1,13 +1,32 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
 	"github.com/tvgelderen/fiscora/auth"
+	"github.com/tvgelderen/fiscora/config"
+	"github.com/tvgelderen/fiscora/notify"
+	"github.com/tvgelderen/fiscora/repository"
 )
 
-const userIdKey = "user_id"
+const (
+	userIdKey = "user_id"
+
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	notificationTimeout = 30 * time.Second
+)
 
 func (h *APIHandler) AuthorizeEndpoint(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -23,6 +42,147 @@ func (h *APIHandler) AuthorizeEndpoint(next echo.HandlerFunc) echo.HandlerFunc {
 
 		c.Set(userIdKey, id)
 
+		ctx := repository.WithAuditInfo(c.Request().Context(), repository.AuditInfo{
+			ActorID:   id,
+			RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		})
+		c.SetRequest(c.Request().WithContext(ctx))
+
 		return next(c)
 	}
 }
+
+// Idempotent makes POST requests with an Idempotency-Key header safe to retry.
+// The first response for a key is stored and replayed for repeats within the
+// ttl. Reusing a key for a different request is rejected. It must run after
+// AuthorizeEndpoint, since keys are scoped to the user. Responses are stored
+// as is, so it must not wrap endpoints that respond with a secret.
+func (h *APIHandler) Idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(headerIdempotencyKey)
+		if c.Request().Method != http.MethodPost || key == "" {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.String(http.StatusBadRequest, "Idempotency key is too long")
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			log.Errorf("Error reading request body: %v", err.Error())
+			return c.String(http.StatusBadRequest, "Error decoding request body")
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request().Method + " " + c.Request().URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		userId := getUserId(c)
+		ctx := c.Request().Context()
+
+		idempotencyKey, err := h.IdempotencyRepository.Reserve(ctx, userId, key, requestHash, config.Envs.IdempotencyKeyTTL)
+		if err != nil {
+			log.Errorf("Error reserving idempotency key: %v", err.Error())
+			return c.String(http.StatusInternalServerError, "Something went wrong")
+		}
+		if idempotencyKey != nil {
+			if idempotencyKey.RequestHash != requestHash {
+				return c.String(http.StatusUnprocessableEntity, "Idempotency key was already used for a different request")
+			}
+			if !idempotencyKey.StatusCode.Valid {
+				return c.String(http.StatusConflict, "A request with this idempotency key is still being processed")
+			}
+
+			c.Response().Header().Set(headerIdempotentReplayed, "true")
+			return c.Blob(int(idempotencyKey.StatusCode.Int32), idempotencyKey.ContentType.String, idempotencyKey.ResponseBody)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+
+		err = next(c)
+		if err != nil || c.Response().Status >= http.StatusInternalServerError {
+			if releaseErr := h.IdempotencyRepository.Release(ctx, userId, key); releaseErr != nil {
+				log.Errorf("Error releasing idempotency key: %v", releaseErr.Error())
+			}
+			return err
+		}
+
+		err = h.IdempotencyRepository.Complete(ctx, repository.CompleteIdempotencyKeyParams{
+			UserID:       userId,
+			Key:          key,
+			StatusCode:   sql.NullInt32{Int32: int32(c.Response().Status), Valid: true},
+			ContentType:  sql.NullString{String: c.Response().Header().Get(echo.HeaderContentType), Valid: true},
+			ResponseBody: recorder.body.Bytes(),
+		})
+		if err != nil {
+			log.Errorf("Error storing idempotency key response: %v", err.Error())
+		}
+
+		return nil
+	}
+}
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+// EvaluateBudgetAlerts checks the budget thresholds of the user after a
+// request changed transactions or budgets, and delivers the alerts that fired.
+// Failing to evaluate the alerts does not fail the request. It must run after
+// AuthorizeEndpoint.
+func (h *APIHandler) EvaluateBudgetAlerts(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err != nil || c.Request().Method == http.MethodGet || c.Response().Status >= http.StatusBadRequest {
+			return err
+		}
+
+		userId := getUserId(c)
+		alerts, alertErr := h.BudgetRepository.EvaluateAlerts(c.Request().Context(), userId)
+		if alertErr != nil {
+			log.Errorf("Error evaluating budget alerts: %v", alertErr.Error())
+			return nil
+		}
+		if len(*alerts) == 0 {
+			return nil
+		}
+
+		user, userErr := h.UserRepository.GetById(c.Request().Context(), userId)
+		if userErr != nil {
+			log.Errorf("Error getting user from db: %v", userErr.Error())
+			return nil
+		}
+
+		go h.deliverNotifications(user.Email, alerts)
+
+		return nil
+	}
+}
+
+// deliverNotifications sends the notifications of alerts through the notifier.
+// It runs after the response, so it does not use the request context.
+func (h *APIHandler) deliverNotifications(email string, alerts *[]repository.FiredBudgetAlert) {
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	for _, alert := range *alerts {
+		err := h.Notifier.Notify(ctx, notify.Message{
+			To:      email,
+			Subject: alert.Notification.Title,
+			Body:    alert.Notification.Message,
+		})
+		if err != nil {
+			log.Errorf("Error delivering notification %d: %v", alert.Notification.ID, err.Error())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleGetNotifications(c echo.Context) error {
+	notifications, err := h.NotificationRepository.Get(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error getting notifications from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToNotificationReturns(notifications))
+}
+
+func (h *APIHandler) HandleReadNotification(c echo.Context) error {
+	notificationId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing notification id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	err = h.NotificationRepository.MarkRead(c.Request().Context(), getUserId(c), int32(notificationId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error marking notification as read: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *APIHandler) HandleReadAllNotifications(c echo.Context) error {
+	_, err := h.NotificationRepository.MarkAllRead(c.Request().Context(), getUserId(c))
+	if err != nil {
+		log.Errorf("Error marking notifications as read: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
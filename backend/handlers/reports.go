@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/report"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// variancePeriod is a period of a budget with the amounts allocated to its
+// expenses in that period.
+type variancePeriod struct {
+	budget   repository.Budget
+	periodId sql.NullInt32
+	start    time.Time
+	end      time.Time
+	current  bool
+	expenses []report.Expense
+	// expenseIds are the ids of the budget expenses in the same order, which
+	// are missing for expenses that were removed after the period closed
+	expenseIds []sql.NullInt32
+}
+
+// HandleGetVarianceReport compares the allocated amounts of the budget
+// expenses with the actual spending, for the periods of a budget or of all
+// budgets that overlap the dates. The dates default to today, so without them
+// the report covers the current periods.
+func (h *APIHandler) HandleGetVarianceReport(c echo.Context) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	start, end := today, today
+	if c.QueryParam("startDate") != "" {
+		date, err := getStartDate(c)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Invalid start date")
+		}
+		start = date
+	}
+	if c.QueryParam("endDate") != "" {
+		date, err := getEndDate(c)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Invalid end date")
+		}
+		end = date
+	}
+	if end.Before(start) {
+		return c.String(http.StatusBadRequest, "End date is before start date")
+	}
+
+	userId := getUserId(c)
+	ctx := c.Request().Context()
+
+	var budgets []repository.BudgetWithExpenses
+	if budgetId := c.QueryParam("budgetId"); budgetId != "" {
+		budget, err := h.BudgetRepository.GetById(ctx, userId, budgetId)
+		if err != nil {
+			if repository.NoRowsFound(err) {
+				return c.NoContent(http.StatusNotFound)
+			}
+			log.Errorf("Error getting budget from db: %v", err.Error())
+			return c.String(http.StatusInternalServerError, "Something went wrong")
+		}
+		budgets = append(budgets, *budget)
+	} else {
+		allBudgets, err := h.BudgetRepository.Get(ctx, userId)
+		if err != nil {
+			log.Errorf("Error getting budgets from db: %v", err.Error())
+			return c.String(http.StatusInternalServerError, "Something went wrong")
+		}
+		budgets = *allBudgets
+	}
+
+	var periods []variancePeriod
+	for _, budget := range budgets {
+		if budget.Type != repository.BudgetTypeCustom {
+			closed, err := h.BudgetRepository.GetPeriods(ctx, userId, budget.ID)
+			if err != nil {
+				log.Errorf("Error getting budget periods from db: %v", err.Error())
+				return c.String(http.StatusInternalServerError, "Something went wrong")
+			}
+			for _, period := range *closed {
+				if period.EndDate.Before(start) || period.StartDate.After(end) {
+					continue
+				}
+				periods = append(periods, toClosedVariancePeriod(budget.Budget, period))
+			}
+		}
+
+		if budget.EndDate.Before(start) || budget.StartDate.After(end) {
+			continue
+		}
+		periods = append(periods, toCurrentVariancePeriod(budget))
+	}
+
+	result := types.VarianceReportReturn{
+		StartDate: start,
+		EndDate:   end,
+		Periods:   make([]types.BudgetVarianceReturn, 0, len(periods)),
+	}
+	if len(periods) == 0 {
+		return c.JSON(http.StatusOK, result)
+	}
+
+	spendingStart, spendingEnd := periods[0].start, periods[0].end
+	for _, period := range periods {
+		spendingStart = minTime(spendingStart, period.start)
+		spendingEnd = maxTime(spendingEnd, period.end)
+	}
+	spending, err := h.BudgetRepository.GetSpending(ctx, userId, spendingStart, spendingEnd)
+	if err != nil {
+		log.Errorf("Error getting budget spending from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	budgetSpending := make(map[string][]report.Spending)
+	for _, row := range *spending {
+		amount, _ := strconv.ParseFloat(row.Amount, 64)
+		budgetSpending[row.BudgetID] = append(budgetSpending[row.BudgetID], report.Spending{
+			ExpenseID: row.BudgetExpenseID,
+			Date:      row.Date,
+			Amount:    amount,
+		})
+	}
+
+	for _, period := range periods {
+		variance := report.Analyze(report.Params{
+			Start:    period.start,
+			End:      period.end,
+			AsOf:     today,
+			Expenses: period.expenses,
+			Spending: budgetSpending[period.budget.ID],
+		})
+
+		expenses := make([]types.ExpenseVarianceReturn, len(variance.Expenses))
+		for idx, expense := range variance.Expenses {
+			expenses[idx] = types.ExpenseVarianceReturn{
+				VarianceSummaryReturn: types.ToVarianceSummaryReturn(expense.Summary),
+				BudgetExpenseID:       types.NewNullInt(period.expenseIds[idx]),
+				Name:                  expense.Name,
+			}
+		}
+
+		result.Periods = append(result.Periods, types.BudgetVarianceReturn{
+			VarianceSummaryReturn: types.ToVarianceSummaryReturn(variance.Summary),
+			BudgetID:              period.budget.ID,
+			BudgetName:            period.budget.Name,
+			PeriodID:              types.NewNullInt(period.periodId),
+			StartDate:             period.start,
+			EndDate:               period.end,
+			Current:               period.current,
+			Expenses:              expenses,
+		})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func toCurrentVariancePeriod(budget repository.BudgetWithExpenses) variancePeriod {
+	period := variancePeriod{
+		budget:  budget.Budget,
+		start:   budget.StartDate,
+		end:     budget.EndDate,
+		current: true,
+	}
+	for _, expense := range budget.Expenses {
+		allocated, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+		rollover, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+		period.expenses = append(period.expenses, report.Expense{
+			ID:        expense.ID,
+			Name:      expense.Name,
+			Allocated: allocated + rollover,
+		})
+		period.expenseIds = append(period.expenseIds, sql.NullInt32{Int32: expense.ID, Valid: true})
+	}
+
+	return period
+}
+
+func toClosedVariancePeriod(budget repository.Budget, closed repository.BudgetPeriodWithExpenses) variancePeriod {
+	period := variancePeriod{
+		budget:   budget,
+		periodId: sql.NullInt32{Int32: closed.ID, Valid: true},
+		start:    closed.StartDate,
+		end:      closed.EndDate,
+	}
+	for _, expense := range closed.Expenses {
+		allocated, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+		rollover, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+		period.expenses = append(period.expenses, report.Expense{
+			// Removed expenses have no id left for spending to count against
+			ID:        expense.BudgetExpenseID.Int32,
+			Name:      expense.Name,
+			Allocated: allocated + rollover,
+		})
+		period.expenseIds = append(period.expenseIds, expense.BudgetExpenseID)
+	}
+
+	return period
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// HandleGetNetWorth adds the balance of the transactions up to today and the
+// value of the investments, and subtracts what is left of the loans.
+func (h *APIHandler) HandleGetNetWorth(c echo.Context) error {
+	userId := getUserId(c)
+	ctx := c.Request().Context()
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	cash, err := h.TransactionRepository.GetBalanceBefore(ctx, userId, today.AddDate(0, 0, 1))
+	if err != nil {
+		log.Errorf("Error getting balance from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	accounts, err := h.InvestmentRepository.Get(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting investment accounts from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+	loans, err := h.LoanRepository.Get(ctx, userId)
+	if err != nil {
+		log.Errorf("Error getting loans from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	var investments float64
+	for _, account := range *accounts {
+		investments += account.Portfolio.MarketValue
+	}
+	var debt float64
+	for _, loan := range *loans {
+		debt += loan.Balance
+	}
+
+	return c.JSON(http.StatusOK, types.NetWorthReturn{
+		Date:        today,
+		Cash:        math.Round(cash*100) / 100,
+		Investments: math.Round(investments*100) / 100,
+		Loans:       math.Round(debt*100) / 100,
+		NetWorth:    math.Round((cash+investments-debt)*100) / 100,
+	})
+}
+
+// maxSummaryYears limits the period of a summary report, so a report per day
+// stays a reasonable size.
+const maxSummaryYears = 10
+
+// HandleGetSummaryReport sums the income and expense between the dates per
+// day, week, month, quarter or year, split per type, tag, payee or budget.
+// Weeks, quarters and years follow the week start and fiscal year start of the
+// user.
+func (h *APIHandler) HandleGetSummaryReport(c echo.Context) error {
+	from, err := time.Parse("2006-01-02", c.QueryParam("from"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid from date")
+	}
+	to, err := time.Parse("2006-01-02", c.QueryParam("to"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid to date")
+	}
+	if to.Before(from) {
+		return c.String(http.StatusBadRequest, "To date is before from date")
+	}
+	if to.After(from.AddDate(maxSummaryYears, 0, 0)) {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("Period is longer than %d years", maxSummaryYears))
+	}
+
+	groupBy := c.QueryParam("groupBy")
+	if groupBy == "" {
+		groupBy = report.GroupByMonth
+	}
+	if !slices.Contains(report.GroupBys, groupBy) {
+		return c.String(http.StatusBadRequest, "Invalid groupBy")
+	}
+	by := c.QueryParam("by")
+	if by == "" {
+		by = repository.SummaryByType
+	}
+	if !slices.Contains(repository.SummaryGroupings, by) {
+		return c.String(http.StatusBadRequest, "Invalid by")
+	}
+
+	calendar, err := h.getCalendar(c)
+	if err != nil {
+		log.Errorf("Error getting user from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	dayAmounts, err := h.TransactionRepository.GetDailyAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: getUserId(c),
+		Start:  from,
+		End:    to,
+	}, by)
+	if err != nil {
+		log.Errorf("Error getting daily transaction amounts from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	// A transaction with multiple tags is in the group of each tag, so the
+	// totals are summed from the amounts per type, which count it once
+	var totals []report.Amount
+	if by == repository.SummaryByTag {
+		typeAmounts, err := h.TransactionRepository.GetDailyAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+			UserID: getUserId(c),
+			Start:  from,
+			End:    to,
+		}, repository.SummaryByType)
+		if err != nil {
+			log.Errorf("Error getting daily transaction amounts from db: %v", err.Error())
+			return c.String(http.StatusInternalServerError, "Something went wrong")
+		}
+		totals = toReportAmounts(*typeAmounts)
+	}
+
+	buckets := report.Buckets(report.BucketParams{
+		From:     from,
+		To:       to,
+		GroupBy:  groupBy,
+		Calendar: calendar,
+		Amounts:  toReportAmounts(*dayAmounts),
+		Totals:   totals,
+	})
+
+	var income, expense float64
+	for _, bucket := range buckets {
+		income += bucket.Income
+		expense += bucket.Expense
+	}
+
+	return c.JSON(http.StatusOK, types.SummaryReportReturn{
+		SummaryTotalsReturn: types.SummaryTotalsReturn{
+			Income:  math.Round(income*100) / 100,
+			Expense: math.Round(expense*100) / 100,
+			Net:     math.Round((income-expense)*100) / 100,
+		},
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		By:      by,
+		Buckets: types.ToSummaryBucketReturns(buckets),
+	})
+}
+
+func toReportAmounts(dayAmounts []repository.DayAmount) []report.Amount {
+	amounts := make([]report.Amount, len(dayAmounts))
+	for idx, dayAmount := range dayAmounts {
+		amounts[idx] = report.Amount{
+			Date:    dayAmount.Date,
+			Key:     dayAmount.Key,
+			Income:  dayAmount.Income,
+			Expense: math.Abs(dayAmount.Expense),
+		}
+	}
+	return amounts
+}
+
+// HandleGetComparisonReport compares the income or expense per type of a
+// month with the same month last year and with the trailing average. A change
+// is flagged as significant from the threshold percentage on, which defaults
+// to 10%.
+func (h *APIHandler) HandleGetComparisonReport(c echo.Context) error {
+	income := false
+	if c.QueryParam("income") != "" {
+		value, err := strconv.ParseBool(c.QueryParam("income"))
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Invalid income type")
+		}
+		income = value
+	}
+
+	threshold := report.DefaultSignificantPercentage
+	if c.QueryParam("threshold") != "" {
+		value, err := strconv.ParseFloat(c.QueryParam("threshold"), 64)
+		if err != nil || value < 0 {
+			return c.String(http.StatusBadRequest, "Invalid threshold")
+		}
+		threshold = value
+	}
+
+	month := time.Date(getYear(c), time.Month(getMonth(c)), 1, 0, 0, 0, 0, time.UTC)
+
+	monthTypeAmounts, err := h.TransactionRepository.GetMonthlyTypeAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: getUserId(c),
+		Start:  month.AddDate(0, -report.TrailingMonths, 0),
+		End:    month.AddDate(0, 1, -1),
+	})
+	if err != nil {
+		log.Errorf("Error getting monthly transaction amounts per type from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	transactionTypes := repository.ExpenseTypes
+	if income {
+		transactionTypes = repository.IncomeTypes
+	}
+
+	var amounts []report.MonthAmount
+	for _, monthTypeAmount := range *monthTypeAmounts {
+		if !slices.Contains(transactionTypes, monthTypeAmount.Type) {
+			continue
+		}
+
+		amount := math.Abs(monthTypeAmount.Expense)
+		if income {
+			amount = monthTypeAmount.Income
+		}
+		if amount == 0 {
+			continue
+		}
+
+		amounts = append(amounts, report.MonthAmount{
+			Month:  monthTypeAmount.Month,
+			Key:    monthTypeAmount.Type,
+			Amount: amount,
+		})
+	}
+
+	comparison := report.Compare(report.ComparisonParams{
+		Month:                 month,
+		Amounts:               amounts,
+		SignificantPercentage: threshold,
+		SignificantAmount:     report.DefaultSignificantAmount,
+	})
+
+	return c.JSON(http.StatusOK, types.ComparisonReportReturn{
+		Month:      month,
+		Income:     income,
+		Total:      types.ToComparisonReturn(comparison.Total),
+		Categories: types.ToComparisonReturns(comparison.Categories),
+	})
+}
@@ -38,27 +38,27 @@ func (h *APIHandler) HandleGetTransactionMonthInfo(c echo.Context) error {
 func (h *APIHandler) HandleGetTransactionYearInfo(c echo.Context) error {
 	userId := getUserId(c)
 	year := getYear(c)
+	dateRange := getYearRange(year)
 
-	yearInfo := make(map[int]types.MonthInfoReturn)
+	monthAmounts, err := h.TransactionRepository.GetMonthlyAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: userId,
+		Start:  dateRange.Start,
+		End:    dateRange.End,
+	})
+	if err != nil {
+		log.Errorf("Error getting monthly transaction amounts from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
 
+	yearInfo := make(map[int]types.MonthInfoReturn)
 	for month := 1; month < 13; month++ {
-		dateRange := getMonthRange(month, year)
-		transactionAmounts, err := h.TransactionRepository.GetAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
-			UserID: userId,
-			Start:  dateRange.Start,
-			End:    dateRange.End,
-		})
-		if err != nil {
-			if repository.NoRowsFound(err) {
-				return c.NoContent(http.StatusNotFound)
-			}
-			log.Errorf("Error getting transactions from db: %v", err.Error())
-			return c.String(http.StatusInternalServerError, "Something went wrong")
+		yearInfo[month] = types.MonthInfoReturn{}
+	}
+	for _, monthAmount := range *monthAmounts {
+		yearInfo[int(monthAmount.Month.Month())] = types.MonthInfoReturn{
+			Income:  monthAmount.Income,
+			Expense: math.Abs(monthAmount.Expense),
 		}
-
-		monthInfo := types.GetMonthInfo(transactionAmounts)
-
-		yearInfo[month] = monthInfo
 	}
 
 	return c.JSON(http.StatusOK, yearInfo)
@@ -70,6 +70,20 @@ func (h *APIHandler) HandleGetTransactionsYearInfoPerType(c echo.Context) error
 		return c.String(http.StatusBadRequest, "Invalid income type")
 	}
 
+	userId := getUserId(c)
+	year := getYear(c)
+	dateRange := getYearRange(year)
+
+	monthTypeAmounts, err := h.TransactionRepository.GetMonthlyTypeAmountsBetweenDates(c.Request().Context(), repository.GetBetweenDatesParams{
+		UserID: userId,
+		Start:  dateRange.Start,
+		End:    dateRange.End,
+	})
+	if err != nil {
+		log.Errorf("Error getting monthly transaction amounts per type from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
 	transactionTypes := make(map[string]float64)
 
 	if income {
@@ -82,19 +96,14 @@ func (h *APIHandler) HandleGetTransactionsYearInfoPerType(c echo.Context) error
 		}
 	}
 
-	for month := 1; month < 13; month++ {
-		transactionTypesMonth, err := getTransactionsPerType(c, h.TransactionRepository, month, income)
-		if err != nil {
-			return err
+	for _, monthTypeAmount := range *monthTypeAmounts {
+		if _, ok := transactionTypes[monthTypeAmount.Type]; !ok {
+			continue
 		}
-
-		for key := range transactionTypes {
-			if val, ok := transactionTypesMonth[key]; ok {
-				transactionTypes[key] += val
-			} else {
-				log.Errorf("Error getting yearly transaction info per type: invalid key")
-				return c.String(http.StatusInternalServerError, "Something went wrong")
-			}
+		if income {
+			transactionTypes[monthTypeAmount.Type] += monthTypeAmount.Income
+		} else {
+			transactionTypes[monthTypeAmount.Type] += math.Abs(monthTypeAmount.Expense)
 		}
 	}
 
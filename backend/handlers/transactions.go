@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"github.com/tvgelderen/fiscora/repository"
@@ -70,6 +74,27 @@ func (h *APIHandler) HandleGetUnassignedTransactions(c echo.Context) error {
 	return c.JSON(http.StatusOK, types.ToBaseTransactionReturns(transactions))
 }
 
+func (h *APIHandler) HandleGetTransaction(c echo.Context) error {
+	userId := getUserId(c)
+	transactionId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing transaction id from request: %v", err.Error())
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	transaction, err := h.TransactionRepository.GetFullById(c.Request().Context(), userId, int32(transactionId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting transaction from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	setETag(c, transaction.Version)
+	return c.JSON(http.StatusOK, types.ToTransactionReturn(*transaction))
+}
+
 func (h *APIHandler) HandleCreateTransaction(c echo.Context) error {
 	decoder := json.NewDecoder(c.Request().Body)
 	transaction := types.TransactionForm{}
@@ -84,17 +109,22 @@ func (h *APIHandler) HandleCreateTransaction(c echo.Context) error {
 	userId := getUserId(c)
 
 	if transaction.Recurring {
-		err = h.TransactionRepository.AddRecurring(c.Request().Context(), repository.AddRecurringParams{
-			Params: repository.CreateRecurringTransactionParams{
-				UserID:       userId,
-				StartDate:    transaction.StartDate.Time,
-				EndDate:      transaction.EndDate.Time,
-				Interval:     transaction.Interval.String,
-				DaysInterval: transaction.DaysInterval.NullInt32,
-			},
-			Amount:      transaction.Amount,
-			Description: transaction.Description,
-			Type:        transaction.Type,
+		var rrule string
+		rrule, err = transaction.RecurrenceRule()
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Invalid recurrence rule")
+		}
+
+		err = h.TransactionRepository.AddRecurring(c.Request().Context(), repository.CreateRecurringTransactionParams{
+			UserID:       userId,
+			StartDate:    transaction.StartDate.Time,
+			EndDate:      transaction.EndDate.NullTime,
+			Interval:     transaction.Interval.String,
+			DaysInterval: transaction.DaysInterval.NullInt32,
+			Rrule:        rrule,
+			Description:  transaction.Description,
+			Amount:       strconv.FormatFloat(transaction.Amount, 'f', -1, 64),
+			Type:         transaction.Type,
 		})
 	} else {
 		_, err = h.TransactionRepository.Add(c.Request().Context(), repository.CreateTransactionParams{
@@ -131,6 +161,14 @@ func (h *APIHandler) HandleUpdateTransaction(c echo.Context) error {
 		log.Errorf("Error parsing transaction id from request: %v", err.Error())
 		return c.String(http.StatusBadRequest, "Error decoding request body")
 	}
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+	scope, err := getRecurrenceScope(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid scope")
+	}
 
 	transaction, err := h.TransactionRepository.GetById(c.Request().Context(), userId, int32(transactionId))
 	if err != nil {
@@ -141,44 +179,179 @@ func (h *APIHandler) HandleUpdateTransaction(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
-	if transaction.RecurringTransactionID.Valid {
-		err = h.TransactionRepository.UpdateRecurring(c.Request().Context(), repository.UpdateRecurringParams{
-			Params: repository.UpdateRecurringTransactionParams{
-				ID:           transaction.RecurringTransactionID.Int32,
-				UserID:       userId,
-				StartDate:    transactionForm.StartDate.Time,
-				EndDate:      transactionForm.EndDate.Time,
-				Interval:     transactionForm.Interval.String,
-				DaysInterval: transactionForm.DaysInterval.NullInt32,
-			},
-			Amount:      transactionForm.Amount,
+	return h.updateTransaction(c, transaction, transactionForm, version, scope)
+}
+
+// HandleUpdateOccurrence updates the occurrence of a recurring transaction on
+// a date, which also works for occurrences that are only projected.
+func (h *APIHandler) HandleUpdateOccurrence(c echo.Context) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	transactionForm := types.TransactionForm{}
+	err := decoder.Decode(&transactionForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	userId := getUserId(c)
+	recurringTransactionId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing recurring transaction id from request: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid date format")
+	}
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+	scope, err := getRecurrenceScope(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid scope")
+	}
+
+	transaction, err := h.TransactionRepository.GetOccurrence(c.Request().Context(), userId, int32(recurringTransactionId), date)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting occurrence: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.updateTransaction(c, transaction, transactionForm, version, scope)
+}
+
+// updateTransaction applies the form to a transaction. For an occurrence of a
+// recurring transaction the scope decides whether only this occurrence, this
+// and the following occurrences, or the whole series changes. For this and
+// the following occurrences the series is split, and the new series starts
+// at the occurrence, or at the start date of the form when it is later.
+func (h *APIHandler) updateTransaction(c echo.Context, transaction *repository.Transaction, transactionForm types.TransactionForm, version sql.NullInt32, scope string) error {
+	userId := transaction.UserID
+	amount := strconv.FormatFloat(transactionForm.Amount, 'f', -1, 64)
+
+	if !transaction.RecurringTransactionID.Valid || scope == repository.RecurrenceScopeOccurrence {
+		params := repository.UpdateTransactionParams{
+			ID:          transaction.ID,
+			UserID:      userId,
+			Date:        transactionForm.StartDate.Time,
+			Amount:      amount,
 			Description: transactionForm.Description,
 			Type:        transactionForm.Type,
-		})
+			Version:     version,
+		}
+
+		id := transaction.ID
+		var err error
+		if transaction.RecurringTransactionID.Valid {
+			id, err = h.TransactionRepository.UpdateOccurrence(c.Request().Context(), *transaction, params)
+		} else {
+			err = h.TransactionRepository.Update(c.Request().Context(), params)
+		}
 		if err != nil {
-			log.Errorf("Error updating recurring transaction: %v", err.Error())
+			if repository.NoRowsFound(err) {
+				return c.NoContent(http.StatusNotFound)
+			}
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return c.String(http.StatusPreconditionFailed, "Transaction was changed by someone else")
+			}
+			log.Errorf("Error updating transaction: %v", err.Error())
 			return c.String(http.StatusInternalServerError, "Something went wrong")
 		}
 
+		h.setTransactionETag(c, userId, id)
 		return c.NoContent(http.StatusNoContent)
 	}
 
-	err = h.TransactionRepository.Update(c.Request().Context(), repository.UpdateTransactionParams{
-		ID:          int32(transactionId),
-		UserID:      userId,
-		Date:        transactionForm.StartDate.Time,
-		Amount:      strconv.FormatFloat(transactionForm.Amount, 'f', -1, 64),
-		Description: transactionForm.Description,
-		Type:        transactionForm.Type,
-	})
+	rrule, err := transactionForm.RecurrenceRule()
 	if err != nil {
-		log.Errorf("Error updating transaction: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Invalid recurrence rule")
+	}
+
+	isFirst, err := h.isFirstOccurrence(c, transaction)
+	if err != nil {
+		log.Errorf("Error getting recurring transaction: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
+	occurrence := repository.OccurrenceVersion{
+		TransactionID: transaction.ID,
+		Version:       version,
+	}
+	if scope == repository.RecurrenceScopeFollowing && !isFirst {
+		err = h.TransactionRepository.SplitRecurring(c.Request().Context(), repository.SplitRecurringParams{
+			UserID:     userId,
+			ID:         transaction.RecurringTransactionID.Int32,
+			Date:       transaction.Date,
+			Occurrence: occurrence,
+			Params: repository.CreateRecurringTransactionParams{
+				UserID:       userId,
+				StartDate:    transactionForm.StartDate.Time,
+				EndDate:      transactionForm.EndDate.NullTime,
+				Interval:     transactionForm.Interval.String,
+				DaysInterval: transactionForm.DaysInterval.NullInt32,
+				Rrule:        rrule,
+				Description:  transactionForm.Description,
+				Amount:       amount,
+				Type:         transactionForm.Type,
+			},
+		})
+	} else {
+		err = h.TransactionRepository.UpdateRecurring(c.Request().Context(), repository.UpdateRecurringTransactionParams{
+			ID:           transaction.RecurringTransactionID.Int32,
+			UserID:       userId,
+			StartDate:    transactionForm.StartDate.Time,
+			EndDate:      transactionForm.EndDate.NullTime,
+			Interval:     transactionForm.Interval.String,
+			DaysInterval: transactionForm.DaysInterval.NullInt32,
+			Rrule:        rrule,
+			Description:  transactionForm.Description,
+			Amount:       amount,
+			Type:         transactionForm.Type,
+		}, occurrence)
+	}
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Transaction was changed by someone else")
+		}
+		if errors.Is(err, repository.ErrSplitBeforeDate) {
+			return c.String(http.StatusBadRequest, "Start date is before the occurrence")
+		}
+		log.Errorf("Error updating recurring transaction: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	h.setTransactionETag(c, userId, transaction.ID)
 	return c.NoContent(http.StatusNoContent)
 }
 
+// isFirstOccurrence reports whether the transaction is the start of its
+// series, in which case changing it and all following occurrences changes
+// the whole series.
+func (h *APIHandler) isFirstOccurrence(c echo.Context, transaction *repository.Transaction) (bool, error) {
+	recurringTransaction, err := h.TransactionRepository.GetRecurring(c.Request().Context(), transaction.UserID, transaction.RecurringTransactionID.Int32)
+	if err != nil {
+		return false, err
+	}
+
+	return !transaction.Date.After(recurringTransaction.StartDate), nil
+}
+
+// setTransactionETag sets the ETag of a transaction after it was changed. The
+// transaction may no longer exist after rewriting a recurring series.
+func (h *APIHandler) setTransactionETag(c echo.Context, userId uuid.UUID, id int32) {
+	transaction, err := h.TransactionRepository.GetById(c.Request().Context(), userId, id)
+	if err == nil {
+		setETag(c, transaction.Version)
+	}
+}
+
 func (h *APIHandler) HandleRemoveTransactionFromBudget(c echo.Context) error {
 	userId := getUserId(c)
 	transactionId, err := strconv.ParseInt(c.Param("id"), 10, 32)
@@ -187,11 +360,19 @@ func (h *APIHandler) HandleRemoveTransactionFromBudget(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	err = h.TransactionRepository.RemoveBudgetId(c.Request().Context(), userId, int32(transactionId))
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+
+	err = h.TransactionRepository.RemoveBudgetId(c.Request().Context(), userId, int32(transactionId), version)
 	if err != nil {
 		if repository.NoRowsFound(err) {
 			return c.NoContent(http.StatusNotFound)
 		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Transaction was changed by someone else")
+		}
 		log.Errorf("Error deleting transaction: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
@@ -207,6 +388,15 @@ func (h *APIHandler) HandleDeleteTransaction(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+	scope, err := getRecurrenceScope(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid scope")
+	}
+
 	transaction, err := h.TransactionRepository.GetById(c.Request().Context(), userId, int32(transactionId))
 	if err != nil {
 		if repository.NoRowsFound(err) {
@@ -216,12 +406,60 @@ func (h *APIHandler) HandleDeleteTransaction(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
 
-	if transaction.RecurringTransactionID.Valid {
-		err = h.TransactionRepository.RemoveRecurring(c.Request().Context(), userId, transaction.RecurringTransactionID.Int32)
+	return h.deleteTransaction(c, transaction, version, scope)
+}
+
+// HandleDeleteOccurrence deletes the occurrence of a recurring transaction on
+// a date, which also works for occurrences that are only projected.
+func (h *APIHandler) HandleDeleteOccurrence(c echo.Context) error {
+	userId := getUserId(c)
+	recurringTransactionId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing recurring transaction id from request: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid date format")
+	}
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid If-Match header")
+	}
+	scope, err := getRecurrenceScope(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid scope")
+	}
+
+	transaction, err := h.TransactionRepository.GetOccurrence(c.Request().Context(), userId, int32(recurringTransactionId), date)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error getting occurrence: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return h.deleteTransaction(c, transaction, version, scope)
+}
+
+func (h *APIHandler) deleteTransaction(c echo.Context, transaction *repository.Transaction, version sql.NullInt32, scope string) error {
+	userId := transaction.UserID
+
+	if !transaction.RecurringTransactionID.Valid || scope == repository.RecurrenceScopeOccurrence {
+		var err error
+		if transaction.RecurringTransactionID.Valid {
+			err = h.TransactionRepository.RemoveOccurrence(c.Request().Context(), *transaction, version)
+		} else {
+			err = h.TransactionRepository.Remove(c.Request().Context(), userId, transaction.ID, version)
+		}
 		if err != nil {
 			if repository.NoRowsFound(err) {
 				return c.NoContent(http.StatusNotFound)
 			}
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return c.String(http.StatusPreconditionFailed, "Transaction was changed by someone else")
+			}
 			log.Errorf("Error deleting transaction: %v", err.Error())
 			return c.String(http.StatusInternalServerError, "Something went wrong")
 		}
@@ -229,8 +467,28 @@ func (h *APIHandler) HandleDeleteTransaction(c echo.Context) error {
 		return c.NoContent(http.StatusNoContent)
 	}
 
-	err = h.TransactionRepository.Remove(c.Request().Context(), userId, int32(transactionId))
+	isFirst, err := h.isFirstOccurrence(c, transaction)
+	if err != nil {
+		log.Errorf("Error getting recurring transaction: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	occurrence := repository.OccurrenceVersion{
+		TransactionID: transaction.ID,
+		Version:       version,
+	}
+	if scope == repository.RecurrenceScopeFollowing && !isFirst {
+		err = h.TransactionRepository.EndRecurring(c.Request().Context(), userId, transaction.RecurringTransactionID.Int32, transaction.Date, occurrence)
+	} else {
+		err = h.TransactionRepository.RemoveRecurring(c.Request().Context(), userId, transaction.RecurringTransactionID.Int32, occurrence)
+	}
 	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.String(http.StatusPreconditionFailed, "Transaction was changed by someone else")
+		}
 		log.Errorf("Error deleting transaction: %v", err.Error())
 		return c.String(http.StatusInternalServerError, "Something went wrong")
 	}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleBulkTransactions(c echo.Context) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	bulkForm := types.TransactionBulkForm{}
+	err := decoder.Decode(&bulkForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	if bulkForm.Ids == nil && bulkForm.Filter == nil {
+		return c.String(http.StatusBadRequest, "Either ids or filter is required")
+	}
+
+	switch bulkForm.Operation {
+	case repository.BulkOperationSetType:
+		if !slices.Contains(repository.IncomeTypes, bulkForm.Type) && !slices.Contains(repository.ExpenseTypes, bulkForm.Type) {
+			return c.String(http.StatusBadRequest, "Invalid transaction type")
+		}
+	case repository.BulkOperationAssignBudget:
+		if bulkForm.BudgetID == "" || bulkForm.BudgetExpenseID <= 0 {
+			return c.String(http.StatusBadRequest, "Invalid budget")
+		}
+	case repository.BulkOperationAddTags, repository.BulkOperationRemoveTags:
+		if len(bulkForm.Tags) == 0 {
+			return c.String(http.StatusBadRequest, "No tags provided")
+		}
+		for _, tag := range bulkForm.Tags {
+			if utf8.RuneCountInString(tag) > repository.MaxTagLength {
+				return c.String(http.StatusBadRequest, "Tag is too long")
+			}
+		}
+	}
+
+	params := types.ToBulkParams(&bulkForm)
+	params.UserID = getUserId(c)
+
+	result, err := h.TransactionRepository.Bulk(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidBulkOperation) {
+			return c.String(http.StatusBadRequest, "Invalid operation")
+		}
+		if errors.Is(err, repository.ErrEmptyBulkSelection) {
+			return c.String(http.StatusBadRequest, "Either ids or filter is required")
+		}
+		if errors.Is(err, repository.ErrEmptyBulkFilter) {
+			return c.String(http.StatusBadRequest, "Filter has no criteria")
+		}
+		log.Errorf("Error applying bulk operation: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.TransactionBulkReturn{
+		Operation: result.Operation,
+		Matched:   result.Matched,
+		Affected:  result.Affected,
+	})
+}
+
+func (h *APIHandler) HandleGetTags(c echo.Context) error {
+	userId := getUserId(c)
+	tags, err := h.TransactionRepository.GetTags(c.Request().Context(), userId)
+	if err != nil {
+		log.Errorf("Error getting tags from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
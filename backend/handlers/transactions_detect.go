@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/recurrence"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+// detectionMonths is how far back the history is searched by default. Yearly
+// series need three occurrences, so this covers a little over two years.
+const detectionMonths = 27
+
+func (h *APIHandler) HandleDetectRecurringTransactions(c echo.Context) error {
+	months := detectionMonths
+	if monthsParam := c.QueryParam("months"); monthsParam != "" {
+		parsed, err := strconv.ParseInt(monthsParam, 10, 16)
+		if err != nil || parsed < 1 {
+			return c.String(http.StatusBadRequest, "Invalid months")
+		}
+		months = int(parsed)
+	}
+
+	userId := getUserId(c)
+	since := time.Now().UTC().AddDate(0, -months, 0)
+
+	proposals, err := h.TransactionRepository.DetectRecurring(c.Request().Context(), userId, since)
+	if err != nil {
+		log.Errorf("Error detecting recurring transactions: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToRecurringProposalReturns(proposals))
+}
+
+func (h *APIHandler) HandleAcceptRecurringProposal(c echo.Context) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	proposal := types.RecurringProposalForm{}
+	err := decoder.Decode(&proposal)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return c.String(http.StatusBadRequest, "Error decoding request body")
+	}
+
+	if len(proposal.TransactionIDs) == 0 {
+		return c.String(http.StatusBadRequest, "No transactions provided")
+	}
+	if !proposal.StartDate.Valid || !proposal.NextDate.Valid || !proposal.NextDate.Time.After(proposal.StartDate.Time) {
+		return c.String(http.StatusBadRequest, "Invalid dates")
+	}
+	if proposal.Interval.String == "" {
+		proposal.Interval.String = recurrence.IntervalCustom
+	}
+
+	rrule, err := proposal.RecurrenceRule()
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid recurrence rule")
+	}
+
+	userId := getUserId(c)
+	recurringTransaction, err := h.TransactionRepository.ConvertToRecurring(c.Request().Context(), repository.ConvertToRecurringParams{
+		UserID:         userId,
+		TransactionIDs: proposal.TransactionIDs,
+		NextDate:       proposal.NextDate.Time,
+		Params: repository.CreateRecurringTransactionParams{
+			UserID:       userId,
+			StartDate:    proposal.StartDate.Time,
+			EndDate:      proposal.EndDate.NullTime,
+			Interval:     proposal.Interval.String,
+			DaysInterval: proposal.DaysInterval.NullInt32,
+			Rrule:        rrule,
+			Description:  proposal.Description,
+			Amount:       strconv.FormatFloat(proposal.Amount, 'f', -1, 64),
+			Type:         proposal.Type,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrTransactionsUnavailable) {
+			return c.String(http.StatusConflict, "Transactions can not be part of this series")
+		}
+		log.Errorf("Error converting transactions to recurring transaction: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusCreated, types.TransactionRecurring{
+		ID:           types.NewNullInt(sql.NullInt32{Int32: recurringTransaction.ID, Valid: true}),
+		StartDate:    types.NewNullTime(sql.NullTime{Time: recurringTransaction.StartDate, Valid: true}),
+		EndDate:      types.NewNullTime(recurringTransaction.EndDate),
+		Interval:     types.NewNullString(sql.NullString{String: recurringTransaction.Interval, Valid: true}),
+		DaysInterval: types.NewNullInt(recurringTransaction.DaysInterval),
+		RRule:        types.NewNullString(sql.NullString{String: recurringTransaction.Rrule, Valid: true}),
+	})
+}
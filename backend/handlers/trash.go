@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+	"github.com/tvgelderen/fiscora/types"
+)
+
+func (h *APIHandler) HandleGetTrash(c echo.Context) error {
+	userId := getUserId(c)
+
+	transactions, err := h.TransactionRepository.GetDeleted(c.Request().Context(), userId)
+	if err != nil {
+		log.Errorf("Error getting deleted transactions from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	budgets, err := h.BudgetRepository.GetDeleted(c.Request().Context(), userId)
+	if err != nil {
+		log.Errorf("Error getting deleted budgets from db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	returnBudgets := make([]types.BudgetReturn, len(*budgets))
+	for idx, budget := range *budgets {
+		returnBudgets[idx] = types.ToBudgetReturn(&budget)
+	}
+
+	return c.JSON(http.StatusOK, types.TrashReturn{
+		Transactions: types.ToTransactionReturns(transactions),
+		Budgets:      returnBudgets,
+	})
+}
+
+func (h *APIHandler) HandleRestoreTransaction(c echo.Context) error {
+	userId := getUserId(c)
+	transactionId, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Errorf("Error parsing transaction id from request: %v", err.Error())
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	err = h.TransactionRepository.Restore(c.Request().Context(), userId, int32(transactionId))
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error restoring transaction: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *APIHandler) HandleRestoreBudget(c echo.Context) error {
+	userId := getUserId(c)
+	budgetId := c.Param("id")
+	if budgetId == "" {
+		log.Errorf("Error parsing budget id from request")
+		return c.String(http.StatusBadRequest, "Invalid url parameter")
+	}
+
+	err := h.BudgetRepository.Restore(c.Request().Context(), userId, budgetId)
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error restoring budget: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/report"
 	"github.com/tvgelderen/fiscora/repository"
 	"github.com/tvgelderen/fiscora/types"
 )
@@ -28,3 +31,57 @@ func (h *APIHandler) HandleGetMe(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, types.ToUser(user))
 }
+
+func (h *APIHandler) HandleUpdateSettings(c echo.Context) error {
+	settingsForm, ok := decodeUserSettingsForm(c)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Invalid settings")
+	}
+
+	user, err := h.UserRepository.UpdateSettings(c.Request().Context(), repository.UpdateUserSettingsParams{
+		ID:              getUserId(c),
+		WeekStart:       int16(settingsForm.WeekStart),
+		FiscalYearStart: int16(settingsForm.FiscalYearStart),
+	})
+	if err != nil {
+		if repository.NoRowsFound(err) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Errorf("Error updating user settings in db: %v", err.Error())
+		return c.String(http.StatusInternalServerError, "Something went wrong")
+	}
+
+	return c.JSON(http.StatusOK, types.ToUser(user))
+}
+
+func decodeUserSettingsForm(c echo.Context) (*types.UserSettingsForm, bool) {
+	decoder := json.NewDecoder(c.Request().Body)
+	settingsForm := types.UserSettingsForm{}
+	err := decoder.Decode(&settingsForm)
+	if err != nil {
+		log.Errorf("Error decoding request body: %v", err.Error())
+		return nil, false
+	}
+
+	if settingsForm.WeekStart < int(time.Sunday) || settingsForm.WeekStart > int(time.Saturday) {
+		return nil, false
+	}
+	if settingsForm.FiscalYearStart < int(time.January) || settingsForm.FiscalYearStart > int(time.December) {
+		return nil, false
+	}
+
+	return &settingsForm, true
+}
+
+// getCalendar returns how the user divides time in reports.
+func (h *APIHandler) getCalendar(c echo.Context) (report.Calendar, error) {
+	user, err := h.UserRepository.GetById(c.Request().Context(), getUserId(c))
+	if err != nil {
+		return report.Calendar{}, err
+	}
+
+	return report.Calendar{
+		WeekStart:       time.Weekday(user.WeekStart),
+		FiscalYearStart: time.Month(user.FiscalYearStart),
+	}, nil
+}
@@ -2,33 +2,65 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"math/rand"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/tvgelderen/fiscora/auth"
+	"github.com/tvgelderen/fiscora/config"
+	"github.com/tvgelderen/fiscora/notify"
 	"github.com/tvgelderen/fiscora/repository"
 	"github.com/tvgelderen/fiscora/types"
 )
 
 type APIHandler struct {
-	UserRepository        repository.IUserRepository
-	TransactionRepository repository.ITransactionRepository
-	BudgetRepository      repository.IBudgetRepository
-	AuthService           *auth.AuthService
+	UserRepository         repository.IUserRepository
+	TransactionRepository  repository.ITransactionRepository
+	BudgetRepository       repository.IBudgetRepository
+	AuditRepository        repository.IAuditRepository
+	IdempotencyRepository  repository.IIdempotencyRepository
+	CalendarRepository     repository.ICalendarRepository
+	NotificationRepository repository.INotificationRepository
+	GoalRepository         repository.IGoalRepository
+	LoanRepository         repository.ILoanRepository
+	InvestmentRepository   repository.IInvestmentRepository
+	AuthService            *auth.AuthService
+	Notifier               notify.Notifier
 }
 
 func NewAPIHandler(db *sql.DB, auth *auth.AuthService) *APIHandler {
 	return &APIHandler{
-		UserRepository:        repository.CreateUserRepository(db),
-		TransactionRepository: repository.CreateTransactionRepository(db),
-		BudgetRepository:      repository.CreateBudgetRepository(db),
-		AuthService:           auth,
+		UserRepository:         repository.CreateUserRepository(db),
+		TransactionRepository:  repository.CreateTransactionRepository(db, config.Envs.RecurringHorizon),
+		BudgetRepository:       repository.CreateBudgetRepository(db),
+		AuditRepository:        repository.CreateAuditRepository(db),
+		IdempotencyRepository:  repository.CreateIdempotencyRepository(db),
+		CalendarRepository:     repository.CreateCalendarRepository(db),
+		NotificationRepository: repository.CreateNotificationRepository(db),
+		GoalRepository:         repository.CreateGoalRepository(db),
+		LoanRepository:         repository.CreateLoanRepository(db),
+		InvestmentRepository:   repository.CreateInvestmentRepository(db),
+		AuthService:            auth,
+		Notifier:               newNotifier(),
 	}
 }
 
+// newNotifier sends notifications by email when SMTP is configured, and only
+// logs them otherwise.
+func newNotifier() notify.Notifier {
+	env := config.Envs
+	if env.SMTPHost == "" {
+		return notify.LogNotifier{}
+	}
+
+	return notify.NewSMTPNotifier(env.SMTPHost, env.SMTPPort, env.SMTPUsername, env.SMTPPassword, env.SMTPFrom)
+}
+
 func getUserId(c echo.Context) uuid.UUID {
 	return c.Get(userIdKey).(uuid.UUID)
 }
@@ -47,7 +79,7 @@ func getYear(c echo.Context) int {
 	yearParam := c.QueryParam("year")
 	year, err := strconv.ParseInt(yearParam, 10, 16)
 	if err != nil {
-		year = int64(time.Now().Month())
+		year = int64(time.Now().Year())
 	}
 
 	return int(year)
@@ -63,6 +95,41 @@ func getEndDate(c echo.Context) (time.Time, error) {
 	return time.Parse("2006-01-02", endDate)
 }
 
+// getRecurrenceScope returns which occurrences of a recurring transaction a
+// change applies to. Without a scope the whole series changes.
+func getRecurrenceScope(c echo.Context) (string, error) {
+	scope := c.QueryParam("scope")
+	if scope == "" {
+		return repository.RecurrenceScopeSeries, nil
+	}
+	if !slices.Contains(repository.RecurrenceScopes, scope) {
+		return "", fmt.Errorf("invalid scope: %s", scope)
+	}
+
+	return scope, nil
+}
+
+// getIfMatch returns the version from the If-Match header. A missing header or
+// a wildcard means the change does not depend on a specific version.
+func getIfMatch(c echo.Context) (sql.NullInt32, error) {
+	ifMatch := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return sql.NullInt32{}, nil
+	}
+
+	ifMatch = strings.TrimPrefix(ifMatch, "W/")
+	version, err := strconv.ParseInt(strings.Trim(ifMatch, `"`), 10, 32)
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+
+	return sql.NullInt32{Int32: int32(version), Valid: true}, nil
+}
+
+func setETag(c echo.Context, version int32) {
+	c.Response().Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
 func getMonthRange(month int, year int) types.DateRange {
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, -1)
@@ -72,6 +139,15 @@ func getMonthRange(month int, year int) types.DateRange {
 	}
 }
 
+func getYearRange(year int) types.DateRange {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, -1)
+	return types.DateRange{
+		Start: start,
+		End:   end,
+	}
+}
+
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 func generateRandomString(length int) string {
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+// CloseBudgetPeriods closes the periods of periodic budgets that have ended
+// and starts their next period.
+func CloseBudgetPeriods(budgetRepository repository.IBudgetRepository) Job {
+	return Job{
+		Name:     "close-budget-periods",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			nrows, err := budgetRepository.ClosePeriods(ctx)
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Closed %d budget periods", nrows)
+			}
+
+			return nil
+		},
+	}
+}
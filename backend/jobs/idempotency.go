@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+// PurgeIdempotencyKeys deletes idempotency keys that are older than the ttl
+// and can no longer be replayed.
+func PurgeIdempotencyKeys(idempotencyRepository repository.IIdempotencyRepository, ttl time.Duration) Job {
+	return Job{
+		Name:     "purge-idempotency-keys",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			nrows, err := idempotencyRepository.PurgeExpired(ctx, time.Now().UTC().Add(-ttl))
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Purged %d expired idempotency keys", nrows)
+			}
+
+			return nil
+		},
+	}
+}
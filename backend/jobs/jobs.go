@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job once immediately and then on its interval until the
+// context is cancelled.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, job)
+	}
+}
+
+func run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil {
+			log.Errorf("Error running job %s: %v", job.Name, err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
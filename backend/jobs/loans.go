@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+// PostLoanPayments books the loan payments that have come due as principal
+// and interest transactions.
+func PostLoanPayments(loanRepository repository.ILoanRepository) Job {
+	return Job{
+		Name:     "post-loan-payments",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			nrows, err := loanRepository.PostPayments(ctx)
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Posted %d loan payments", nrows)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+// MaterializeRecurring stores the occurrences of recurring transactions that
+// have come within the horizon.
+func MaterializeRecurring(transactionRepository repository.ITransactionRepository) Job {
+	return Job{
+		Name:     "materialize-recurring",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			nrows, err := transactionRepository.Materialize(ctx)
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Materialized %d recurring transactions", nrows)
+			}
+
+			return nil
+		},
+	}
+}
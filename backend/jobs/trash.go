@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+// PurgeTrash permanently deletes transactions and budgets that have been in
+// the trash for longer than the retention period.
+func PurgeTrash(transactionRepository repository.ITransactionRepository, budgetRepository repository.IBudgetRepository, retentionDays int) Job {
+	return Job{
+		Name:     "purge-trash",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			before := time.Now().UTC().AddDate(0, 0, -retentionDays)
+
+			nrows, err := transactionRepository.PurgeDeleted(ctx, before)
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Purged %d transactions from the trash", nrows)
+			}
+
+			nrows, err = budgetRepository.PurgeDeleted(ctx, before)
+			if err != nil {
+				return err
+			}
+			if nrows > 0 {
+				log.Infof("Purged %d budgets from the trash", nrows)
+			}
+
+			return nil
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"github.com/tvgelderen/fiscora/auth"
 	"github.com/tvgelderen/fiscora/config"
 	"github.com/tvgelderen/fiscora/handlers"
+	"github.com/tvgelderen/fiscora/jobs"
 	"github.com/tvgelderen/fiscora/seed"
 )
 
@@ -39,8 +41,17 @@ func main() {
 	authService := auth.NewAuthService()
 	handler := handlers.NewAPIHandler(conn, authService)
 
+	jobs.Start(context.Background(),
+		jobs.PurgeTrash(handler.TransactionRepository, handler.BudgetRepository, env.TrashRetentionDays),
+		jobs.PurgeIdempotencyKeys(handler.IdempotencyRepository, env.IdempotencyKeyTTL),
+		jobs.MaterializeRecurring(handler.TransactionRepository),
+		jobs.CloseBudgetPeriods(handler.BudgetRepository),
+		jobs.PostLoanPayments(handler.LoanRepository),
+	)
+
 	e := echo.New()
 
+	e.Use(middleware.RequestID())
 	e.Use(middleware.CORSWithConfig(middleware.DefaultCORSConfig))
 	e.Use(middleware.Logger())
 
@@ -53,14 +64,23 @@ func main() {
 
 	users := base.Group("/users", handler.AuthorizeEndpoint)
 	users.GET("/me", handler.HandleGetMe)
+	users.PUT("/me/settings", handler.HandleUpdateSettings)
 
-	transactions := base.Group("/transactions", handler.AuthorizeEndpoint)
+	transactions := base.Group("/transactions", handler.AuthorizeEndpoint, handler.Idempotent, handler.EvaluateBudgetAlerts)
 	transactions.GET("", handler.HandleGetTransactions)
 	transactions.POST("", handler.HandleCreateTransaction)
+	transactions.GET("/:id", handler.HandleGetTransaction)
 	transactions.PUT("/:id", handler.HandleUpdateTransaction)
 	transactions.DELETE("/:id", handler.HandleDeleteTransaction)
 	transactions.DELETE("/:id/budget", handler.HandleRemoveTransactionFromBudget)
+	transactions.GET("/recurring/detect", handler.HandleDetectRecurringTransactions)
+	transactions.POST("/recurring/detect/accept", handler.HandleAcceptRecurringProposal)
+	transactions.PUT("/recurring/:id/occurrences/:date", handler.HandleUpdateOccurrence)
+	transactions.DELETE("/recurring/:id/occurrences/:date", handler.HandleDeleteOccurrence)
+	transactions.POST("/:id/restore", handler.HandleRestoreTransaction)
+	transactions.POST("/bulk", handler.HandleBulkTransactions)
 	transactions.GET("/unassigned", handler.HandleGetUnassignedTransactions)
+	transactions.GET("/tags", handler.HandleGetTags)
 	transactions.GET("/types/intervals", handler.HandleGetTransactionIntervals)
 	transactions.GET("/types/income", handler.HandleGetIncomeTypes)
 	transactions.GET("/types/expense", handler.HandleGetExpenseTypes)
@@ -69,14 +89,86 @@ func main() {
 	transactions.GET("/summary/year", handler.HandleGetTransactionYearInfo)
 	transactions.GET("/summary/year/type", handler.HandleGetTransactionsYearInfoPerType)
 
-	budgets := base.Group("/budgets", handler.AuthorizeEndpoint)
+	budgets := base.Group("/budgets", handler.AuthorizeEndpoint, handler.Idempotent, handler.EvaluateBudgetAlerts)
 	budgets.GET("", handler.HandleGetBudgets)
 	budgets.POST("", handler.HandleCreateBudget)
+	budgets.GET("/templates", handler.HandleGetBudgetTemplates)
+	budgets.DELETE("/templates/:id", handler.HandleDeleteBudgetTemplate)
+	budgets.POST("/templates/:id/budgets", handler.HandleCreateBudgetFromTemplate)
 	budgets.GET("/:id", handler.HandleGetBudget)
+	budgets.POST("/:id/template", handler.HandleCreateBudgetTemplate)
+	budgets.POST("/:id/clone", handler.HandleCloneBudget)
+	budgets.GET("/:id/periods", handler.HandleGetBudgetPeriods)
+	budgets.GET("/:id/envelopes", handler.HandleGetEnvelopes)
+	budgets.GET("/:id/envelopes/history", handler.HandleGetEnvelopeHistory)
+	budgets.POST("/:id/envelopes/assign", handler.HandleAssignEnvelope)
+	budgets.POST("/:id/envelopes/move", handler.HandleMoveEnvelope)
 	budgets.PUT("/:id", handler.HandleUpdateBudget)
 	budgets.DELETE("/:id", handler.HandleDeleteBudget)
+	budgets.POST("/:id/restore", handler.HandleRestoreBudget)
 	budgets.DELETE("/:id/expenses/:expense_id", handler.HandleDeleteBudgetExpense)
 	budgets.POST("/:id/expenses/:expense_id/transactions", handler.HandleAddBudgetTransactions)
+	budgets.POST("/:id/transactions/exclude", handler.HandleExcludeBudgetTransactions)
+	budgets.POST("/:id/transactions/include", handler.HandleIncludeBudgetTransactions)
+
+	base.GET("/calendar/feed/:token", handler.HandleGetCalendarFeed)
+	// Not idempotent, the stored response would contain the feed token
+	calendar := base.Group("/calendar", handler.AuthorizeEndpoint)
+	calendar.GET("", handler.HandleGetCalendar)
+	calendar.POST("/token", handler.HandleCreateCalendarToken)
+	calendar.DELETE("/token", handler.HandleDeleteCalendarToken)
+
+	forecast := base.Group("/forecast", handler.AuthorizeEndpoint)
+	forecast.GET("", handler.HandleGetForecast)
+
+	goals := base.Group("/goals", handler.AuthorizeEndpoint, handler.Idempotent)
+	goals.GET("", handler.HandleGetGoals)
+	goals.POST("", handler.HandleCreateGoal)
+	goals.GET("/:id", handler.HandleGetGoal)
+	goals.PUT("/:id", handler.HandleUpdateGoal)
+	goals.DELETE("/:id", handler.HandleDeleteGoal)
+
+	loans := base.Group("/loans", handler.AuthorizeEndpoint, handler.Idempotent, handler.EvaluateBudgetAlerts)
+	loans.GET("", handler.HandleGetLoans)
+	loans.POST("", handler.HandleCreateLoan)
+	loans.GET("/:id", handler.HandleGetLoan)
+	loans.PUT("/:id", handler.HandleUpdateLoan)
+	loans.DELETE("/:id", handler.HandleDeleteLoan)
+	loans.POST("/:id/repayments", handler.HandleCreateLoanRepayment)
+	loans.DELETE("/:id/repayments/:repayment_id", handler.HandleDeleteLoanRepayment)
+	loans.POST("/:id/simulate", handler.HandleSimulateLoan)
+
+	investments := base.Group("/investments", handler.AuthorizeEndpoint, handler.Idempotent)
+	investments.GET("", handler.HandleGetInvestmentAccounts)
+	investments.POST("", handler.HandleCreateInvestmentAccount)
+	investments.GET("/prices", handler.HandleGetInvestmentPrices)
+	investments.POST("/prices", handler.HandleCreateInvestmentPrices)
+	investments.POST("/prices/import", handler.HandleImportInvestmentPrices)
+	investments.GET("/:id", handler.HandleGetInvestmentAccount)
+	investments.PUT("/:id", handler.HandleUpdateInvestmentAccount)
+	investments.DELETE("/:id", handler.HandleDeleteInvestmentAccount)
+	investments.POST("/:id/trades", handler.HandleCreateInvestmentTrade)
+	investments.DELETE("/:id/trades/:trade_id", handler.HandleDeleteInvestmentTrade)
+
+	reports := base.Group("/reports", handler.AuthorizeEndpoint)
+	reports.GET("/variance", handler.HandleGetVarianceReport)
+	reports.GET("/net-worth", handler.HandleGetNetWorth)
+	reports.GET("/summary", handler.HandleGetSummaryReport)
+	reports.GET("/comparison", handler.HandleGetComparisonReport)
+
+	notifications := base.Group("/notifications", handler.AuthorizeEndpoint, handler.Idempotent)
+	notifications.GET("", handler.HandleGetNotifications)
+	notifications.POST("/read", handler.HandleReadAllNotifications)
+	notifications.POST("/:id/read", handler.HandleReadNotification)
+
+	export := base.Group("/export", handler.AuthorizeEndpoint)
+	export.GET("", handler.HandleExport)
+
+	audit := base.Group("/audit", handler.AuthorizeEndpoint)
+	audit.GET("/:entity/:id", handler.HandleGetAuditLog)
+
+	trash := base.Group("/trash", handler.AuthorizeEndpoint)
+	trash.GET("", handler.HandleGetTrash)
 
 	e.Logger.Fatal(e.Start(env.Port))
 }
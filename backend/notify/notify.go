@@ -0,0 +1,28 @@
+// Package notify delivers notifications to users outside of the app.
+package notify
+
+import (
+	"context"
+
+	"github.com/labstack/gommon/log"
+)
+
+// Message is a notification for a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// LogNotifier only logs messages. It is used when no other notifier is
+// configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, message Message) error {
+	log.Infof("Notification for %s: %s", message.To, message.Subject)
+	return nil
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// catchMail accepts a single SMTP session on the listener and returns the
+// envelope and data of the message it received.
+func catchMail(t *testing.T, listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		write := func(line string) {
+			conn.Write([]byte(line + "\r\n"))
+		}
+
+		var lines []string
+		write("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				received <- lines
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+				lines = append(lines, strings.TrimSpace(line))
+				write("250 OK")
+			case command == "DATA":
+				write("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(dataLine, "\r\n"))
+				}
+				write("250 OK")
+			case command == "QUIT":
+				write("221 Bye")
+				received <- lines
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+
+	return received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := catchMail(t, listener)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	notifier := NewSMTPNotifier(host, port, "", "", "fiscora@example.com")
+	err = notifier.Notify(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Groceries is at 80% of its budget",
+		Body:    "You spent 400.00 of 500.00.\nThere is 100.00 left.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case lines := <-received:
+		mail := strings.Join(lines, "\n")
+		for _, want := range []string{
+			"MAIL FROM:<fiscora@example.com>",
+			"RCPT TO:<user@example.com>",
+			"To: user@example.com",
+			"Subject: Groceries is at 80% of its budget",
+			"You spent 400.00 of 500.00.\nThere is 100.00 left.",
+		} {
+			if !strings.Contains(mail, want) {
+				t.Errorf("mail does not contain %q:\n%s", want, mail)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+	}
+}
+
+// TestSMTPNotifierMailCatcher sends a message to a mail catcher such as
+// MailHog or Mailpit listening on NOTIFY_TEST_SMTP_ADDR, e.g. localhost:1025.
+func TestSMTPNotifierMailCatcher(t *testing.T) {
+	addr := os.Getenv("NOTIFY_TEST_SMTP_ADDR")
+	if addr == "" {
+		t.Skip("NOTIFY_TEST_SMTP_ADDR not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := NewSMTPNotifier(host, port, "", "", "fiscora@example.com")
+	err = notifier.Notify(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Test notification",
+		Body:    "This is a test notification.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package portfolio values investment holdings from their trades and prices.
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Trade kind
+const (
+	TradeBuy      string = "Buy"
+	TradeSell            = "Sell"
+	TradeDividend        = "Dividend"
+)
+
+var TradeKinds = []string{
+	TradeBuy,
+	TradeSell,
+	TradeDividend,
+}
+
+// Cost basis method
+const (
+	CostBasisFIFO    string = "FIFO"
+	CostBasisAverage        = "Average"
+)
+
+var CostBasisMethods = []string{
+	CostBasisFIFO,
+	CostBasisAverage,
+}
+
+var ErrInsufficientQuantity = errors.New("sold more than was held")
+
+// quantityEpsilon absorbs rounding in quantities with six decimals
+const quantityEpsilon = 1e-9
+
+// Trade buys or sells a quantity of a symbol at a price per unit, or pays a
+// dividend of the price per unit over the quantity. Fees are added to the cost
+// of a buy and deducted from the proceeds of a sell or dividend.
+type Trade struct {
+	Symbol   string
+	Kind     string
+	Date     time.Time
+	Quantity float64
+	Price    float64
+	Fees     float64
+}
+
+// Price is the price per unit of a symbol on a day.
+type Price struct {
+	Symbol string
+	Date   time.Time
+	Price  float64
+}
+
+// Holding is the position in a symbol. The price is the last known price,
+// which is the price of the last trade when there is no price for the symbol.
+// Closed positions are kept for their realized gains and dividends.
+type Holding struct {
+	Symbol         string
+	Quantity       float64
+	CostBasis      float64
+	Price          float64
+	PriceDate      time.Time
+	MarketValue    float64
+	UnrealizedGain float64
+	RealizedGain   float64
+	Dividends      float64
+}
+
+type Portfolio struct {
+	Holdings       []Holding
+	CostBasis      float64
+	MarketValue    float64
+	UnrealizedGain float64
+	RealizedGain   float64
+	Dividends      float64
+}
+
+type lot struct {
+	quantity float64
+	cost     float64
+}
+
+type position struct {
+	lots      []lot
+	realized  float64
+	dividends float64
+	price     Price
+}
+
+// Value values the holdings as of a day with the cost basis method. Trades
+// and prices after that day are ignored. It fails when a trade sells more
+// than was held at the time.
+func Value(trades []Trade, prices []Price, method string, asOf time.Time) (Portfolio, error) {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	positions := make(map[string]*position)
+	for _, trade := range sorted {
+		if trade.Date.After(asOf) {
+			continue
+		}
+
+		pos, ok := positions[trade.Symbol]
+		if !ok {
+			pos = &position{}
+			positions[trade.Symbol] = pos
+		}
+
+		switch trade.Kind {
+		case TradeBuy:
+			pos.lots = append(pos.lots, lot{
+				quantity: trade.Quantity,
+				cost:     trade.Quantity*trade.Price + trade.Fees,
+			})
+			pos.price = Price{Symbol: trade.Symbol, Date: trade.Date, Price: trade.Price}
+		case TradeSell:
+			cost, err := pos.sell(trade.Quantity, method)
+			if err != nil {
+				return Portfolio{}, err
+			}
+			pos.realized += trade.Quantity*trade.Price - trade.Fees - cost
+			pos.price = Price{Symbol: trade.Symbol, Date: trade.Date, Price: trade.Price}
+		case TradeDividend:
+			pos.dividends += trade.Quantity*trade.Price - trade.Fees
+		}
+	}
+
+	for _, price := range prices {
+		pos, ok := positions[price.Symbol]
+		if !ok || price.Date.After(asOf) || price.Date.Before(pos.price.Date) {
+			continue
+		}
+		pos.price = price
+	}
+
+	symbols := make([]string, 0, len(positions))
+	for symbol := range positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	portfolio := Portfolio{
+		Holdings: make([]Holding, len(symbols)),
+	}
+	for idx, symbol := range symbols {
+		pos := positions[symbol]
+
+		var quantity, cost float64
+		for _, lot := range pos.lots {
+			quantity += lot.quantity
+			cost += lot.cost
+		}
+		value := quantity * pos.price.Price
+
+		portfolio.Holdings[idx] = Holding{
+			Symbol:         symbol,
+			Quantity:       math.Round(quantity*1e6) / 1e6,
+			CostBasis:      round(cost),
+			Price:          pos.price.Price,
+			PriceDate:      pos.price.Date,
+			MarketValue:    round(value),
+			UnrealizedGain: round(value - cost),
+			RealizedGain:   round(pos.realized),
+			Dividends:      round(pos.dividends),
+		}
+		portfolio.CostBasis += cost
+		portfolio.MarketValue += value
+		portfolio.RealizedGain += pos.realized
+		portfolio.Dividends += pos.dividends
+	}
+	portfolio.UnrealizedGain = round(portfolio.MarketValue - portfolio.CostBasis)
+	portfolio.CostBasis = round(portfolio.CostBasis)
+	portfolio.MarketValue = round(portfolio.MarketValue)
+	portfolio.RealizedGain = round(portfolio.RealizedGain)
+	portfolio.Dividends = round(portfolio.Dividends)
+
+	return portfolio, nil
+}
+
+// sell removes a quantity from the lots and returns its cost. FIFO takes it
+// from the oldest lots first, average cost takes it from all lots in
+// proportion, so they keep the same cost per unit.
+func (pos *position) sell(quantity float64, method string) (float64, error) {
+	var held float64
+	for _, lot := range pos.lots {
+		held += lot.quantity
+	}
+	if quantity > held+quantityEpsilon {
+		return 0, ErrInsufficientQuantity
+	}
+
+	var cost float64
+	if method == CostBasisAverage {
+		share := min(quantity/held, 1)
+		for idx := range pos.lots {
+			cost += pos.lots[idx].cost * share
+			pos.lots[idx].quantity -= pos.lots[idx].quantity * share
+			pos.lots[idx].cost -= pos.lots[idx].cost * share
+		}
+	} else {
+		remaining := quantity
+		for idx := range pos.lots {
+			lot := &pos.lots[idx]
+			if remaining <= quantityEpsilon {
+				break
+			}
+			taken := min(lot.quantity, remaining)
+			part := lot.cost * taken / lot.quantity
+			cost += part
+			lot.cost -= part
+			lot.quantity -= taken
+			remaining -= taken
+		}
+	}
+
+	lots := pos.lots[:0]
+	for _, lot := range pos.lots {
+		if lot.quantity > quantityEpsilon {
+			lots = append(lots, lot)
+		}
+	}
+	pos.lots = lots
+
+	return cost, nil
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,98 @@
+package portfolio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var trades = []Trade{
+	{Symbol: "VWRL", Kind: TradeBuy, Date: date(2024, time.January, 10), Quantity: 10, Price: 100, Fees: 2},
+	{Symbol: "VWRL", Kind: TradeBuy, Date: date(2024, time.February, 10), Quantity: 10, Price: 120, Fees: 2},
+	{Symbol: "VWRL", Kind: TradeSell, Date: date(2024, time.March, 10), Quantity: 15, Price: 130, Fees: 4},
+	{Symbol: "VWRL", Kind: TradeDividend, Date: date(2024, time.March, 20), Quantity: 5, Price: 0.5},
+	{Symbol: "AAPL", Kind: TradeBuy, Date: date(2024, time.January, 5), Quantity: 2, Price: 180},
+	// After the day of the valuation
+	{Symbol: "AAPL", Kind: TradeBuy, Date: date(2024, time.May, 1), Quantity: 100, Price: 180},
+}
+
+var prices = []Price{
+	{Symbol: "VWRL", Date: date(2024, time.April, 1), Price: 140},
+	{Symbol: "AAPL", Date: date(2024, time.January, 1), Price: 170},
+	{Symbol: "AAPL", Date: date(2024, time.April, 1), Price: 200},
+	{Symbol: "AAPL", Date: date(2024, time.May, 1), Price: 300},
+}
+
+func TestValueFIFO(t *testing.T) {
+	portfolio, err := Value(trades, prices, CostBasisFIFO, date(2024, time.April, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(portfolio.Holdings) != 2 || portfolio.Holdings[0].Symbol != "AAPL" {
+		t.Fatalf("expected AAPL and VWRL, got %+v", portfolio.Holdings)
+	}
+
+	aapl := portfolio.Holdings[0]
+	if aapl.Quantity != 2 || aapl.Price != 200 || aapl.MarketValue != 400 || aapl.UnrealizedGain != 40 {
+		t.Errorf("expected 2 AAPL worth 400, got %+v", aapl)
+	}
+
+	// Sold all of the first lot (1002) and half of the second (601)
+	vwrl := portfolio.Holdings[1]
+	if vwrl.Quantity != 5 || vwrl.CostBasis != 601 {
+		t.Errorf("expected 5 VWRL with a cost basis of 601, got %+v", vwrl)
+	}
+	if vwrl.RealizedGain != 1946-1603 {
+		t.Errorf("expected a realized gain of 343, got %v", vwrl.RealizedGain)
+	}
+	if vwrl.MarketValue != 700 || vwrl.UnrealizedGain != 99 || vwrl.Dividends != 2.5 {
+		t.Errorf("expected 5 VWRL worth 700 with 2.5 dividends, got %+v", vwrl)
+	}
+
+	if portfolio.MarketValue != 1100 || portfolio.CostBasis != 961 {
+		t.Errorf("expected a portfolio worth 1100 with a cost basis of 961, got %+v", portfolio)
+	}
+}
+
+func TestValueAverage(t *testing.T) {
+	portfolio, err := Value(trades, prices, CostBasisAverage, date(2024, time.April, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The average cost of the 20 units is 110.2
+	vwrl := portfolio.Holdings[1]
+	if vwrl.CostBasis != 551 {
+		t.Errorf("expected a cost basis of 551, got %v", vwrl.CostBasis)
+	}
+	if vwrl.RealizedGain != 1946-1653 {
+		t.Errorf("expected a realized gain of 293, got %v", vwrl.RealizedGain)
+	}
+}
+
+func TestValueLastTradePrice(t *testing.T) {
+	portfolio, err := Value(trades, nil, CostBasisFIFO, date(2024, time.April, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vwrl := portfolio.Holdings[1]
+	if vwrl.Price != 130 || !vwrl.PriceDate.Equal(date(2024, time.March, 10)) {
+		t.Errorf("expected the price of the sell, got %v on %s", vwrl.Price, vwrl.PriceDate.Format(time.DateOnly))
+	}
+}
+
+func TestValueInsufficientQuantity(t *testing.T) {
+	_, err := Value([]Trade{
+		{Symbol: "VWRL", Kind: TradeBuy, Date: date(2024, time.January, 10), Quantity: 10, Price: 100},
+		{Symbol: "VWRL", Kind: TradeSell, Date: date(2024, time.January, 11), Quantity: 11, Price: 100},
+	}, nil, CostBasisFIFO, date(2024, time.April, 15))
+	if !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+}
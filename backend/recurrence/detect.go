@@ -0,0 +1,235 @@
+package recurrence
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// minDetectedOccurrences is the number of transactions needed before a
+	// series is proposed
+	minDetectedOccurrences = 3
+	// minDetectedConfidence drops proposals that are more likely coincidence
+	minDetectedConfidence = 0.5
+	// amountTolerance is how far, relative to the typical amount, an amount
+	// may be off to still belong to the same series
+	amountTolerance = 0.15
+)
+
+// Observation is a transaction that may be part of a recurring series.
+type Observation struct {
+	ID          int32
+	Description string
+	Amount      float64
+	Type        string
+	Date        time.Time
+}
+
+// Proposal is a recurring series found in the history of a user.
+type Proposal struct {
+	Description string
+	Amount      float64
+	Type        string
+	Interval    string
+	RRule       string
+	StartDate   time.Time
+	LastDate    time.Time
+	NextDate    time.Time
+	// Confidence is between 0 and 1, 1 being a perfectly regular series
+	Confidence     float64
+	TransactionIDs []int32
+}
+
+type cadence struct {
+	interval  string
+	days      float64
+	tolerance float64
+}
+
+var cadences = []cadence{
+	{interval: IntervalWeekly, days: 7, tolerance: 1},
+	{interval: IntervalBiweekly, days: 14, tolerance: 2},
+	{interval: IntervalMonthly, days: 30.44, tolerance: 4},
+	{interval: IntervalQuarterly, days: 91.31, tolerance: 7},
+	{interval: IntervalYearly, days: 365.25, tolerance: 10},
+}
+
+// Detect groups observations by description and direction of the amount and
+// proposes the groups that repeat at a regular cadence with a similar amount.
+// Series that stopped before now are left out. Proposals are ordered by
+// confidence, the most likely first.
+func Detect(observations []Observation, now time.Time) []Proposal {
+	groups := make(map[string][]Observation)
+	for _, observation := range observations {
+		key := normalizeDescription(observation.Description)
+		if key == "" || observation.Amount == 0 {
+			continue
+		}
+		if observation.Amount > 0 {
+			key = "+" + key
+		} else {
+			key = "-" + key
+		}
+		groups[key] = append(groups[key], observation)
+	}
+
+	var proposals []Proposal
+	for _, group := range groups {
+		proposal, ok := detectSeries(group, now)
+		if ok {
+			proposals = append(proposals, proposal)
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Confidence != proposals[j].Confidence {
+			return proposals[i].Confidence > proposals[j].Confidence
+		}
+		return proposals[i].Description < proposals[j].Description
+	})
+
+	return proposals
+}
+
+func detectSeries(observations []Observation, now time.Time) (Proposal, bool) {
+	amounts := make([]float64, len(observations))
+	for idx, observation := range observations {
+		amounts[idx] = observation.Amount
+	}
+	typical := median(amounts)
+
+	var matching []Observation
+	for _, observation := range observations {
+		if math.Abs(observation.Amount-typical) <= math.Abs(typical)*amountTolerance {
+			matching = append(matching, observation)
+		}
+	}
+	if len(matching) < minDetectedOccurrences {
+		return Proposal{}, false
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].Date.Before(matching[j].Date)
+	})
+
+	gaps := make([]float64, 0, len(matching)-1)
+	for idx := 1; idx < len(matching); idx++ {
+		gaps = append(gaps, matching[idx].Date.Sub(matching[idx-1].Date).Hours()/24)
+	}
+
+	cadence, ok := findCadence(median(gaps))
+	if !ok {
+		return Proposal{}, false
+	}
+
+	regular := 0
+	for _, gap := range gaps {
+		if math.Abs(gap-cadence.days) <= cadence.tolerance {
+			regular++
+		}
+	}
+	regularity := float64(regular) / float64(len(gaps))
+
+	var deviation float64
+	for _, observation := range matching {
+		deviation += math.Abs(observation.Amount - typical)
+	}
+	amountScore := 1 - math.Min(1, deviation/float64(len(matching))/math.Abs(typical))
+	countScore := math.Min(1, float64(len(matching))/6)
+
+	confidence := regularity * (0.5 + 0.5*amountScore) * (0.5 + 0.5*countScore)
+	confidence = math.Round(confidence*100) / 100
+	if confidence < minDetectedConfidence {
+		return Proposal{}, false
+	}
+
+	first := matching[0]
+	last := matching[len(matching)-1]
+
+	// A series that missed two occurrences in a row has most likely ended
+	if now.Sub(last.Date).Hours()/24 > 2*cadence.days+cadence.tolerance {
+		return Proposal{}, false
+	}
+
+	rrule, err := FromInterval(cadence.interval, 0, first.Date)
+	if err != nil {
+		return Proposal{}, false
+	}
+	rule, err := Parse(rrule, first.Date)
+	if err != nil {
+		return Proposal{}, false
+	}
+
+	// Occurrences are often a few days early or late, so the next date is the
+	// first one that is not the occurrence of the last transaction
+	next := rule.After(last.Date.AddDate(0, 0, int(cadence.tolerance)), false)
+
+	ids := make([]int32, len(matching))
+	for idx, observation := range matching {
+		ids[idx] = observation.ID
+	}
+
+	return Proposal{
+		Description:    last.Description,
+		Amount:         last.Amount,
+		Type:           mostCommonType(matching),
+		Interval:       cadence.interval,
+		RRule:          rrule,
+		StartDate:      first.Date,
+		LastDate:       last.Date,
+		NextDate:       next,
+		Confidence:     confidence,
+		TransactionIDs: ids,
+	}, true
+}
+
+func findCadence(gap float64) (cadence, bool) {
+	for _, cadence := range cadences {
+		if math.Abs(gap-cadence.days) <= cadence.tolerance {
+			return cadence, true
+		}
+	}
+
+	return cadence{}, false
+}
+
+// normalizeDescription drops the parts of a description that differ between
+// occurrences, such as dates and reference numbers.
+func normalizeDescription(description string) string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	return strings.Join(fields, " ")
+}
+
+func mostCommonType(observations []Observation) string {
+	counts := make(map[string]int)
+	for _, observation := range observations {
+		counts[observation.Type]++
+	}
+
+	var result string
+	for idx := len(observations) - 1; idx >= 0; idx-- {
+		if counts[observations[idx].Type] > counts[result] {
+			result = observations[idx].Type
+		}
+	}
+
+	return result
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,72 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	observations := []Observation{
+		{ID: 1, Description: "Salary ACME 2024-01", Amount: 3000, Type: "Salary", Date: date(2024, time.January, 25)},
+		{ID: 2, Description: "Salary ACME 2024-02", Amount: 3000, Type: "Salary", Date: date(2024, time.February, 23)},
+		{ID: 3, Description: "Salary ACME 2024-03", Amount: 3100, Type: "Salary", Date: date(2024, time.March, 25)},
+		{ID: 4, Description: "Salary ACME 2024-04", Amount: 3100, Type: "Salary", Date: date(2024, time.April, 25)},
+		{ID: 5, Description: "Netflix", Amount: -12.99, Type: "Subscriptions", Date: date(2024, time.February, 2)},
+		{ID: 6, Description: "Netflix", Amount: -12.99, Type: "Subscriptions", Date: date(2024, time.March, 2)},
+		{ID: 7, Description: "Netflix", Amount: -12.99, Type: "Subscriptions", Date: date(2024, time.April, 2)},
+		{ID: 8, Description: "Netflix", Amount: -49.99, Type: "Shopping", Date: date(2024, time.April, 10)},
+		{ID: 9, Description: "Groceries", Amount: -54.20, Type: "Groceries", Date: date(2024, time.March, 3)},
+		{ID: 10, Description: "Groceries", Amount: -61.10, Type: "Groceries", Date: date(2024, time.March, 9)},
+		{ID: 11, Description: "Groceries", Amount: -57.80, Type: "Groceries", Date: date(2024, time.March, 21)},
+		{ID: 12, Description: "Gym", Amount: -30, Type: "Sport", Date: date(2023, time.June, 1)},
+		{ID: 13, Description: "Gym", Amount: -30, Type: "Sport", Date: date(2023, time.July, 1)},
+		{ID: 14, Description: "Gym", Amount: -30, Type: "Sport", Date: date(2023, time.August, 1)},
+	}
+
+	proposals := Detect(observations, date(2024, time.April, 28))
+	if len(proposals) != 2 {
+		t.Fatalf("got %d proposals %+v, want 2", len(proposals), proposals)
+	}
+
+	salary := proposals[0]
+	if salary.Interval != IntervalMonthly || salary.Type != "Salary" || salary.Amount != 3100 {
+		t.Fatalf("unexpected salary proposal %+v", salary)
+	}
+	if len(salary.TransactionIDs) != 4 {
+		t.Fatalf("got transactions %v, want 4", salary.TransactionIDs)
+	}
+	assertDates(t, []time.Time{salary.NextDate}, []string{"2024-05-25"})
+
+	netflix := proposals[1]
+	if netflix.Interval != IntervalMonthly || len(netflix.TransactionIDs) != 3 {
+		t.Fatalf("unexpected netflix proposal %+v", netflix)
+	}
+	for _, id := range netflix.TransactionIDs {
+		if id == 8 {
+			t.Fatalf("one-off purchase was part of the series: %v", netflix.TransactionIDs)
+		}
+	}
+	assertDates(t, []time.Time{netflix.NextDate}, []string{"2024-05-02"})
+}
+
+func TestDetectWeekly(t *testing.T) {
+	var observations []Observation
+	for idx := range 8 {
+		observations = append(observations, Observation{
+			ID:          int32(idx + 1),
+			Description: "Cleaner",
+			Amount:      -40,
+			Type:        "Home",
+			Date:        date(2024, time.January, 1).AddDate(0, 0, idx*7),
+		})
+	}
+
+	proposals := Detect(observations, date(2024, time.February, 20))
+	if len(proposals) != 1 {
+		t.Fatalf("got %d proposals, want 1", len(proposals))
+	}
+	if proposals[0].Interval != IntervalWeekly || proposals[0].Confidence != 1 {
+		t.Fatalf("unexpected proposal %+v", proposals[0])
+	}
+	assertDates(t, []time.Time{proposals[0].NextDate}, []string{"2024-02-26"})
+}
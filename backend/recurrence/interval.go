@@ -0,0 +1,76 @@
+package recurrence
+
+import (
+	"fmt"
+	"time"
+)
+
+// Interval
+const (
+	IntervalDaily     string = "Daily"
+	IntervalWeekly           = "Weekly"
+	IntervalBiweekly         = "Biweekly"
+	IntervalMonthly          = "Monthly"
+	IntervalQuarterly        = "Quarterly"
+	IntervalYearly           = "Yearly"
+	IntervalOther            = "Other"
+	IntervalCustom           = "Custom"
+)
+
+var Intervals = []string{
+	IntervalDaily,
+	IntervalWeekly,
+	IntervalBiweekly,
+	IntervalMonthly,
+	IntervalQuarterly,
+	IntervalYearly,
+	IntervalOther,
+	IntervalCustom,
+}
+
+// FromInterval returns the recurrence rule for one of the preset intervals,
+// starting at start. Other repeats every daysInterval days. Custom has no
+// preset and must come with its own rule.
+//
+// Monthly, quarterly and yearly series that start on a day some months do not
+// have fall back to the last day of those months, so a series starting on
+// January 31 continues on February 28 (or 29) and March 31.
+func FromInterval(interval string, daysInterval int32, start time.Time) (string, error) {
+	switch interval {
+	case IntervalDaily:
+		return "FREQ=DAILY", nil
+	case IntervalWeekly:
+		return "FREQ=WEEKLY", nil
+	case IntervalBiweekly:
+		return "FREQ=WEEKLY;INTERVAL=2", nil
+	case IntervalMonthly:
+		return "FREQ=MONTHLY" + monthDay(start), nil
+	case IntervalQuarterly:
+		return "FREQ=MONTHLY;INTERVAL=3" + monthDay(start), nil
+	case IntervalYearly:
+		if start.Day() < 29 {
+			return "FREQ=YEARLY", nil
+		}
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d", start.Month()) + monthDay(start), nil
+	case IntervalOther:
+		if daysInterval < 1 {
+			return "", ErrInvalidInterval
+		}
+		return fmt.Sprintf("FREQ=DAILY;INTERVAL=%d", daysInterval), nil
+	}
+
+	return "", ErrInvalidInterval
+}
+
+// monthDay pins the day of the month for days that not every month has.
+func monthDay(start time.Time) string {
+	day := start.Day()
+	switch {
+	case day < 29:
+		return ""
+	case day == 31:
+		return ";BYMONTHDAY=-1"
+	default:
+		return fmt.Sprintf(";BYMONTHDAY=%d,-1;BYSETPOS=1", day)
+	}
+}
@@ -0,0 +1,86 @@
+// Package recurrence parses and expands RFC 5545 recurrence rules for
+// recurring transactions.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var (
+	ErrInvalidRule          = errors.New("invalid recurrence rule")
+	ErrUnsupportedFrequency = errors.New("recurrence rules must repeat daily or less often")
+	ErrInvalidInterval      = errors.New("invalid interval")
+)
+
+// Rule is a recurrence rule anchored at the start date of a series.
+type Rule struct {
+	rule *rrule.RRule
+}
+
+// Parse parses an RRULE value such as "FREQ=MONTHLY;BYMONTHDAY=-1", with or
+// without the "RRULE:" prefix, and anchors it at start. The start date of a
+// series is stored separately, so DTSTART is not accepted in the rule.
+func Parse(value string, start time.Time) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" || strings.Contains(value, "\n") {
+		return nil, ErrInvalidRule
+	}
+
+	options, err := rrule.StrToROption(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if !options.Dtstart.IsZero() {
+		return nil, fmt.Errorf("%w: DTSTART is not allowed", ErrInvalidRule)
+	}
+	if options.Freq > rrule.DAILY {
+		return nil, ErrUnsupportedFrequency
+	}
+	if len(options.Byhour) != 0 || len(options.Byminute) != 0 || len(options.Bysecond) != 0 {
+		return nil, fmt.Errorf("%w: time of day is not allowed", ErrInvalidRule)
+	}
+
+	options.Dtstart = start
+	rule, err := rrule.NewRRule(*options)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	return &Rule{rule: rule}, nil
+}
+
+// Validate reports whether value is a recurrence rule that Parse accepts.
+func Validate(value string) error {
+	_, err := Parse(value, time.Now().UTC())
+	return err
+}
+
+// Between returns the occurrences from start up to, but not including, end.
+func (r *Rule) Between(start time.Time, end time.Time) []time.Time {
+	if !start.Before(end) {
+		return nil
+	}
+
+	occurrences := r.rule.Between(start, end, true)
+	if len(occurrences) != 0 && occurrences[len(occurrences)-1].Equal(end) {
+		occurrences = occurrences[:len(occurrences)-1]
+	}
+
+	return occurrences
+}
+
+// After returns the first occurrence after date, or the zero time when the
+// rule has no more occurrences.
+func (r *Rule) After(date time.Time, inclusive bool) time.Time {
+	return r.rule.After(date, inclusive)
+}
+
+// String returns the rule without its start date.
+func (r *Rule) String() string {
+	return r.rule.OrigOptions.RRuleString()
+}
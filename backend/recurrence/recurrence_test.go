@@ -0,0 +1,371 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func dates(t *testing.T, occurrences []time.Time) []string {
+	t.Helper()
+	result := make([]string, len(occurrences))
+	for idx, occurrence := range occurrences {
+		result[idx] = occurrence.Format(time.DateOnly)
+	}
+	return result
+}
+
+func assertDates(t *testing.T, got []time.Time, want []string) {
+	t.Helper()
+	gotDates := dates(t, got)
+	if len(gotDates) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d %v", len(gotDates), gotDates, len(want), want)
+	}
+	for idx := range want {
+		if gotDates[idx] != want[idx] {
+			t.Fatalf("occurrence %d: got %s, want %s (all: %v)", idx, gotDates[idx], want[idx], gotDates)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		end   time.Time
+		want  []string
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			start: date(2024, time.February, 27),
+			end:   date(2024, time.March, 2),
+			want:  []string{"2024-02-27", "2024-02-28", "2024-02-29", "2024-03-01"},
+		},
+		{
+			name:  "every second friday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR",
+			start: date(2024, time.January, 5),
+			end:   date(2024, time.March, 1),
+			want:  []string{"2024-01-05", "2024-01-19", "2024-02-02", "2024-02-16"},
+		},
+		{
+			name:  "second friday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=2FR",
+			start: date(2024, time.January, 1),
+			end:   date(2024, time.May, 1),
+			want:  []string{"2024-01-12", "2024-02-09", "2024-03-08", "2024-04-12"},
+		},
+		{
+			name:  "quarterly",
+			rule:  "FREQ=MONTHLY;INTERVAL=3",
+			start: date(2024, time.January, 15),
+			end:   date(2025, time.January, 15),
+			want:  []string{"2024-01-15", "2024-04-15", "2024-07-15", "2024-10-15"},
+		},
+		{
+			name:  "yearly on march 31",
+			rule:  "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=31",
+			start: date(2023, time.January, 1),
+			end:   date(2026, time.January, 1),
+			want:  []string{"2023-03-31", "2024-03-31", "2025-03-31"},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2023, time.December, 1),
+			end:   date(2024, time.May, 1),
+			want:  []string{"2023-12-31", "2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:  "last business day of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: date(2024, time.January, 1),
+			end:   date(2024, time.July, 1),
+			// March 31 and June 30 2024 fall on a weekend.
+			want: []string{"2024-01-31", "2024-02-29", "2024-03-29", "2024-04-30", "2024-05-31", "2024-06-28"},
+		},
+		{
+			name:  "plain monthly on the 31st skips shorter months",
+			rule:  "FREQ=MONTHLY",
+			start: date(2024, time.January, 31),
+			end:   date(2024, time.June, 1),
+			want:  []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			name:  "february 29 only in leap years",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			start: date(2023, time.January, 1),
+			end:   date(2033, time.January, 1),
+			want:  []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:  "end is exclusive",
+			rule:  "FREQ=MONTHLY",
+			start: date(2024, time.January, 1),
+			end:   date(2024, time.April, 1),
+			want:  []string{"2024-01-01", "2024-02-01", "2024-03-01"},
+		},
+		{
+			name:  "count limits the occurrences",
+			rule:  "FREQ=WEEKLY;COUNT=3",
+			start: date(2024, time.January, 1),
+			end:   date(2025, time.January, 1),
+			want:  []string{"2024-01-01", "2024-01-08", "2024-01-15"},
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20240103T000000Z",
+			start: date(2024, time.January, 1),
+			end:   date(2025, time.January, 1),
+			want:  []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:  "rrule prefix",
+			rule:  "RRULE:FREQ=DAILY;INTERVAL=10",
+			start: date(2024, time.January, 1),
+			end:   date(2024, time.February, 1),
+			want:  []string{"2024-01-01", "2024-01-11", "2024-01-21", "2024-01-31"},
+		},
+		{
+			name:  "empty range",
+			rule:  "FREQ=DAILY",
+			start: date(2024, time.January, 1),
+			end:   date(2024, time.January, 1),
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := Parse(test.rule, test.start)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", test.rule, err)
+			}
+			assertDates(t, rule.Between(test.start, test.end), test.want)
+		})
+	}
+}
+
+func TestFromInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		interval     string
+		daysInterval int32
+		start        time.Time
+		end          time.Time
+		wantRule     string
+		want         []string
+	}{
+		{
+			name:     "weekly",
+			interval: IntervalWeekly,
+			start:    date(2024, time.February, 26),
+			end:      date(2024, time.March, 12),
+			wantRule: "FREQ=WEEKLY",
+			want:     []string{"2024-02-26", "2024-03-04", "2024-03-11"},
+		},
+		{
+			name:     "biweekly",
+			interval: IntervalBiweekly,
+			start:    date(2024, time.February, 26),
+			end:      date(2024, time.April, 1),
+			wantRule: "FREQ=WEEKLY;INTERVAL=2",
+			want:     []string{"2024-02-26", "2024-03-11", "2024-03-25"},
+		},
+		{
+			name:     "monthly mid month",
+			interval: IntervalMonthly,
+			start:    date(2024, time.January, 15),
+			end:      date(2024, time.April, 1),
+			wantRule: "FREQ=MONTHLY",
+			want:     []string{"2024-01-15", "2024-02-15", "2024-03-15"},
+		},
+		{
+			name:     "monthly on the 31st in a leap year",
+			interval: IntervalMonthly,
+			start:    date(2024, time.January, 31),
+			end:      date(2024, time.July, 1),
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			want:     []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30", "2024-05-31", "2024-06-30"},
+		},
+		{
+			name:     "monthly on the 31st in a common year",
+			interval: IntervalMonthly,
+			start:    date(2023, time.January, 31),
+			end:      date(2023, time.April, 1),
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			want:     []string{"2023-01-31", "2023-02-28", "2023-03-31"},
+		},
+		{
+			name:     "monthly on the 30th keeps the 30th",
+			interval: IntervalMonthly,
+			start:    date(2023, time.January, 30),
+			end:      date(2023, time.May, 1),
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=30,-1;BYSETPOS=1",
+			want:     []string{"2023-01-30", "2023-02-28", "2023-03-30", "2023-04-30"},
+		},
+		{
+			name:     "monthly on the 29th across leap and common years",
+			interval: IntervalMonthly,
+			start:    date(2023, time.December, 29),
+			end:      date(2025, time.April, 1),
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=29,-1;BYSETPOS=1",
+			want: []string{
+				"2023-12-29", "2024-01-29", "2024-02-29", "2024-03-29", "2024-04-29", "2024-05-29",
+				"2024-06-29", "2024-07-29", "2024-08-29", "2024-09-29", "2024-10-29", "2024-11-29",
+				"2024-12-29", "2025-01-29", "2025-02-28", "2025-03-29",
+			},
+		},
+		{
+			name:     "monthly on the 28th is unchanged",
+			interval: IntervalMonthly,
+			start:    date(2023, time.January, 28),
+			end:      date(2023, time.April, 1),
+			wantRule: "FREQ=MONTHLY",
+			want:     []string{"2023-01-28", "2023-02-28", "2023-03-28"},
+		},
+		{
+			name:     "quarterly from the end of the month",
+			interval: IntervalQuarterly,
+			start:    date(2023, time.November, 30),
+			end:      date(2024, time.December, 1),
+			wantRule: "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=30,-1;BYSETPOS=1",
+			want:     []string{"2023-11-30", "2024-02-29", "2024-05-30", "2024-08-30", "2024-11-30"},
+		},
+		{
+			name:     "yearly on february 29",
+			interval: IntervalYearly,
+			start:    date(2024, time.February, 29),
+			end:      date(2029, time.February, 1),
+			wantRule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29,-1;BYSETPOS=1",
+			want:     []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"},
+		},
+		{
+			name:     "yearly on march 31",
+			interval: IntervalYearly,
+			start:    date(2024, time.March, 31),
+			end:      date(2027, time.January, 1),
+			wantRule: "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=-1",
+			want:     []string{"2024-03-31", "2025-03-31", "2026-03-31"},
+		},
+		{
+			name:     "yearly mid month",
+			interval: IntervalYearly,
+			start:    date(2024, time.June, 1),
+			end:      date(2026, time.June, 2),
+			wantRule: "FREQ=YEARLY",
+			want:     []string{"2024-06-01", "2025-06-01", "2026-06-01"},
+		},
+		{
+			name:         "other across february in a leap year",
+			interval:     IntervalOther,
+			daysInterval: 15,
+			start:        date(2024, time.February, 1),
+			end:          date(2024, time.March, 18),
+			wantRule:     "FREQ=DAILY;INTERVAL=15",
+			want:         []string{"2024-02-01", "2024-02-16", "2024-03-02", "2024-03-17"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := FromInterval(test.interval, test.daysInterval, test.start)
+			if err != nil {
+				t.Fatalf("FromInterval: %v", err)
+			}
+			if value != test.wantRule {
+				t.Fatalf("got rule %q, want %q", value, test.wantRule)
+			}
+
+			rule, err := Parse(value, test.start)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", value, err)
+			}
+			assertDates(t, rule.Between(test.start, test.end), test.want)
+		})
+	}
+}
+
+func TestFromIntervalErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		interval     string
+		daysInterval int32
+	}{
+		{name: "other without days", interval: IntervalOther},
+		{name: "other with negative days", interval: IntervalOther, daysInterval: -3},
+		{name: "custom has no preset", interval: IntervalCustom},
+		{name: "unknown", interval: "Fortnightly"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromInterval(test.interval, test.daysInterval, date(2024, time.January, 1))
+			if !errors.Is(err, ErrInvalidInterval) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidInterval)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want error
+	}{
+		{name: "empty", rule: "", want: ErrInvalidRule},
+		{name: "missing frequency", rule: "INTERVAL=2", want: ErrInvalidRule},
+		{name: "unknown property", rule: "FREQ=DAILY;FOO=BAR", want: ErrInvalidRule},
+		{name: "bad weekday", rule: "FREQ=WEEKLY;BYDAY=XX", want: ErrInvalidRule},
+		{name: "out of range month day", rule: "FREQ=MONTHLY;BYMONTHDAY=32", want: ErrInvalidRule},
+		{name: "dtstart", rule: "FREQ=DAILY;DTSTART=20240101T000000Z", want: ErrInvalidRule},
+		{name: "multiple lines", rule: "DTSTART:20240101T000000Z\nRRULE:FREQ=DAILY", want: ErrInvalidRule},
+		{name: "time of day", rule: "FREQ=DAILY;BYHOUR=9", want: ErrInvalidRule},
+		{name: "hourly", rule: "FREQ=HOURLY", want: ErrUnsupportedFrequency},
+		{name: "secondly", rule: "FREQ=SECONDLY", want: ErrUnsupportedFrequency},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.rule, date(2024, time.January, 1))
+			if !errors.Is(err, test.want) {
+				t.Fatalf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, time.January, 31))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rule.After(date(2024, time.February, 1), false); !got.Equal(date(2024, time.February, 29)) {
+		t.Fatalf("got %s, want 2024-02-29", got.Format(time.DateOnly))
+	}
+	if got := rule.After(date(2024, time.February, 29), true); !got.Equal(date(2024, time.February, 29)) {
+		t.Fatalf("got %s, want 2024-02-29", got.Format(time.DateOnly))
+	}
+	if got := rule.After(date(2024, time.February, 29), false); !got.Equal(date(2024, time.March, 31)) {
+		t.Fatalf("got %s, want 2024-03-31", got.Format(time.DateOnly))
+	}
+}
+
+func TestString(t *testing.T) {
+	rule, err := Parse("RRULE:FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=30,-1;BYSETPOS=1", date(2024, time.January, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rule.String(); got != "FREQ=MONTHLY;INTERVAL=3;BYSETPOS=1;BYMONTHDAY=30,-1" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Significant change defaults
+const (
+	DefaultSignificantPercentage float64 = 10
+	DefaultSignificantAmount             = 10
+)
+
+// TrailingMonths is the number of months before the compared month that make
+// up the trailing average.
+const TrailingMonths = 12
+
+// MonthAmount is the amount of a category in a month.
+type MonthAmount struct {
+	Month  time.Time
+	Key    string
+	Amount float64
+}
+
+// Delta compares the current amount with a base amount. Without a base
+// amount the percentage is meaningless and the delta is not comparable.
+type Delta struct {
+	Base        float64
+	Difference  float64
+	Percentage  float64
+	Comparable  bool
+	Significant bool
+}
+
+type Comparison struct {
+	Key             string
+	Current         float64
+	LastYear        Delta
+	TrailingAverage Delta
+}
+
+type ComparisonParams struct {
+	// Month is the month to compare, any day in the month will do
+	Month   time.Time
+	Amounts []MonthAmount
+	// A change is significant when it is at least the percentage of the base
+	// amount and at least the amount. Changes from nothing only need to reach
+	// the amount.
+	SignificantPercentage float64
+	SignificantAmount     float64
+}
+
+type ComparisonReport struct {
+	Total      Comparison
+	Categories []Comparison
+}
+
+// Compare compares the amounts of a month with the same month last year and
+// with the average of the trailing months before it, per category and in
+// total. Categories are ordered by the current amount, largest first.
+func Compare(params ComparisonParams) ComparisonReport {
+	month := time.Date(params.Month.Year(), params.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastYear := month.AddDate(-1, 0, 0)
+	trailingStart := month.AddDate(0, -TrailingMonths, 0)
+
+	type amounts struct {
+		current  float64
+		lastYear float64
+		trailing float64
+	}
+
+	categories := make(map[string]*amounts)
+	var total amounts
+	for _, amount := range params.Amounts {
+		amountMonth := time.Date(amount.Month.Year(), amount.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if amountMonth.Before(trailingStart) || amountMonth.After(month) {
+			continue
+		}
+
+		category, ok := categories[amount.Key]
+		if !ok {
+			category = &amounts{}
+			categories[amount.Key] = category
+		}
+
+		if amountMonth.Equal(month) {
+			category.current += amount.Amount
+			total.current += amount.Amount
+			continue
+		}
+		if amountMonth.Equal(lastYear) {
+			category.lastYear += amount.Amount
+			total.lastYear += amount.Amount
+		}
+		category.trailing += amount.Amount
+		total.trailing += amount.Amount
+	}
+
+	compare := func(key string, amounts amounts) Comparison {
+		return Comparison{
+			Key:             key,
+			Current:         round(amounts.current),
+			LastYear:        delta(amounts.current, amounts.lastYear, params),
+			TrailingAverage: delta(amounts.current, amounts.trailing/TrailingMonths, params),
+		}
+	}
+
+	comparisons := make([]Comparison, 0, len(categories))
+	for key, category := range categories {
+		comparisons = append(comparisons, compare(key, *category))
+	}
+	slices.SortFunc(comparisons, func(a Comparison, b Comparison) int {
+		if a.Current != b.Current {
+			if a.Current > b.Current {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return ComparisonReport{
+		Total:      compare("", total),
+		Categories: comparisons,
+	}
+}
+
+func delta(current float64, base float64, params ComparisonParams) Delta {
+	difference := current - base
+	result := Delta{
+		Base:       round(base),
+		Difference: round(difference),
+		Comparable: round(base) != 0,
+	}
+
+	significant := math.Abs(difference) >= params.SignificantAmount
+	if result.Comparable {
+		result.Percentage = round(difference / math.Abs(base) * 100)
+		significant = significant && math.Abs(result.Percentage) >= params.SignificantPercentage
+	}
+	result.Significant = significant && difference != 0
+
+	return result
+}
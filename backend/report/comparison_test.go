@@ -0,0 +1,79 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	amounts := []MonthAmount{
+		{Month: date(2024, time.June, 1), Key: "Groceries", Amount: 354},
+		{Month: date(2024, time.June, 1), Key: "Entertainment", Amount: 52},
+		{Month: date(2024, time.June, 1), Key: "Travel", Amount: 500},
+		// Same month last year
+		{Month: date(2023, time.June, 1), Key: "Groceries", Amount: 300},
+		{Month: date(2023, time.June, 1), Key: "Entertainment", Amount: 50},
+		// Too long ago and in the future
+		{Month: date(2023, time.May, 1), Key: "Groceries", Amount: 1000},
+		{Month: date(2024, time.July, 1), Key: "Groceries", Amount: 1000},
+	}
+	for month := time.July; month <= time.December; month++ {
+		amounts = append(amounts, MonthAmount{Month: date(2023, month, 1), Key: "Groceries", Amount: 300})
+	}
+	for month := time.January; month <= time.May; month++ {
+		amounts = append(amounts, MonthAmount{Month: date(2024, month, 1), Key: "Groceries", Amount: 360})
+	}
+
+	comparison := Compare(ComparisonParams{
+		Month:                 date(2024, time.June, 15),
+		Amounts:               amounts,
+		SignificantPercentage: DefaultSignificantPercentage,
+		SignificantAmount:     DefaultSignificantAmount,
+	})
+
+	if len(comparison.Categories) != 3 {
+		t.Fatalf("got %d categories, want 3", len(comparison.Categories))
+	}
+	travel := comparison.Categories[0]
+	if travel.Key != "Travel" || travel.LastYear.Comparable || !travel.LastYear.Significant {
+		t.Fatalf("unexpected travel comparison %+v", travel)
+	}
+
+	groceries := comparison.Categories[1]
+	if groceries.Key != "Groceries" || groceries.Current != 354 {
+		t.Fatalf("unexpected groceries comparison %+v", groceries)
+	}
+	if groceries.LastYear.Difference != 54 || groceries.LastYear.Percentage != 18 || !groceries.LastYear.Significant {
+		t.Fatalf("unexpected groceries last year delta %+v", groceries.LastYear)
+	}
+	// 7 months of 300 and 5 months of 360 is 325 on average
+	if groceries.TrailingAverage.Base != 325 || groceries.TrailingAverage.Percentage != 8.92 || groceries.TrailingAverage.Significant {
+		t.Fatalf("unexpected groceries trailing delta %+v", groceries.TrailingAverage)
+	}
+
+	// 4% up, but also less than the significant amount
+	entertainment := comparison.Categories[2]
+	if entertainment.LastYear.Percentage != 4 || entertainment.LastYear.Significant {
+		t.Fatalf("unexpected entertainment last year delta %+v", entertainment.LastYear)
+	}
+
+	if comparison.Total.Current != 906 || comparison.Total.LastYear.Base != 350 {
+		t.Fatalf("unexpected total comparison %+v", comparison.Total)
+	}
+}
+
+func TestCompareDrop(t *testing.T) {
+	comparison := Compare(ComparisonParams{
+		Month: date(2024, time.June, 1),
+		Amounts: []MonthAmount{
+			{Month: date(2023, time.June, 1), Key: "Gym", Amount: 40},
+		},
+		SignificantPercentage: DefaultSignificantPercentage,
+		SignificantAmount:     DefaultSignificantAmount,
+	})
+
+	gym := comparison.Categories[0]
+	if gym.Current != 0 || gym.LastYear.Percentage != -100 || !gym.LastYear.Significant {
+		t.Fatalf("unexpected gym comparison %+v", gym)
+	}
+}
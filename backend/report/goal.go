@@ -0,0 +1,113 @@
+package report
+
+import "time"
+
+// Goal status
+const (
+	GoalStatusOnTrack   string = "OnTrack"
+	GoalStatusBehind           = "Behind"
+	GoalStatusCompleted        = "Completed"
+)
+
+var GoalStatuses = []string{
+	GoalStatusOnTrack,
+	GoalStatusBehind,
+	GoalStatusCompleted,
+}
+
+// Goal is an amount to save by the target date. Initial is the amount that
+// was already saved at the start, and Value is the current value of an
+// account the goal is saved in, which counts as saved as well.
+type Goal struct {
+	Target     float64
+	Initial    float64
+	Value      float64
+	Start      time.Time
+	TargetDate time.Time
+}
+
+// Contribution is money put towards a goal. Withdrawals are negative.
+type Contribution struct {
+	Date   time.Time
+	Amount float64
+}
+
+// GoalProgress is the state of a goal on a day. Expected is what should be
+// saved on that day at the linear pace from the start to the target date, and
+// the required monthly contribution is what it takes to reach the target from
+// here in the months that are left.
+type GoalProgress struct {
+	Saved           float64
+	Remaining       float64
+	Percentage      float64
+	Expected        float64
+	MonthsLeft      int
+	RequiredMonthly float64
+	Status          string
+}
+
+// Progress calculates the progress of a goal as of a day. Contributions
+// before the start or after that day are ignored. Once the target date has
+// passed, whatever is remaining is required at once.
+func Progress(goal Goal, contributions []Contribution, asOf time.Time) GoalProgress {
+	start := truncateDay(goal.Start)
+	targetDate := truncateDay(goal.TargetDate)
+	asOf = truncateDay(asOf)
+
+	saved := goal.Initial + goal.Value
+	for _, contribution := range contributions {
+		date := truncateDay(contribution.Date)
+		if date.Before(start) || date.After(asOf) {
+			continue
+		}
+		saved += contribution.Amount
+	}
+
+	expected := goal.Target
+	if total := daysBetween(start, targetDate); asOf.Before(targetDate) && total > 0 {
+		elapsed := max(daysBetween(start, asOf), 0)
+		expected = goal.Initial + (goal.Target-goal.Initial)*float64(elapsed)/float64(total)
+	}
+
+	remaining := max(goal.Target-saved, 0)
+	monthsLeft := monthsBetween(asOf, targetDate)
+
+	progress := GoalProgress{
+		Saved:           round(saved),
+		Remaining:       round(remaining),
+		Expected:        round(expected),
+		MonthsLeft:      monthsLeft,
+		RequiredMonthly: round(remaining / float64(max(monthsLeft, 1))),
+		Status:          GoalStatusOnTrack,
+	}
+	if goal.Target > 0 {
+		progress.Percentage = round(saved / goal.Target * 100)
+	}
+
+	switch {
+	case remaining == 0:
+		progress.Status = GoalStatusCompleted
+	case progress.Saved < progress.Expected:
+		progress.Status = GoalStatusBehind
+	}
+
+	return progress
+}
+
+// monthsBetween returns the number of months from a day up to a later day, in
+// which a partial month counts as a whole one.
+func monthsBetween(from time.Time, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if from.AddDate(0, months, 0).Before(to) {
+		months++
+	}
+	for months > 0 && !from.AddDate(0, months-1, 0).Before(to) {
+		months--
+	}
+
+	return months
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	goal := Goal{
+		Target:     1200,
+		Initial:    100,
+		Start:      date(2024, time.January, 1),
+		TargetDate: date(2024, time.December, 31),
+	}
+	contributions := []Contribution{
+		{Date: date(2024, time.January, 15), Amount: 200},
+		{Date: date(2024, time.March, 15), Amount: 250},
+		{Date: date(2024, time.April, 2), Amount: -50},
+		// Before the start and after today
+		{Date: date(2023, time.December, 31), Amount: 1000},
+		{Date: date(2024, time.August, 1), Amount: 1000},
+	}
+
+	progress := Progress(goal, contributions, date(2024, time.July, 1))
+
+	if progress.Saved != 500 {
+		t.Errorf("expected 500 saved, got %v", progress.Saved)
+	}
+	if progress.Remaining != 700 {
+		t.Errorf("expected 700 remaining, got %v", progress.Remaining)
+	}
+	if progress.MonthsLeft != 6 {
+		t.Errorf("expected 6 months left, got %d", progress.MonthsLeft)
+	}
+	if progress.RequiredMonthly != 116.67 {
+		t.Errorf("expected 116.67 required monthly, got %v", progress.RequiredMonthly)
+	}
+	// 182 of 365 days have passed
+	if progress.Expected != 648.49 {
+		t.Errorf("expected 648.49 expected, got %v", progress.Expected)
+	}
+	if progress.Status != GoalStatusBehind {
+		t.Errorf("expected status %s, got %s", GoalStatusBehind, progress.Status)
+	}
+}
+
+func TestProgressStatus(t *testing.T) {
+	goal := Goal{
+		Target:     1000,
+		Start:      date(2024, time.January, 1),
+		TargetDate: date(2024, time.May, 1),
+	}
+
+	tests := []struct {
+		name          string
+		contributions []Contribution
+		asOf          time.Time
+		status        string
+		required      float64
+	}{
+		{
+			name:          "ahead of the pace",
+			contributions: []Contribution{{Date: date(2024, time.January, 2), Amount: 400}},
+			asOf:          date(2024, time.February, 1),
+			status:        GoalStatusOnTrack,
+			required:      200,
+		},
+		{
+			name:          "reached",
+			contributions: []Contribution{{Date: date(2024, time.January, 2), Amount: 1100}},
+			asOf:          date(2024, time.February, 1),
+			status:        GoalStatusCompleted,
+			required:      0,
+		},
+		{
+			name:          "past the target date",
+			contributions: []Contribution{{Date: date(2024, time.January, 2), Amount: 600}},
+			asOf:          date(2024, time.June, 1),
+			status:        GoalStatusBehind,
+			required:      400,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progress := Progress(goal, test.contributions, test.asOf)
+			if progress.Status != test.status {
+				t.Errorf("expected status %s, got %s", test.status, progress.Status)
+			}
+			if progress.RequiredMonthly != test.required {
+				t.Errorf("expected %v required monthly, got %v", test.required, progress.RequiredMonthly)
+			}
+		})
+	}
+}
+
+func TestProgressAccountValue(t *testing.T) {
+	goal := Goal{
+		Target:     1000,
+		Initial:    100,
+		Value:      650,
+		Start:      date(2024, time.January, 1),
+		TargetDate: date(2024, time.December, 31),
+	}
+	contributions := []Contribution{{Date: date(2024, time.February, 1), Amount: 50}}
+
+	progress := Progress(goal, contributions, date(2024, time.July, 1))
+
+	if progress.Saved != 800 || progress.Remaining != 200 || progress.Percentage != 80 {
+		t.Errorf("expected 800 saved with the account value, got %+v", progress)
+	}
+}
+
+func TestMonthsBetween(t *testing.T) {
+	tests := []struct {
+		from   time.Time
+		to     time.Time
+		months int
+	}{
+		{date(2024, time.January, 1), date(2024, time.January, 1), 0},
+		{date(2024, time.January, 1), date(2024, time.January, 2), 1},
+		{date(2024, time.January, 1), date(2024, time.February, 1), 1},
+		{date(2024, time.January, 15), date(2024, time.March, 1), 2},
+		{date(2024, time.March, 1), date(2024, time.January, 1), 0},
+	}
+
+	for _, test := range tests {
+		if months := monthsBetween(test.from, test.to); months != test.months {
+			t.Errorf("expected %d months from %s to %s, got %d", test.months, test.from.Format(time.DateOnly), test.to.Format(time.DateOnly), months)
+		}
+	}
+}
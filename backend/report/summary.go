@@ -0,0 +1,231 @@
+package report
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Summary grouping
+const (
+	GroupByDay     string = "day"
+	GroupByWeek           = "week"
+	GroupByMonth          = "month"
+	GroupByQuarter        = "quarter"
+	GroupByYear           = "year"
+)
+
+var GroupBys = []string{
+	GroupByDay,
+	GroupByWeek,
+	GroupByMonth,
+	GroupByQuarter,
+	GroupByYear,
+}
+
+// Calendar is how a user divides time. Weeks start on the week start, and
+// quarters and years follow the fiscal year, which starts on the first day of
+// the fiscal year start month. Without a fiscal year start the fiscal year is
+// the calendar year.
+type Calendar struct {
+	WeekStart       time.Weekday
+	FiscalYearStart time.Month
+}
+
+// BucketStart returns the first day of the bucket the date falls in.
+func (calendar Calendar) BucketStart(date time.Time, groupBy string) time.Time {
+	day := truncateDay(date)
+
+	switch groupBy {
+	case GroupByWeek:
+		offset := (int(day.Weekday()) - int(calendar.WeekStart) + 7) % 7
+		return day.AddDate(0, 0, -offset)
+	case GroupByMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case GroupByQuarter:
+		months := calendar.fiscalMonth(day) % 3
+		return time.Date(day.Year(), day.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	case GroupByYear:
+		months := calendar.fiscalMonth(day)
+		return time.Date(day.Year(), day.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// fiscalMonth returns the number of whole months between the start of the
+// fiscal year and the date.
+func (calendar Calendar) fiscalMonth(date time.Time) int {
+	start := calendar.FiscalYearStart
+	if start < time.January || start > time.December {
+		start = time.January
+	}
+	return (int(date.Month()) - int(start) + 12) % 12
+}
+
+func nextBucketStart(start time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case GroupByMonth:
+		return start.AddDate(0, 1, 0)
+	case GroupByQuarter:
+		return start.AddDate(0, 3, 0)
+	case GroupByYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Amount is the income and expense of a group on a day, both positive.
+type Amount struct {
+	Date    time.Time
+	Key     string
+	Income  float64
+	Expense float64
+}
+
+// Totals is the income, expense and the net result, which is negative when
+// more was spent than earned.
+type Totals struct {
+	Income  float64
+	Expense float64
+	Net     float64
+}
+
+type BucketGroup struct {
+	Totals
+	Key string
+}
+
+// Bucket is a day, week, month, quarter or year with the totals per group
+// ordered by key. Groups without amounts are left out.
+type Bucket struct {
+	Totals
+	Start  time.Time
+	End    time.Time
+	Groups []BucketGroup
+}
+
+type BucketParams struct {
+	// From and To are the first and last day of the period
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	Calendar Calendar
+	Amounts  []Amount
+	// Totals are summed for the totals of the buckets instead of the
+	// amounts, for when an amount can be in multiple groups. The keys of the
+	// totals are ignored.
+	Totals []Amount
+}
+
+// Buckets divides the period in buckets and sums the amounts per bucket and
+// group. Every bucket in the period is returned, also the empty ones, and the
+// first and last bucket are cut off at the start and end of the period.
+// Amounts outside the period are ignored.
+func Buckets(params BucketParams) []Bucket {
+	from := truncateDay(params.From)
+	to := truncateDay(params.To)
+	if to.Before(from) {
+		return []Bucket{}
+	}
+
+	buckets := []Bucket{}
+	for start := params.Calendar.BucketStart(from, params.GroupBy); !start.After(to); start = nextBucketStart(start, params.GroupBy) {
+		end := nextBucketStart(start, params.GroupBy).AddDate(0, 0, -1)
+		buckets = append(buckets, Bucket{
+			Start:  maxTime(start, from),
+			End:    minTime(end, to),
+			Groups: []BucketGroup{},
+		})
+	}
+
+	bucketIndex := func(date time.Time) (int, bool) {
+		if date.Before(from) || date.After(to) {
+			return 0, false
+		}
+		idx, _ := slices.BinarySearchFunc(buckets, date, func(bucket Bucket, date time.Time) int {
+			if bucket.End.Before(date) {
+				return -1
+			}
+			if bucket.Start.After(date) {
+				return 1
+			}
+			return 0
+		})
+		return idx, true
+	}
+
+	groups := make([]map[string]*Totals, len(buckets))
+	for _, amount := range params.Amounts {
+		idx, ok := bucketIndex(truncateDay(amount.Date))
+		if !ok {
+			continue
+		}
+
+		if groups[idx] == nil {
+			groups[idx] = make(map[string]*Totals)
+		}
+		totals, ok := groups[idx][amount.Key]
+		if !ok {
+			totals = &Totals{}
+			groups[idx][amount.Key] = totals
+		}
+		totals.Income += amount.Income
+		totals.Expense += amount.Expense
+	}
+
+	for _, amount := range params.Totals {
+		idx, ok := bucketIndex(truncateDay(amount.Date))
+		if !ok {
+			continue
+		}
+		buckets[idx].Income += amount.Income
+		buckets[idx].Expense += amount.Expense
+	}
+
+	for idx := range buckets {
+		bucket := &buckets[idx]
+		for key, totals := range groups[idx] {
+			if params.Totals == nil {
+				bucket.Income += totals.Income
+				bucket.Expense += totals.Expense
+			}
+			bucket.Groups = append(bucket.Groups, BucketGroup{
+				Totals: roundTotals(*totals),
+				Key:    key,
+			})
+		}
+		bucket.Totals = roundTotals(bucket.Totals)
+
+		slices.SortFunc(bucket.Groups, func(a BucketGroup, b BucketGroup) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+	}
+
+	return buckets
+}
+
+func roundTotals(totals Totals) Totals {
+	return Totals{
+		Income:  round(totals.Income),
+		Expense: round(totals.Expense),
+		Net:     round(totals.Income - totals.Expense),
+	}
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
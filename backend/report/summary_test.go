@@ -0,0 +1,142 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketStart(t *testing.T) {
+	calendar := Calendar{WeekStart: time.Monday, FiscalYearStart: time.April}
+	// A Sunday
+	sunday := date(2024, time.June, 9)
+
+	tests := []struct {
+		groupBy string
+		want    time.Time
+	}{
+		{GroupByDay, date(2024, time.June, 9)},
+		{GroupByWeek, date(2024, time.June, 3)},
+		{GroupByMonth, date(2024, time.June, 1)},
+		{GroupByQuarter, date(2024, time.April, 1)},
+		{GroupByYear, date(2024, time.April, 1)},
+	}
+	for _, test := range tests {
+		if got := calendar.BucketStart(sunday, test.groupBy); !got.Equal(test.want) {
+			t.Errorf("%s: got %s, want %s", test.groupBy, got, test.want)
+		}
+	}
+
+	sundayStart := Calendar{WeekStart: time.Sunday}
+	if got := sundayStart.BucketStart(sunday, GroupByWeek); !got.Equal(sunday) {
+		t.Errorf("got week start %s, want %s", got, sunday)
+	}
+	// Without a fiscal year start the year is the calendar year
+	if got := sundayStart.BucketStart(sunday, GroupByYear); !got.Equal(date(2024, time.January, 1)) {
+		t.Errorf("got year start %s", got)
+	}
+}
+
+func TestBucketStartFiscalYearBeforeStart(t *testing.T) {
+	calendar := Calendar{FiscalYearStart: time.October}
+
+	if got := calendar.BucketStart(date(2024, time.February, 15), GroupByYear); !got.Equal(date(2023, time.October, 1)) {
+		t.Errorf("got year start %s, want 2023-10-01", got)
+	}
+	if got := calendar.BucketStart(date(2024, time.February, 15), GroupByQuarter); !got.Equal(date(2024, time.January, 1)) {
+		t.Errorf("got quarter start %s, want 2024-01-01", got)
+	}
+	if got := calendar.BucketStart(date(2024, time.December, 31), GroupByQuarter); !got.Equal(date(2024, time.October, 1)) {
+		t.Errorf("got quarter start %s, want 2024-10-01", got)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	buckets := Buckets(BucketParams{
+		From:     date(2024, time.January, 15),
+		To:       date(2024, time.March, 10),
+		GroupBy:  GroupByMonth,
+		Calendar: Calendar{WeekStart: time.Monday},
+		Amounts: []Amount{
+			{Date: date(2024, time.January, 20), Key: "Groceries", Expense: 50},
+			{Date: date(2024, time.January, 25), Key: "Salary", Income: 2000},
+			{Date: date(2024, time.January, 31), Key: "Groceries", Expense: 25.5},
+			{Date: date(2024, time.March, 10), Key: "Groceries", Expense: 10},
+			// Outside of the period
+			{Date: date(2024, time.January, 14), Key: "Groceries", Expense: 100},
+			{Date: date(2024, time.March, 11), Key: "Groceries", Expense: 100},
+		},
+	})
+
+	if len(buckets) != 3 {
+		t.Fatalf("got %d buckets, want 3", len(buckets))
+	}
+
+	january := buckets[0]
+	if !january.Start.Equal(date(2024, time.January, 15)) || !january.End.Equal(date(2024, time.January, 31)) {
+		t.Fatalf("unexpected january bounds %s - %s", january.Start, january.End)
+	}
+	if january.Income != 2000 || january.Expense != 75.5 || january.Net != 1924.5 {
+		t.Fatalf("unexpected january totals %+v", january.Totals)
+	}
+	if len(january.Groups) != 2 || january.Groups[0].Key != "Groceries" || january.Groups[0].Expense != 75.5 || january.Groups[0].Net != -75.5 {
+		t.Fatalf("unexpected january groups %+v", january.Groups)
+	}
+
+	february := buckets[1]
+	if february.Net != 0 || len(february.Groups) != 0 {
+		t.Fatalf("expected an empty february, got %+v", february)
+	}
+
+	march := buckets[2]
+	if !march.End.Equal(date(2024, time.March, 10)) || march.Expense != 10 {
+		t.Fatalf("unexpected march %+v", march)
+	}
+}
+
+func TestBucketsWeeks(t *testing.T) {
+	buckets := Buckets(BucketParams{
+		From:     date(2024, time.June, 1),
+		To:       date(2024, time.June, 30),
+		GroupBy:  GroupByWeek,
+		Calendar: Calendar{WeekStart: time.Monday},
+	})
+
+	// June 1st is a Saturday and June 30th a Sunday
+	if len(buckets) != 5 {
+		t.Fatalf("got %d buckets, want 5", len(buckets))
+	}
+	if !buckets[0].End.Equal(date(2024, time.June, 2)) || !buckets[1].Start.Equal(date(2024, time.June, 3)) {
+		t.Fatalf("unexpected first weeks %s - %s, %s", buckets[0].Start, buckets[0].End, buckets[1].Start)
+	}
+	if !buckets[4].Start.Equal(date(2024, time.June, 24)) || !buckets[4].End.Equal(date(2024, time.June, 30)) {
+		t.Fatalf("unexpected last week %s - %s", buckets[4].Start, buckets[4].End)
+	}
+}
+
+func TestBucketsTotals(t *testing.T) {
+	// An expense of 30 with two tags is in both groups, but only counts once
+	// in the totals
+	buckets := Buckets(BucketParams{
+		From:     date(2024, time.June, 1),
+		To:       date(2024, time.June, 30),
+		GroupBy:  GroupByMonth,
+		Calendar: Calendar{WeekStart: time.Monday},
+		Amounts: []Amount{
+			{Date: date(2024, time.June, 3), Key: "Holiday", Expense: 30},
+			{Date: date(2024, time.June, 3), Key: "Food", Expense: 30},
+		},
+		Totals: []Amount{
+			{Date: date(2024, time.June, 3), Expense: 30},
+			// Outside of the period
+			{Date: date(2024, time.July, 1), Expense: 100},
+		},
+	})
+
+	june := buckets[0]
+	if june.Expense != 30 || june.Net != -30 {
+		t.Fatalf("unexpected june totals %+v", june.Totals)
+	}
+	if len(june.Groups) != 2 || june.Groups[0].Expense != 30 || june.Groups[1].Expense != 30 {
+		t.Fatalf("unexpected june groups %+v", june.Groups)
+	}
+}
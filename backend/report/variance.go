@@ -0,0 +1,164 @@
+// Package report compares budgets and savings goals with the actual
+// transactions.
+package report
+
+import (
+	"math"
+	"time"
+)
+
+// Expense is an expense of a budget with the amount allocated to it in the
+// period.
+type Expense struct {
+	ID        int32
+	Name      string
+	Allocated float64
+}
+
+// Spending is an amount spent on an expense. Refunds are negative.
+type Spending struct {
+	ExpenseID int32
+	Date      time.Time
+	Amount    float64
+}
+
+type Params struct {
+	// Start and End are the first and last day of the period
+	Start time.Time
+	End   time.Time
+	// AsOf is the last day with known spending, usually today
+	AsOf     time.Time
+	Expenses []Expense
+	Spending []Spending
+}
+
+// Point is the cumulative spending at the end of a day next to the spending
+// at the ideal linear pace. After AsOf the spending is projected.
+type Point struct {
+	Date      time.Time
+	Actual    float64
+	Ideal     float64
+	Projected bool
+}
+
+// Summary compares the allocated amount with the actual spending. A positive
+// variance is money left, a negative variance is overspending. The projected
+// amount is the spending at the end of the period at the current pace.
+type Summary struct {
+	Allocated         float64
+	Actual            float64
+	Variance          float64
+	Projected         float64
+	ProjectedVariance float64
+	BurnDown          []Point
+}
+
+type ExpenseVariance struct {
+	Summary
+	ID   int32
+	Name string
+}
+
+type Variance struct {
+	Summary
+	Expenses []ExpenseVariance
+}
+
+// Analyze calculates the variance of each expense and of the whole period.
+// Spending outside the period or on unknown expenses is ignored.
+func Analyze(params Params) Variance {
+	start := truncateDay(params.Start)
+	end := truncateDay(params.End)
+	asOf := truncateDay(params.AsOf)
+	days := daysBetween(start, end) + 1
+	if days < 1 {
+		return Variance{}
+	}
+
+	daily := make(map[int32][]float64, len(params.Expenses))
+	for _, expense := range params.Expenses {
+		daily[expense.ID] = make([]float64, days)
+	}
+	total := make([]float64, days)
+	for _, spending := range params.Spending {
+		day := daysBetween(start, truncateDay(spending.Date))
+		amounts, ok := daily[spending.ExpenseID]
+		if !ok || day < 0 || day >= days {
+			continue
+		}
+		amounts[day] += spending.Amount
+		total[day] += spending.Amount
+	}
+
+	// The number of days of the period with known spending
+	elapsed := min(max(daysBetween(start, asOf)+1, 0), days)
+
+	var allocated float64
+	expenses := make([]ExpenseVariance, len(params.Expenses))
+	for idx, expense := range params.Expenses {
+		expenses[idx] = ExpenseVariance{
+			Summary: summarize(start, expense.Allocated, daily[expense.ID], elapsed),
+			ID:      expense.ID,
+			Name:    expense.Name,
+		}
+		allocated += expense.Allocated
+	}
+
+	return Variance{
+		Summary:  summarize(start, allocated, total, elapsed),
+		Expenses: expenses,
+	}
+}
+
+func summarize(start time.Time, allocated float64, daily []float64, elapsed int) Summary {
+	days := len(daily)
+
+	var actual float64
+	for day := 0; day < elapsed; day++ {
+		actual += daily[day]
+	}
+
+	// Without any elapsed days there is no pace to project from
+	projected := actual
+	if elapsed > 0 {
+		projected = actual / float64(elapsed) * float64(days)
+	}
+
+	points := make([]Point, days)
+	var cumulative float64
+	for day := 0; day < days; day++ {
+		point := Point{
+			Date:  start.AddDate(0, 0, day),
+			Ideal: round(allocated * float64(day+1) / float64(days)),
+		}
+		if day < elapsed {
+			cumulative += daily[day]
+			point.Actual = round(cumulative)
+		} else {
+			point.Actual = round(actual + (projected-actual)*float64(day+1-elapsed)/float64(days-elapsed))
+			point.Projected = true
+		}
+		points[day] = point
+	}
+
+	return Summary{
+		Allocated:         round(allocated),
+		Actual:            round(actual),
+		Variance:          round(allocated - actual),
+		Projected:         round(projected),
+		ProjectedVariance: round(allocated - projected),
+		BurnDown:          points,
+	}
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func truncateDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAnalyze(t *testing.T) {
+	variance := Analyze(Params{
+		Start: date(2024, time.June, 1),
+		End:   date(2024, time.June, 30),
+		AsOf:  date(2024, time.June, 10),
+		Expenses: []Expense{
+			{ID: 1, Name: "Groceries", Allocated: 300},
+			{ID: 2, Name: "Entertainment", Allocated: 100},
+		},
+		Spending: []Spending{
+			{ExpenseID: 1, Date: date(2024, time.June, 2), Amount: 60},
+			{ExpenseID: 1, Date: date(2024, time.June, 8), Amount: 75},
+			{ExpenseID: 1, Date: date(2024, time.June, 9), Amount: -5},
+			{ExpenseID: 2, Date: date(2024, time.June, 5), Amount: 20},
+			// Outside of the period and on an unknown expense
+			{ExpenseID: 1, Date: date(2024, time.May, 31), Amount: 100},
+			{ExpenseID: 3, Date: date(2024, time.June, 5), Amount: 100},
+		},
+	})
+
+	groceries := variance.Expenses[0]
+	if groceries.Actual != 130 || groceries.Variance != 170 {
+		t.Fatalf("unexpected groceries variance %+v", groceries.Summary)
+	}
+	// 130 in 10 days is 390 in 30 days
+	if groceries.Projected != 390 || groceries.ProjectedVariance != -90 {
+		t.Fatalf("unexpected groceries projection %+v", groceries.Summary)
+	}
+
+	if len(groceries.BurnDown) != 30 {
+		t.Fatalf("got %d points, want 30", len(groceries.BurnDown))
+	}
+	tenth := groceries.BurnDown[9]
+	if tenth.Actual != 130 || tenth.Ideal != 100 || tenth.Projected {
+		t.Fatalf("unexpected point on the 10th %+v", tenth)
+	}
+	last := groceries.BurnDown[29]
+	if last.Actual != 390 || last.Ideal != 300 || !last.Projected {
+		t.Fatalf("unexpected point on the 30th %+v", last)
+	}
+
+	if variance.Allocated != 400 || variance.Actual != 150 || variance.Projected != 450 || variance.ProjectedVariance != -50 {
+		t.Fatalf("unexpected total variance %+v", variance.Summary)
+	}
+}
+
+func TestAnalyzeBeforeStart(t *testing.T) {
+	variance := Analyze(Params{
+		Start:    date(2024, time.June, 1),
+		End:      date(2024, time.June, 30),
+		AsOf:     date(2024, time.May, 20),
+		Expenses: []Expense{{ID: 1, Name: "Groceries", Allocated: 300}},
+	})
+
+	if variance.Projected != 0 || variance.ProjectedVariance != 300 {
+		t.Fatalf("unexpected variance %+v", variance.Summary)
+	}
+	if !variance.BurnDown[0].Projected {
+		t.Fatalf("expected the first day to be projected")
+	}
+}
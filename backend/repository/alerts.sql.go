@@ -0,0 +1,103 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: alerts.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createBudgetAlert = `-- name: CreateBudgetAlert :one
+INSERT INTO budget_alerts (budget_expense_id, threshold, period_start, percentage_used)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING id, budget_expense_id, threshold, period_start, percentage_used, created
+`
+
+type CreateBudgetAlertParams struct {
+	BudgetExpenseID int32
+	Threshold       int32
+	PeriodStart     time.Time
+	PercentageUsed  string
+}
+
+func (q *Queries) CreateBudgetAlert(ctx context.Context, arg CreateBudgetAlertParams) (BudgetAlert, error) {
+	row := q.db.QueryRowContext(ctx, createBudgetAlert,
+		arg.BudgetExpenseID,
+		arg.Threshold,
+		arg.PeriodStart,
+		arg.PercentageUsed,
+	)
+	var i BudgetAlert
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetExpenseID,
+		&i.Threshold,
+		&i.PeriodStart,
+		&i.PercentageUsed,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getBudgetExpensesWithThresholds = `-- name: GetBudgetExpensesWithThresholds :many
+SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version, be.current_amount, be.rollover, be.rollover_amount, be.rollover_period_id, be.rollover_start_date, be.rollover_end_date, be.categories, be.tags, be.payees, be.thresholds, b.name as budget_name, b.start_date as period_start FROM full_budget_expense be
+JOIN budgets b ON be.budget_id = b.id
+WHERE b.user_id = $1 AND b.deleted IS NULL AND cardinality(be.thresholds) > 0
+ORDER BY b.id, be.id
+`
+
+type GetBudgetExpensesWithThresholdsRow struct {
+	FullBudgetExpense FullBudgetExpense
+	BudgetName        string
+	PeriodStart       time.Time
+}
+
+func (q *Queries) GetBudgetExpensesWithThresholds(ctx context.Context, userID uuid.UUID) ([]GetBudgetExpensesWithThresholdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetExpensesWithThresholds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBudgetExpensesWithThresholdsRow
+	for rows.Next() {
+		var i GetBudgetExpensesWithThresholdsRow
+		if err := rows.Scan(
+			&i.FullBudgetExpense.ID,
+			&i.FullBudgetExpense.BudgetID,
+			&i.FullBudgetExpense.Name,
+			&i.FullBudgetExpense.AllocatedAmount,
+			&i.FullBudgetExpense.Created,
+			&i.FullBudgetExpense.Updated,
+			&i.FullBudgetExpense.Version,
+			&i.FullBudgetExpense.CurrentAmount,
+			&i.FullBudgetExpense.Rollover,
+			&i.FullBudgetExpense.RolloverAmount,
+			&i.FullBudgetExpense.RolloverPeriodID,
+			&i.FullBudgetExpense.RolloverStartDate,
+			&i.FullBudgetExpense.RolloverEndDate,
+			pq.Array(&i.FullBudgetExpense.Categories),
+			pq.Array(&i.FullBudgetExpense.Tags),
+			pq.Array(&i.FullBudgetExpense.Payees),
+			pq.Array(&i.FullBudgetExpense.Thresholds),
+			&i.BudgetName,
+			&i.PeriodStart,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,476 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+type auditInfoKey struct{}
+
+// AuditInfo identifies who made a change and in which request. It travels
+// with the request context so repositories can record it next to the change.
+type AuditInfo struct {
+	ActorID   uuid.UUID
+	RequestID string
+}
+
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+func getAuditInfo(ctx context.Context) (AuditInfo, bool) {
+	info, ok := ctx.Value(auditInfoKey{}).(AuditInfo)
+	return info, ok
+}
+
+type IAuditRepository interface {
+	GetForEntity(ctx context.Context, userId uuid.UUID, entityType string, entityId string) (*[]AuditLog, error)
+}
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func CreateAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (repository *AuditRepository) GetForEntity(ctx context.Context, userId uuid.UUID, entityType string, entityId string) (*[]AuditLog, error) {
+	db := New(repository.db)
+	auditLogs, err := db.GetAuditLogForEntity(ctx, GetAuditLogForEntityParams{
+		UserID:     userId,
+		EntityType: entityType,
+		EntityID:   entityId,
+		Limit:      MaxFetchLimit,
+		Offset:     0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &auditLogs, nil
+}
+
+type auditEntry struct {
+	UserID     uuid.UUID
+	EntityType string
+	EntityID   string
+	Action     string
+	Before     any
+	After      any
+}
+
+// writeAudit appends an entry to the audit log. It should be called with the
+// queries of the database transaction that made the change, so the change and
+// its audit entry are committed together.
+func writeAudit(ctx context.Context, db *Queries, entry auditEntry) error {
+	before, err := toNullRawMessage(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := toNullRawMessage(entry.After)
+	if err != nil {
+		return err
+	}
+
+	params := CreateAuditLogParams{
+		UserID:     entry.UserID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		Before:     before,
+		After:      after,
+	}
+	if info, ok := getAuditInfo(ctx); ok {
+		params.ActorID = uuid.NullUUID{UUID: info.ActorID, Valid: true}
+		params.RequestID = sql.NullString{String: info.RequestID, Valid: info.RequestID != ""}
+	}
+
+	return db.CreateAuditLog(ctx, params)
+}
+
+// writeTransactionsAudit records a change to many transactions with an entry
+// per transaction. The transactions before the change are matched by id with
+// the transactions after it, and transactions that ended up in the trash are
+// recorded as deleted.
+func writeTransactionsAudit(ctx context.Context, db *Queries, before []Transaction, after []Transaction) error {
+	for _, transaction := range after {
+		entry := auditEntry{
+			UserID:     transaction.UserID,
+			EntityType: AuditEntityTransaction,
+			EntityID:   int32ToString(transaction.ID),
+			Action:     AuditActionUpdate,
+			After:      snapshotTransaction(transaction),
+		}
+		if transaction.Deleted.Valid {
+			entry.Action = AuditActionDelete
+		}
+		for _, previous := range before {
+			if previous.ID == transaction.ID {
+				entry.Before = snapshotTransaction(previous)
+				break
+			}
+		}
+
+		err := writeAudit(ctx, db, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTransactionsDeletedAudit records transactions that were moved to the
+// trash. Moving to the trash only sets the deletion time and bumps the
+// version, so the transactions before the change follow from the rows after.
+func writeTransactionsDeletedAudit(ctx context.Context, db *Queries, deleted []Transaction) error {
+	before := make([]Transaction, len(deleted))
+	for idx, transaction := range deleted {
+		before[idx] = transaction
+		before[idx].Deleted = sql.NullTime{}
+		before[idx].Version = transaction.Version - 1
+	}
+
+	return writeTransactionsAudit(ctx, db, before, deleted)
+}
+
+// writeTransactionsRestoredAudit records transactions that were restored from
+// the trash together with their series. They were all moved to the trash at
+// the time deleted, and restoring only clears it and bumps the version.
+func writeTransactionsRestoredAudit(ctx context.Context, db *Queries, restored []Transaction, deleted time.Time) error {
+	for _, transaction := range restored {
+		before := transaction
+		before.Deleted = sql.NullTime{Time: deleted, Valid: true}
+		before.Version = transaction.Version - 1
+
+		err := writeAudit(ctx, db, auditEntry{
+			UserID:     transaction.UserID,
+			EntityType: AuditEntityTransaction,
+			EntityID:   int32ToString(transaction.ID),
+			Action:     AuditActionRestore,
+			Before:     snapshotTransaction(before),
+			After:      snapshotTransaction(transaction),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toNullRawMessage(value any) (pqtype.NullRawMessage, error) {
+	if value == nil {
+		return pqtype.NullRawMessage{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return pqtype.NullRawMessage{}, err
+	}
+
+	return pqtype.NullRawMessage{RawMessage: data, Valid: true}, nil
+}
+
+type transactionSnapshot struct {
+	ID                     int32      `json:"id"`
+	BudgetID               *string    `json:"budgetId"`
+	BudgetExpenseID        *int32     `json:"budgetExpenseId"`
+	RecurringTransactionID *int32     `json:"recurringTransactionId"`
+	Description            string     `json:"description"`
+	Amount                 string     `json:"amount"`
+	Type                   string     `json:"type"`
+	Date                   time.Time  `json:"date"`
+	Deleted                *time.Time `json:"deleted"`
+	Version                int32      `json:"version"`
+}
+
+func snapshotTransaction(transaction Transaction) transactionSnapshot {
+	return transactionSnapshot{
+		ID:                     transaction.ID,
+		BudgetID:               nullStringPtr(transaction.BudgetID),
+		BudgetExpenseID:        nullInt32Ptr(transaction.BudgetExpenseID),
+		RecurringTransactionID: nullInt32Ptr(transaction.RecurringTransactionID),
+		Description:            transaction.Description,
+		Amount:                 transaction.Amount,
+		Type:                   transaction.Type,
+		Date:                   transaction.Date,
+		Deleted:                nullTimePtr(transaction.Deleted),
+		Version:                transaction.Version,
+	}
+}
+
+type recurringTransactionSnapshot struct {
+	ID           int32      `json:"id"`
+	StartDate    time.Time  `json:"startDate"`
+	EndDate      *time.Time `json:"endDate"`
+	Interval     string     `json:"interval"`
+	DaysInterval *int32     `json:"daysInterval"`
+	RRule        string     `json:"rrule"`
+	Description  string     `json:"description"`
+	Amount       string     `json:"amount"`
+	Type         string     `json:"type"`
+	Deleted      *time.Time `json:"deleted"`
+}
+
+func snapshotRecurringTransaction(recurringTransaction RecurringTransaction) recurringTransactionSnapshot {
+	return recurringTransactionSnapshot{
+		ID:           recurringTransaction.ID,
+		StartDate:    recurringTransaction.StartDate,
+		EndDate:      nullTimePtr(recurringTransaction.EndDate),
+		Interval:     recurringTransaction.Interval,
+		DaysInterval: nullInt32Ptr(recurringTransaction.DaysInterval),
+		RRule:        recurringTransaction.Rrule,
+		Description:  recurringTransaction.Description,
+		Amount:       recurringTransaction.Amount,
+		Type:         recurringTransaction.Type,
+		Deleted:      nullTimePtr(recurringTransaction.Deleted),
+	}
+}
+
+type budgetSnapshot struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Amount      string     `json:"amount"`
+	StartDate   time.Time  `json:"startDate"`
+	EndDate     time.Time  `json:"endDate"`
+	Type        string     `json:"type"`
+	ZeroBased   bool       `json:"zeroBased"`
+	Deleted     *time.Time `json:"deleted"`
+	Version     int32      `json:"version"`
+}
+
+func snapshotBudget(budget Budget) budgetSnapshot {
+	return budgetSnapshot{
+		ID:          budget.ID,
+		Name:        budget.Name,
+		Description: budget.Description,
+		Amount:      budget.Amount,
+		StartDate:   budget.StartDate,
+		EndDate:     budget.EndDate,
+		Type:        budget.Type,
+		ZeroBased:   budget.ZeroBased,
+		Deleted:     nullTimePtr(budget.Deleted),
+		Version:     budget.Version,
+	}
+}
+
+type budgetExpenseSnapshot struct {
+	ID              int32    `json:"id"`
+	BudgetID        string   `json:"budgetId"`
+	Name            string   `json:"name"`
+	AllocatedAmount string   `json:"allocatedAmount"`
+	Rollover        string   `json:"rollover"`
+	Categories      []string `json:"categories"`
+	Tags            []string `json:"tags"`
+	Payees          []string `json:"payees"`
+	Thresholds      []int32  `json:"thresholds"`
+	Version         int32    `json:"version"`
+}
+
+func snapshotBudgetExpense(budgetExpense BudgetExpense) budgetExpenseSnapshot {
+	return budgetExpenseSnapshot{
+		ID:              budgetExpense.ID,
+		BudgetID:        budgetExpense.BudgetID,
+		Name:            budgetExpense.Name,
+		AllocatedAmount: budgetExpense.AllocatedAmount,
+		Rollover:        budgetExpense.Rollover,
+		Categories:      budgetExpense.Categories,
+		Tags:            budgetExpense.Tags,
+		Payees:          budgetExpense.Payees,
+		Thresholds:      budgetExpense.Thresholds,
+		Version:         budgetExpense.Version,
+	}
+}
+
+type envelopeMovementSnapshot struct {
+	ID            int32     `json:"id"`
+	BudgetID      string    `json:"budgetId"`
+	FromExpenseID *int32    `json:"fromExpenseId"`
+	ToExpenseID   *int32    `json:"toExpenseId"`
+	Amount        string    `json:"amount"`
+	Description   string    `json:"description"`
+	Date          time.Time `json:"date"`
+}
+
+func snapshotEnvelopeMovement(movement BudgetEnvelopeMovement) envelopeMovementSnapshot {
+	return envelopeMovementSnapshot{
+		ID:            movement.ID,
+		BudgetID:      movement.BudgetID,
+		FromExpenseID: nullInt32Ptr(movement.FromExpenseID),
+		ToExpenseID:   nullInt32Ptr(movement.ToExpenseID),
+		Amount:        movement.Amount,
+		Description:   movement.Description,
+		Date:          movement.Date,
+	}
+}
+
+type goalSnapshot struct {
+	ID            int32     `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	TargetAmount  string    `json:"targetAmount"`
+	InitialAmount string    `json:"initialAmount"`
+	StartDate     time.Time `json:"startDate"`
+	TargetDate    time.Time `json:"targetDate"`
+	Tags          []string  `json:"tags"`
+	// InvestmentAccountID is the linked investment account, 0 without one
+	InvestmentAccountID int32 `json:"investmentAccountId"`
+	Version             int32 `json:"version"`
+}
+
+func snapshotGoal(goal Goal) goalSnapshot {
+	return goalSnapshot{
+		ID:                  goal.ID,
+		Name:                goal.Name,
+		Description:         goal.Description,
+		TargetAmount:        goal.TargetAmount,
+		InitialAmount:       goal.InitialAmount,
+		StartDate:           goal.StartDate,
+		TargetDate:          goal.TargetDate,
+		Tags:                goal.Tags,
+		InvestmentAccountID: goal.InvestmentAccountID.Int32,
+		Version:             goal.Version,
+	}
+}
+
+type loanSnapshot struct {
+	ID               int32     `json:"id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Principal        string    `json:"principal"`
+	InterestRate     string    `json:"interestRate"`
+	Term             int32     `json:"term"`
+	Schedule         string    `json:"schedule"`
+	Type             string    `json:"type"`
+	FirstPaymentDate time.Time `json:"firstPaymentDate"`
+	Version          int32     `json:"version"`
+}
+
+func snapshotLoan(loan Loan) loanSnapshot {
+	return loanSnapshot{
+		ID:               loan.ID,
+		Name:             loan.Name,
+		Description:      loan.Description,
+		Principal:        loan.Principal,
+		InterestRate:     loan.InterestRate,
+		Term:             loan.Term,
+		Schedule:         loan.Schedule,
+		Type:             loan.Type,
+		FirstPaymentDate: loan.FirstPaymentDate,
+		Version:          loan.Version,
+	}
+}
+
+type investmentAccountSnapshot struct {
+	ID              int32  `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	CostBasisMethod string `json:"costBasisMethod"`
+	Version         int32  `json:"version"`
+}
+
+func snapshotInvestmentAccount(account InvestmentAccount) investmentAccountSnapshot {
+	return investmentAccountSnapshot{
+		ID:              account.ID,
+		Name:            account.Name,
+		Description:     account.Description,
+		CostBasisMethod: account.CostBasisMethod,
+		Version:         account.Version,
+	}
+}
+
+type investmentTradeSnapshot struct {
+	ID        int32     `json:"id"`
+	AccountID int32     `json:"accountId"`
+	Symbol    string    `json:"symbol"`
+	Kind      string    `json:"kind"`
+	Date      time.Time `json:"date"`
+	Quantity  string    `json:"quantity"`
+	Price     string    `json:"price"`
+	Fees      string    `json:"fees"`
+}
+
+func snapshotInvestmentTrade(trade InvestmentTrade) investmentTradeSnapshot {
+	return investmentTradeSnapshot{
+		ID:        trade.ID,
+		AccountID: trade.AccountID,
+		Symbol:    trade.Symbol,
+		Kind:      trade.Kind,
+		Date:      trade.Date,
+		Quantity:  trade.Quantity,
+		Price:     trade.Price,
+		Fees:      trade.Fees,
+	}
+}
+
+func int32ToString(id int32) string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+func nullInt32Ptr(value sql.NullInt32) *int32 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int32
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// Audit entity type
+const (
+	AuditEntityTransaction          string = "transaction"
+	AuditEntityRecurringTransaction        = "recurring_transaction"
+	AuditEntityBudget                      = "budget"
+	AuditEntityBudgetExpense               = "budget_expense"
+	AuditEntityEnvelopeMovement            = "envelope_movement"
+	AuditEntityGoal                        = "goal"
+	AuditEntityLoan                        = "loan"
+	AuditEntityInvestmentAccount           = "investment_account"
+	AuditEntityInvestmentTrade             = "investment_trade"
+)
+
+var AuditEntities = []string{
+	AuditEntityTransaction,
+	AuditEntityRecurringTransaction,
+	AuditEntityBudget,
+	AuditEntityBudgetExpense,
+	AuditEntityEnvelopeMovement,
+	AuditEntityGoal,
+	AuditEntityLoan,
+	AuditEntityInvestmentAccount,
+	AuditEntityInvestmentTrade,
+}
+
+// Audit action
+const (
+	AuditActionCreate  string = "create"
+	AuditActionUpdate         = "update"
+	AuditActionDelete         = "delete"
+	AuditActionRestore        = "restore"
+	AuditActionPurge          = "purge"
+	AuditActionTag            = "tag"
+	AuditActionUntag          = "untag"
+)
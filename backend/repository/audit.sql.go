@@ -0,0 +1,100 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: audit.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+INSERT INTO audit_log (user_id, actor_id, request_id, entity_type, entity_id, action, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAuditLogParams struct {
+	UserID     uuid.UUID
+	ActorID    uuid.NullUUID
+	RequestID  sql.NullString
+	EntityType string
+	EntityID   string
+	Action     string
+	Before     pqtype.NullRawMessage
+	After      pqtype.NullRawMessage
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditLog,
+		arg.UserID,
+		arg.ActorID,
+		arg.RequestID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Action,
+		arg.Before,
+		arg.After,
+	)
+	return err
+}
+
+const getAuditLogForEntity = `-- name: GetAuditLogForEntity :many
+SELECT id, user_id, actor_id, request_id, entity_type, entity_id, action, before, after, created FROM audit_log
+WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+ORDER BY created DESC, id DESC
+LIMIT $4
+OFFSET $5
+`
+
+type GetAuditLogForEntityParams struct {
+	UserID     uuid.UUID
+	EntityType string
+	EntityID   string
+	Limit      int32
+	Offset     int32
+}
+
+func (q *Queries) GetAuditLogForEntity(ctx context.Context, arg GetAuditLogForEntityParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogForEntity,
+		arg.UserID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ActorID,
+			&i.RequestID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Action,
+			&i.Before,
+			&i.After,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
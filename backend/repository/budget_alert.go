@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// FiredBudgetAlert is the notification for the highest threshold of a budget
+// expense that was reached for the first time in the current period.
+type FiredBudgetAlert struct {
+	BudgetAlert
+	Notification Notification
+}
+
+// EvaluateAlerts records the thresholds of the budget expenses of the user
+// that are reached in the current period of their budget. A threshold fires
+// once per period, and when several thresholds of an expense are reached at
+// once only the highest one is notified.
+func (repository *BudgetRepository) EvaluateAlerts(ctx context.Context, userId uuid.UUID) (*[]FiredBudgetAlert, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	expenses, err := db.GetBudgetExpensesWithThresholds(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []FiredBudgetAlert{}
+	for _, row := range expenses {
+		expense := row.FullBudgetExpense
+		allocated, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+		rollover, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+		spent, _ := strconv.ParseFloat(expense.CurrentAmount, 64)
+		available := allocated + rollover
+		percentage := spentPercentage(spent, available)
+
+		var fired *BudgetAlert
+		for _, threshold := range expense.Thresholds {
+			if percentage < float64(threshold) {
+				continue
+			}
+
+			alert, err := db.CreateBudgetAlert(ctx, CreateBudgetAlertParams{
+				BudgetExpenseID: expense.ID,
+				Threshold:       threshold,
+				PeriodStart:     row.PeriodStart,
+				PercentageUsed:  strconv.FormatFloat(percentage, 'f', 2, 64),
+			})
+			if err != nil {
+				// The threshold already fired in this period
+				if NoRowsFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if fired == nil || alert.Threshold > fired.Threshold {
+				fired = &alert
+			}
+		}
+		if fired == nil {
+			continue
+		}
+
+		notification, err := db.CreateNotification(ctx, CreateNotificationParams{
+			UserID:  userId,
+			Title:   fmt.Sprintf("%s is at %d%% of %s", expense.Name, int(math.Floor(percentage)), row.BudgetName),
+			Message: budgetAlertMessage(expense.Name, row.BudgetName, spent, available),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, FiredBudgetAlert{
+			BudgetAlert:  *fired,
+			Notification: notification,
+		})
+	}
+
+	return &alerts, tx.Commit()
+}
+
+func budgetAlertMessage(expenseName string, budgetName string, spent float64, available float64) string {
+	message := fmt.Sprintf("You spent %.2f of the %.2f for %s in %s.", spent, available, expenseName, budgetName)
+	if spent > available {
+		return message + fmt.Sprintf(" That is %.2f over budget.", spent-available)
+	}
+	return message + fmt.Sprintf(" There is %.2f left.", available-spent)
+}
+
+// spentPercentage returns how much of the amount is spent. Spending anything
+// from nothing is 100 percent.
+func spentPercentage(spent float64, amount float64) float64 {
+	if amount <= 0 {
+		if spent > 0 {
+			return 100
+		}
+		return 0
+	}
+
+	return math.Round(spent/amount*10000) / 100
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotZeroBased    = errors.New("budget is not zero-based")
+	ErrUnknownEnvelope = errors.New("envelope does not belong to the budget")
+)
+
+// Envelope ledger entry
+const (
+	EnvelopeEntryIncome string = "Income"
+	EnvelopeEntryMove          = "Move"
+	EnvelopeEntrySpend         = "Spend"
+)
+
+// Envelope is an expense of a zero-based budget. Its balance is the money
+// moved into it minus the money moved out of it and the money spent from it.
+type Envelope struct {
+	ExpenseID int32
+	Name      string
+	Assigned  float64
+	Spent     float64
+	Balance   float64
+}
+
+// EnvelopeSummary is the state of a zero-based budget. Income flows into the
+// pool, and to be budgeted is what is left in the pool after the assignments
+// to the envelopes. It is negative when more was assigned than came in.
+type EnvelopeSummary struct {
+	Income       float64
+	Assigned     float64
+	ToBeBudgeted float64
+	Envelopes    []Envelope
+}
+
+// EnvelopeLedgerEntry moves money from the pool or an envelope to the pool or
+// another envelope. Income has no source and spending has no destination. The
+// balances are those after the entry.
+type EnvelopeLedgerEntry struct {
+	Date          time.Time
+	Kind          string
+	Description   string
+	Amount        float64
+	TransactionID sql.NullInt32
+	MovementID    sql.NullInt32
+	FromExpenseID sql.NullInt32
+	ToExpenseID   sql.NullInt32
+	FromBalance   sql.NullFloat64
+	ToBalance     sql.NullFloat64
+	ToBeBudgeted  float64
+}
+
+type MoveEnvelopeMoneyParams struct {
+	UserID        uuid.UUID
+	BudgetID      string
+	FromExpenseID sql.NullInt32
+	ToExpenseID   sql.NullInt32
+	Amount        float64
+	Description   string
+}
+
+func (repository *BudgetRepository) GetEnvelopes(ctx context.Context, userId uuid.UUID, id string) (*EnvelopeSummary, error) {
+	budget, entries, err := repository.getEnvelopeLedger(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := EnvelopeSummary{
+		Envelopes: make([]Envelope, len(budget.Expenses)),
+	}
+	envelopeMap := make(map[int32]*Envelope, len(budget.Expenses))
+	for idx, expense := range budget.Expenses {
+		summary.Envelopes[idx] = Envelope{
+			ExpenseID: expense.ID,
+			Name:      expense.Name,
+		}
+		envelopeMap[expense.ID] = &summary.Envelopes[idx]
+	}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case EnvelopeEntryIncome:
+			summary.Income += entry.Amount
+		case EnvelopeEntrySpend:
+			if envelope, ok := envelopeMap[entry.FromExpenseID.Int32]; ok {
+				envelope.Spent += entry.Amount
+			}
+		case EnvelopeEntryMove:
+			if !entry.FromExpenseID.Valid {
+				summary.Assigned += entry.Amount
+			} else if envelope, ok := envelopeMap[entry.FromExpenseID.Int32]; ok {
+				envelope.Assigned -= entry.Amount
+			}
+			if !entry.ToExpenseID.Valid {
+				summary.Assigned -= entry.Amount
+			} else if envelope, ok := envelopeMap[entry.ToExpenseID.Int32]; ok {
+				envelope.Assigned += entry.Amount
+			}
+		}
+	}
+
+	for idx := range summary.Envelopes {
+		envelope := &summary.Envelopes[idx]
+		envelope.Assigned = roundAmount(envelope.Assigned)
+		envelope.Spent = roundAmount(envelope.Spent)
+		envelope.Balance = roundAmount(envelope.Assigned - envelope.Spent)
+	}
+	summary.Income = roundAmount(summary.Income)
+	summary.Assigned = roundAmount(summary.Assigned)
+	summary.ToBeBudgeted = roundAmount(summary.Income - summary.Assigned)
+
+	return &summary, nil
+}
+
+// GetEnvelopeHistory returns the ledger of a zero-based budget, oldest first,
+// with the balance of the pool and the envelopes involved after each entry.
+func (repository *BudgetRepository) GetEnvelopeHistory(ctx context.Context, userId uuid.UUID, id string) (*[]EnvelopeLedgerEntry, error) {
+	_, entries, err := repository.getEnvelopeLedger(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entries, nil
+}
+
+// MoveEnvelopeMoney moves money between the pool and the envelopes of a
+// zero-based budget. Without a source the money is assigned from the pool, and
+// without a destination it goes back to the pool. The pool may go negative,
+// which the summary reports as a negative to be budgeted.
+func (repository *BudgetRepository) MoveEnvelopeMoney(ctx context.Context, params MoveEnvelopeMoneyParams) (*BudgetEnvelopeMovement, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	budget, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: params.UserID,
+		ID:     params.BudgetID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !budget.ZeroBased {
+		return nil, ErrNotZeroBased
+	}
+
+	expenses, err := db.GetBudgetExpenses(ctx, budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, expenseId := range []sql.NullInt32{params.FromExpenseID, params.ToExpenseID} {
+		if expenseId.Valid && !containsExpense(expenses, expenseId.Int32) {
+			return nil, ErrUnknownEnvelope
+		}
+	}
+
+	now := time.Now().UTC()
+	movement, err := db.CreateEnvelopeMovement(ctx, CreateEnvelopeMovementParams{
+		BudgetID:      budget.ID,
+		FromExpenseID: params.FromExpenseID,
+		ToExpenseID:   params.ToExpenseID,
+		Amount:        strconv.FormatFloat(params.Amount, 'f', 2, 64),
+		Description:   params.Description,
+		Date:          time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     budget.UserID,
+		EntityType: AuditEntityEnvelopeMovement,
+		EntityID:   int32ToString(movement.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotEnvelopeMovement(movement),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &movement, tx.Commit()
+}
+
+// getEnvelopeLedger merges the income since the first period of the budget,
+// the movements and the spending from the envelopes up to today into a single
+// ledger. Income that counts against an envelope of the budget is a refund of
+// the envelope rather than income.
+func (repository *BudgetRepository) getEnvelopeLedger(ctx context.Context, userId uuid.UUID, id string) (*BudgetWithExpenses, []EnvelopeLedgerEntry, error) {
+	budget, err := repository.GetById(ctx, userId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !budget.ZeroBased {
+		return nil, nil, ErrNotZeroBased
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := today
+	if budget.EndDate.Before(until) {
+		until = budget.EndDate
+	}
+
+	db := New(repository.db)
+	start, err := db.GetBudgetFirstStartDate(ctx, budget.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	income, err := db.GetEnvelopeIncome(ctx, GetEnvelopeIncomeParams{
+		UserID:    userId,
+		BudgetID:  budget.ID,
+		StartDate: start,
+		EndDate:   until,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	movements, err := db.GetEnvelopeMovements(ctx, budget.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	spending, err := db.GetEnvelopeTransactions(ctx, GetEnvelopeTransactionsParams{
+		UserID:   userId,
+		BudgetID: budget.ID,
+		Until:    today,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]EnvelopeLedgerEntry, 0, len(income)+len(movements)+len(spending))
+	for _, transaction := range income {
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		entries = append(entries, EnvelopeLedgerEntry{
+			Date:          transaction.Date,
+			Kind:          EnvelopeEntryIncome,
+			Description:   transaction.Description,
+			Amount:        amount,
+			TransactionID: sql.NullInt32{Int32: transaction.ID, Valid: true},
+		})
+	}
+	for _, movement := range movements {
+		amount, _ := strconv.ParseFloat(movement.Amount, 64)
+		entries = append(entries, EnvelopeLedgerEntry{
+			Date:          movement.Date,
+			Kind:          EnvelopeEntryMove,
+			Description:   movement.Description,
+			Amount:        amount,
+			MovementID:    sql.NullInt32{Int32: movement.ID, Valid: true},
+			FromExpenseID: movement.FromExpenseID,
+			ToExpenseID:   movement.ToExpenseID,
+		})
+	}
+	for _, transaction := range spending {
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		entries = append(entries, EnvelopeLedgerEntry{
+			Date:          transaction.Date,
+			Kind:          EnvelopeEntrySpend,
+			Description:   transaction.Description,
+			Amount:        -amount,
+			TransactionID: sql.NullInt32{Int32: transaction.ID, Valid: true},
+			FromExpenseID: sql.NullInt32{Int32: transaction.BudgetExpenseID, Valid: true},
+		})
+	}
+
+	// Income comes first on a day, so money can be assigned on the day it
+	// comes in
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entryOrder(entries[i].Kind) < entryOrder(entries[j].Kind)
+	})
+
+	var pool float64
+	balances := make(map[int32]float64, len(budget.Expenses))
+	for idx := range entries {
+		entry := &entries[idx]
+		if entry.FromExpenseID.Valid {
+			balances[entry.FromExpenseID.Int32] -= entry.Amount
+			entry.FromBalance = sql.NullFloat64{Float64: roundAmount(balances[entry.FromExpenseID.Int32]), Valid: true}
+		} else if entry.Kind != EnvelopeEntryIncome {
+			pool -= entry.Amount
+		}
+		if entry.ToExpenseID.Valid {
+			balances[entry.ToExpenseID.Int32] += entry.Amount
+			entry.ToBalance = sql.NullFloat64{Float64: roundAmount(balances[entry.ToExpenseID.Int32]), Valid: true}
+		} else if entry.Kind != EnvelopeEntrySpend {
+			pool += entry.Amount
+		}
+		entry.ToBeBudgeted = roundAmount(pool)
+	}
+
+	return budget, entries, nil
+}
+
+func entryOrder(kind string) int {
+	switch kind {
+	case EnvelopeEntryIncome:
+		return 0
+	case EnvelopeEntryMove:
+		return 1
+	}
+	return 2
+}
+
+func containsExpense(expenses []BudgetExpense, id int32) bool {
+	for _, expense := range expenses {
+		if expense.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetMatchedTransaction is a transaction without a budget that counts
+// against an expense of the budget because it matches the categories, tags or
+// payees of the expense.
+type BudgetMatchedTransaction struct {
+	FullTransaction
+	ExpenseID   int32
+	ExpenseName string
+}
+
+// GetMatchedTransactions returns the transactions within the current period
+// of the budget that count against its expenses without being assigned.
+func (repository *BudgetRepository) GetMatchedTransactions(ctx context.Context, userId uuid.UUID, id string) (*[]BudgetMatchedTransaction, error) {
+	db := New(repository.db)
+	rows, err := db.GetBudgetMatchedTransactions(ctx, GetBudgetMatchedTransactionsParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]BudgetMatchedTransaction, len(rows))
+	for idx, row := range rows {
+		transactions[idx] = BudgetMatchedTransaction{
+			FullTransaction: row.FullTransaction,
+			ExpenseID:       row.MatchedExpenseID,
+			ExpenseName:     row.MatchedExpenseName,
+		}
+	}
+
+	return &transactions, nil
+}
+
+// GetUnmatchedTransactions returns the spending within the current period of
+// the budget that is neither assigned to a budget nor matched by one of its
+// expenses. Transactions excluded from the budget are left out.
+func (repository *BudgetRepository) GetUnmatchedTransactions(ctx context.Context, userId uuid.UUID, id string) (*[]FullTransaction, error) {
+	db := New(repository.db)
+	rows, err := db.GetBudgetUnmatchedTransactions(ctx, GetBudgetUnmatchedTransactionsParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]FullTransaction, len(rows))
+	for idx, row := range rows {
+		transactions[idx] = row.FullTransaction
+	}
+
+	return &transactions, nil
+}
+
+// ExcludeTransactions stops transactions from counting against the budget
+// through the matchers of its expenses. Assigning a transaction to an expense
+// still counts it.
+func (repository *BudgetRepository) ExcludeTransactions(ctx context.Context, userId uuid.UUID, id string, transactionIds []int32) (int64, error) {
+	db := New(repository.db)
+	_, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: userId,
+		ID:     id,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return db.ExcludeBudgetTransactions(ctx, ExcludeBudgetTransactionsParams{
+		UserID:   userId,
+		BudgetID: id,
+		Ids:      transactionIds,
+	})
+}
+
+// IncludeTransactions undoes the exclusion of transactions from the budget.
+func (repository *BudgetRepository) IncludeTransactions(ctx context.Context, userId uuid.UUID, id string, transactionIds []int32) (int64, error) {
+	db := New(repository.db)
+	_, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: userId,
+		ID:     id,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return db.IncludeBudgetTransactions(ctx, IncludeBudgetTransactionsParams{
+		BudgetID: id,
+		Ids:      transactionIds,
+	})
+}
+
+// GetSpending returns what was spent on the expenses of the budgets of the user
+// between the dates, through assigned and matched transactions alike.
+func (repository *BudgetRepository) GetSpending(ctx context.Context, userId uuid.UUID, start time.Time, end time.Time) (*[]GetBudgetSpendingBetweenDatesRow, error) {
+	db := New(repository.db)
+	spending, err := db.GetBudgetSpendingBetweenDates(ctx, GetBudgetSpendingBetweenDatesParams{
+		UserID:    userId,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spending, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// The plan test checks that the spending of a budget is matched with the
+// transactions of its user only, on a migrated database seeded with the
+// transactions of other users. It is skipped unless
+// BENCH_DB_CONNECTION_STRING is set, for example:
+//
+//	BENCH_DB_CONNECTION_STRING=postgres://... go test ./repository -run Plan
+
+const planUserCount = 20
+
+func TestBudgetExpenseTransactionsPlan(t *testing.T) {
+	connectionString := os.Getenv("BENCH_DB_CONNECTION_STRING")
+	if connectionString == "" {
+		t.Skip("BENCH_DB_CONNECTION_STRING is not set")
+	}
+
+	conn, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		t.Fatalf("error connecting to db: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var budgetId string
+	var payeeExpenseId int32
+	for i := 0; i < planUserCount; i++ {
+		userId := uuid.New()
+		_, err = New(conn).CreateUser(ctx, CreateUserParams{
+			ID:         userId,
+			Provider:   "bench",
+			ProviderID: userId.String()[:32],
+			Username:   userId.String()[:32],
+			Email:      userId.String() + "@bench",
+		})
+		if err != nil {
+			t.Fatalf("error creating user: %v", err)
+		}
+		// Deleting the user cascades to the budgets and transactions
+		t.Cleanup(func() {
+			_, _ = conn.Exec("DELETE FROM users WHERE id = $1", userId)
+		})
+
+		budget, err := New(conn).CreateBudget(ctx, CreateBudgetParams{
+			ID:        userId.String()[:16],
+			UserID:    userId,
+			Name:      "Plan",
+			Amount:    "1000",
+			StartDate: start,
+			EndDate:   start.AddDate(0, 1, -1),
+			Type:      BudgetTypeMonthly,
+		})
+		if err != nil {
+			t.Fatalf("error creating budget: %v", err)
+		}
+		_, err = New(conn).CreateBudgetExpense(ctx, CreateBudgetExpenseParams{
+			BudgetID:        budget.ID,
+			Name:            "Spending",
+			AllocatedAmount: "500",
+			Rollover:        BudgetRolloverReset,
+			Categories:      slices.Concat(ExpenseTypes, IncomeTypes),
+		})
+		if err != nil {
+			t.Fatalf("error creating budget expense: %v", err)
+		}
+		// Unescaped, the payee would match every transaction
+		payeeExpense, err := New(conn).CreateBudgetExpense(ctx, CreateBudgetExpenseParams{
+			BudgetID:        budget.ID,
+			Name:            "Payee",
+			AllocatedAmount: "500",
+			Rollover:        BudgetRolloverReset,
+			Payees:          []string{"%_"},
+		})
+		if err != nil {
+			t.Fatalf("error creating budget expense: %v", err)
+		}
+		budgetId = budget.ID
+		payeeExpenseId = payeeExpense.ID
+
+		_, err = conn.ExecContext(ctx, `
+			INSERT INTO transactions (user_id, description, amount, type, date)
+			SELECT $1, 'Plan ' || n,
+				CASE WHEN n % 5 = 0 THEN (random() * 2000)::DECIMAL(19, 2) ELSE -(random() * 200)::DECIMAL(19, 2) END,
+				CASE WHEN n % 5 = 0 THEN ($2::text[])[1 + n % array_length($2::text[], 1)] ELSE ($3::text[])[1 + n % array_length($3::text[], 1)] END,
+				$4::timestamp + (n % 365) * interval '1 day'
+			FROM generate_series(1, 5000) AS n`,
+			userId, pq.Array(IncomeTypes), pq.Array(ExpenseTypes), start)
+		if err != nil {
+			t.Fatalf("error seeding transactions: %v", err)
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, "ANALYZE transactions")
+	if err != nil {
+		t.Fatalf("error analyzing transactions: %v", err)
+	}
+
+	var plan string
+	err = conn.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT * FROM full_budget_expense WHERE budget_id = $1", budgetId).Scan(&plan)
+	if err != nil {
+		t.Fatalf("error explaining budget expenses: %v", err)
+	}
+
+	var nodes []struct {
+		Plan planNode
+	}
+	err = json.Unmarshal([]byte(plan), &nodes)
+	if err != nil {
+		t.Fatalf("error decoding plan: %v", err)
+	}
+	for _, node := range nodes {
+		if node.Plan.scansAll("transactions") {
+			t.Fatalf("budget expenses scan all transactions:\n%s", plan)
+		}
+	}
+
+	var matched, payeeMatched, incomeMatched int
+	err = conn.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE bet.budget_expense_id = $2), count(*) FILTER (WHERE t.amount > 0)
+		FROM budget_expense_transactions($1) bet
+		JOIN transactions t ON t.id = bet.transaction_id`, budgetId, payeeExpenseId).Scan(&matched, &payeeMatched, &incomeMatched)
+	if err != nil {
+		t.Fatalf("error matching transactions: %v", err)
+	}
+	if matched == 0 || payeeMatched != 0 || incomeMatched != 0 {
+		t.Fatalf("got %d matches, %d on the payee and %d income, want only expenses matched by category", matched, payeeMatched, incomeMatched)
+	}
+}
+
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// scansAll reports whether the plan scans all rows of the relation.
+func (node planNode) scansAll(relation string) bool {
+	if node.NodeType == "Seq Scan" && node.RelationName == relation {
+		return true
+	}
+	return slices.ContainsFunc(node.Plans, func(child planNode) bool {
+		return child.scansAll(relation)
+	})
+}
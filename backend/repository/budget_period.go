@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget type
+const (
+	BudgetTypeWeekly  string = "Weekly"
+	BudgetTypeMonthly        = "Monthly"
+	BudgetTypeYearly         = "Yearly"
+	BudgetTypeCustom         = "Custom"
+)
+
+var BudgetTypes = []string{
+	BudgetTypeWeekly,
+	BudgetTypeMonthly,
+	BudgetTypeYearly,
+	BudgetTypeCustom,
+}
+
+// Budget rollover
+const (
+	BudgetRolloverReset   string = "Reset"
+	BudgetRolloverUnspent        = "Unspent"
+	BudgetRolloverDeficit        = "Deficit"
+)
+
+var BudgetRollovers = []string{
+	BudgetRolloverReset,
+	BudgetRolloverUnspent,
+	BudgetRolloverDeficit,
+}
+
+// BudgetPeriodEnd returns the last day of the period of a periodic budget
+// that starts at start. Custom budgets have no periods and keep their end.
+func BudgetPeriodEnd(budgetType string, start time.Time, end time.Time) time.Time {
+	switch budgetType {
+	case BudgetTypeWeekly:
+		return start.AddDate(0, 0, 6)
+	case BudgetTypeMonthly:
+		return addMonths(start, 1).AddDate(0, 0, -1)
+	case BudgetTypeYearly:
+		return addMonths(start, 12).AddDate(0, 0, -1)
+	}
+
+	return end
+}
+
+// nextBudgetPeriod returns the dates of the period of a periodic budget that
+// follows the period from start to end. Monthly and yearly periods are
+// counted in months from anchor, the start of the first period, so a budget
+// that starts on the 31st starts on the 31st again after a shorter month.
+func nextBudgetPeriod(budgetType string, anchor time.Time, start time.Time, end time.Time) (time.Time, time.Time) {
+	var step int
+	switch budgetType {
+	case BudgetTypeMonthly:
+		step = 1
+	case BudgetTypeYearly:
+		step = 12
+	default:
+		next := end.AddDate(0, 0, 1)
+		return next, BudgetPeriodEnd(budgetType, next, end)
+	}
+
+	months := (start.Year()-anchor.Year())*12 + int(start.Month()-anchor.Month()) + step
+	return addMonths(anchor, months), addMonths(anchor, months+step).AddDate(0, 0, -1)
+}
+
+// addMonths adds months to a date, keeping the day within the month, so a
+// period starting on the 31st does not run into the month after the next.
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
+// BudgetPeriodWithExpenses is a closed period of a periodic budget with the
+// amount spent on each expense within the period.
+type BudgetPeriodWithExpenses struct {
+	BudgetPeriod
+	Expenses []FullBudgetPeriodExpense
+}
+
+// GetPeriods returns the closed periods of a budget, oldest first.
+func (repository *BudgetRepository) GetPeriods(ctx context.Context, userId uuid.UUID, id string) (*[]BudgetPeriodWithExpenses, error) {
+	db := New(repository.db)
+	_, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: userId,
+		ID:     id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := db.GetBudgetPeriods(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := db.GetBudgetPeriodExpenses(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	periodMap := make(map[int32][]FullBudgetPeriodExpense, len(periods))
+	for _, expense := range expenses {
+		periodMap[expense.PeriodID] = append(periodMap[expense.PeriodID], expense)
+	}
+
+	periodsWithExpenses := make([]BudgetPeriodWithExpenses, len(periods))
+	for idx, period := range periods {
+		periodsWithExpenses[idx] = BudgetPeriodWithExpenses{
+			BudgetPeriod: period,
+			Expenses:     periodMap[period.ID],
+		}
+	}
+
+	return &periodsWithExpenses, nil
+}
+
+// ClosePeriods closes the periods of periodic budgets that ended before today
+// and starts their next period. The allocations of a closed period are kept,
+// so later changes to the budget do not rewrite its history, and each expense
+// carries its rollover into the next period. Each budget is closed in its own
+// database transaction.
+func (repository *BudgetRepository) ClosePeriods(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	db := New(repository.db)
+	budgets, err := db.GetBudgetsWithEndedPeriod(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	var closed int64
+	for _, budget := range budgets {
+		nperiods, err := repository.closePeriods(ctx, budget.ID, today)
+		if err != nil {
+			return closed, err
+		}
+		closed += nperiods
+	}
+
+	return closed, nil
+}
+
+// closePeriods closes the ended periods of a budget. The budget is locked and
+// read again, so a change to the budget since it was listed is not
+// overwritten with its old dates.
+func (repository *BudgetRepository) closePeriods(ctx context.Context, id string, today time.Time) (int64, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	budget, err := db.GetBudgetForUpdate(ctx, id)
+	if err != nil {
+		// The budget was deleted in the meantime
+		if NoRowsFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if budget.Type == BudgetTypeCustom || !budget.EndDate.Before(today) {
+		return 0, nil
+	}
+	before := budget
+
+	var closed int64
+	for budget.EndDate.Before(today) {
+		period, err := db.CreateBudgetPeriod(ctx, CreateBudgetPeriodParams{
+			BudgetID:  budget.ID,
+			StartDate: budget.StartDate,
+			EndDate:   budget.EndDate,
+			Amount:    budget.Amount,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		// The spending of the expenses is calculated over the dates of the
+		// budget, which are still those of the closing period
+		expenses, err := db.GetFullBudgetExpenses(ctx, budget.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		err = db.CreateBudgetPeriodExpenses(ctx, CreateBudgetPeriodExpensesParams{
+			PeriodID: period.ID,
+			BudgetID: budget.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, expense := range expenses {
+			err = db.SetBudgetExpenseRollover(ctx, SetBudgetExpenseRolloverParams{
+				ID:               expense.ID,
+				RolloverAmount:   strconv.FormatFloat(rolloverAmount(expense), 'f', 2, 64),
+				RolloverPeriodID: sql.NullInt32{Int32: period.ID, Valid: true},
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		start, end := nextBudgetPeriod(budget.Type, budget.AnchorDate, budget.StartDate, budget.EndDate)
+		budget, err = db.AdvanceBudgetPeriod(ctx, AdvanceBudgetPeriodParams{
+			ID:        budget.ID,
+			StartDate: start,
+			EndDate:   end,
+		})
+		if err != nil {
+			return 0, err
+		}
+		closed++
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     budget.UserID,
+		EntityType: AuditEntityBudget,
+		EntityID:   budget.ID,
+		Action:     AuditActionUpdate,
+		Before:     snapshotBudget(before),
+		After:      snapshotBudget(budget),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return closed, tx.Commit()
+}
+
+// rolloverAmount returns what an expense carries into the next period. The
+// amount available in a period is the allocation plus what was carried into
+// it, so unspent money and deficits keep accumulating over the periods.
+func rolloverAmount(expense FullBudgetExpense) float64 {
+	allocated, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+	carried, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+	spent, _ := strconv.ParseFloat(expense.CurrentAmount, 64)
+	left := allocated + carried - spent
+
+	switch expense.Rollover {
+	case BudgetRolloverUnspent:
+		return math.Max(left, 0)
+	case BudgetRolloverDeficit:
+		return math.Min(left, 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBudgetPeriodMonthlyEndOfMonth(t *testing.T) {
+	anchor := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+	start := anchor
+	end := BudgetPeriodEnd(BudgetTypeMonthly, anchor, anchor)
+
+	want := []struct{ start, end string }{
+		{"2025-01-31", "2025-02-27"},
+		{"2025-02-28", "2025-03-30"},
+		{"2025-03-31", "2025-04-29"},
+		{"2025-04-30", "2025-05-30"},
+		{"2025-05-31", "2025-06-29"},
+		{"2025-06-30", "2025-07-30"},
+	}
+	for idx, period := range want {
+		if start.Format(time.DateOnly) != period.start || end.Format(time.DateOnly) != period.end {
+			t.Fatalf("period %d: got %s to %s, want %s to %s", idx, start.Format(time.DateOnly), end.Format(time.DateOnly), period.start, period.end)
+		}
+		start, end = nextBudgetPeriod(BudgetTypeMonthly, anchor, start, end)
+	}
+}
+
+func TestNextBudgetPeriodYearlyLeapDay(t *testing.T) {
+	anchor := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+	start := anchor
+	end := BudgetPeriodEnd(BudgetTypeYearly, anchor, anchor)
+
+	for range 4 {
+		start, end = nextBudgetPeriod(BudgetTypeYearly, anchor, start, end)
+	}
+	if start.Format(time.DateOnly) != "2028-02-29" || end.Format(time.DateOnly) != "2029-02-27" {
+		t.Fatalf("got %s to %s, want 2028-02-29 to 2029-02-27", start.Format(time.DateOnly), end.Format(time.DateOnly))
+	}
+}
+
+func TestNextBudgetPeriodWeekly(t *testing.T) {
+	start := time.Date(2025, time.December, 29, 0, 0, 0, 0, time.UTC)
+	end := BudgetPeriodEnd(BudgetTypeWeekly, start, start)
+
+	start, end = nextBudgetPeriod(BudgetTypeWeekly, start, start, end)
+	if start.Format(time.DateOnly) != "2026-01-05" || end.Format(time.DateOnly) != "2026-01-11" {
+		t.Fatalf("got %s to %s, want 2026-01-05 to 2026-01-11", start.Format(time.DateOnly), end.Format(time.DateOnly))
+	}
+}
@@ -3,7 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,12 +14,37 @@ type IBudgetRepository interface {
 
 	Add(ctx context.Context, params CreateBudgetParams) (*Budget, error)
 	Update(ctx context.Context, params UpdateBudgetParams) error
-	Remove(ctx context.Context, userId uuid.UUID, id string) error
+	Remove(ctx context.Context, userId uuid.UUID, id string, version sql.NullInt32) error
+
+	GetDeleted(ctx context.Context, userId uuid.UUID) (*[]BudgetWithExpenses, error)
+	Restore(ctx context.Context, userId uuid.UUID, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 
 	GetExpenses(ctx context.Context, budgetId string) (*[]BudgetExpense, error)
 	AddExpense(ctx context.Context, params CreateBudgetExpenseParams) (*BudgetExpense, error)
 	UpdateExpense(ctx context.Context, params UpdateBudgetExpenseParams) error
-	RemoveExpense(ctx context.Context, id int32, budgetId string) error
+	RemoveExpense(ctx context.Context, id int32, budgetId string, version sql.NullInt32) error
+
+	GetPeriods(ctx context.Context, userId uuid.UUID, id string) (*[]BudgetPeriodWithExpenses, error)
+	ClosePeriods(ctx context.Context) (int64, error)
+
+	GetTemplates(ctx context.Context, userId uuid.UUID) (*[]BudgetTemplateWithExpenses, error)
+	AddTemplate(ctx context.Context, userId uuid.UUID, budgetId string, name string) (*BudgetTemplateWithExpenses, error)
+	RemoveTemplate(ctx context.Context, userId uuid.UUID, id int32) error
+	CreateFromTemplate(ctx context.Context, params CreateFromTemplateParams) (*Budget, error)
+	Clone(ctx context.Context, params CloneBudgetParams) (*Budget, error)
+
+	EvaluateAlerts(ctx context.Context, userId uuid.UUID) (*[]FiredBudgetAlert, error)
+
+	GetMatchedTransactions(ctx context.Context, userId uuid.UUID, id string) (*[]BudgetMatchedTransaction, error)
+	GetUnmatchedTransactions(ctx context.Context, userId uuid.UUID, id string) (*[]FullTransaction, error)
+	GetSpending(ctx context.Context, userId uuid.UUID, start time.Time, end time.Time) (*[]GetBudgetSpendingBetweenDatesRow, error)
+	ExcludeTransactions(ctx context.Context, userId uuid.UUID, id string, transactionIds []int32) (int64, error)
+	IncludeTransactions(ctx context.Context, userId uuid.UUID, id string, transactionIds []int32) (int64, error)
+
+	GetEnvelopes(ctx context.Context, userId uuid.UUID, id string) (*EnvelopeSummary, error)
+	GetEnvelopeHistory(ctx context.Context, userId uuid.UUID, id string) (*[]EnvelopeLedgerEntry, error)
+	MoveEnvelopeMoney(ctx context.Context, params MoveEnvelopeMoneyParams) (*BudgetEnvelopeMovement, error)
 }
 
 type BudgetRepository struct {
@@ -51,9 +76,9 @@ func (repository *BudgetRepository) Get(ctx context.Context, userId uuid.UUID) (
 		return nil, err
 	}
 
-	budgetMap := make(map[string][]BudgetExpense, len(budgets))
+	budgetMap := make(map[string][]FullBudgetExpense, len(budgets))
 	for _, budgetExpense := range budgetExpenses {
-		budgetMap[budgetExpense.BudgetExpense.BudgetID] = append(budgetMap[budgetExpense.BudgetExpense.BudgetID], budgetExpense.BudgetExpense)
+		budgetMap[budgetExpense.FullBudgetExpense.BudgetID] = append(budgetMap[budgetExpense.FullBudgetExpense.BudgetID], budgetExpense.FullBudgetExpense)
 	}
 
 	budgetsWithExpenses := make([]BudgetWithExpenses, len(budgets))
@@ -76,7 +101,7 @@ func (repository *BudgetRepository) GetById(ctx context.Context, userId uuid.UUI
 	if err != nil {
 		return nil, err
 	}
-	expenses, err := db.GetBudgetExpenses(ctx, id)
+	expenses, err := db.GetFullBudgetExpenses(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -90,13 +115,40 @@ func (repository *BudgetRepository) GetById(ctx context.Context, userId uuid.UUI
 }
 
 func (repository *BudgetRepository) Add(ctx context.Context, params CreateBudgetParams) (*Budget, error) {
-	db := New(repository.db)
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
 	budget, err := db.CreateBudget(ctx, params)
-	return &budget, err
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     budget.UserID,
+		EntityType: AuditEntityBudget,
+		EntityID:   budget.ID,
+		Action:     AuditActionCreate,
+		After:      snapshotBudget(budget),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, tx.Commit()
 }
 
 func (repository *BudgetRepository) Update(ctx context.Context, params UpdateBudgetParams) error {
-	db := New(repository.db)
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
 	budget, err := db.GetBudget(ctx, GetBudgetParams{
 		UserID: params.UserID,
 		ID:     params.ID,
@@ -105,13 +157,10 @@ func (repository *BudgetRepository) Update(ctx context.Context, params UpdateBud
 		return err
 	}
 
-	if budget.StartDate.UTC() != params.StartDate || budget.EndDate.UTC() != params.EndDate {
-		fmt.Println("RemoveTransactionBudgetIdOutsideDates")
-		fmt.Println(budget.ID)
-		fmt.Println(params.StartDate)
-		fmt.Println(params.EndDate)
-
-		err := db.RemoveTransactionBudgetIdOutsideDates(ctx, RemoveTransactionBudgetIdOutsideDatesParams{
+	// Transactions of earlier periods of a periodic budget stay assigned as its
+	// history
+	if params.Type == BudgetTypeCustom && (budget.StartDate.UTC() != params.StartDate || budget.EndDate.UTC() != params.EndDate) {
+		before, err := db.GetTransactionsOutsideBudgetDates(ctx, GetTransactionsOutsideBudgetDatesParams{
 			UserID:    params.UserID,
 			BudgetID:  budget.ID,
 			StartDate: params.StartDate,
@@ -120,27 +169,202 @@ func (repository *BudgetRepository) Update(ctx context.Context, params UpdateBud
 		if err != nil {
 			return err
 		}
+		after, err := db.RemoveTransactionBudgetIdOutsideDates(ctx, RemoveTransactionBudgetIdOutsideDatesParams{
+			UserID:    params.UserID,
+			BudgetID:  budget.ID,
+			StartDate: params.StartDate,
+			EndDate:   params.EndDate,
+		})
+		if err != nil {
+			return err
+		}
+		err = writeTransactionsAudit(ctx, db, before, after)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = db.UpdateBudget(ctx, params)
-	return err
+	updatedBudget, err := db.UpdateBudget(ctx, params)
+	if err != nil {
+		if NoRowsFound(err) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     budget.UserID,
+		EntityType: AuditEntityBudget,
+		EntityID:   budget.ID,
+		Action:     AuditActionUpdate,
+		Before:     snapshotBudget(budget),
+		After:      snapshotBudget(updatedBudget),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (repository *BudgetRepository) Remove(ctx context.Context, userId uuid.UUID, id string) error {
+// Remove moves the budget to the trash. Its transactions stay assigned so
+// restoring the budget restores the assignments as well.
+func (repository *BudgetRepository) Remove(ctx context.Context, userId uuid.UUID, id string, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	budget, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: userId,
+		ID:     id,
+	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := db.DeleteBudget(ctx, DeleteBudgetParams{
+		UserID:  userId,
+		ID:      id,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityBudget,
+		EntityID:   id,
+		Action:     AuditActionDelete,
+		Before:     snapshotBudget(budget),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *BudgetRepository) GetDeleted(ctx context.Context, userId uuid.UUID) (*[]BudgetWithExpenses, error) {
 	db := New(repository.db)
+	budgets, err := db.GetDeletedBudgets(ctx, GetDeletedBudgetsParams{
+		UserID: userId,
+		Limit:  MaxFetchLimit,
+		Offset: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	budgetExpenses, err := db.GetDeletedBudgetsExpenses(ctx, GetDeletedBudgetsExpensesParams{
+		UserID: userId,
+		Limit:  MaxFetchLimit,
+		Offset: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	budgetMap := make(map[string][]FullBudgetExpense, len(budgets))
+	for _, budgetExpense := range budgetExpenses {
+		budgetMap[budgetExpense.FullBudgetExpense.BudgetID] = append(budgetMap[budgetExpense.FullBudgetExpense.BudgetID], budgetExpense.FullBudgetExpense)
+	}
+
+	budgetsWithExpenses := make([]BudgetWithExpenses, len(budgets))
+	for idx, budget := range budgets {
+		budgetsWithExpenses[idx] = BudgetWithExpenses{
+			Budget:   budget,
+			Expenses: budgetMap[budget.ID],
+		}
+	}
 
-	err := db.RemoveTransactionBudgetIdForBudget(ctx, RemoveTransactionBudgetIdForBudgetParams{
-		UserID:   userId,
-		BudgetID: id,
+	return &budgetsWithExpenses, nil
+}
+
+func (repository *BudgetRepository) Restore(ctx context.Context, userId uuid.UUID, id string) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	nrows, err := db.RestoreBudget(ctx, RestoreBudgetParams{
+		UserID: userId,
+		ID:     id,
 	})
 	if err != nil {
 		return err
 	}
+	if nrows == 0 {
+		return sql.ErrNoRows
+	}
 
-	return db.DeleteBudget(ctx, DeleteBudgetParams{
+	budget, err := db.GetBudget(ctx, GetBudgetParams{
 		UserID: userId,
 		ID:     id,
 	})
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityBudget,
+		EntityID:   id,
+		Action:     AuditActionRestore,
+		After:      snapshotBudget(budget),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *BudgetRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	unassigned, err := db.GetTransactionsForPurgedBudgets(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	transactions, err := db.RemoveTransactionBudgetIdForPurgedBudgets(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	err = writeTransactionsAudit(ctx, db, unassigned, transactions)
+	if err != nil {
+		return 0, err
+	}
+	budgets, err := db.PurgeDeletedBudgets(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	for _, budget := range budgets {
+		err = writeAudit(ctx, db, auditEntry{
+			UserID:     budget.UserID,
+			EntityType: AuditEntityBudget,
+			EntityID:   budget.ID,
+			Action:     AuditActionPurge,
+			Before:     snapshotBudget(budget),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(budgets)), tx.Commit()
 }
 
 func (repository *BudgetRepository) GetExpenses(ctx context.Context, budgetId string) (*[]BudgetExpense, error) {
@@ -154,26 +378,116 @@ func (repository *BudgetRepository) GetExpenses(ctx context.Context, budgetId st
 }
 
 func (repository *BudgetRepository) AddExpense(ctx context.Context, params CreateBudgetExpenseParams) (*BudgetExpense, error) {
-	db := New(repository.db)
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
 	budgetExpense, err := db.CreateBudgetExpense(ctx, params)
-	return &budgetExpense, err
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeBudgetExpenseAudit(ctx, db, budgetExpense.BudgetID, budgetExpense.ID, AuditActionCreate, nil, &budgetExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budgetExpense, tx.Commit()
 }
 
 func (repository *BudgetRepository) UpdateExpense(ctx context.Context, params UpdateBudgetExpenseParams) error {
-	db := New(repository.db)
-	_, err := db.UpdateBudgetExpense(ctx, params)
-	return err
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	before, err := db.GetBudgetExpenseById(ctx, params.ID)
+	if err != nil {
+		return err
+	}
+
+	budgetExpense, err := db.UpdateBudgetExpense(ctx, params)
+	if err != nil {
+		if NoRowsFound(err) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+
+	err = writeBudgetExpenseAudit(ctx, db, budgetExpense.BudgetID, budgetExpense.ID, AuditActionUpdate, &before, &budgetExpense)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (repository *BudgetRepository) RemoveExpense(ctx context.Context, id int32, budgetId string) error {
-	db := New(repository.db)
-	return db.DeleteBudgetExpense(ctx, DeleteBudgetExpenseParams{
+func (repository *BudgetRepository) RemoveExpense(ctx context.Context, id int32, budgetId string, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	budgetExpense, err := db.GetBudgetExpense(ctx, GetBudgetExpenseParams{
 		ID:       id,
 		BudgetID: budgetId,
 	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := db.DeleteBudgetExpense(ctx, DeleteBudgetExpenseParams{
+		ID:       id,
+		BudgetID: budgetId,
+		Version:  version,
+	})
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	err = writeBudgetExpenseAudit(ctx, db, budgetId, id, AuditActionDelete, &budgetExpense, nil)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func writeBudgetExpenseAudit(ctx context.Context, db *Queries, budgetId string, id int32, action string, before *BudgetExpense, after *BudgetExpense) error {
+	userId, err := db.GetBudgetUserId(ctx, budgetId)
+	if err != nil {
+		return err
+	}
+
+	entry := auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityBudgetExpense,
+		EntityID:   int32ToString(id),
+		Action:     action,
+	}
+	if before != nil {
+		entry.Before = snapshotBudgetExpense(*before)
+	}
+	if after != nil {
+		entry.After = snapshotBudgetExpense(*after)
+	}
+
+	return writeAudit(ctx, db, entry)
 }
 
+// BudgetWithExpenses has the expenses of a budget with the amount spent on
+// them within the dates of the budget.
 type BudgetWithExpenses struct {
 	Budget
-	Expenses []BudgetExpense
+	Expenses []FullBudgetExpense
 }
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidBudgetDates = errors.New("budget ends before it starts")
+
+// BudgetTemplateWithExpenses is the structure of a budget without its dates,
+// to create new budgets from.
+type BudgetTemplateWithExpenses struct {
+	BudgetTemplate
+	Expenses []BudgetTemplateExpense
+}
+
+type CreateFromTemplateParams struct {
+	UserID     uuid.UUID
+	TemplateID int32
+	BudgetID   string
+	Name       string
+	StartDate  time.Time
+	EndDate    time.Time
+	Percentage float64
+}
+
+// CloneBudgetParams copies a budget. Without a start date the copy gets the
+// dates of the budget, and without an end date a custom budget keeps its
+// length.
+type CloneBudgetParams struct {
+	UserID     uuid.UUID
+	SourceID   string
+	BudgetID   string
+	Name       string
+	StartDate  time.Time
+	EndDate    time.Time
+	Percentage float64
+}
+
+func (repository *BudgetRepository) GetTemplates(ctx context.Context, userId uuid.UUID) (*[]BudgetTemplateWithExpenses, error) {
+	db := New(repository.db)
+	templates, err := db.GetBudgetTemplates(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := db.GetBudgetTemplatesExpenses(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	templateMap := make(map[int32][]BudgetTemplateExpense, len(templates))
+	for _, expense := range expenses {
+		templateMap[expense.TemplateID] = append(templateMap[expense.TemplateID], expense)
+	}
+
+	templatesWithExpenses := make([]BudgetTemplateWithExpenses, len(templates))
+	for idx, template := range templates {
+		templatesWithExpenses[idx] = BudgetTemplateWithExpenses{
+			BudgetTemplate: template,
+			Expenses:       templateMap[template.ID],
+		}
+	}
+
+	return &templatesWithExpenses, nil
+}
+
+// AddTemplate saves the structure of a budget as a template. Without a name
+// the template is named after the budget.
+func (repository *BudgetRepository) AddTemplate(ctx context.Context, userId uuid.UUID, budgetId string, name string) (*BudgetTemplateWithExpenses, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	budget, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: userId,
+		ID:     budgetId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = budget.Name
+	}
+
+	template, err := db.CreateBudgetTemplate(ctx, CreateBudgetTemplateParams{
+		UserID:      userId,
+		Name:        name,
+		Description: budget.Description,
+		Amount:      budget.Amount,
+		Type:        budget.Type,
+		ZeroBased:   budget.ZeroBased,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.CreateBudgetTemplateExpenses(ctx, CreateBudgetTemplateExpensesParams{
+		TemplateID: template.ID,
+		BudgetID:   budget.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := db.GetBudgetTemplateExpenses(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BudgetTemplateWithExpenses{
+		BudgetTemplate: template,
+		Expenses:       expenses,
+	}, tx.Commit()
+}
+
+func (repository *BudgetRepository) RemoveTemplate(ctx context.Context, userId uuid.UUID, id int32) error {
+	db := New(repository.db)
+	rows, err := db.DeleteBudgetTemplate(ctx, DeleteBudgetTemplateParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CreateFromTemplate creates a budget with the structure of a template for new
+// dates, with the amounts scaled by the percentage. A periodic budget only
+// needs the start of its first period.
+func (repository *BudgetRepository) CreateFromTemplate(ctx context.Context, params CreateFromTemplateParams) (*Budget, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	template, err := db.GetBudgetTemplate(ctx, GetBudgetTemplateParams{
+		ID:     params.TemplateID,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	templateExpenses, err := db.GetBudgetTemplateExpenses(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := params.Name
+	if name == "" {
+		name = template.Name
+	}
+
+	expenses := make([]CreateBudgetExpenseParams, len(templateExpenses))
+	for idx, expense := range templateExpenses {
+		expenses[idx] = CreateBudgetExpenseParams{
+			Name:            expense.Name,
+			AllocatedAmount: scaleAmount(expense.AllocatedAmount, params.Percentage),
+			Rollover:        expense.Rollover,
+			Categories:      expense.Categories,
+			Tags:            expense.Tags,
+			Payees:          expense.Payees,
+			Thresholds:      expense.Thresholds,
+		}
+	}
+
+	budget, err := createBudgetWithExpenses(ctx, db, CreateBudgetParams{
+		ID:          params.BudgetID,
+		UserID:      params.UserID,
+		Name:        name,
+		Description: template.Description,
+		Amount:      scaleAmount(template.Amount, params.Percentage),
+		StartDate:   params.StartDate,
+		EndDate:     BudgetPeriodEnd(template.Type, params.StartDate, params.EndDate),
+		Type:        template.Type,
+		ZeroBased:   template.ZeroBased,
+	}, expenses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, tx.Commit()
+}
+
+// Clone copies a budget and its expenses, with the amounts scaled by the
+// percentage. Transactions, rollovers and envelope movements are not copied.
+func (repository *BudgetRepository) Clone(ctx context.Context, params CloneBudgetParams) (*Budget, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	source, err := db.GetBudget(ctx, GetBudgetParams{
+		UserID: params.UserID,
+		ID:     params.SourceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sourceExpenses, err := db.GetBudgetExpenses(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := params.Name
+	if name == "" {
+		name = source.Name
+	}
+	start, end := source.StartDate, source.EndDate
+	if !params.StartDate.IsZero() {
+		start = params.StartDate
+		end = params.EndDate
+		if end.IsZero() {
+			end = start.Add(source.EndDate.Sub(source.StartDate))
+		}
+	}
+
+	expenses := make([]CreateBudgetExpenseParams, len(sourceExpenses))
+	for idx, expense := range sourceExpenses {
+		expenses[idx] = CreateBudgetExpenseParams{
+			Name:            expense.Name,
+			AllocatedAmount: scaleAmount(expense.AllocatedAmount, params.Percentage),
+			Rollover:        expense.Rollover,
+			Categories:      expense.Categories,
+			Tags:            expense.Tags,
+			Payees:          expense.Payees,
+			Thresholds:      expense.Thresholds,
+		}
+	}
+
+	budget, err := createBudgetWithExpenses(ctx, db, CreateBudgetParams{
+		ID:          params.BudgetID,
+		UserID:      params.UserID,
+		Name:        name,
+		Description: source.Description,
+		Amount:      scaleAmount(source.Amount, params.Percentage),
+		StartDate:   start,
+		EndDate:     BudgetPeriodEnd(source.Type, start, end),
+		Type:        source.Type,
+		ZeroBased:   source.ZeroBased,
+	}, expenses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, tx.Commit()
+}
+
+// createBudgetWithExpenses creates a budget with its expenses using the
+// queries of a database transaction.
+func createBudgetWithExpenses(ctx context.Context, db *Queries, params CreateBudgetParams, expenses []CreateBudgetExpenseParams) (Budget, error) {
+	if params.EndDate.Before(params.StartDate) {
+		return Budget{}, ErrInvalidBudgetDates
+	}
+
+	budget, err := db.CreateBudget(ctx, params)
+	if err != nil {
+		return Budget{}, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     budget.UserID,
+		EntityType: AuditEntityBudget,
+		EntityID:   budget.ID,
+		Action:     AuditActionCreate,
+		After:      snapshotBudget(budget),
+	})
+	if err != nil {
+		return Budget{}, err
+	}
+
+	for _, expense := range expenses {
+		expense.BudgetID = budget.ID
+		budgetExpense, err := db.CreateBudgetExpense(ctx, expense)
+		if err != nil {
+			return Budget{}, err
+		}
+
+		err = writeBudgetExpenseAudit(ctx, db, budget.ID, budgetExpense.ID, AuditActionCreate, nil, &budgetExpense)
+		if err != nil {
+			return Budget{}, err
+		}
+	}
+
+	return budget, nil
+}
+
+// scaleAmount scales an amount by a percentage, rounded to cents.
+func scaleAmount(amount string, percentage float64) string {
+	value, _ := strconv.ParseFloat(amount, 64)
+	return strconv.FormatFloat(math.Round(value*percentage)/100, 'f', 2, 64)
+}
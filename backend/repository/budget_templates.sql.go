@@ -0,0 +1,230 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: budget_templates.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createBudgetTemplate = `-- name: CreateBudgetTemplate :one
+INSERT INTO budget_templates (user_id, name, description, amount, type, zero_based)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, name, description, amount, type, zero_based, created
+`
+
+type CreateBudgetTemplateParams struct {
+	UserID      uuid.UUID
+	Name        string
+	Description string
+	Amount      string
+	Type        string
+	ZeroBased   bool
+}
+
+func (q *Queries) CreateBudgetTemplate(ctx context.Context, arg CreateBudgetTemplateParams) (BudgetTemplate, error) {
+	row := q.db.QueryRowContext(ctx, createBudgetTemplate,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Amount,
+		arg.Type,
+		arg.ZeroBased,
+	)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.ZeroBased,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createBudgetTemplateExpenses = `-- name: CreateBudgetTemplateExpenses :exec
+INSERT INTO budget_template_expenses (template_id, name, allocated_amount, rollover, categories, tags, payees, thresholds)
+SELECT $1::int, be.name, be.allocated_amount, be.rollover, be.categories, be.tags, be.payees, be.thresholds
+FROM budget_expenses be
+WHERE be.budget_id = $2::text
+ORDER BY be.id
+`
+
+type CreateBudgetTemplateExpensesParams struct {
+	TemplateID int32
+	BudgetID   string
+}
+
+func (q *Queries) CreateBudgetTemplateExpenses(ctx context.Context, arg CreateBudgetTemplateExpensesParams) error {
+	_, err := q.db.ExecContext(ctx, createBudgetTemplateExpenses, arg.TemplateID, arg.BudgetID)
+	return err
+}
+
+const deleteBudgetTemplate = `-- name: DeleteBudgetTemplate :execrows
+DELETE FROM budget_templates
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteBudgetTemplateParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteBudgetTemplate(ctx context.Context, arg DeleteBudgetTemplateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBudgetTemplate, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBudgetTemplate = `-- name: GetBudgetTemplate :one
+SELECT id, user_id, name, description, amount, type, zero_based, created FROM budget_templates
+WHERE id = $1 AND user_id = $2
+`
+
+type GetBudgetTemplateParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetBudgetTemplate(ctx context.Context, arg GetBudgetTemplateParams) (BudgetTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetTemplate, arg.ID, arg.UserID)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.ZeroBased,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getBudgetTemplateExpenses = `-- name: GetBudgetTemplateExpenses :many
+SELECT id, template_id, name, allocated_amount, rollover, categories, tags, payees, thresholds FROM budget_template_expenses
+WHERE template_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetBudgetTemplateExpenses(ctx context.Context, templateID int32) ([]BudgetTemplateExpense, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetTemplateExpenses, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetTemplateExpense
+	for rows.Next() {
+		var i BudgetTemplateExpense
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.Name,
+			&i.AllocatedAmount,
+			&i.Rollover,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Tags),
+			pq.Array(&i.Payees),
+			pq.Array(&i.Thresholds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetTemplates = `-- name: GetBudgetTemplates :many
+SELECT id, user_id, name, description, amount, type, zero_based, created FROM budget_templates
+WHERE user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) GetBudgetTemplates(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetTemplates, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetTemplate
+	for rows.Next() {
+		var i BudgetTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.ZeroBased,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetTemplatesExpenses = `-- name: GetBudgetTemplatesExpenses :many
+SELECT te.id, te.template_id, te.name, te.allocated_amount, te.rollover, te.categories, te.tags, te.payees, te.thresholds FROM budget_template_expenses te
+JOIN budget_templates t ON te.template_id = t.id
+WHERE t.user_id = $1
+ORDER BY te.id
+`
+
+func (q *Queries) GetBudgetTemplatesExpenses(ctx context.Context, userID uuid.UUID) ([]BudgetTemplateExpense, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetTemplatesExpenses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetTemplateExpense
+	for rows.Next() {
+		var i BudgetTemplateExpense
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.Name,
+			&i.AllocatedAmount,
+			&i.Rollover,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Tags),
+			pq.Array(&i.Payees),
+			pq.Array(&i.Thresholds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,15 +7,52 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const advanceBudgetPeriod = `-- name: AdvanceBudgetPeriod :one
+UPDATE budgets
+SET start_date = $2, end_date = $3, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1
+RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date
+`
+
+type AdvanceBudgetPeriodParams struct {
+	ID        string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) AdvanceBudgetPeriod(ctx context.Context, arg AdvanceBudgetPeriodParams) (Budget, error) {
+	row := q.db.QueryRowContext(ctx, advanceBudgetPeriod, arg.ID, arg.StartDate, arg.EndDate)
+	var i Budget
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Amount,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+		&i.Type,
+		&i.ZeroBased,
+		&i.AnchorDate,
+	)
+	return i, err
+}
+
 const createBudget = `-- name: CreateBudget :one
-INSERT INTO budgets (id, user_id, name, description, amount, start_date, end_date)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated
+INSERT INTO budgets (id, user_id, name, description, amount, start_date, end_date, type, zero_based, anchor_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $6)
+RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date
 `
 
 type CreateBudgetParams struct {
@@ -26,6 +63,8 @@ type CreateBudgetParams struct {
 	Amount      string
 	StartDate   time.Time
 	EndDate     time.Time
+	Type        string
+	ZeroBased   bool
 }
 
 func (q *Queries) CreateBudget(ctx context.Context, arg CreateBudgetParams) (Budget, error) {
@@ -37,6 +76,8 @@ func (q *Queries) CreateBudget(ctx context.Context, arg CreateBudgetParams) (Bud
 		arg.Amount,
 		arg.StartDate,
 		arg.EndDate,
+		arg.Type,
+		arg.ZeroBased,
 	)
 	var i Budget
 	err := row.Scan(
@@ -49,70 +90,176 @@ func (q *Queries) CreateBudget(ctx context.Context, arg CreateBudgetParams) (Bud
 		&i.EndDate,
 		&i.Created,
 		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+		&i.Type,
+		&i.ZeroBased,
+		&i.AnchorDate,
 	)
 	return i, err
 }
 
 const createBudgetExpense = `-- name: CreateBudgetExpense :one
-INSERT INTO budget_expenses (budget_id, name, allocated_amount)
-VALUES ($1, $2, $3)
-RETURNING id, budget_id, name, allocated_amount, current_amount, created, updated
+INSERT INTO budget_expenses (budget_id, name, allocated_amount, rollover, categories, tags, payees, thresholds)
+VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'), COALESCE($6::text[], '{}'), COALESCE($7::text[], '{}'),
+    COALESCE($8::int[], '{}'))
+RETURNING id, budget_id, name, allocated_amount, created, updated, version, rollover, rollover_amount, rollover_period_id, categories, tags, payees, thresholds
 `
 
 type CreateBudgetExpenseParams struct {
 	BudgetID        string
 	Name            string
 	AllocatedAmount string
+	Rollover        string
+	Categories      []string
+	Tags            []string
+	Payees          []string
+	Thresholds      []int32
 }
 
 func (q *Queries) CreateBudgetExpense(ctx context.Context, arg CreateBudgetExpenseParams) (BudgetExpense, error) {
-	row := q.db.QueryRowContext(ctx, createBudgetExpense, arg.BudgetID, arg.Name, arg.AllocatedAmount)
+	row := q.db.QueryRowContext(ctx, createBudgetExpense,
+		arg.BudgetID,
+		arg.Name,
+		arg.AllocatedAmount,
+		arg.Rollover,
+		pq.Array(arg.Categories),
+		pq.Array(arg.Tags),
+		pq.Array(arg.Payees),
+		pq.Array(arg.Thresholds),
+	)
 	var i BudgetExpense
 	err := row.Scan(
 		&i.ID,
 		&i.BudgetID,
 		&i.Name,
 		&i.AllocatedAmount,
-		&i.CurrentAmount,
 		&i.Created,
 		&i.Updated,
+		&i.Version,
+		&i.Rollover,
+		&i.RolloverAmount,
+		&i.RolloverPeriodID,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Tags),
+		pq.Array(&i.Payees),
+		pq.Array(&i.Thresholds),
 	)
 	return i, err
 }
 
-const deleteBudget = `-- name: DeleteBudget :exec
-DELETE FROM budgets 
-WHERE id = $1 AND user_id = $2
+const createBudgetPeriod = `-- name: CreateBudgetPeriod :one
+INSERT INTO budget_periods (budget_id, start_date, end_date, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING id, budget_id, start_date, end_date, amount, created
 `
 
-type DeleteBudgetParams struct {
-	ID     string
-	UserID uuid.UUID
+type CreateBudgetPeriodParams struct {
+	BudgetID  string
+	StartDate time.Time
+	EndDate   time.Time
+	Amount    string
+}
+
+func (q *Queries) CreateBudgetPeriod(ctx context.Context, arg CreateBudgetPeriodParams) (BudgetPeriod, error) {
+	row := q.db.QueryRowContext(ctx, createBudgetPeriod,
+		arg.BudgetID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Amount,
+	)
+	var i BudgetPeriod
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Amount,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createBudgetPeriodExpenses = `-- name: CreateBudgetPeriodExpenses :exec
+INSERT INTO budget_period_expenses (period_id, budget_expense_id, name, allocated_amount, rollover, rollover_amount, rollover_period_id)
+SELECT $1::int, be.id, be.name, be.allocated_amount, be.rollover, be.rollover_amount, be.rollover_period_id
+FROM budget_expenses be
+WHERE be.budget_id = $2::text
+`
+
+type CreateBudgetPeriodExpensesParams struct {
+	PeriodID int32
+	BudgetID string
 }
 
-func (q *Queries) DeleteBudget(ctx context.Context, arg DeleteBudgetParams) error {
-	_, err := q.db.ExecContext(ctx, deleteBudget, arg.ID, arg.UserID)
+func (q *Queries) CreateBudgetPeriodExpenses(ctx context.Context, arg CreateBudgetPeriodExpensesParams) error {
+	_, err := q.db.ExecContext(ctx, createBudgetPeriodExpenses, arg.PeriodID, arg.BudgetID)
 	return err
 }
 
-const deleteBudgetExpense = `-- name: DeleteBudgetExpense :exec
+const deleteBudget = `-- name: DeleteBudget :execrows
+UPDATE budgets
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($3::int IS NULL OR version = $3::int)
+`
+
+type DeleteBudgetParams struct {
+	ID      string
+	UserID  uuid.UUID
+	Version sql.NullInt32
+}
+
+func (q *Queries) DeleteBudget(ctx context.Context, arg DeleteBudgetParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBudget, arg.ID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBudgetExpense = `-- name: DeleteBudgetExpense :execrows
 DELETE FROM budget_expenses
-WHERE id = $1 AND budget_id = $2
+WHERE id = $1 AND budget_id = $2 AND ($3::int IS NULL OR version = $3::int)
 `
 
 type DeleteBudgetExpenseParams struct {
 	ID       int32
 	BudgetID string
+	Version  sql.NullInt32
 }
 
-func (q *Queries) DeleteBudgetExpense(ctx context.Context, arg DeleteBudgetExpenseParams) error {
-	_, err := q.db.ExecContext(ctx, deleteBudgetExpense, arg.ID, arg.BudgetID)
-	return err
+func (q *Queries) DeleteBudgetExpense(ctx context.Context, arg DeleteBudgetExpenseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBudgetExpense, arg.ID, arg.BudgetID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const excludeBudgetTransactions = `-- name: ExcludeBudgetTransactions :execrows
+INSERT INTO budget_excluded_transactions (budget_id, transaction_id)
+SELECT $2::text, t.id FROM transactions t
+WHERE t.user_id = $1 AND t.id = ANY($3::int[])
+ON CONFLICT DO NOTHING
+`
+
+type ExcludeBudgetTransactionsParams struct {
+	UserID   uuid.UUID
+	BudgetID string
+	Ids      []int32
+}
+
+func (q *Queries) ExcludeBudgetTransactions(ctx context.Context, arg ExcludeBudgetTransactionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, excludeBudgetTransactions, arg.UserID, arg.BudgetID, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getBudget = `-- name: GetBudget :one
-SELECT id, user_id, name, description, amount, start_date, end_date, created, updated FROM budgets
-WHERE id = $1 AND user_id = $2
+SELECT id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date FROM budgets
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
 `
 
 type GetBudgetParams struct {
@@ -133,12 +280,76 @@ func (q *Queries) GetBudget(ctx context.Context, arg GetBudgetParams) (Budget, e
 		&i.EndDate,
 		&i.Created,
 		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+		&i.Type,
+		&i.ZeroBased,
+		&i.AnchorDate,
+	)
+	return i, err
+}
+
+const getBudgetExpense = `-- name: GetBudgetExpense :one
+SELECT id, budget_id, name, allocated_amount, created, updated, version, rollover, rollover_amount, rollover_period_id, categories, tags, payees, thresholds FROM budget_expenses
+WHERE id = $1 AND budget_id = $2
+`
+
+type GetBudgetExpenseParams struct {
+	ID       int32
+	BudgetID string
+}
+
+func (q *Queries) GetBudgetExpense(ctx context.Context, arg GetBudgetExpenseParams) (BudgetExpense, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetExpense, arg.ID, arg.BudgetID)
+	var i BudgetExpense
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.AllocatedAmount,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+		&i.Rollover,
+		&i.RolloverAmount,
+		&i.RolloverPeriodID,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Tags),
+		pq.Array(&i.Payees),
+		pq.Array(&i.Thresholds),
+	)
+	return i, err
+}
+
+const getBudgetExpenseById = `-- name: GetBudgetExpenseById :one
+SELECT id, budget_id, name, allocated_amount, created, updated, version, rollover, rollover_amount, rollover_period_id, categories, tags, payees, thresholds FROM budget_expenses
+WHERE id = $1
+`
+
+func (q *Queries) GetBudgetExpenseById(ctx context.Context, id int32) (BudgetExpense, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetExpenseById, id)
+	var i BudgetExpense
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.AllocatedAmount,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+		&i.Rollover,
+		&i.RolloverAmount,
+		&i.RolloverPeriodID,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Tags),
+		pq.Array(&i.Payees),
+		pq.Array(&i.Thresholds),
 	)
 	return i, err
 }
 
 const getBudgetExpenses = `-- name: GetBudgetExpenses :many
-SELECT id, budget_id, name, allocated_amount, current_amount, created, updated FROM budget_expenses
+SELECT id, budget_id, name, allocated_amount, created, updated, version, rollover, rollover_amount, rollover_period_id, categories, tags, payees, thresholds FROM budget_expenses
 WHERE budget_id = $1
 `
 
@@ -156,9 +367,16 @@ func (q *Queries) GetBudgetExpenses(ctx context.Context, budgetID string) ([]Bud
 			&i.BudgetID,
 			&i.Name,
 			&i.AllocatedAmount,
-			&i.CurrentAmount,
 			&i.Created,
 			&i.Updated,
+			&i.Version,
+			&i.Rollover,
+			&i.RolloverAmount,
+			&i.RolloverPeriodID,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Tags),
+			pq.Array(&i.Payees),
+			pq.Array(&i.Thresholds),
 		); err != nil {
 			return nil, err
 		}
@@ -173,39 +391,168 @@ func (q *Queries) GetBudgetExpenses(ctx context.Context, budgetID string) ([]Bud
 	return items, nil
 }
 
-const getBudgets = `-- name: GetBudgets :many
-SELECT id, user_id, name, description, amount, start_date, end_date, created, updated FROM budgets
-WHERE user_id = $1
-ORDER BY created DESC
-LIMIT $2
-OFFSET $3
+const getBudgetForUpdate = `-- name: GetBudgetForUpdate :one
+SELECT id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date FROM budgets
+WHERE id = $1 AND deleted IS NULL
+FOR UPDATE
 `
 
-type GetBudgetsParams struct {
+func (q *Queries) GetBudgetForUpdate(ctx context.Context, id string) (Budget, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetForUpdate, id)
+	var i Budget
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Amount,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+		&i.Type,
+		&i.ZeroBased,
+		&i.AnchorDate,
+	)
+	return i, err
+}
+
+const getBudgetMatchedTransactions = `-- name: GetBudgetMatchedTransactions :many
+SELECT t.id, t.user_id, t.budget_id, t.budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted, t.version, t.start_date, t.end_date, t.interval, t.days_interval, t.rrule, t.recurring_created, t.recurring_updated, t.recurring_deleted, t.budget_name, t.budget_expense_name, t.tags, be.id as matched_expense_id, be.name as matched_expense_name FROM budgets b
+JOIN LATERAL budget_expense_transactions(b.id) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON bet.matched
+JOIN full_transaction t ON bet.transaction_id = t.id
+JOIN budget_expenses be ON bet.budget_expense_id = be.id
+WHERE b.id = $1 AND b.user_id = $2 AND t.deleted IS NULL AND t.date >= b.start_date AND t.date <= b.end_date
+ORDER BY t.date
+`
+
+type GetBudgetMatchedTransactionsParams struct {
+	ID     string
 	UserID uuid.UUID
-	Limit  int32
-	Offset int32
 }
 
-func (q *Queries) GetBudgets(ctx context.Context, arg GetBudgetsParams) ([]Budget, error) {
-	rows, err := q.db.QueryContext(ctx, getBudgets, arg.UserID, arg.Limit, arg.Offset)
+type GetBudgetMatchedTransactionsRow struct {
+	FullTransaction    FullTransaction
+	MatchedExpenseID   int32
+	MatchedExpenseName string
+}
+
+func (q *Queries) GetBudgetMatchedTransactions(ctx context.Context, arg GetBudgetMatchedTransactionsParams) ([]GetBudgetMatchedTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetMatchedTransactions, arg.ID, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Budget
+	var items []GetBudgetMatchedTransactionsRow
 	for rows.Next() {
-		var i Budget
+		var i GetBudgetMatchedTransactionsRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+			&i.MatchedExpenseID,
+			&i.MatchedExpenseName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetPeriodExpenses = `-- name: GetBudgetPeriodExpenses :many
+SELECT pe.id, pe.period_id, pe.budget_expense_id, pe.name, pe.allocated_amount, pe.spent_amount, pe.rollover, pe.rollover_amount, pe.rollover_period_id, pe.rollover_start_date, pe.rollover_end_date FROM full_budget_period_expense pe
+JOIN budget_periods p ON pe.period_id = p.id
+WHERE p.budget_id = $1
+ORDER BY pe.period_id, pe.id
+`
+
+func (q *Queries) GetBudgetPeriodExpenses(ctx context.Context, budgetID string) ([]FullBudgetPeriodExpense, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetPeriodExpenses, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FullBudgetPeriodExpense
+	for rows.Next() {
+		var i FullBudgetPeriodExpense
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
+			&i.PeriodID,
+			&i.BudgetExpenseID,
 			&i.Name,
-			&i.Description,
-			&i.Amount,
+			&i.AllocatedAmount,
+			&i.SpentAmount,
+			&i.Rollover,
+			&i.RolloverAmount,
+			&i.RolloverPeriodID,
+			&i.RolloverStartDate,
+			&i.RolloverEndDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetPeriods = `-- name: GetBudgetPeriods :many
+SELECT id, budget_id, start_date, end_date, amount, created FROM budget_periods
+WHERE budget_id = $1
+ORDER BY start_date
+`
+
+func (q *Queries) GetBudgetPeriods(ctx context.Context, budgetID string) ([]BudgetPeriod, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetPeriods, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetPeriod
+	for rows.Next() {
+		var i BudgetPeriod
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
 			&i.StartDate,
 			&i.EndDate,
+			&i.Amount,
 			&i.Created,
-			&i.Updated,
 		); err != nil {
 			return nil, err
 		}
@@ -220,40 +567,42 @@ func (q *Queries) GetBudgets(ctx context.Context, arg GetBudgetsParams) ([]Budge
 	return items, nil
 }
 
-const getBudgetsExpenses = `-- name: GetBudgetsExpenses :many
-SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.current_amount, be.created, be.updated FROM budgets b JOIN budget_expenses be ON b.id = be.budget_id
-WHERE b.user_id = $1
-LIMIT $2
-OFFSET $3
+const getBudgetSpendingBetweenDates = `-- name: GetBudgetSpendingBetweenDates :many
+SELECT b.id as budget_id, bet.budget_expense_id::int as budget_expense_id, t.date, (-t.amount)::DECIMAL(19, 2) as amount FROM budgets b
+JOIN LATERAL budget_expense_transactions(b.id) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON true
+JOIN transactions t ON bet.transaction_id = t.id
+WHERE b.user_id = $1 AND b.deleted IS NULL AND t.deleted IS NULL
+    AND t.date >= $2::timestamp AND t.date <= $3::timestamp
+ORDER BY t.date
 `
 
-type GetBudgetsExpensesParams struct {
-	UserID uuid.UUID
-	Limit  int32
-	Offset int32
+type GetBudgetSpendingBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
 }
 
-type GetBudgetsExpensesRow struct {
-	BudgetExpense BudgetExpense
+type GetBudgetSpendingBetweenDatesRow struct {
+	BudgetID        string
+	BudgetExpenseID int32
+	Date            time.Time
+	Amount          string
 }
 
-func (q *Queries) GetBudgetsExpenses(ctx context.Context, arg GetBudgetsExpensesParams) ([]GetBudgetsExpensesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getBudgetsExpenses, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) GetBudgetSpendingBetweenDates(ctx context.Context, arg GetBudgetSpendingBetweenDatesParams) ([]GetBudgetSpendingBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetSpendingBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetBudgetsExpensesRow
+	var items []GetBudgetSpendingBetweenDatesRow
 	for rows.Next() {
-		var i GetBudgetsExpensesRow
+		var i GetBudgetSpendingBetweenDatesRow
 		if err := rows.Scan(
-			&i.BudgetExpense.ID,
-			&i.BudgetExpense.BudgetID,
-			&i.BudgetExpense.Name,
-			&i.BudgetExpense.AllocatedAmount,
-			&i.BudgetExpense.CurrentAmount,
-			&i.BudgetExpense.Created,
-			&i.BudgetExpense.Updated,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.Date,
+			&i.Amount,
 		); err != nil {
 			return nil, err
 		}
@@ -268,72 +617,684 @@ func (q *Queries) GetBudgetsExpenses(ctx context.Context, arg GetBudgetsExpenses
 	return items, nil
 }
 
-const updateBudget = `-- name: UpdateBudget :one
-UPDATE budgets
-SET name = $3, description = $4, amount = $5, start_date = $6, end_date = $7, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated
+const getBudgetUnmatchedTransactions = `-- name: GetBudgetUnmatchedTransactions :many
+SELECT t.id, t.user_id, t.budget_id, t.budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted, t.version, t.start_date, t.end_date, t.interval, t.days_interval, t.rrule, t.recurring_created, t.recurring_updated, t.recurring_deleted, t.budget_name, t.budget_expense_name, t.tags FROM full_transaction t
+JOIN budgets b ON b.user_id = t.user_id
+WHERE b.id = $1 AND b.user_id = $2 AND t.deleted IS NULL AND t.amount < 0 AND t.budget_id IS NULL
+    AND t.date >= b.start_date AND t.date <= b.end_date
+    AND NOT EXISTS (SELECT 1 FROM budget_expense_transactions(b.id) bet WHERE bet.transaction_id = t.id)
+    AND NOT EXISTS (SELECT 1 FROM budget_excluded_transactions x WHERE x.transaction_id = t.id AND x.budget_id = b.id)
+ORDER BY t.date
 `
 
-type UpdateBudgetParams struct {
-	ID          string
-	UserID      uuid.UUID
-	Name        string
-	Description string
-	Amount      string
-	StartDate   time.Time
-	EndDate     time.Time
-}
-
-func (q *Queries) UpdateBudget(ctx context.Context, arg UpdateBudgetParams) (Budget, error) {
-	row := q.db.QueryRowContext(ctx, updateBudget,
-		arg.ID,
-		arg.UserID,
-		arg.Name,
-		arg.Description,
-		arg.Amount,
-		arg.StartDate,
-		arg.EndDate,
-	)
-	var i Budget
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Name,
-		&i.Description,
-		&i.Amount,
-		&i.StartDate,
-		&i.EndDate,
-		&i.Created,
-		&i.Updated,
-	)
-	return i, err
+type GetBudgetUnmatchedTransactionsParams struct {
+	ID     string
+	UserID uuid.UUID
 }
 
-const updateBudgetExpense = `-- name: UpdateBudgetExpense :one
-UPDATE budget_expenses 
-SET name = $2, allocated_amount = $3
-WHERE id = $1
-RETURNING id, budget_id, name, allocated_amount, current_amount, created, updated
-`
-
-type UpdateBudgetExpenseParams struct {
-	ID              int32
-	Name            string
-	AllocatedAmount string
+type GetBudgetUnmatchedTransactionsRow struct {
+	FullTransaction FullTransaction
 }
 
-func (q *Queries) UpdateBudgetExpense(ctx context.Context, arg UpdateBudgetExpenseParams) (BudgetExpense, error) {
-	row := q.db.QueryRowContext(ctx, updateBudgetExpense, arg.ID, arg.Name, arg.AllocatedAmount)
+func (q *Queries) GetBudgetUnmatchedTransactions(ctx context.Context, arg GetBudgetUnmatchedTransactionsParams) ([]GetBudgetUnmatchedTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetUnmatchedTransactions, arg.ID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBudgetUnmatchedTransactionsRow
+	for rows.Next() {
+		var i GetBudgetUnmatchedTransactionsRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetUserId = `-- name: GetBudgetUserId :one
+SELECT user_id FROM budgets
+WHERE id = $1
+`
+
+func (q *Queries) GetBudgetUserId(ctx context.Context, id string) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetUserId, id)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const getBudgets = `-- name: GetBudgets :many
+SELECT id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date FROM budgets
+WHERE user_id = $1 AND deleted IS NULL
+ORDER BY created DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetBudgetsParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetBudgets(ctx context.Context, arg GetBudgetsParams) ([]Budget, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgets, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Budget
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+			&i.Type,
+			&i.ZeroBased,
+			&i.AnchorDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetsExpenses = `-- name: GetBudgetsExpenses :many
+SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version, be.current_amount, be.rollover, be.rollover_amount, be.rollover_period_id, be.rollover_start_date, be.rollover_end_date, be.categories, be.tags, be.payees, be.thresholds FROM budgets b JOIN full_budget_expense be ON b.id = be.budget_id
+WHERE b.user_id = $1 AND b.deleted IS NULL
+LIMIT $2
+OFFSET $3
+`
+
+type GetBudgetsExpensesParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetBudgetsExpensesRow struct {
+	FullBudgetExpense FullBudgetExpense
+}
+
+func (q *Queries) GetBudgetsExpenses(ctx context.Context, arg GetBudgetsExpensesParams) ([]GetBudgetsExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetsExpenses, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBudgetsExpensesRow
+	for rows.Next() {
+		var i GetBudgetsExpensesRow
+		if err := rows.Scan(
+			&i.FullBudgetExpense.ID,
+			&i.FullBudgetExpense.BudgetID,
+			&i.FullBudgetExpense.Name,
+			&i.FullBudgetExpense.AllocatedAmount,
+			&i.FullBudgetExpense.Created,
+			&i.FullBudgetExpense.Updated,
+			&i.FullBudgetExpense.Version,
+			&i.FullBudgetExpense.CurrentAmount,
+			&i.FullBudgetExpense.Rollover,
+			&i.FullBudgetExpense.RolloverAmount,
+			&i.FullBudgetExpense.RolloverPeriodID,
+			&i.FullBudgetExpense.RolloverStartDate,
+			&i.FullBudgetExpense.RolloverEndDate,
+			pq.Array(&i.FullBudgetExpense.Categories),
+			pq.Array(&i.FullBudgetExpense.Tags),
+			pq.Array(&i.FullBudgetExpense.Payees),
+			pq.Array(&i.FullBudgetExpense.Thresholds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBudgetsWithEndedPeriod = `-- name: GetBudgetsWithEndedPeriod :many
+SELECT id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date FROM budgets
+WHERE type != 'Custom' AND deleted IS NULL AND end_date < $1::timestamp
+ORDER BY id
+`
+
+func (q *Queries) GetBudgetsWithEndedPeriod(ctx context.Context, before time.Time) ([]Budget, error) {
+	rows, err := q.db.QueryContext(ctx, getBudgetsWithEndedPeriod, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Budget
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+			&i.Type,
+			&i.ZeroBased,
+			&i.AnchorDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedBudgets = `-- name: GetDeletedBudgets :many
+SELECT id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date FROM budgets
+WHERE user_id = $1 AND deleted IS NOT NULL
+ORDER BY deleted DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetDeletedBudgetsParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetDeletedBudgets(ctx context.Context, arg GetDeletedBudgetsParams) ([]Budget, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedBudgets, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Budget
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+			&i.Type,
+			&i.ZeroBased,
+			&i.AnchorDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedBudgetsExpenses = `-- name: GetDeletedBudgetsExpenses :many
+SELECT be.id, be.budget_id, be.name, be.allocated_amount, be.created, be.updated, be.version, be.current_amount, be.rollover, be.rollover_amount, be.rollover_period_id, be.rollover_start_date, be.rollover_end_date, be.categories, be.tags, be.payees, be.thresholds FROM budgets b JOIN full_budget_expense be ON b.id = be.budget_id
+WHERE b.user_id = $1 AND b.deleted IS NOT NULL
+LIMIT $2
+OFFSET $3
+`
+
+type GetDeletedBudgetsExpensesParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetDeletedBudgetsExpensesRow struct {
+	FullBudgetExpense FullBudgetExpense
+}
+
+func (q *Queries) GetDeletedBudgetsExpenses(ctx context.Context, arg GetDeletedBudgetsExpensesParams) ([]GetDeletedBudgetsExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedBudgetsExpenses, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedBudgetsExpensesRow
+	for rows.Next() {
+		var i GetDeletedBudgetsExpensesRow
+		if err := rows.Scan(
+			&i.FullBudgetExpense.ID,
+			&i.FullBudgetExpense.BudgetID,
+			&i.FullBudgetExpense.Name,
+			&i.FullBudgetExpense.AllocatedAmount,
+			&i.FullBudgetExpense.Created,
+			&i.FullBudgetExpense.Updated,
+			&i.FullBudgetExpense.Version,
+			&i.FullBudgetExpense.CurrentAmount,
+			&i.FullBudgetExpense.Rollover,
+			&i.FullBudgetExpense.RolloverAmount,
+			&i.FullBudgetExpense.RolloverPeriodID,
+			&i.FullBudgetExpense.RolloverStartDate,
+			&i.FullBudgetExpense.RolloverEndDate,
+			pq.Array(&i.FullBudgetExpense.Categories),
+			pq.Array(&i.FullBudgetExpense.Tags),
+			pq.Array(&i.FullBudgetExpense.Payees),
+			pq.Array(&i.FullBudgetExpense.Thresholds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFullBudgetExpenses = `-- name: GetFullBudgetExpenses :many
+SELECT id, budget_id, name, allocated_amount, created, updated, version, current_amount, rollover, rollover_amount, rollover_period_id, rollover_start_date, rollover_end_date, categories, tags, payees, thresholds FROM full_budget_expense
+WHERE budget_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetFullBudgetExpenses(ctx context.Context, budgetID string) ([]FullBudgetExpense, error) {
+	rows, err := q.db.QueryContext(ctx, getFullBudgetExpenses, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FullBudgetExpense
+	for rows.Next() {
+		var i FullBudgetExpense
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Name,
+			&i.AllocatedAmount,
+			&i.Created,
+			&i.Updated,
+			&i.Version,
+			&i.CurrentAmount,
+			&i.Rollover,
+			&i.RolloverAmount,
+			&i.RolloverPeriodID,
+			&i.RolloverStartDate,
+			&i.RolloverEndDate,
+			pq.Array(&i.Categories),
+			pq.Array(&i.Tags),
+			pq.Array(&i.Payees),
+			pq.Array(&i.Thresholds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsForPurgedBudgets = `-- name: GetTransactionsForPurgedBudgets :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted < $1::timestamp)
+ORDER BY id
+FOR UPDATE
+`
+
+func (q *Queries) GetTransactionsForPurgedBudgets(ctx context.Context, before time.Time) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsForPurgedBudgets, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const includeBudgetTransactions = `-- name: IncludeBudgetTransactions :execrows
+DELETE FROM budget_excluded_transactions
+WHERE budget_id = $1::text AND transaction_id = ANY($2::int[])
+`
+
+type IncludeBudgetTransactionsParams struct {
+	BudgetID string
+	Ids      []int32
+}
+
+func (q *Queries) IncludeBudgetTransactions(ctx context.Context, arg IncludeBudgetTransactionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, includeBudgetTransactions, arg.BudgetID, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDeletedBudgets = `-- name: PurgeDeletedBudgets :many
+DELETE FROM budgets
+WHERE deleted < $1::timestamp
+RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date
+`
+
+func (q *Queries) PurgeDeletedBudgets(ctx context.Context, before time.Time) ([]Budget, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedBudgets, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Budget
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+			&i.Type,
+			&i.ZeroBased,
+			&i.AnchorDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeTransactionBudgetIdForPurgedBudgets = `-- name: RemoveTransactionBudgetIdForPurgedBudgets :many
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted < $1::timestamp)
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
+`
+
+func (q *Queries) RemoveTransactionBudgetIdForPurgedBudgets(ctx context.Context, before time.Time) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, removeTransactionBudgetIdForPurgedBudgets, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreBudget = `-- name: RestoreBudget :execrows
+UPDATE budgets
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL
+`
+
+type RestoreBudgetParams struct {
+	ID     string
+	UserID uuid.UUID
+}
+
+func (q *Queries) RestoreBudget(ctx context.Context, arg RestoreBudgetParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreBudget, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setBudgetExpenseRollover = `-- name: SetBudgetExpenseRollover :exec
+UPDATE budget_expenses
+SET rollover_amount = $2, rollover_period_id = $3
+WHERE id = $1
+`
+
+type SetBudgetExpenseRolloverParams struct {
+	ID               int32
+	RolloverAmount   string
+	RolloverPeriodID sql.NullInt32
+}
+
+func (q *Queries) SetBudgetExpenseRollover(ctx context.Context, arg SetBudgetExpenseRolloverParams) error {
+	_, err := q.db.ExecContext(ctx, setBudgetExpenseRollover, arg.ID, arg.RolloverAmount, arg.RolloverPeriodID)
+	return err
+}
+
+const updateBudget = `-- name: UpdateBudget :one
+UPDATE budgets
+SET name = $3, description = $4, amount = $5, start_date = $6, end_date = $7, type = $8, zero_based = $9,
+    anchor_date = CASE WHEN start_date = $6 THEN anchor_date ELSE $6 END, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($10::int IS NULL OR version = $10::int)
+RETURNING id, user_id, name, description, amount, start_date, end_date, created, updated, deleted, version, type, zero_based, anchor_date
+`
+
+type UpdateBudgetParams struct {
+	ID          string
+	UserID      uuid.UUID
+	Name        string
+	Description string
+	Amount      string
+	StartDate   time.Time
+	EndDate     time.Time
+	Type        string
+	ZeroBased   bool
+	Version     sql.NullInt32
+}
+
+func (q *Queries) UpdateBudget(ctx context.Context, arg UpdateBudgetParams) (Budget, error) {
+	row := q.db.QueryRowContext(ctx, updateBudget,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Amount,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Type,
+		arg.ZeroBased,
+		arg.Version,
+	)
+	var i Budget
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Amount,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+		&i.Type,
+		&i.ZeroBased,
+		&i.AnchorDate,
+	)
+	return i, err
+}
+
+const updateBudgetExpense = `-- name: UpdateBudgetExpense :one
+UPDATE budget_expenses 
+SET name = $2, allocated_amount = $3, rollover = $4, categories = COALESCE($5::text[], '{}'), tags = COALESCE($6::text[], '{}'), payees = COALESCE($7::text[], '{}'),
+    thresholds = COALESCE($8::int[], '{}'), updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND ($9::int IS NULL OR version = $9::int)
+RETURNING id, budget_id, name, allocated_amount, created, updated, version, rollover, rollover_amount, rollover_period_id, categories, tags, payees, thresholds
+`
+
+type UpdateBudgetExpenseParams struct {
+	ID              int32
+	Name            string
+	AllocatedAmount string
+	Rollover        string
+	Categories      []string
+	Tags            []string
+	Payees          []string
+	Thresholds      []int32
+	Version         sql.NullInt32
+}
+
+func (q *Queries) UpdateBudgetExpense(ctx context.Context, arg UpdateBudgetExpenseParams) (BudgetExpense, error) {
+	row := q.db.QueryRowContext(ctx, updateBudgetExpense,
+		arg.ID,
+		arg.Name,
+		arg.AllocatedAmount,
+		arg.Rollover,
+		pq.Array(arg.Categories),
+		pq.Array(arg.Tags),
+		pq.Array(arg.Payees),
+		pq.Array(arg.Thresholds),
+		arg.Version,
+	)
 	var i BudgetExpense
 	err := row.Scan(
 		&i.ID,
 		&i.BudgetID,
 		&i.Name,
 		&i.AllocatedAmount,
-		&i.CurrentAmount,
 		&i.Created,
 		&i.Updated,
+		&i.Version,
+		&i.Rollover,
+		&i.RolloverAmount,
+		&i.RolloverPeriodID,
+		pq.Array(&i.Categories),
+		pq.Array(&i.Tags),
+		pq.Array(&i.Payees),
+		pq.Array(&i.Thresholds),
 	)
 	return i, err
 }
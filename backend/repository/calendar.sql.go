@@ -0,0 +1,51 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: calendar.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const deleteCalendarToken = `-- name: DeleteCalendarToken :exec
+DELETE FROM calendar_tokens
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteCalendarToken(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteCalendarToken, userID)
+	return err
+}
+
+const getCalendarTokenUserId = `-- name: GetCalendarTokenUserId :one
+SELECT user_id FROM calendar_tokens
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetCalendarTokenUserId(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getCalendarTokenUserId, tokenHash)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const upsertCalendarToken = `-- name: UpsertCalendarToken :exec
+INSERT INTO calendar_tokens (user_id, token_hash)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created = (now() at time zone 'utc')
+`
+
+type UpsertCalendarTokenParams struct {
+	UserID    uuid.UUID
+	TokenHash string
+}
+
+func (q *Queries) UpsertCalendarToken(ctx context.Context, arg UpsertCalendarTokenParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCalendarToken, arg.UserID, arg.TokenHash)
+	return err
+}
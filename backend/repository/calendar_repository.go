@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
+
+type ICalendarRepository interface {
+	CreateToken(ctx context.Context, userId uuid.UUID) (string, error)
+	GetUserIdByToken(ctx context.Context, token string) (uuid.UUID, error)
+	RemoveToken(ctx context.Context, userId uuid.UUID) error
+}
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func CreateCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{
+		db: db,
+	}
+}
+
+// CreateToken creates a new calendar feed token for the user, replacing the
+// previous one. Only a hash of the token is stored, so the token can not be
+// shown again.
+func (repository *CalendarRepository) CreateToken(ctx context.Context, userId uuid.UUID) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+
+	db := New(repository.db)
+	err = db.UpsertCalendarToken(ctx, UpsertCalendarTokenParams{
+		UserID:    userId,
+		TokenHash: hashCalendarToken(token),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (repository *CalendarRepository) GetUserIdByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	db := New(repository.db)
+	return db.GetCalendarTokenUserId(ctx, hashCalendarToken(token))
+}
+
+func (repository *CalendarRepository) RemoveToken(ctx context.Context, userId uuid.UUID) error {
+	db := New(repository.db)
+	return db.DeleteCalendarToken(ctx, userId)
+}
+
+func hashCalendarToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
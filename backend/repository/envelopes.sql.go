@@ -0,0 +1,211 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: envelopes.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createEnvelopeMovement = `-- name: CreateEnvelopeMovement :one
+INSERT INTO budget_envelope_movements (budget_id, from_expense_id, to_expense_id, amount, description, date)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, budget_id, from_expense_id, to_expense_id, amount, description, date, created
+`
+
+type CreateEnvelopeMovementParams struct {
+	BudgetID      string
+	FromExpenseID sql.NullInt32
+	ToExpenseID   sql.NullInt32
+	Amount        string
+	Description   string
+	Date          time.Time
+}
+
+func (q *Queries) CreateEnvelopeMovement(ctx context.Context, arg CreateEnvelopeMovementParams) (BudgetEnvelopeMovement, error) {
+	row := q.db.QueryRowContext(ctx, createEnvelopeMovement,
+		arg.BudgetID,
+		arg.FromExpenseID,
+		arg.ToExpenseID,
+		arg.Amount,
+		arg.Description,
+		arg.Date,
+	)
+	var i BudgetEnvelopeMovement
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.FromExpenseID,
+		&i.ToExpenseID,
+		&i.Amount,
+		&i.Description,
+		&i.Date,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getBudgetFirstStartDate = `-- name: GetBudgetFirstStartDate :one
+SELECT COALESCE((SELECT MIN(p.start_date) FROM budget_periods p WHERE p.budget_id = b.id), b.start_date)::timestamp AS start_date
+FROM budgets b
+WHERE b.id = $1
+`
+
+func (q *Queries) GetBudgetFirstStartDate(ctx context.Context, id string) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getBudgetFirstStartDate, id)
+	var start_date time.Time
+	err := row.Scan(&start_date)
+	return start_date, err
+}
+
+const getEnvelopeIncome = `-- name: GetEnvelopeIncome :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0
+    AND NOT EXISTS (SELECT 1 FROM budget_expense_transactions($2::text) bet WHERE bet.transaction_id = transactions.id)
+    AND date >= $3::timestamp AND date <= $4::timestamp
+ORDER BY date, id
+`
+
+type GetEnvelopeIncomeParams struct {
+	UserID    uuid.UUID
+	BudgetID  string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) GetEnvelopeIncome(ctx context.Context, arg GetEnvelopeIncomeParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getEnvelopeIncome,
+		arg.UserID,
+		arg.BudgetID,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnvelopeMovements = `-- name: GetEnvelopeMovements :many
+SELECT id, budget_id, from_expense_id, to_expense_id, amount, description, date, created FROM budget_envelope_movements
+WHERE budget_id = $1
+ORDER BY date, id
+`
+
+func (q *Queries) GetEnvelopeMovements(ctx context.Context, budgetID string) ([]BudgetEnvelopeMovement, error) {
+	rows, err := q.db.QueryContext(ctx, getEnvelopeMovements, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetEnvelopeMovement
+	for rows.Next() {
+		var i BudgetEnvelopeMovement
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.FromExpenseID,
+			&i.ToExpenseID,
+			&i.Amount,
+			&i.Description,
+			&i.Date,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnvelopeTransactions = `-- name: GetEnvelopeTransactions :many
+SELECT t.id, t.description, t.amount, t.date, bet.budget_expense_id::int as budget_expense_id FROM transactions t
+JOIN budget_expense_transactions($2::text) AS bet(transaction_id, budget_id, budget_expense_id, matched) ON bet.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL
+    AND t.date <= $3::timestamp
+ORDER BY t.date, t.id
+`
+
+type GetEnvelopeTransactionsParams struct {
+	UserID   uuid.UUID
+	BudgetID string
+	Until    time.Time
+}
+
+type GetEnvelopeTransactionsRow struct {
+	ID              int32
+	Description     string
+	Amount          string
+	Date            time.Time
+	BudgetExpenseID int32
+}
+
+func (q *Queries) GetEnvelopeTransactions(ctx context.Context, arg GetEnvelopeTransactionsParams) ([]GetEnvelopeTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnvelopeTransactions, arg.UserID, arg.BudgetID, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnvelopeTransactionsRow
+	for rows.Next() {
+		var i GetEnvelopeTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Date,
+			&i.BudgetExpenseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
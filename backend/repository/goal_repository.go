@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tvgelderen/fiscora/report"
+)
+
+type IGoalRepository interface {
+	Get(ctx context.Context, userId uuid.UUID) (*[]GoalWithProgress, error)
+	GetById(ctx context.Context, userId uuid.UUID, id int32) (*GoalWithProgress, error)
+	GetTransactions(ctx context.Context, userId uuid.UUID, id int32) (*[]FullTransaction, error)
+
+	Add(ctx context.Context, params CreateGoalParams) (*Goal, error)
+	Update(ctx context.Context, params UpdateGoalParams) (*Goal, error)
+	Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error
+}
+
+// GoalWithProgress is a goal with its progress as of today. The contributions
+// to a goal are the transactions with one of its tags since its start, where
+// money moved out of the account towards the goal counts as saved and money
+// moved back counts as withdrawn. The market value of the investment account
+// of a goal counts as saved as well.
+type GoalWithProgress struct {
+	Goal
+	Progress report.GoalProgress
+}
+
+// ErrGoalAccountNotFound is returned when a goal links an investment account
+// that does not exist or belongs to another user.
+var ErrGoalAccountNotFound = errors.New("goal investment account not found")
+
+type GoalRepository struct {
+	db *sql.DB
+}
+
+func CreateGoalRepository(db *sql.DB) *GoalRepository {
+	return &GoalRepository{
+		db: db,
+	}
+}
+
+func (repository *GoalRepository) Get(ctx context.Context, userId uuid.UUID) (*[]GoalWithProgress, error) {
+	db := New(repository.db)
+	goals, err := db.GetGoals(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	goalsWithProgress, err := withGoalProgress(ctx, db, userId, goals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goalsWithProgress, nil
+}
+
+func (repository *GoalRepository) GetById(ctx context.Context, userId uuid.UUID, id int32) (*GoalWithProgress, error) {
+	db := New(repository.db)
+	goal, err := db.GetGoal(ctx, GetGoalParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	goalsWithProgress, err := withGoalProgress(ctx, db, userId, []Goal{goal})
+	if err != nil {
+		return nil, err
+	}
+
+	return &goalsWithProgress[0], nil
+}
+
+// GetTransactions returns the transactions that contribute to a goal.
+func (repository *GoalRepository) GetTransactions(ctx context.Context, userId uuid.UUID, id int32) (*[]FullTransaction, error) {
+	db := New(repository.db)
+	transactions, err := db.GetGoalTransactions(ctx, GetGoalTransactionsParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fullTransactions := make([]FullTransaction, len(transactions))
+	for idx, transaction := range transactions {
+		fullTransactions[idx] = transaction.FullTransaction
+	}
+
+	return &fullTransactions, nil
+}
+
+func (repository *GoalRepository) Add(ctx context.Context, params CreateGoalParams) (*Goal, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	err = checkGoalAccount(ctx, db, params.UserID, params.InvestmentAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := db.CreateGoal(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     goal.UserID,
+		EntityType: AuditEntityGoal,
+		EntityID:   int32ToString(goal.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotGoal(goal),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, tx.Commit()
+}
+
+func (repository *GoalRepository) Update(ctx context.Context, params UpdateGoalParams) (*Goal, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	goal, err := db.GetGoal(ctx, GetGoalParams{
+		ID:     params.ID,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = checkGoalAccount(ctx, db, params.UserID, params.InvestmentAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedGoal, err := db.UpdateGoal(ctx, params)
+	if err != nil {
+		if NoRowsFound(err) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     goal.UserID,
+		EntityType: AuditEntityGoal,
+		EntityID:   int32ToString(goal.ID),
+		Action:     AuditActionUpdate,
+		Before:     snapshotGoal(goal),
+		After:      snapshotGoal(updatedGoal),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedGoal, tx.Commit()
+}
+
+func (repository *GoalRepository) Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	goal, err := db.GetGoal(ctx, GetGoalParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := db.DeleteGoal(ctx, DeleteGoalParams{
+		ID:      id,
+		UserID:  userId,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityGoal,
+		EntityID:   int32ToString(goal.ID),
+		Action:     AuditActionDelete,
+		Before:     snapshotGoal(goal),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func withGoalProgress(ctx context.Context, db *Queries, userId uuid.UUID, goals []Goal) ([]GoalWithProgress, error) {
+	rows, err := db.GetGoalContributions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	contributions := make(map[int32][]report.Contribution, len(goals))
+	for _, row := range rows {
+		amount, _ := strconv.ParseFloat(row.Amount, 64)
+		contributions[row.GoalID] = append(contributions[row.GoalID], report.Contribution{
+			Date:   row.Date,
+			Amount: amount,
+		})
+	}
+
+	values, err := getGoalAccountValues(ctx, db, userId, goals)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	goalsWithProgress := make([]GoalWithProgress, len(goals))
+	for idx, goal := range goals {
+		target, _ := strconv.ParseFloat(goal.TargetAmount, 64)
+		initial, _ := strconv.ParseFloat(goal.InitialAmount, 64)
+		goalsWithProgress[idx] = GoalWithProgress{
+			Goal: goal,
+			Progress: report.Progress(report.Goal{
+				Target:     target,
+				Initial:    initial,
+				Value:      values[goal.InvestmentAccountID.Int32],
+				Start:      goal.StartDate,
+				TargetDate: goal.TargetDate,
+			}, contributions[goal.ID], now),
+		}
+	}
+
+	return goalsWithProgress, nil
+}
+
+func checkGoalAccount(ctx context.Context, db *Queries, userId uuid.UUID, accountId sql.NullInt32) error {
+	if !accountId.Valid {
+		return nil
+	}
+
+	_, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     accountId.Int32,
+		UserID: userId,
+	})
+	if NoRowsFound(err) {
+		return ErrGoalAccountNotFound
+	}
+	return err
+}
+
+// getGoalAccountValues returns the market value of the investment accounts
+// linked to the goals by account id.
+func getGoalAccountValues(ctx context.Context, db *Queries, userId uuid.UUID, goals []Goal) (map[int32]float64, error) {
+	values := make(map[int32]float64)
+	if !slices.ContainsFunc(goals, func(goal Goal) bool { return goal.InvestmentAccountID.Valid }) {
+		return values, nil
+	}
+
+	accounts, err := db.GetInvestmentAccounts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := db.GetInvestmentAccountsTrades(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := getPortfolioPrices(ctx, db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeMap := make(map[int32][]InvestmentTrade, len(accounts))
+	for _, trade := range trades {
+		tradeMap[trade.AccountID] = append(tradeMap[trade.AccountID], trade)
+	}
+
+	for _, account := range accounts {
+		accountWithPortfolio, err := withPortfolio(account, tradeMap[account.ID], prices)
+		if err != nil {
+			return nil, err
+		}
+		values[account.ID] = accountWithPortfolio.Portfolio.MarketValue
+	}
+
+	return values, nil
+}
@@ -0,0 +1,313 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: goals.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createGoal = `-- name: CreateGoal :one
+INSERT INTO goals (user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, investment_account_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($9::text[], '{}'), $8)
+RETURNING id, user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, created, updated, version, investment_account_id
+`
+
+type CreateGoalParams struct {
+	UserID              uuid.UUID
+	Name                string
+	Description         string
+	TargetAmount        string
+	InitialAmount       string
+	StartDate           time.Time
+	TargetDate          time.Time
+	InvestmentAccountID sql.NullInt32
+	Tags                []string
+}
+
+func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, createGoal,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.TargetAmount,
+		arg.InitialAmount,
+		arg.StartDate,
+		arg.TargetDate,
+		arg.InvestmentAccountID,
+		pq.Array(arg.Tags),
+	)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TargetAmount,
+		&i.InitialAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		pq.Array(&i.Tags),
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+		&i.InvestmentAccountID,
+	)
+	return i, err
+}
+
+const deleteGoal = `-- name: DeleteGoal :execrows
+DELETE FROM goals
+WHERE id = $1 AND user_id = $2 AND ($3::int IS NULL OR version = $3::int)
+`
+
+type DeleteGoalParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
+}
+
+func (q *Queries) DeleteGoal(ctx context.Context, arg DeleteGoalParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteGoal, arg.ID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getGoal = `-- name: GetGoal :one
+SELECT id, user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, created, updated, version, investment_account_id FROM goals
+WHERE id = $1 AND user_id = $2
+`
+
+type GetGoalParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetGoal(ctx context.Context, arg GetGoalParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, getGoal, arg.ID, arg.UserID)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TargetAmount,
+		&i.InitialAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		pq.Array(&i.Tags),
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+		&i.InvestmentAccountID,
+	)
+	return i, err
+}
+
+const getGoalContributions = `-- name: GetGoalContributions :many
+SELECT g.id as goal_id, t.date, (-t.amount)::DECIMAL(19, 2) as amount FROM goals g
+JOIN transactions t ON t.user_id = g.user_id
+WHERE g.user_id = $1 AND t.deleted IS NULL AND t.date >= g.start_date
+    AND EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = ANY(g.tags))
+ORDER BY t.date
+`
+
+type GetGoalContributionsRow struct {
+	GoalID int32
+	Date   time.Time
+	Amount string
+}
+
+func (q *Queries) GetGoalContributions(ctx context.Context, userID uuid.UUID) ([]GetGoalContributionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalContributions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGoalContributionsRow
+	for rows.Next() {
+		var i GetGoalContributionsRow
+		if err := rows.Scan(&i.GoalID, &i.Date, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalTransactions = `-- name: GetGoalTransactions :many
+SELECT t.id, t.user_id, t.budget_id, t.budget_expense_id, t.recurring_transaction_id, t.description, t.amount, t.type, t.date, t.created, t.updated, t.deleted, t.version, t.start_date, t.end_date, t.interval, t.days_interval, t.rrule, t.recurring_created, t.recurring_updated, t.recurring_deleted, t.budget_name, t.budget_expense_name, t.tags FROM full_transaction t
+JOIN goals g ON g.user_id = t.user_id
+WHERE g.id = $1 AND g.user_id = $2 AND t.deleted IS NULL AND t.date >= g.start_date AND t.tags && g.tags
+ORDER BY t.date
+`
+
+type GetGoalTransactionsParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+type GetGoalTransactionsRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetGoalTransactions(ctx context.Context, arg GetGoalTransactionsParams) ([]GetGoalTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalTransactions, arg.ID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGoalTransactionsRow
+	for rows.Next() {
+		var i GetGoalTransactionsRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoals = `-- name: GetGoals :many
+SELECT id, user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, created, updated, version, investment_account_id FROM goals
+WHERE user_id = $1
+ORDER BY target_date, id
+`
+
+func (q *Queries) GetGoals(ctx context.Context, userID uuid.UUID) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, getGoals, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.TargetAmount,
+			&i.InitialAmount,
+			&i.StartDate,
+			&i.TargetDate,
+			pq.Array(&i.Tags),
+			&i.Created,
+			&i.Updated,
+			&i.Version,
+			&i.InvestmentAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateGoal = `-- name: UpdateGoal :one
+UPDATE goals
+SET name = $3, description = $4, target_amount = $5, initial_amount = $6, start_date = $7, target_date = $8, tags = COALESCE($10::text[], '{}'),
+    investment_account_id = $9, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND ($11::int IS NULL OR version = $11::int)
+RETURNING id, user_id, name, description, target_amount, initial_amount, start_date, target_date, tags, created, updated, version, investment_account_id
+`
+
+type UpdateGoalParams struct {
+	ID                  int32
+	UserID              uuid.UUID
+	Name                string
+	Description         string
+	TargetAmount        string
+	InitialAmount       string
+	StartDate           time.Time
+	TargetDate          time.Time
+	InvestmentAccountID sql.NullInt32
+	Tags                []string
+	Version             sql.NullInt32
+}
+
+func (q *Queries) UpdateGoal(ctx context.Context, arg UpdateGoalParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, updateGoal,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.TargetAmount,
+		arg.InitialAmount,
+		arg.StartDate,
+		arg.TargetDate,
+		arg.InvestmentAccountID,
+		pq.Array(arg.Tags),
+		arg.Version,
+	)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TargetAmount,
+		&i.InitialAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		pq.Array(&i.Tags),
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+		&i.InvestmentAccountID,
+	)
+	return i, err
+}
@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: idempotency_keys.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2
+`
+
+type CompleteIdempotencyKeyParams struct {
+	UserID       uuid.UUID
+	Key          string
+	StatusCode   sql.NullInt32
+	ContentType  sql.NullString
+	ResponseBody []byte
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.ResponseBody,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, request_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = NULL,
+    response_body = NULL,
+    created = (now() at time zone 'utc')
+WHERE idempotency_keys.created < $4::timestamp
+`
+
+type CreateIdempotencyKeyParams struct {
+	UserID        uuid.UUID
+	Key           string
+	RequestHash   string
+	ExpiredBefore time.Time
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.RequestHash,
+		arg.ExpiredBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created < $1::timestamp
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID uuid.UUID
+	Key    string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, key, request_hash, status_code, content_type, response_body, created FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID uuid.UUID
+	Key    string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.Created,
+	)
+	return i, err
+}
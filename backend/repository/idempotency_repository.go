@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IIdempotencyRepository interface {
+	Reserve(ctx context.Context, userId uuid.UUID, key string, requestHash string, ttl time.Duration) (*IdempotencyKey, error)
+	Complete(ctx context.Context, params CompleteIdempotencyKeyParams) error
+	Release(ctx context.Context, userId uuid.UUID, key string) error
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func CreateIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// Reserve claims the key for a new request. It returns nil when the key was
+// claimed, or the stored key when it was already used within the ttl. A
+// stored key without a status code belongs to a request that is still being
+// processed.
+func (repository *IdempotencyRepository) Reserve(ctx context.Context, userId uuid.UUID, key string, requestHash string, ttl time.Duration) (*IdempotencyKey, error) {
+	db := New(repository.db)
+	nrows, err := db.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		UserID:        userId,
+		Key:           key,
+		RequestHash:   requestHash,
+		ExpiredBefore: time.Now().UTC().Add(-ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if nrows != 0 {
+		return nil, nil
+	}
+
+	idempotencyKey, err := db.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		UserID: userId,
+		Key:    key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (repository *IdempotencyRepository) Complete(ctx context.Context, params CompleteIdempotencyKeyParams) error {
+	db := New(repository.db)
+	return db.CompleteIdempotencyKey(ctx, params)
+}
+
+// Release removes a claimed key so a failed request can be retried with it.
+func (repository *IdempotencyRepository) Release(ctx context.Context, userId uuid.UUID, key string) error {
+	db := New(repository.db)
+	return db.DeleteIdempotencyKey(ctx, DeleteIdempotencyKeyParams{
+		UserID: userId,
+		Key:    key,
+	})
+}
+
+func (repository *IdempotencyRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	db := New(repository.db)
+	return db.DeleteExpiredIdempotencyKeys(ctx, before)
+}
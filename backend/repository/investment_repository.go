@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tvgelderen/fiscora/portfolio"
+)
+
+type IInvestmentRepository interface {
+	Get(ctx context.Context, userId uuid.UUID) (*[]InvestmentAccountWithPortfolio, error)
+	GetById(ctx context.Context, userId uuid.UUID, id int32) (*InvestmentAccountWithPortfolio, error)
+
+	Add(ctx context.Context, params CreateInvestmentAccountParams) (*InvestmentAccount, error)
+	Update(ctx context.Context, params UpdateInvestmentAccountParams) (*InvestmentAccount, error)
+	Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error
+
+	AddTrade(ctx context.Context, userId uuid.UUID, params CreateInvestmentTradeParams) (*InvestmentTrade, error)
+	RemoveTrade(ctx context.Context, userId uuid.UUID, accountId int32, id int32) error
+
+	GetPrices(ctx context.Context, userId uuid.UUID) (*[]InvestmentPrice, error)
+	AddPrices(ctx context.Context, userId uuid.UUID, prices []portfolio.Price) error
+}
+
+// InvestmentAccountWithPortfolio is an investment account with its trades and
+// the valuation of its holdings as of today.
+type InvestmentAccountWithPortfolio struct {
+	InvestmentAccount
+	Trades    []InvestmentTrade
+	Portfolio portfolio.Portfolio
+}
+
+type InvestmentRepository struct {
+	db *sql.DB
+}
+
+func CreateInvestmentRepository(db *sql.DB) *InvestmentRepository {
+	return &InvestmentRepository{
+		db: db,
+	}
+}
+
+func (repository *InvestmentRepository) Get(ctx context.Context, userId uuid.UUID) (*[]InvestmentAccountWithPortfolio, error) {
+	db := New(repository.db)
+	accounts, err := db.GetInvestmentAccounts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := db.GetInvestmentAccountsTrades(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := getPortfolioPrices(ctx, db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeMap := make(map[int32][]InvestmentTrade, len(accounts))
+	for _, trade := range trades {
+		tradeMap[trade.AccountID] = append(tradeMap[trade.AccountID], trade)
+	}
+
+	accountsWithPortfolio := make([]InvestmentAccountWithPortfolio, len(accounts))
+	for idx, account := range accounts {
+		accountsWithPortfolio[idx], err = withPortfolio(account, tradeMap[account.ID], prices)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &accountsWithPortfolio, nil
+}
+
+func (repository *InvestmentRepository) GetById(ctx context.Context, userId uuid.UUID, id int32) (*InvestmentAccountWithPortfolio, error) {
+	db := New(repository.db)
+	account, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	trades, err := db.GetInvestmentTrades(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := getPortfolioPrices(ctx, db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	accountWithPortfolio, err := withPortfolio(account, trades, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountWithPortfolio, nil
+}
+
+func (repository *InvestmentRepository) Add(ctx context.Context, params CreateInvestmentAccountParams) (*InvestmentAccount, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	account, err := db.CreateInvestmentAccount(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     account.UserID,
+		EntityType: AuditEntityInvestmentAccount,
+		EntityID:   int32ToString(account.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotInvestmentAccount(account),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, tx.Commit()
+}
+
+func (repository *InvestmentRepository) Update(ctx context.Context, params UpdateInvestmentAccountParams) (*InvestmentAccount, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	account, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     params.ID,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAccount, err := db.UpdateInvestmentAccount(ctx, params)
+	if err != nil {
+		if NoRowsFound(err) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     account.UserID,
+		EntityType: AuditEntityInvestmentAccount,
+		EntityID:   int32ToString(account.ID),
+		Action:     AuditActionUpdate,
+		Before:     snapshotInvestmentAccount(account),
+		After:      snapshotInvestmentAccount(updatedAccount),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedAccount, tx.Commit()
+}
+
+func (repository *InvestmentRepository) Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	account, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := db.DeleteInvestmentAccount(ctx, DeleteInvestmentAccountParams{
+		ID:      id,
+		UserID:  userId,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityInvestmentAccount,
+		EntityID:   int32ToString(account.ID),
+		Action:     AuditActionDelete,
+		Before:     snapshotInvestmentAccount(account),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddTrade adds a trade to an account. It fails with
+// portfolio.ErrInsufficientQuantity when it sells more than was held at the
+// time.
+func (repository *InvestmentRepository) AddTrade(ctx context.Context, userId uuid.UUID, params CreateInvestmentTradeParams) (*InvestmentTrade, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	account, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     params.AccountID,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := db.CreateInvestmentTrade(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	err = checkInvestmentTrades(ctx, db, account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityInvestmentTrade,
+		EntityID:   int32ToString(trade.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotInvestmentTrade(trade),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &trade, tx.Commit()
+}
+
+// RemoveTrade removes a trade from an account. It fails with
+// portfolio.ErrInsufficientQuantity when a later sell depends on it.
+func (repository *InvestmentRepository) RemoveTrade(ctx context.Context, userId uuid.UUID, accountId int32, id int32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	account, err := db.GetInvestmentAccount(ctx, GetInvestmentAccountParams{
+		ID:     accountId,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	trade, err := db.DeleteInvestmentTrade(ctx, DeleteInvestmentTradeParams{
+		ID:        id,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return err
+	}
+	err = checkInvestmentTrades(ctx, db, account)
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityInvestmentTrade,
+		EntityID:   int32ToString(trade.ID),
+		Action:     AuditActionDelete,
+		Before:     snapshotInvestmentTrade(trade),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPrices returns the latest price of each symbol.
+func (repository *InvestmentRepository) GetPrices(ctx context.Context, userId uuid.UUID) (*[]InvestmentPrice, error) {
+	db := New(repository.db)
+	prices, err := db.GetLatestInvestmentPrices(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prices, nil
+}
+
+// AddPrices stores prices, replacing the price of a symbol on a day that was
+// stored before.
+func (repository *InvestmentRepository) AddPrices(ctx context.Context, userId uuid.UUID, prices []portfolio.Price) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	for _, price := range prices {
+		err := db.UpsertInvestmentPrice(ctx, UpsertInvestmentPriceParams{
+			UserID: userId,
+			Symbol: price.Symbol,
+			Date:   price.Date,
+			Price:  strconv.FormatFloat(price.Price, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkInvestmentTrades checks that the trades of an account never sell more
+// than was held, including trades in the future.
+func checkInvestmentTrades(ctx context.Context, db *Queries, account InvestmentAccount) error {
+	trades, err := db.GetInvestmentTrades(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = portfolio.Value(toPortfolioTrades(trades), nil, account.CostBasisMethod, time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+	return err
+}
+
+func getPortfolioPrices(ctx context.Context, db *Queries, userId uuid.UUID) ([]portfolio.Price, error) {
+	rows, err := db.GetInvestmentPrices(ctx, GetInvestmentPricesParams{
+		UserID: userId,
+		Until:  time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]portfolio.Price, len(rows))
+	for idx, row := range rows {
+		price, _ := strconv.ParseFloat(row.Price, 64)
+		prices[idx] = portfolio.Price{
+			Symbol: row.Symbol,
+			Date:   row.Date,
+			Price:  price,
+		}
+	}
+
+	return prices, nil
+}
+
+func withPortfolio(account InvestmentAccount, trades []InvestmentTrade, prices []portfolio.Price) (InvestmentAccountWithPortfolio, error) {
+	value, err := portfolio.Value(toPortfolioTrades(trades), prices, account.CostBasisMethod, time.Now().UTC())
+	if err != nil {
+		return InvestmentAccountWithPortfolio{}, err
+	}
+
+	return InvestmentAccountWithPortfolio{
+		InvestmentAccount: account,
+		Trades:            trades,
+		Portfolio:         value,
+	}, nil
+}
+
+func toPortfolioTrades(trades []InvestmentTrade) []portfolio.Trade {
+	result := make([]portfolio.Trade, len(trades))
+	for idx, trade := range trades {
+		quantity, _ := strconv.ParseFloat(trade.Quantity, 64)
+		price, _ := strconv.ParseFloat(trade.Price, 64)
+		fees, _ := strconv.ParseFloat(trade.Fees, 64)
+		result[idx] = portfolio.Trade{
+			Symbol:   trade.Symbol,
+			Kind:     trade.Kind,
+			Date:     trade.Date,
+			Quantity: quantity,
+			Price:    price,
+			Fees:     fees,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,416 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: investments.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createInvestmentAccount = `-- name: CreateInvestmentAccount :one
+INSERT INTO investment_accounts (user_id, name, description, cost_basis_method)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, name, description, cost_basis_method, created, updated, version
+`
+
+type CreateInvestmentAccountParams struct {
+	UserID          uuid.UUID
+	Name            string
+	Description     string
+	CostBasisMethod string
+}
+
+func (q *Queries) CreateInvestmentAccount(ctx context.Context, arg CreateInvestmentAccountParams) (InvestmentAccount, error) {
+	row := q.db.QueryRowContext(ctx, createInvestmentAccount,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.CostBasisMethod,
+	)
+	var i InvestmentAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.CostBasisMethod,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createInvestmentTrade = `-- name: CreateInvestmentTrade :one
+INSERT INTO investment_trades (account_id, symbol, kind, date, quantity, price, fees)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, account_id, symbol, kind, date, quantity, price, fees, created
+`
+
+type CreateInvestmentTradeParams struct {
+	AccountID int32
+	Symbol    string
+	Kind      string
+	Date      time.Time
+	Quantity  string
+	Price     string
+	Fees      string
+}
+
+func (q *Queries) CreateInvestmentTrade(ctx context.Context, arg CreateInvestmentTradeParams) (InvestmentTrade, error) {
+	row := q.db.QueryRowContext(ctx, createInvestmentTrade,
+		arg.AccountID,
+		arg.Symbol,
+		arg.Kind,
+		arg.Date,
+		arg.Quantity,
+		arg.Price,
+		arg.Fees,
+	)
+	var i InvestmentTrade
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Symbol,
+		&i.Kind,
+		&i.Date,
+		&i.Quantity,
+		&i.Price,
+		&i.Fees,
+		&i.Created,
+	)
+	return i, err
+}
+
+const deleteInvestmentAccount = `-- name: DeleteInvestmentAccount :execrows
+DELETE FROM investment_accounts
+WHERE id = $1 AND user_id = $2 AND ($3::int IS NULL OR version = $3::int)
+`
+
+type DeleteInvestmentAccountParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
+}
+
+func (q *Queries) DeleteInvestmentAccount(ctx context.Context, arg DeleteInvestmentAccountParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteInvestmentAccount, arg.ID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteInvestmentTrade = `-- name: DeleteInvestmentTrade :one
+DELETE FROM investment_trades
+WHERE id = $1 AND account_id = $2
+RETURNING id, account_id, symbol, kind, date, quantity, price, fees, created
+`
+
+type DeleteInvestmentTradeParams struct {
+	ID        int32
+	AccountID int32
+}
+
+func (q *Queries) DeleteInvestmentTrade(ctx context.Context, arg DeleteInvestmentTradeParams) (InvestmentTrade, error) {
+	row := q.db.QueryRowContext(ctx, deleteInvestmentTrade, arg.ID, arg.AccountID)
+	var i InvestmentTrade
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Symbol,
+		&i.Kind,
+		&i.Date,
+		&i.Quantity,
+		&i.Price,
+		&i.Fees,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getInvestmentAccount = `-- name: GetInvestmentAccount :one
+SELECT id, user_id, name, description, cost_basis_method, created, updated, version FROM investment_accounts
+WHERE id = $1 AND user_id = $2
+`
+
+type GetInvestmentAccountParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetInvestmentAccount(ctx context.Context, arg GetInvestmentAccountParams) (InvestmentAccount, error) {
+	row := q.db.QueryRowContext(ctx, getInvestmentAccount, arg.ID, arg.UserID)
+	var i InvestmentAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.CostBasisMethod,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getInvestmentAccounts = `-- name: GetInvestmentAccounts :many
+SELECT id, user_id, name, description, cost_basis_method, created, updated, version FROM investment_accounts
+WHERE user_id = $1
+ORDER BY created, id
+`
+
+func (q *Queries) GetInvestmentAccounts(ctx context.Context, userID uuid.UUID) ([]InvestmentAccount, error) {
+	rows, err := q.db.QueryContext(ctx, getInvestmentAccounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvestmentAccount
+	for rows.Next() {
+		var i InvestmentAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.CostBasisMethod,
+			&i.Created,
+			&i.Updated,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvestmentAccountsTrades = `-- name: GetInvestmentAccountsTrades :many
+SELECT it.id, it.account_id, it.symbol, it.kind, it.date, it.quantity, it.price, it.fees, it.created FROM investment_trades it
+JOIN investment_accounts ia ON it.account_id = ia.id
+WHERE ia.user_id = $1
+ORDER BY it.date, it.id
+`
+
+func (q *Queries) GetInvestmentAccountsTrades(ctx context.Context, userID uuid.UUID) ([]InvestmentTrade, error) {
+	rows, err := q.db.QueryContext(ctx, getInvestmentAccountsTrades, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvestmentTrade
+	for rows.Next() {
+		var i InvestmentTrade
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Symbol,
+			&i.Kind,
+			&i.Date,
+			&i.Quantity,
+			&i.Price,
+			&i.Fees,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvestmentPrices = `-- name: GetInvestmentPrices :many
+SELECT user_id, symbol, date, price, created FROM investment_prices
+WHERE user_id = $1 AND date <= $2::timestamp
+ORDER BY symbol, date
+`
+
+type GetInvestmentPricesParams struct {
+	UserID uuid.UUID
+	Until  time.Time
+}
+
+func (q *Queries) GetInvestmentPrices(ctx context.Context, arg GetInvestmentPricesParams) ([]InvestmentPrice, error) {
+	rows, err := q.db.QueryContext(ctx, getInvestmentPrices, arg.UserID, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvestmentPrice
+	for rows.Next() {
+		var i InvestmentPrice
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Symbol,
+			&i.Date,
+			&i.Price,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvestmentTrades = `-- name: GetInvestmentTrades :many
+SELECT id, account_id, symbol, kind, date, quantity, price, fees, created FROM investment_trades
+WHERE account_id = $1
+ORDER BY date, id
+`
+
+func (q *Queries) GetInvestmentTrades(ctx context.Context, accountID int32) ([]InvestmentTrade, error) {
+	rows, err := q.db.QueryContext(ctx, getInvestmentTrades, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvestmentTrade
+	for rows.Next() {
+		var i InvestmentTrade
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Symbol,
+			&i.Kind,
+			&i.Date,
+			&i.Quantity,
+			&i.Price,
+			&i.Fees,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestInvestmentPrices = `-- name: GetLatestInvestmentPrices :many
+SELECT DISTINCT ON (symbol) user_id, symbol, date, price, created FROM investment_prices
+WHERE user_id = $1
+ORDER BY symbol, date DESC
+`
+
+func (q *Queries) GetLatestInvestmentPrices(ctx context.Context, userID uuid.UUID) ([]InvestmentPrice, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestInvestmentPrices, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvestmentPrice
+	for rows.Next() {
+		var i InvestmentPrice
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Symbol,
+			&i.Date,
+			&i.Price,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateInvestmentAccount = `-- name: UpdateInvestmentAccount :one
+UPDATE investment_accounts
+SET name = $3, description = $4, cost_basis_method = $5, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND ($6::int IS NULL OR version = $6::int)
+RETURNING id, user_id, name, description, cost_basis_method, created, updated, version
+`
+
+type UpdateInvestmentAccountParams struct {
+	ID              int32
+	UserID          uuid.UUID
+	Name            string
+	Description     string
+	CostBasisMethod string
+	Version         sql.NullInt32
+}
+
+func (q *Queries) UpdateInvestmentAccount(ctx context.Context, arg UpdateInvestmentAccountParams) (InvestmentAccount, error) {
+	row := q.db.QueryRowContext(ctx, updateInvestmentAccount,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.CostBasisMethod,
+		arg.Version,
+	)
+	var i InvestmentAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.CostBasisMethod,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
+
+const upsertInvestmentPrice = `-- name: UpsertInvestmentPrice :exec
+INSERT INTO investment_prices (user_id, symbol, date, price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, symbol, date) DO UPDATE SET price = EXCLUDED.price, created = (now() at time zone 'utc')
+`
+
+type UpsertInvestmentPriceParams struct {
+	UserID uuid.UUID
+	Symbol string
+	Date   time.Time
+	Price  string
+}
+
+func (q *Queries) UpsertInvestmentPrice(ctx context.Context, arg UpsertInvestmentPriceParams) error {
+	_, err := q.db.ExecContext(ctx, upsertInvestmentPrice,
+		arg.UserID,
+		arg.Symbol,
+		arg.Date,
+		arg.Price,
+	)
+	return err
+}
@@ -0,0 +1,482 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tvgelderen/fiscora/amortization"
+)
+
+type ILoanRepository interface {
+	Get(ctx context.Context, userId uuid.UUID) (*[]LoanWithSchedule, error)
+	GetById(ctx context.Context, userId uuid.UUID, id int32) (*LoanWithSchedule, error)
+
+	Add(ctx context.Context, params CreateLoanParams) (*Loan, error)
+	Update(ctx context.Context, params UpdateLoanParams) (*Loan, error)
+	Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error
+
+	AddRepayment(ctx context.Context, params AddLoanRepaymentParams) (*LoanRepayment, error)
+	RemoveRepayment(ctx context.Context, userId uuid.UUID, loanId int32, id int32) error
+
+	PostPayments(ctx context.Context) (int64, error)
+}
+
+// LoanWithSchedule is a loan with its extra repayments, the scheduled payments
+// that were posted as transactions and its amortization schedule. The balance
+// is what is left after the payments that are due up to today.
+type LoanWithSchedule struct {
+	Loan
+	Repayments   []LoanRepayment
+	Payments     []LoanPayment
+	Amortization amortization.Schedule
+	Balance      float64
+}
+
+// AddLoanRepaymentParams is an extra repayment of principal, which is booked
+// as a transaction of the type of the loan.
+type AddLoanRepaymentParams struct {
+	UserID uuid.UUID
+	LoanID int32
+	Date   time.Time
+	Amount float64
+}
+
+type LoanRepository struct {
+	db *sql.DB
+}
+
+func CreateLoanRepository(db *sql.DB) *LoanRepository {
+	return &LoanRepository{
+		db: db,
+	}
+}
+
+func (repository *LoanRepository) Get(ctx context.Context, userId uuid.UUID) (*[]LoanWithSchedule, error) {
+	db := New(repository.db)
+	loans, err := db.GetLoans(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	repayments, err := db.GetLoansRepayments(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := db.GetLoansPayments(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	repaymentMap := make(map[int32][]LoanRepayment, len(loans))
+	for _, repayment := range repayments {
+		repaymentMap[repayment.LoanID] = append(repaymentMap[repayment.LoanID], repayment)
+	}
+	paymentMap := make(map[int32][]LoanPayment, len(loans))
+	for _, payment := range payments {
+		paymentMap[payment.LoanID] = append(paymentMap[payment.LoanID], payment)
+	}
+
+	loansWithSchedule := make([]LoanWithSchedule, len(loans))
+	for idx, loan := range loans {
+		loansWithSchedule[idx] = withLoanSchedule(loan, repaymentMap[loan.ID], paymentMap[loan.ID])
+	}
+
+	return &loansWithSchedule, nil
+}
+
+func (repository *LoanRepository) GetById(ctx context.Context, userId uuid.UUID, id int32) (*LoanWithSchedule, error) {
+	db := New(repository.db)
+	loan, err := db.GetLoan(ctx, GetLoanParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	repayments, err := db.GetLoanRepayments(ctx, loan.ID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := db.GetLoanPayments(ctx, loan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	loanWithSchedule := withLoanSchedule(loan, repayments, payments)
+
+	return &loanWithSchedule, nil
+}
+
+func (repository *LoanRepository) Add(ctx context.Context, params CreateLoanParams) (*Loan, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	loan, err := db.CreateLoan(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     loan.UserID,
+		EntityType: AuditEntityLoan,
+		EntityID:   int32ToString(loan.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotLoan(loan),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &loan, tx.Commit()
+}
+
+// Update changes a loan. Payments that were posted already keep their
+// transactions, the changes apply to the payments that follow.
+func (repository *LoanRepository) Update(ctx context.Context, params UpdateLoanParams) (*Loan, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	loan, err := db.GetLoan(ctx, GetLoanParams{
+		ID:     params.ID,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedLoan, err := db.UpdateLoan(ctx, params)
+	if err != nil {
+		if NoRowsFound(err) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     loan.UserID,
+		EntityType: AuditEntityLoan,
+		EntityID:   int32ToString(loan.ID),
+		Action:     AuditActionUpdate,
+		Before:     snapshotLoan(loan),
+		After:      snapshotLoan(updatedLoan),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedLoan, tx.Commit()
+}
+
+// Remove deletes a loan. The transactions of its payments and repayments stay
+// as the history of what was paid.
+func (repository *LoanRepository) Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	loan, err := db.GetLoan(ctx, GetLoanParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := db.DeleteLoan(ctx, DeleteLoanParams{
+		ID:      id,
+		UserID:  userId,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityLoan,
+		EntityID:   int32ToString(loan.ID),
+		Action:     AuditActionDelete,
+		Before:     snapshotLoan(loan),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *LoanRepository) AddRepayment(ctx context.Context, params AddLoanRepaymentParams) (*LoanRepayment, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	loan, err := db.GetLoan(ctx, GetLoanParams{
+		ID:     params.LoanID,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := createLoanTransaction(ctx, db, CreateTransactionParams{
+		UserID:      loan.UserID,
+		Amount:      strconv.FormatFloat(-params.Amount, 'f', 2, 64),
+		Description: fmt.Sprintf("%s extra repayment", loan.Name),
+		Type:        loan.Type,
+		Date:        params.Date,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repayment, err := db.CreateLoanRepayment(ctx, CreateLoanRepaymentParams{
+		LoanID:        loan.ID,
+		TransactionID: sql.NullInt32{Int32: transaction.ID, Valid: true},
+		Date:          params.Date,
+		Amount:        strconv.FormatFloat(params.Amount, 'f', 2, 64),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &repayment, tx.Commit()
+}
+
+// RemoveRepayment deletes an extra repayment and moves its transaction to the
+// trash.
+func (repository *LoanRepository) RemoveRepayment(ctx context.Context, userId uuid.UUID, loanId int32, id int32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	loan, err := db.GetLoan(ctx, GetLoanParams{
+		ID:     loanId,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	repayment, err := db.DeleteLoanRepayment(ctx, DeleteLoanRepaymentParams{
+		ID:     id,
+		LoanID: loan.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if !repayment.TransactionID.Valid {
+		return tx.Commit()
+	}
+
+	transaction, err := db.GetTransactionById(ctx, GetTransactionByIdParams{
+		ID:     repayment.TransactionID.Int32,
+		UserID: userId,
+	})
+	if err != nil {
+		// The transaction is in the trash already
+		if NoRowsFound(err) {
+			return tx.Commit()
+		}
+		return err
+	}
+	_, err = db.DeleteTransaction(ctx, DeleteTransactionParams{
+		ID:     transaction.ID,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityTransaction,
+		EntityID:   int32ToString(transaction.ID),
+		Action:     AuditActionDelete,
+		Before:     snapshotTransaction(transaction),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PostPayments books the scheduled payments of loans that are due up to today
+// as a principal and an interest transaction. A payment without interest only
+// gets a principal transaction.
+func (repository *LoanRepository) PostPayments(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	loans, err := New(repository.db).GetLoansWithDuePayments(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	var posted int64
+	for _, loan := range loans {
+		nrows, err := repository.postPayments(ctx, loan, today)
+		if err != nil {
+			return posted, err
+		}
+		posted += nrows
+	}
+
+	return posted, nil
+}
+
+func (repository *LoanRepository) postPayments(ctx context.Context, loan Loan, until time.Time) (int64, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	repayments, err := db.GetLoanRepayments(ctx, loan.ID)
+	if err != nil {
+		return 0, err
+	}
+	payments, err := db.GetLoanPayments(ctx, loan.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int32
+	if len(payments) != 0 {
+		last = payments[len(payments)-1].Number
+	}
+
+	var posted int64
+	schedule := withLoanSchedule(loan, repayments, payments).Amortization
+	for _, payment := range schedule.Payments {
+		if int32(payment.Number) <= last || payment.Date.After(until) {
+			continue
+		}
+
+		params := CreateLoanPaymentParams{
+			LoanID: loan.ID,
+			Number: int32(payment.Number),
+			Date:   payment.Date,
+		}
+		if payment.Principal != 0 {
+			transaction, err := createLoanTransaction(ctx, db, CreateTransactionParams{
+				UserID:      loan.UserID,
+				Amount:      strconv.FormatFloat(-payment.Principal, 'f', 2, 64),
+				Description: fmt.Sprintf("%s principal %d/%d", loan.Name, payment.Number, loan.Term),
+				Type:        loan.Type,
+				Date:        payment.Date,
+			})
+			if err != nil {
+				return 0, err
+			}
+			params.PrincipalTransactionID = sql.NullInt32{Int32: transaction.ID, Valid: true}
+		}
+		if payment.Interest != 0 {
+			transaction, err := createLoanTransaction(ctx, db, CreateTransactionParams{
+				UserID:      loan.UserID,
+				Amount:      strconv.FormatFloat(-payment.Interest, 'f', 2, 64),
+				Description: fmt.Sprintf("%s interest %d/%d", loan.Name, payment.Number, loan.Term),
+				Type:        ExpenseTypeInterest,
+				Date:        payment.Date,
+			})
+			if err != nil {
+				return 0, err
+			}
+			params.InterestTransactionID = sql.NullInt32{Int32: transaction.ID, Valid: true}
+		}
+
+		err = db.CreateLoanPayment(ctx, params)
+		if err != nil {
+			return 0, err
+		}
+		posted++
+	}
+
+	return posted, tx.Commit()
+}
+
+// createLoanTransaction creates a transaction of a loan payment or repayment
+// and audits it like any other created transaction.
+func createLoanTransaction(ctx context.Context, db *Queries, params CreateTransactionParams) (Transaction, error) {
+	transaction, err := db.CreateTransaction(ctx, params)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     transaction.UserID,
+		EntityType: AuditEntityTransaction,
+		EntityID:   int32ToString(transaction.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotTransaction(transaction),
+	})
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+func withLoanSchedule(loan Loan, repayments []LoanRepayment, payments []LoanPayment) LoanWithSchedule {
+	now := time.Now().UTC()
+	amortizationLoan := ToAmortizationLoan(loan)
+	schedule := amortization.Amortize(amortizationLoan, ToAmortizationRepayments(repayments))
+
+	return LoanWithSchedule{
+		Loan:         loan,
+		Repayments:   repayments,
+		Payments:     payments,
+		Amortization: schedule,
+		Balance:      schedule.BalanceAt(amortizationLoan, now),
+	}
+}
+
+func ToAmortizationLoan(loan Loan) amortization.Loan {
+	principal, _ := strconv.ParseFloat(loan.Principal, 64)
+	rate, _ := strconv.ParseFloat(loan.InterestRate, 64)
+
+	return amortization.Loan{
+		Principal:    principal,
+		Rate:         rate,
+		Term:         int(loan.Term),
+		Schedule:     loan.Schedule,
+		FirstPayment: loan.FirstPaymentDate,
+	}
+}
+
+func ToAmortizationRepayments(repayments []LoanRepayment) []amortization.Repayment {
+	result := make([]amortization.Repayment, len(repayments))
+	for idx, repayment := range repayments {
+		amount, _ := strconv.ParseFloat(repayment.Amount, 64)
+		result[idx] = amortization.Repayment{
+			Date:   repayment.Date,
+			Amount: amount,
+		}
+	}
+
+	return result
+}
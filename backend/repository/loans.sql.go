@@ -0,0 +1,482 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: loans.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createLoan = `-- name: CreateLoan :one
+INSERT INTO loans (user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date, created, updated, version
+`
+
+type CreateLoanParams struct {
+	UserID           uuid.UUID
+	Name             string
+	Description      string
+	Principal        string
+	InterestRate     string
+	Term             int32
+	Schedule         string
+	Type             string
+	FirstPaymentDate time.Time
+}
+
+func (q *Queries) CreateLoan(ctx context.Context, arg CreateLoanParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, createLoan,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Principal,
+		arg.InterestRate,
+		arg.Term,
+		arg.Schedule,
+		arg.Type,
+		arg.FirstPaymentDate,
+	)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Principal,
+		&i.InterestRate,
+		&i.Term,
+		&i.Schedule,
+		&i.Type,
+		&i.FirstPaymentDate,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createLoanPayment = `-- name: CreateLoanPayment :exec
+INSERT INTO loan_payments (loan_id, number, date, principal_transaction_id, interest_transaction_id)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateLoanPaymentParams struct {
+	LoanID                 int32
+	Number                 int32
+	Date                   time.Time
+	PrincipalTransactionID sql.NullInt32
+	InterestTransactionID  sql.NullInt32
+}
+
+func (q *Queries) CreateLoanPayment(ctx context.Context, arg CreateLoanPaymentParams) error {
+	_, err := q.db.ExecContext(ctx, createLoanPayment,
+		arg.LoanID,
+		arg.Number,
+		arg.Date,
+		arg.PrincipalTransactionID,
+		arg.InterestTransactionID,
+	)
+	return err
+}
+
+const createLoanRepayment = `-- name: CreateLoanRepayment :one
+INSERT INTO loan_repayments (loan_id, transaction_id, date, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING id, loan_id, transaction_id, date, amount, created
+`
+
+type CreateLoanRepaymentParams struct {
+	LoanID        int32
+	TransactionID sql.NullInt32
+	Date          time.Time
+	Amount        string
+}
+
+func (q *Queries) CreateLoanRepayment(ctx context.Context, arg CreateLoanRepaymentParams) (LoanRepayment, error) {
+	row := q.db.QueryRowContext(ctx, createLoanRepayment,
+		arg.LoanID,
+		arg.TransactionID,
+		arg.Date,
+		arg.Amount,
+	)
+	var i LoanRepayment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.TransactionID,
+		&i.Date,
+		&i.Amount,
+		&i.Created,
+	)
+	return i, err
+}
+
+const deleteLoan = `-- name: DeleteLoan :execrows
+DELETE FROM loans
+WHERE id = $1 AND user_id = $2 AND ($3::int IS NULL OR version = $3::int)
+`
+
+type DeleteLoanParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
+}
+
+func (q *Queries) DeleteLoan(ctx context.Context, arg DeleteLoanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLoan, arg.ID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLoanRepayment = `-- name: DeleteLoanRepayment :one
+DELETE FROM loan_repayments
+WHERE id = $1 AND loan_id = $2
+RETURNING id, loan_id, transaction_id, date, amount, created
+`
+
+type DeleteLoanRepaymentParams struct {
+	ID     int32
+	LoanID int32
+}
+
+func (q *Queries) DeleteLoanRepayment(ctx context.Context, arg DeleteLoanRepaymentParams) (LoanRepayment, error) {
+	row := q.db.QueryRowContext(ctx, deleteLoanRepayment, arg.ID, arg.LoanID)
+	var i LoanRepayment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.TransactionID,
+		&i.Date,
+		&i.Amount,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getLoan = `-- name: GetLoan :one
+SELECT id, user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date, created, updated, version FROM loans
+WHERE id = $1 AND user_id = $2
+`
+
+type GetLoanParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetLoan(ctx context.Context, arg GetLoanParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, getLoan, arg.ID, arg.UserID)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Principal,
+		&i.InterestRate,
+		&i.Term,
+		&i.Schedule,
+		&i.Type,
+		&i.FirstPaymentDate,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getLoanPayments = `-- name: GetLoanPayments :many
+SELECT loan_id, number, date, principal_transaction_id, interest_transaction_id, created FROM loan_payments
+WHERE loan_id = $1
+ORDER BY number
+`
+
+func (q *Queries) GetLoanPayments(ctx context.Context, loanID int32) ([]LoanPayment, error) {
+	rows, err := q.db.QueryContext(ctx, getLoanPayments, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoanPayment
+	for rows.Next() {
+		var i LoanPayment
+		if err := rows.Scan(
+			&i.LoanID,
+			&i.Number,
+			&i.Date,
+			&i.PrincipalTransactionID,
+			&i.InterestTransactionID,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoanRepayments = `-- name: GetLoanRepayments :many
+SELECT id, loan_id, transaction_id, date, amount, created FROM loan_repayments
+WHERE loan_id = $1
+ORDER BY date, id
+`
+
+func (q *Queries) GetLoanRepayments(ctx context.Context, loanID int32) ([]LoanRepayment, error) {
+	rows, err := q.db.QueryContext(ctx, getLoanRepayments, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoanRepayment
+	for rows.Next() {
+		var i LoanRepayment
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.TransactionID,
+			&i.Date,
+			&i.Amount,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoans = `-- name: GetLoans :many
+SELECT id, user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date, created, updated, version FROM loans
+WHERE user_id = $1
+ORDER BY created, id
+`
+
+func (q *Queries) GetLoans(ctx context.Context, userID uuid.UUID) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, getLoans, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Loan
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Principal,
+			&i.InterestRate,
+			&i.Term,
+			&i.Schedule,
+			&i.Type,
+			&i.FirstPaymentDate,
+			&i.Created,
+			&i.Updated,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoansPayments = `-- name: GetLoansPayments :many
+SELECT lp.loan_id, lp.number, lp.date, lp.principal_transaction_id, lp.interest_transaction_id, lp.created FROM loan_payments lp
+JOIN loans l ON lp.loan_id = l.id
+WHERE l.user_id = $1
+ORDER BY lp.loan_id, lp.number
+`
+
+func (q *Queries) GetLoansPayments(ctx context.Context, userID uuid.UUID) ([]LoanPayment, error) {
+	rows, err := q.db.QueryContext(ctx, getLoansPayments, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoanPayment
+	for rows.Next() {
+		var i LoanPayment
+		if err := rows.Scan(
+			&i.LoanID,
+			&i.Number,
+			&i.Date,
+			&i.PrincipalTransactionID,
+			&i.InterestTransactionID,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoansRepayments = `-- name: GetLoansRepayments :many
+SELECT lr.id, lr.loan_id, lr.transaction_id, lr.date, lr.amount, lr.created FROM loan_repayments lr
+JOIN loans l ON lr.loan_id = l.id
+WHERE l.user_id = $1
+ORDER BY lr.date, lr.id
+`
+
+func (q *Queries) GetLoansRepayments(ctx context.Context, userID uuid.UUID) ([]LoanRepayment, error) {
+	rows, err := q.db.QueryContext(ctx, getLoansRepayments, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoanRepayment
+	for rows.Next() {
+		var i LoanRepayment
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.TransactionID,
+			&i.Date,
+			&i.Amount,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoansWithDuePayments = `-- name: GetLoansWithDuePayments :many
+SELECT l.id, l.user_id, l.name, l.description, l.principal, l.interest_rate, l.term, l.schedule, l.type, l.first_payment_date, l.created, l.updated, l.version FROM loans l
+WHERE l.first_payment_date <= $1::timestamp
+    AND l.term > COALESCE((SELECT MAX(lp.number) FROM loan_payments lp WHERE lp.loan_id = l.id), 0)
+ORDER BY l.id
+`
+
+func (q *Queries) GetLoansWithDuePayments(ctx context.Context, until time.Time) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, getLoansWithDuePayments, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Loan
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Principal,
+			&i.InterestRate,
+			&i.Term,
+			&i.Schedule,
+			&i.Type,
+			&i.FirstPaymentDate,
+			&i.Created,
+			&i.Updated,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLoan = `-- name: UpdateLoan :one
+UPDATE loans
+SET name = $3, description = $4, principal = $5, interest_rate = $6, term = $7, schedule = $8, type = $9, first_payment_date = $10,
+    updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND ($11::int IS NULL OR version = $11::int)
+RETURNING id, user_id, name, description, principal, interest_rate, term, schedule, type, first_payment_date, created, updated, version
+`
+
+type UpdateLoanParams struct {
+	ID               int32
+	UserID           uuid.UUID
+	Name             string
+	Description      string
+	Principal        string
+	InterestRate     string
+	Term             int32
+	Schedule         string
+	Type             string
+	FirstPaymentDate time.Time
+	Version          sql.NullInt32
+}
+
+func (q *Queries) UpdateLoan(ctx context.Context, arg UpdateLoanParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, updateLoan,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Principal,
+		arg.InterestRate,
+		arg.Term,
+		arg.Schedule,
+		arg.Type,
+		arg.FirstPaymentDate,
+		arg.Version,
+	)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Principal,
+		&i.InterestRate,
+		&i.Term,
+		&i.Schedule,
+		&i.Type,
+		&i.FirstPaymentDate,
+		&i.Created,
+		&i.Updated,
+		&i.Version,
+	)
+	return i, err
+}
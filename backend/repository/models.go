@@ -9,8 +9,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
+type AuditLog struct {
+	ID         int64
+	UserID     uuid.UUID
+	ActorID    uuid.NullUUID
+	RequestID  sql.NullString
+	EntityType string
+	EntityID   string
+	Action     string
+	Before     pqtype.NullRawMessage
+	After      pqtype.NullRawMessage
+	Created    time.Time
+}
+
 type Budget struct {
 	ID          string
 	UserID      uuid.UUID
@@ -21,16 +35,137 @@ type Budget struct {
 	EndDate     time.Time
 	Created     time.Time
 	Updated     time.Time
+	Deleted     sql.NullTime
+	Version     int32
+	Type        string
+	ZeroBased   bool
+	AnchorDate  time.Time
+}
+
+type BudgetAlert struct {
+	ID              int32
+	BudgetExpenseID int32
+	Threshold       int32
+	PeriodStart     time.Time
+	PercentageUsed  string
+	Created         time.Time
+}
+
+type BudgetEnvelopeMovement struct {
+	ID            int32
+	BudgetID      string
+	FromExpenseID sql.NullInt32
+	ToExpenseID   sql.NullInt32
+	Amount        string
+	Description   string
+	Date          time.Time
+	Created       time.Time
+}
+
+type BudgetExcludedTransaction struct {
+	BudgetID      string
+	TransactionID int32
+	Created       time.Time
 }
 
 type BudgetExpense struct {
+	ID               int32
+	BudgetID         string
+	Name             string
+	AllocatedAmount  string
+	Created          time.Time
+	Updated          time.Time
+	Version          int32
+	Rollover         string
+	RolloverAmount   string
+	RolloverPeriodID sql.NullInt32
+	Categories       []string
+	Tags             []string
+	Payees           []string
+	Thresholds       []int32
+}
+
+type BudgetPeriod struct {
+	ID        int32
+	BudgetID  string
+	StartDate time.Time
+	EndDate   time.Time
+	Amount    string
+	Created   time.Time
+}
+
+type BudgetPeriodExpense struct {
+	ID               int32
+	PeriodID         int32
+	BudgetExpenseID  sql.NullInt32
+	Name             string
+	AllocatedAmount  string
+	Rollover         string
+	RolloverAmount   string
+	RolloverPeriodID sql.NullInt32
+}
+
+type BudgetTemplate struct {
+	ID          int32
+	UserID      uuid.UUID
+	Name        string
+	Description string
+	Amount      string
+	Type        string
+	ZeroBased   bool
+	Created     time.Time
+}
+
+type BudgetTemplateExpense struct {
 	ID              int32
-	BudgetID        string
+	TemplateID      int32
 	Name            string
 	AllocatedAmount string
-	CurrentAmount   string
-	Created         time.Time
-	Updated         time.Time
+	Rollover        string
+	Categories      []string
+	Tags            []string
+	Payees          []string
+	Thresholds      []int32
+}
+
+type CalendarToken struct {
+	UserID    uuid.UUID
+	TokenHash string
+	Created   time.Time
+}
+
+type FullBudgetExpense struct {
+	ID                int32
+	BudgetID          string
+	Name              string
+	AllocatedAmount   string
+	Created           time.Time
+	Updated           time.Time
+	Version           int32
+	CurrentAmount     string
+	Rollover          string
+	RolloverAmount    string
+	RolloverPeriodID  sql.NullInt32
+	RolloverStartDate sql.NullTime
+	RolloverEndDate   sql.NullTime
+	Categories        []string
+	Tags              []string
+	Payees            []string
+	Thresholds        []int32
+}
+
+type FullBudgetPeriodExpense struct {
+	ID                int32
+	PeriodID          int32
+	BudgetExpenseID   sql.NullInt32
+	Name              string
+	AllocatedAmount   string
+	SpentAmount       string
+	Rollover          string
+	RolloverAmount    string
+	RolloverPeriodID  sql.NullInt32
+	RolloverStartDate sql.NullTime
+	RolloverEndDate   sql.NullTime
 }
 
 type FullTransaction struct {
@@ -45,25 +180,143 @@ type FullTransaction struct {
 	Date                   time.Time
 	Created                time.Time
 	Updated                time.Time
+	Deleted                sql.NullTime
+	Version                int32
 	StartDate              sql.NullTime
 	EndDate                sql.NullTime
 	Interval               sql.NullString
 	DaysInterval           sql.NullInt32
+	Rrule                  sql.NullString
 	RecurringCreated       sql.NullTime
 	RecurringUpdated       sql.NullTime
+	RecurringDeleted       sql.NullTime
 	BudgetName             sql.NullString
 	BudgetExpenseName      sql.NullString
+	Tags                   []string
 }
 
-type RecurringTransaction struct {
-	ID           int32
+type Goal struct {
+	ID                  int32
+	UserID              uuid.UUID
+	Name                string
+	Description         string
+	TargetAmount        string
+	InitialAmount       string
+	StartDate           time.Time
+	TargetDate          time.Time
+	Tags                []string
+	Created             time.Time
+	Updated             time.Time
+	Version             int32
+	InvestmentAccountID sql.NullInt32
+}
+
+type IdempotencyKey struct {
 	UserID       uuid.UUID
-	StartDate    time.Time
-	EndDate      time.Time
-	Interval     string
-	DaysInterval sql.NullInt32
+	Key          string
+	RequestHash  string
+	StatusCode   sql.NullInt32
+	ContentType  sql.NullString
+	ResponseBody []byte
 	Created      time.Time
-	Updated      time.Time
+}
+
+type InvestmentAccount struct {
+	ID              int32
+	UserID          uuid.UUID
+	Name            string
+	Description     string
+	CostBasisMethod string
+	Created         time.Time
+	Updated         time.Time
+	Version         int32
+}
+
+type InvestmentPrice struct {
+	UserID  uuid.UUID
+	Symbol  string
+	Date    time.Time
+	Price   string
+	Created time.Time
+}
+
+type InvestmentTrade struct {
+	ID        int32
+	AccountID int32
+	Symbol    string
+	Kind      string
+	Date      time.Time
+	Quantity  string
+	Price     string
+	Fees      string
+	Created   time.Time
+}
+
+type Loan struct {
+	ID               int32
+	UserID           uuid.UUID
+	Name             string
+	Description      string
+	Principal        string
+	InterestRate     string
+	Term             int32
+	Schedule         string
+	Type             string
+	FirstPaymentDate time.Time
+	Created          time.Time
+	Updated          time.Time
+	Version          int32
+}
+
+type LoanPayment struct {
+	LoanID                 int32
+	Number                 int32
+	Date                   time.Time
+	PrincipalTransactionID sql.NullInt32
+	InterestTransactionID  sql.NullInt32
+	Created                time.Time
+}
+
+type LoanRepayment struct {
+	ID            int32
+	LoanID        int32
+	TransactionID sql.NullInt32
+	Date          time.Time
+	Amount        string
+	Created       time.Time
+}
+
+type Notification struct {
+	ID      int32
+	UserID  uuid.UUID
+	Title   string
+	Message string
+	Read    sql.NullTime
+	Created time.Time
+}
+
+type RecurringTransaction struct {
+	ID                int32
+	UserID            uuid.UUID
+	StartDate         time.Time
+	EndDate           sql.NullTime
+	Interval          string
+	DaysInterval      sql.NullInt32
+	Created           time.Time
+	Updated           time.Time
+	Deleted           sql.NullTime
+	Rrule             string
+	Description       string
+	Amount            string
+	Type              string
+	MaterializedUntil time.Time
+}
+
+type RecurringTransactionException struct {
+	RecurringTransactionID int32
+	Date                   time.Time
+	TransactionID          sql.NullInt32
+	Created                time.Time
 }
 
 type Transaction struct {
@@ -78,15 +331,25 @@ type Transaction struct {
 	Date                   time.Time
 	Created                time.Time
 	Updated                time.Time
+	Deleted                sql.NullTime
+	Version                int32
+}
+
+type TransactionTag struct {
+	TransactionID int32
+	Tag           string
+	Created       time.Time
 }
 
 type User struct {
-	ID         uuid.UUID
-	Provider   string
-	ProviderID string
-	Username   string
-	Email      string
-	Avatar     sql.NullString
-	Created    time.Time
-	Updated    time.Time
+	ID              uuid.UUID
+	Provider        string
+	ProviderID      string
+	Username        string
+	Email           string
+	Avatar          sql.NullString
+	Created         time.Time
+	Updated         time.Time
+	WeekStart       int16
+	FiscalYearStart int16
 }
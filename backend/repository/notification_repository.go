@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type INotificationRepository interface {
+	Get(ctx context.Context, userId uuid.UUID) (*[]Notification, error)
+	MarkRead(ctx context.Context, userId uuid.UUID, id int32) error
+	MarkAllRead(ctx context.Context, userId uuid.UUID) (int64, error)
+}
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func CreateNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+func (repository *NotificationRepository) Get(ctx context.Context, userId uuid.UUID) (*[]Notification, error) {
+	db := New(repository.db)
+	notifications, err := db.GetNotifications(ctx, GetNotificationsParams{
+		UserID: userId,
+		Limit:  MaxFetchLimit,
+		Offset: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifications, nil
+}
+
+func (repository *NotificationRepository) MarkRead(ctx context.Context, userId uuid.UUID, id int32) error {
+	db := New(repository.db)
+	rows, err := db.MarkNotificationRead(ctx, MarkNotificationReadParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (repository *NotificationRepository) MarkAllRead(ctx context.Context, userId uuid.UUID) (int64, error) {
+	db := New(repository.db)
+	return db.MarkAllNotificationsRead(ctx, userId)
+}
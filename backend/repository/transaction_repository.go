@@ -3,46 +3,80 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/recurrence"
 )
 
 type ITransactionRepository interface {
 	GetById(ctx context.Context, userId uuid.UUID, id int32) (*Transaction, error)
+	GetFullById(ctx context.Context, userId uuid.UUID, id int32) (*FullTransaction, error)
 	GetByBudgetId(ctx context.Context, userId uuid.UUID, budgetId string) (*[]FullTransaction, error)
 
 	GetUnassignedBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]Transaction, error)
 
+	GetAll(ctx context.Context, userId uuid.UUID) (*[]FullTransaction, error)
 	GetBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error)
+	GetAllBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error)
 	GetIncomeBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error)
 	GetExpenseBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error)
 
 	GetAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]float64, error)
 	GetIncomeAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]TypeAmount, error)
 	GetExpenseAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]TypeAmount, error)
+	GetMonthlyAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]MonthAmount, error)
+	GetMonthlyTypeAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]MonthTypeAmount, error)
+	GetDailyAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams, by string) (*[]DayAmount, error)
+	GetBalanceBefore(ctx context.Context, userId uuid.UUID, before time.Time) (float64, error)
+	GetNonRecurringSince(ctx context.Context, userId uuid.UUID, since time.Time) (*[]Transaction, error)
 
 	Add(ctx context.Context, params CreateTransactionParams) (*Transaction, error)
 	Update(ctx context.Context, params UpdateTransactionParams) error
 	UpdateBudgetId(ctx context.Context, params UpdateTransactionBudgetIdParams) error
-	Remove(ctx context.Context, userId uuid.UUID, id int32) error
-	RemoveBudgetId(ctx context.Context, userId uuid.UUID, id int32) error
-
-	AddRecurring(ctx context.Context, params AddRecurringParams) error
-	UpdateRecurring(ctx context.Context, params UpdateRecurringParams) error
-	RemoveRecurring(ctx context.Context, userId uuid.UUID, id int32) error
+	Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error
+	RemoveBudgetId(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error
+
+	GetRecurring(ctx context.Context, userId uuid.UUID, id int32) (*RecurringTransaction, error)
+	AddRecurring(ctx context.Context, params CreateRecurringTransactionParams) error
+	UpdateRecurring(ctx context.Context, params UpdateRecurringTransactionParams, occurrence OccurrenceVersion) error
+	RemoveRecurring(ctx context.Context, userId uuid.UUID, id int32, occurrence OccurrenceVersion) error
+	SplitRecurring(ctx context.Context, params SplitRecurringParams) error
+	EndRecurring(ctx context.Context, userId uuid.UUID, id int32, date time.Time, occurrence OccurrenceVersion) error
+	Materialize(ctx context.Context) (int64, error)
+
+	DetectRecurring(ctx context.Context, userId uuid.UUID, since time.Time) ([]recurrence.Proposal, error)
+	ConvertToRecurring(ctx context.Context, params ConvertToRecurringParams) (*RecurringTransaction, error)
+
+	GetOccurrence(ctx context.Context, userId uuid.UUID, recurringTransactionId int32, date time.Time) (*Transaction, error)
+	UpdateOccurrence(ctx context.Context, occurrence Transaction, params UpdateTransactionParams) (int32, error)
+	RemoveOccurrence(ctx context.Context, occurrence Transaction, version sql.NullInt32) error
+
+	GetDeleted(ctx context.Context, userId uuid.UUID) (*[]FullTransaction, error)
+	Restore(ctx context.Context, userId uuid.UUID, id int32) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	Bulk(ctx context.Context, params BulkParams) (*BulkResult, error)
+	GetTags(ctx context.Context, userId uuid.UUID) (*[]string, error)
 }
 
 type TransactionRepository struct {
 	db *sql.DB
+	// horizon is how far ahead occurrences of recurring transactions are
+	// stored. Later occurrences are projected when they are queried.
+	horizon time.Duration
 }
 
-func CreateTransactionRepository(db *sql.DB) *TransactionRepository {
+func CreateTransactionRepository(db *sql.DB, horizon time.Duration) *TransactionRepository {
 	return &TransactionRepository{
-		db: db,
+		db:      db,
+		horizon: horizon,
 	}
 }
 
@@ -55,6 +89,15 @@ func (repository *TransactionRepository) GetById(ctx context.Context, userId uui
 	return &transaction, err
 }
 
+func (repository *TransactionRepository) GetFullById(ctx context.Context, userId uuid.UUID, id int32) (*FullTransaction, error) {
+	db := New(repository.db)
+	transaction, err := db.GetFullTransactionById(ctx, GetFullTransactionByIdParams{
+		UserID: userId,
+		ID:     id,
+	})
+	return &transaction.FullTransaction, err
+}
+
 func (repository *TransactionRepository) GetByBudgetId(ctx context.Context, userId uuid.UUID, budgetId string) (*[]FullTransaction, error) {
 	db := New(repository.db)
 	transactions, err := db.GetTransactionsByBudgetId(ctx, GetTransactionsByBudgetIdParams{
@@ -95,6 +138,23 @@ func (repository *TransactionRepository) GetUnassignedBetweenDates(ctx context.C
 	return &transactions, err
 }
 
+// GetAll returns all transactions of a user that are not in the trash, without
+// a limit and without projected occurrences.
+func (repository *TransactionRepository) GetAll(ctx context.Context, userId uuid.UUID) (*[]FullTransaction, error) {
+	db := New(repository.db)
+	transactions, err := db.GetAllTransactions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	fullTransactions := make([]FullTransaction, len(transactions))
+	for idx, transaction := range transactions {
+		fullTransactions[idx] = transaction.FullTransaction
+	}
+
+	return &fullTransactions, nil
+}
+
 func (repository *TransactionRepository) GetBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error) {
 	db := New(repository.db)
 	transactions, err := db.GetTransactionsBetweenDates(ctx, GetTransactionsBetweenDatesParams{
@@ -113,7 +173,46 @@ func (repository *TransactionRepository) GetBetweenDates(ctx context.Context, pa
 		fullTransactions[idx] = transaction.FullTransaction
 	}
 
-	return &fullTransactions, err
+	fullTransactions, err = withProjectedTransactions(ctx, db, fullTransactions, params, anyAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullTransactions, nil
+}
+
+// GetAllBetweenDates returns every transaction between the dates, where
+// GetBetweenDates stops at the fetch limit, so a running balance over the
+// transactions is not cut off.
+func (repository *TransactionRepository) GetAllBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error) {
+	db := New(repository.db)
+	fullTransactions := []FullTransaction{}
+	for {
+		page, err := db.GetTransactionsBetweenDates(ctx, GetTransactionsBetweenDatesParams{
+			UserID:    params.UserID,
+			StartDate: params.Start,
+			EndDate:   params.End,
+			Limit:     MaxFetchLimit,
+			Offset:    int32(len(fullTransactions)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, transaction := range page {
+			fullTransactions = append(fullTransactions, transaction.FullTransaction)
+		}
+		if len(page) < MaxFetchLimit {
+			break
+		}
+	}
+
+	fullTransactions, err := withProjectedTransactions(ctx, db, fullTransactions, params, anyAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullTransactions, nil
 }
 
 func (repository *TransactionRepository) GetIncomeBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error) {
@@ -134,7 +233,12 @@ func (repository *TransactionRepository) GetIncomeBetweenDates(ctx context.Conte
 		fullTransactions[idx] = transaction.FullTransaction
 	}
 
-	return &fullTransactions, err
+	fullTransactions, err = withProjectedTransactions(ctx, db, fullTransactions, params, isIncome)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullTransactions, nil
 }
 
 func (repository *TransactionRepository) GetExpenseBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]FullTransaction, error) {
@@ -155,7 +259,12 @@ func (repository *TransactionRepository) GetExpenseBetweenDates(ctx context.Cont
 		fullTransactions[idx] = transaction.FullTransaction
 	}
 
-	return &fullTransactions, err
+	fullTransactions, err = withProjectedTransactions(ctx, db, fullTransactions, params, isExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullTransactions, nil
 }
 
 func (repository *TransactionRepository) GetAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]float64, error) {
@@ -169,6 +278,14 @@ func (repository *TransactionRepository) GetAmountsBetweenDates(ctx context.Cont
 		return nil, err
 	}
 
+	projected, err := getProjectedTransactions(ctx, db, params.UserID, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range projected {
+		amounts = append(amounts, transaction.Amount)
+	}
+
 	floats := make([]float64, len(amounts))
 	for idx, typeAmount := range amounts {
 		value, err := strconv.ParseFloat(typeAmount, 64)
@@ -193,6 +310,17 @@ func (repository *TransactionRepository) GetIncomeAmountsBetweenDates(ctx contex
 		return nil, err
 	}
 
+	projected, err := withProjectedTransactions(ctx, db, nil, params, isIncome)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range projected {
+		typeAmounts = append(typeAmounts, GetIncomeTransactionAmountsBetweenDatesRow{
+			Amount: transaction.Amount,
+			Type:   transaction.Type,
+		})
+	}
+
 	returnValues := make([]TypeAmount, len(typeAmounts))
 	for idx, typeAmount := range typeAmounts {
 		value, err := strconv.ParseFloat(typeAmount.Amount, 64)
@@ -220,6 +348,17 @@ func (repository *TransactionRepository) GetExpenseAmountsBetweenDates(ctx conte
 		return nil, err
 	}
 
+	projected, err := withProjectedTransactions(ctx, db, nil, params, isExpense)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range projected {
+		typeAmounts = append(typeAmounts, GetExpenseTransactionAmountsBetweenDatesRow{
+			Amount: transaction.Amount,
+			Type:   transaction.Type,
+		})
+	}
+
 	returnValues := make([]TypeAmount, len(typeAmounts))
 	for idx, typeAmount := range typeAmounts {
 		value, err := strconv.ParseFloat(typeAmount.Amount, 64)
@@ -236,288 +375,1978 @@ func (repository *TransactionRepository) GetExpenseAmountsBetweenDates(ctx conte
 	return &returnValues, nil
 }
 
-func (repository *TransactionRepository) Add(ctx context.Context, params CreateTransactionParams) (*Transaction, error) {
+// GetMonthlyAmountsBetweenDates returns the income and expense per month,
+// including the projected transactions, ordered by month. Months without
+// transactions are left out.
+func (repository *TransactionRepository) GetMonthlyAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]MonthAmount, error) {
 	db := New(repository.db)
-	transaction, err := db.CreateTransaction(ctx, params)
-	return &transaction, err
-}
+	rows, err := db.GetMonthlyTransactionAmountsBetweenDates(ctx, GetMonthlyTransactionAmountsBetweenDatesParams{
+		UserID:    params.UserID,
+		StartDate: params.Start,
+		EndDate:   params.End,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (repository *TransactionRepository) Update(ctx context.Context, params UpdateTransactionParams) error {
-	db := New(repository.db)
-	return db.UpdateTransaction(ctx, params)
-}
+	projected, err := getProjectedTransactions(ctx, db, params.UserID, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
 
-func (repository *TransactionRepository) UpdateBudgetId(ctx context.Context, params UpdateTransactionBudgetIdParams) error {
-	db := New(repository.db)
-	return db.UpdateTransactionBudgetId(ctx, params)
-}
+	monthAmounts := make([]MonthAmount, 0, len(rows))
+	months := make(map[time.Time]int, len(rows))
+	for _, row := range rows {
+		months[row.Month] = len(monthAmounts)
+		monthAmounts = append(monthAmounts, MonthAmount{
+			Month:   row.Month,
+			Income:  parseAmount(row.Income),
+			Expense: parseAmount(row.Expense),
+		})
+	}
 
-func (repository *TransactionRepository) Remove(ctx context.Context, userId uuid.UUID, id int32) error {
-	db := New(repository.db)
-	return db.DeleteTransaction(ctx, DeleteTransactionParams{
-		ID:     id,
-		UserID: userId,
-	})
-}
+	for _, transaction := range projected {
+		month := truncateMonth(transaction.Date)
+		idx, ok := months[month]
+		if !ok {
+			idx = len(monthAmounts)
+			months[month] = idx
+			monthAmounts = append(monthAmounts, MonthAmount{Month: month})
+		}
 
-func (repository *TransactionRepository) RemoveBudgetId(ctx context.Context, userId uuid.UUID, id int32) error {
-	db := New(repository.db)
-	return db.RemoveTransactionBudgetId(ctx, RemoveTransactionBudgetIdParams{
-		ID:     id,
-		UserID: userId,
+		amount := parseAmount(transaction.Amount)
+		if isIncome(amount) {
+			monthAmounts[idx].Income += amount
+		} else {
+			monthAmounts[idx].Expense += amount
+		}
+	}
+
+	slices.SortFunc(monthAmounts, func(a MonthAmount, b MonthAmount) int {
+		return a.Month.Compare(b.Month)
 	})
-}
 
-type AddRecurringParams struct {
-	Params      CreateRecurringTransactionParams
-	Amount      float64
-	Description string
-	Type        string
+	return &monthAmounts, nil
 }
 
-func (repository *TransactionRepository) AddRecurring(ctx context.Context, params AddRecurringParams) error {
+// GetMonthlyTypeAmountsBetweenDates returns the income and expense per month
+// and transaction type, including the projected transactions, ordered by
+// month and type.
+func (repository *TransactionRepository) GetMonthlyTypeAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams) (*[]MonthTypeAmount, error) {
 	db := New(repository.db)
-	recurringTransaction, err := db.CreateRecurringTransaction(ctx, params.Params)
+	rows, err := db.GetMonthlyTypeTransactionAmountsBetweenDates(ctx, GetMonthlyTypeTransactionAmountsBetweenDatesParams{
+		UserID:    params.UserID,
+		StartDate: params.Start,
+		EndDate:   params.End,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	amountString := strconv.FormatFloat(params.Amount, 'f', -1, 64)
-	createParams := getRecurringTransactions(getRecurringTransactionsParams{
-		UserID:                 params.Params.UserID,
-		RecurringTransactionId: recurringTransaction.ID,
-		Description:            params.Description,
-		Amount:                 amountString,
-		Type:                   params.Type,
-		StartDate:              recurringTransaction.StartDate,
-		EndDate:                recurringTransaction.EndDate,
-		Interval:               recurringTransaction.Interval,
-		DaysInterval:           recurringTransaction.DaysInterval.Int32,
-	})
+	projected, err := getProjectedTransactions(ctx, db, params.UserID, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, params := range createParams {
-		_, err := repository.Add(ctx, params)
-		if err != nil {
-			return err
-		}
+	type monthType struct {
+		month time.Time
+		typ   string
 	}
 
-	return err
-}
+	monthTypeAmounts := make([]MonthTypeAmount, 0, len(rows))
+	monthTypes := make(map[monthType]int, len(rows))
+	for _, row := range rows {
+		monthTypes[monthType{row.Month, row.Type}] = len(monthTypeAmounts)
+		monthTypeAmounts = append(monthTypeAmounts, MonthTypeAmount{
+			Month:   row.Month,
+			Type:    row.Type,
+			Income:  parseAmount(row.Income),
+			Expense: parseAmount(row.Expense),
+		})
+	}
 
-type UpdateRecurringParams struct {
-	Params      UpdateRecurringTransactionParams
-	Amount      float64
-	Description string
-	Type        string
-}
+	for _, transaction := range projected {
+		key := monthType{truncateMonth(transaction.Date), transaction.Type}
+		idx, ok := monthTypes[key]
+		if !ok {
+			idx = len(monthTypeAmounts)
+			monthTypes[key] = idx
+			monthTypeAmounts = append(monthTypeAmounts, MonthTypeAmount{Month: key.month, Type: key.typ})
+		}
 
-func (repository *TransactionRepository) UpdateRecurring(ctx context.Context, params UpdateRecurringParams) error {
-	db := New(repository.db)
+		amount := parseAmount(transaction.Amount)
+		if isIncome(amount) {
+			monthTypeAmounts[idx].Income += amount
+		} else {
+			monthTypeAmounts[idx].Expense += amount
+		}
+	}
 
-	userId := params.Params.UserID
-	recurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
-		UserID: userId,
-		ID:     params.Params.ID,
+	slices.SortFunc(monthTypeAmounts, func(a MonthTypeAmount, b MonthTypeAmount) int {
+		if cmp := a.Month.Compare(b.Month); cmp != 0 {
+			return cmp
+		}
+		return strings.Compare(a.Type, b.Type)
 	})
-	if err != nil {
-		return err
-	}
 
-	amountString := strconv.FormatFloat(params.Amount, 'f', -1, 64)
-	startDate := params.Params.StartDate
-	endDate := params.Params.EndDate
-	interval := params.Params.Interval
-	daysInterval := params.Params.DaysInterval
+	return &monthTypeAmounts, nil
+}
+
+// GetDailyAmountsBetweenDates returns the income and expense per day and
+// group, including the projected transactions. Transactions are grouped by
+// type, payee, budget or tag. A transaction with multiple tags counts towards
+// each of its tags, and transactions without a tag or budget are grouped
+// under an empty key.
+func (repository *TransactionRepository) GetDailyAmountsBetweenDates(ctx context.Context, params GetBetweenDatesParams, by string) (*[]DayAmount, error) {
+	db := New(repository.db)
 
-	if recurringTransaction.Interval != interval || recurringTransaction.StartDate.UTC() != startDate {
-		nrows, err := db.DeleteTransactionsByRecurringTransactionId(ctx, DeleteTransactionsByRecurringTransactionIdParams{
-			UserID:                 userId,
-			RecurringTransactionID: recurringTransaction.ID,
+	var dayAmounts []DayAmount
+	if by == SummaryByTag {
+		rows, err := db.GetDailyTagTransactionAmounts(ctx, GetDailyTagTransactionAmountsParams{
+			UserID:    params.UserID,
+			StartDate: params.Start,
+			EndDate:   params.End,
 		})
 		if err != nil {
-			log.Error(fmt.Sprintf("Error deleting recurring transactions: %v", err.Error()))
-			return err
+			return nil, err
 		}
-
-		log.Infof("Successfully deleted %d transactions on updating recurring transaction %d", nrows, recurringTransaction.ID)
-
-		createParams := getRecurringTransactions(getRecurringTransactionsParams{
-			UserID:                 userId,
-			RecurringTransactionId: recurringTransaction.ID,
-			Description:            params.Description,
-			Amount:                 amountString,
-			Type:                   params.Type,
-			StartDate:              startDate,
-			EndDate:                endDate,
-			Interval:               interval,
-			DaysInterval:           daysInterval.Int32,
-		})
-
-		for _, params := range createParams {
-			_, err := repository.Add(ctx, params)
-			if err != nil {
-				log.Error(fmt.Sprintf("Error creating transaction: %v", err.Error()))
-				return err
-			}
+		for _, row := range rows {
+			dayAmounts = append(dayAmounts, DayAmount{
+				Date:    row.Day,
+				Key:     row.Key,
+				Income:  parseAmount(row.Income),
+				Expense: parseAmount(row.Expense),
+			})
 		}
 	} else {
-		transactions, err := db.GetTransactionsByRecurringTransactionId(ctx, GetTransactionsByRecurringTransactionIdParams{
-			UserID:                 userId,
-			RecurringTransactionID: params.Params.ID,
+		rows, err := db.GetDailyTransactionAmounts(ctx, GetDailyTransactionAmountsParams{
+			UserID:    params.UserID,
+			GroupBy:   by,
+			StartDate: params.Start,
+			EndDate:   params.End,
 		})
 		if err != nil {
-			log.Error(fmt.Sprintf("Error getting recurring transactions: %v", err.Error()))
-			return err
+			return nil, err
 		}
-
-		if endDate.After(recurringTransaction.EndDate) {
-			createParams := getRecurringTransactions(getRecurringTransactionsParams{
-				UserID:                 userId,
-				RecurringTransactionId: recurringTransaction.ID,
-				Description:            params.Description,
-				Amount:                 amountString,
-				Type:                   params.Type,
-				StartDate:              transactions[len(transactions)-1].Date,
-				EndDate:                endDate,
-				Interval:               interval,
-				DaysInterval:           daysInterval.Int32,
-			})
-
-			for idx := 1; idx < len(createParams); idx++ {
-				_, err := repository.Add(ctx, createParams[idx])
-				if err != nil {
-					log.Error(fmt.Sprintf("Error creating transaction: %v", err.Error()))
-					return err
-				}
-			}
-		} else if endDate.Before(recurringTransaction.EndDate) {
-			nrows, err := db.DeleteTransactionsByRecurringTransactionIdAndWhereDate(ctx, DeleteTransactionsByRecurringTransactionIdAndWhereDateParams{
-				UserID:                 userId,
-				RecurringTransactionID: recurringTransaction.ID,
-				Date:                   endDate,
+		for _, row := range rows {
+			dayAmounts = append(dayAmounts, DayAmount{
+				Date:    row.Day,
+				Key:     row.Key,
+				Income:  parseAmount(row.Income),
+				Expense: parseAmount(row.Expense),
 			})
-			if err != nil {
-				log.Error(fmt.Sprintf("Error deleting transactions after new end date: %v", err.Error()))
-				return err
-			}
+		}
+	}
 
-			log.Infof("Successfully deleted %d transactions that were after new end date", nrows, recurringTransaction.ID)
-
-			for idx := len(transactions) - 1; idx >= 0; idx-- {
-				if transactions[idx].Date.After(endDate) {
-					err := repository.Remove(ctx, userId, transactions[idx].ID)
-					if err != nil {
-						log.Error(fmt.Sprintf("Error delete transaction: %v", err.Error()))
-						return err
-					}
-				} else {
-					break
-				}
-			}
+	projected, err := getProjectedTransactions(ctx, db, params.UserID, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range projected {
+		// Projected transactions have no budget and no tags
+		key := ""
+		switch by {
+		case SummaryByType:
+			key = transaction.Type
+		case SummaryByPayee:
+			key = transaction.Description
 		}
 
-		if transactions[0].Description != params.Description || transactions[0].Amount != amountString || transactions[0].Type != params.Type {
-			for _, transaction := range transactions {
-				err := db.UpdateTransaction(ctx, UpdateTransactionParams{
-					ID:          transaction.ID,
-					UserID:      userId,
-					Amount:      amountString,
-					Description: params.Description,
-					Type:        params.Type,
-					Date:        transaction.Date,
-				})
-				if err != nil {
-					log.Error(fmt.Sprintf("Error updating transaction: %v", err.Error()))
-					return err
-				}
-			}
+		dayAmount := DayAmount{
+			Date: transaction.Date,
+			Key:  key,
 		}
+		amount := parseAmount(transaction.Amount)
+		if isIncome(amount) {
+			dayAmount.Income = amount
+		} else {
+			dayAmount.Expense = amount
+		}
+		dayAmounts = append(dayAmounts, dayAmount)
+	}
+
+	if dayAmounts == nil {
+		dayAmounts = []DayAmount{}
 	}
 
-	return db.UpdateRecurringTransaction(ctx, params.Params)
+	return &dayAmounts, nil
 }
 
-func (repository *TransactionRepository) RemoveRecurring(ctx context.Context, userId uuid.UUID, id int32) error {
+// GetBalanceBefore returns the sum of all transactions before a date.
+func (repository *TransactionRepository) GetBalanceBefore(ctx context.Context, userId uuid.UUID, before time.Time) (float64, error) {
 	db := New(repository.db)
-	return db.DeleteRecurringTransaction(ctx, DeleteRecurringTransactionParams{
-		ID:     id,
+	balance, err := db.GetBalanceBefore(ctx, GetBalanceBeforeParams{
 		UserID: userId,
+		Before: before,
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(balance, 64)
 }
 
-type getRecurringTransactionsParams struct {
-	UserID                 uuid.UUID
-	RecurringTransactionId int32
-	Description            string
-	Amount                 string
-	Type                   string
-	StartDate              time.Time
-	EndDate                time.Time
-	Interval               string
-	DaysInterval           int32
+// GetNonRecurringSince returns the transactions since a date that are not
+// part of a recurring series.
+func (repository *TransactionRepository) GetNonRecurringSince(ctx context.Context, userId uuid.UUID, since time.Time) (*[]Transaction, error) {
+	db := New(repository.db)
+	transactions, err := db.GetNonRecurringTransactionsSince(ctx, GetNonRecurringTransactionsSinceParams{
+		UserID: userId,
+		Since:  since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions, nil
 }
 
-func getRecurringTransactions(params getRecurringTransactionsParams) []CreateTransactionParams {
-	date := params.StartDate
-	endDate := params.EndDate
+func (repository *TransactionRepository) Add(ctx context.Context, params CreateTransactionParams) (*Transaction, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	var createParams []CreateTransactionParams
-	for date.Before(endDate) {
-		createParams = append(createParams, CreateTransactionParams{
-			UserID:                 params.UserID,
-			RecurringTransactionID: sql.NullInt32{Valid: true, Int32: params.RecurringTransactionId},
-			Amount:                 params.Amount,
-			Description:            params.Description,
-			Type:                   params.Type,
-			Date:                   date,
-		})
+	db := New(repository.db).WithTx(tx)
+	transaction, err := db.CreateTransaction(ctx, params)
+	if err != nil {
+		return nil, err
+	}
 
-		switch params.Interval {
-		case TransactionIntervalDaily:
-			date = addDays(date, 1)
-		case TransactionIntervalWeekly:
-			date = addWeeks(date, 1)
-		case TransactionIntervalMonthly:
-			date = addMonths(date, 1)
-		case TransactionIntervalOther:
-			date = addDays(date, int(params.DaysInterval))
-		}
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     transaction.UserID,
+		EntityType: AuditEntityTransaction,
+		EntityID:   int32ToString(transaction.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotTransaction(transaction),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return createParams
+	return &transaction, tx.Commit()
+}
+
+func (repository *TransactionRepository) Update(ctx context.Context, params UpdateTransactionParams) error {
+	return repository.updateTransaction(ctx, params.UserID, params.ID, func(db *Queries) (int64, error) {
+		return db.UpdateTransaction(ctx, params)
+	})
 }
 
-func addDays(date time.Time, days int) time.Time {
-	return date.AddDate(0, 0, days)
+func (repository *TransactionRepository) UpdateBudgetId(ctx context.Context, params UpdateTransactionBudgetIdParams) error {
+	return repository.updateTransaction(ctx, params.UserID, params.ID, func(db *Queries) (int64, error) {
+		return db.UpdateTransactionBudgetId(ctx, params)
+	})
 }
 
-func addWeeks(date time.Time, weeks int) time.Time {
-	return date.AddDate(0, 0, weeks*7)
+func (repository *TransactionRepository) Remove(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error {
+	return repository.updateTransaction(ctx, userId, id, func(db *Queries) (int64, error) {
+		return db.DeleteTransaction(ctx, DeleteTransactionParams{
+			ID:      id,
+			UserID:  userId,
+			Version: version,
+		})
+	})
 }
 
-func addMonths(date time.Time, months int) time.Time {
-	return date.AddDate(0, months, 0)
+func (repository *TransactionRepository) RemoveBudgetId(ctx context.Context, userId uuid.UUID, id int32, version sql.NullInt32) error {
+	return repository.updateTransaction(ctx, userId, id, func(db *Queries) (int64, error) {
+		return db.RemoveTransactionBudgetId(ctx, RemoveTransactionBudgetIdParams{
+			ID:      id,
+			UserID:  userId,
+			Version: version,
+		})
+	})
 }
 
-type TypeAmount struct {
-	Type   string
-	Amount float64
+// updateTransaction runs a change to a single transaction and records the
+// transaction before and after the change in the audit log. The change is
+// expected to report the number of updated rows; since the transaction exists,
+// no updated rows means its version did not match.
+func (repository *TransactionRepository) updateTransaction(ctx context.Context, userId uuid.UUID, id int32, update func(db *Queries) (int64, error)) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	err = applyTransactionUpdate(ctx, db, userId, id, update)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyTransactionUpdate runs the update of a transaction and audits it.
+func applyTransactionUpdate(ctx context.Context, db *Queries, userId uuid.UUID, id int32, update func(db *Queries) (int64, error)) error {
+	before, err := db.GetTransactionById(ctx, GetTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	nrows, err := update(db)
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrVersionConflict
+	}
+
+	after, err := db.GetTransactionsByIds(ctx, GetTransactionsByIdsParams{
+		UserID: userId,
+		Ids:    []int32{id},
+	})
+	if err != nil {
+		return err
+	}
+
+	action := AuditActionUpdate
+	if len(after) == 1 && after[0].Deleted.Valid {
+		action = AuditActionDelete
+	}
+
+	entry := auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityTransaction,
+		EntityID:   int32ToString(id),
+		Action:     action,
+		Before:     snapshotTransaction(before),
+	}
+	if len(after) == 1 {
+		entry.After = snapshotTransaction(after[0])
+	}
+
+	return writeAudit(ctx, db, entry)
+}
+
+func (repository *TransactionRepository) GetRecurring(ctx context.Context, userId uuid.UUID, id int32) (*RecurringTransaction, error) {
+	db := New(repository.db)
+	recurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		UserID: userId,
+		ID:     id,
+	})
+	return &recurringTransaction, err
+}
+
+func (repository *TransactionRepository) AddRecurring(ctx context.Context, params CreateRecurringTransactionParams) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	recurringTransaction, err := db.CreateRecurringTransaction(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	err = materializeRecurringTransaction(ctx, db, recurringTransaction, repository.horizonEnd())
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     recurringTransaction.UserID,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(recurringTransaction.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotRecurringTransaction(recurringTransaction),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// OccurrenceVersion is the occurrence a change to a whole series or to the
+// following occurrences is made from, with the version the change expects it
+// to have.
+type OccurrenceVersion struct {
+	TransactionID int32
+	Version       sql.NullInt32
+}
+
+// lockOccurrence locks the occurrence a series change is made from and checks
+// its version inside the database transaction of the change, so the change
+// fails with ErrVersionConflict when the occurrence changed in the meantime.
+func lockOccurrence(ctx context.Context, db *Queries, userId uuid.UUID, occurrence OccurrenceVersion) error {
+	// A projected occurrence is not stored, so there is nothing to lock
+	if occurrence.TransactionID == 0 {
+		return nil
+	}
+	_, err := db.LockTransactionVersion(ctx, LockTransactionVersionParams{
+		ID:      occurrence.TransactionID,
+		UserID:  userId,
+		Version: occurrence.Version,
+	})
+	if NoRowsFound(err) {
+		return ErrVersionConflict
+	}
+	return err
+}
+
+func (repository *TransactionRepository) UpdateRecurring(ctx context.Context, params UpdateRecurringTransactionParams, occurrence OccurrenceVersion) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+
+	userId := params.UserID
+	err = lockOccurrence(ctx, db, userId, occurrence)
+	if err != nil {
+		return err
+	}
+	recurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		UserID: userId,
+		ID:     params.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	transactions, err := db.GetTransactionsByRecurringTransactionId(ctx, GetTransactionsByRecurringTransactionIdParams{
+		UserID:                 userId,
+		RecurringTransactionID: params.ID,
+	})
+	if err != nil {
+		log.Error(fmt.Sprintf("Error getting recurring transactions: %v", err.Error()))
+		return err
+	}
+
+	materializedUntil := recurringTransaction.MaterializedUntil
+
+	if recurringTransaction.Rrule != params.Rrule || recurringTransaction.StartDate.UTC() != params.StartDate {
+		deleted, err := db.DeleteTransactionsByRecurringTransactionId(ctx, DeleteTransactionsByRecurringTransactionIdParams{
+			UserID:                 userId,
+			RecurringTransactionID: recurringTransaction.ID,
+		})
+		if err != nil {
+			log.Error(fmt.Sprintf("Error deleting recurring transactions: %v", err.Error()))
+			return err
+		}
+
+		err = writeTransactionsDeletedAudit(ctx, db, deleted)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Successfully deleted %d transactions on updating recurring transaction %d", len(deleted), recurringTransaction.ID)
+
+		err = db.DeleteRecurringTransactionExceptions(ctx, DeleteRecurringTransactionExceptionsParams{
+			RecurringTransactionID: recurringTransaction.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		materializedUntil = params.StartDate
+	} else {
+		if params.EndDate.Valid && params.EndDate.Time.Before(materializedUntil) {
+			deleted, err := db.DeleteTransactionsByRecurringTransactionIdAndWhereDate(ctx, DeleteTransactionsByRecurringTransactionIdAndWhereDateParams{
+				UserID:                 userId,
+				RecurringTransactionID: recurringTransaction.ID,
+				Date:                   params.EndDate.Time,
+			})
+			if err != nil {
+				log.Error(fmt.Sprintf("Error deleting transactions after new end date: %v", err.Error()))
+				return err
+			}
+
+			err = writeTransactionsDeletedAudit(ctx, db, deleted)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("Successfully deleted %d transactions of recurring transaction %d that were after new end date", len(deleted), recurringTransaction.ID)
+
+			err = db.DeleteRecurringTransactionExceptions(ctx, DeleteRecurringTransactionExceptionsParams{
+				RecurringTransactionID: recurringTransaction.ID,
+				FromDate:               params.EndDate.Time,
+			})
+			if err != nil {
+				return err
+			}
+
+			materializedUntil = params.EndDate.Time
+		}
+
+		if recurringTransaction.Description != params.Description || recurringTransaction.Amount != params.Amount || recurringTransaction.Type != params.Type {
+			exceptions, err := db.GetRecurringTransactionExceptions(ctx, recurringTransaction.ID)
+			if err != nil {
+				return err
+			}
+
+			var updatedIds []int32
+			for _, transaction := range transactions {
+				if params.EndDate.Valid && !transaction.Date.Before(params.EndDate.Time) {
+					continue
+				}
+				// Occurrences that were changed on their own keep their changes
+				if slices.ContainsFunc(exceptions, func(exception RecurringTransactionException) bool {
+					return exception.TransactionID.Valid && exception.TransactionID.Int32 == transaction.ID
+				}) {
+					continue
+				}
+
+				_, err := db.UpdateTransaction(ctx, UpdateTransactionParams{
+					ID:          transaction.ID,
+					UserID:      userId,
+					Amount:      params.Amount,
+					Description: params.Description,
+					Type:        params.Type,
+					Date:        transaction.Date,
+				})
+				if err != nil {
+					log.Error(fmt.Sprintf("Error updating transaction: %v", err.Error()))
+					return err
+				}
+				updatedIds = append(updatedIds, transaction.ID)
+			}
+
+			updated, err := db.GetTransactionsByIds(ctx, GetTransactionsByIdsParams{
+				UserID: userId,
+				Ids:    updatedIds,
+			})
+			if err != nil {
+				return err
+			}
+			err = writeTransactionsAudit(ctx, db, transactions, updated)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = db.UpdateRecurringTransaction(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	err = db.SetRecurringTransactionMaterializedUntil(ctx, SetRecurringTransactionMaterializedUntilParams{
+		ID:                recurringTransaction.ID,
+		MaterializedUntil: materializedUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	updatedRecurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		UserID: userId,
+		ID:     params.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = materializeRecurringTransaction(ctx, db, updatedRecurringTransaction, repository.horizonEnd())
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(recurringTransaction.ID),
+		Action:     AuditActionUpdate,
+		Before:     snapshotRecurringTransaction(recurringTransaction),
+		After:      snapshotRecurringTransaction(updatedRecurringTransaction),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *TransactionRepository) RemoveRecurring(ctx context.Context, userId uuid.UUID, id int32, occurrence OccurrenceVersion) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	err = lockOccurrence(ctx, db, userId, occurrence)
+	if err != nil {
+		return err
+	}
+	recurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	deleted, err := db.DeleteRecurringTransaction(ctx, DeleteRecurringTransactionParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The occurrences share the deletion timestamp of the series so restoring the
+	// series does not bring back occurrences that were deleted on their own
+	occurrences, err := db.DeleteTransactionsByRecurringTransactionIdSoft(ctx, DeleteTransactionsByRecurringTransactionIdSoftParams{
+		UserID:                 userId,
+		RecurringTransactionID: id,
+		Deleted:                deleted.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeTransactionsDeletedAudit(ctx, db, occurrences)
+	if err != nil {
+		return err
+	}
+
+	after := recurringTransaction
+	after.Deleted = deleted
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(id),
+		Action:     AuditActionDelete,
+		Before:     snapshotRecurringTransaction(recurringTransaction),
+		After:      snapshotRecurringTransaction(after),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type SplitRecurringParams struct {
+	UserID uuid.UUID
+	ID     int32
+	// Date is the first occurrence that belongs to the new series
+	Date       time.Time
+	Params     CreateRecurringTransactionParams
+	Occurrence OccurrenceVersion
+}
+
+// ErrSplitBeforeDate is returned when the series continuing a split series
+// would start before the occurrence it was split at.
+var ErrSplitBeforeDate = errors.New("split series starts before the split date")
+
+// SplitRecurring ends a series before date and continues it as a new series
+// created from params. This changes an occurrence and all that follow it. The
+// new series starts at the occurrence, or later when params start on a later
+// day.
+func (repository *TransactionRepository) SplitRecurring(ctx context.Context, params SplitRecurringParams) error {
+	splitDay := truncateDay(params.Date)
+	startDay := truncateDay(params.Params.StartDate)
+	if startDay.Before(splitDay) {
+		return ErrSplitBeforeDate
+	}
+	if startDay.Equal(splitDay) {
+		params.Params.StartDate = params.Date
+	}
+
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	err = lockOccurrence(ctx, db, params.UserID, params.Occurrence)
+	if err != nil {
+		return err
+	}
+
+	before, after, err := truncateRecurringTransaction(ctx, db, params.UserID, params.ID, params.Date)
+	if err != nil {
+		return err
+	}
+
+	recurringTransaction, err := db.CreateRecurringTransaction(ctx, params.Params)
+	if err != nil {
+		return err
+	}
+
+	err = materializeRecurringTransaction(ctx, db, recurringTransaction, repository.horizonEnd())
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     params.UserID,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(params.ID),
+		Action:     AuditActionUpdate,
+		Before:     snapshotRecurringTransaction(before),
+		After:      snapshotRecurringTransaction(after),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     params.UserID,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(recurringTransaction.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotRecurringTransaction(recurringTransaction),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EndRecurring ends a series before date, removing that occurrence and all
+// that follow it.
+func (repository *TransactionRepository) EndRecurring(ctx context.Context, userId uuid.UUID, id int32, date time.Time, occurrence OccurrenceVersion) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	err = lockOccurrence(ctx, db, userId, occurrence)
+	if err != nil {
+		return err
+	}
+	before, after, err := truncateRecurringTransaction(ctx, db, userId, id, date)
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(id),
+		Action:     AuditActionUpdate,
+		Before:     snapshotRecurringTransaction(before),
+		After:      snapshotRecurringTransaction(after),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func truncateRecurringTransaction(ctx context.Context, db *Queries, userId uuid.UUID, id int32, date time.Time) (RecurringTransaction, RecurringTransaction, error) {
+	before, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	err = db.EndRecurringTransaction(ctx, EndRecurringTransactionParams{
+		ID:      id,
+		UserID:  userId,
+		EndDate: date,
+	})
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	deleted, err := db.DeleteTransactionsByRecurringTransactionIdAndWhereDate(ctx, DeleteTransactionsByRecurringTransactionIdAndWhereDateParams{
+		UserID:                 userId,
+		RecurringTransactionID: id,
+		Date:                   date,
+	})
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	err = writeTransactionsDeletedAudit(ctx, db, deleted)
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	log.Infof("Successfully deleted %d transactions of recurring transaction %d on ending it", len(deleted), id)
+
+	err = db.DeleteRecurringTransactionExceptions(ctx, DeleteRecurringTransactionExceptionsParams{
+		RecurringTransactionID: id,
+		FromDate:               date,
+	})
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	after, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return RecurringTransaction{}, RecurringTransaction{}, err
+	}
+
+	return before, after, nil
+}
+
+// GetOccurrence returns the occurrence of a series on the day of date. An
+// occurrence that was changed before is returned as it is now, also when it
+// was moved to another day. An occurrence that is only projected is not
+// stored and has no id; UpdateOccurrence and RemoveOccurrence store it.
+func (repository *TransactionRepository) GetOccurrence(ctx context.Context, userId uuid.UUID, recurringTransactionId int32, date time.Time) (*Transaction, error) {
+	db := New(repository.db)
+	recurringTransaction, err := db.GetRecurringTransactionById(ctx, GetRecurringTransactionByIdParams{
+		ID:     recurringTransactionId,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	day := truncateDay(date)
+	exception, err := db.GetRecurringTransactionExceptionOnDay(ctx, GetRecurringTransactionExceptionOnDayParams{
+		RecurringTransactionID: recurringTransactionId,
+		Day:                    day,
+	})
+	if err == nil {
+		// A deleted occurrence has no transaction left
+		if !exception.TransactionID.Valid {
+			return nil, sql.ErrNoRows
+		}
+		transaction, err := db.GetTransactionById(ctx, GetTransactionByIdParams{
+			ID:     exception.TransactionID.Int32,
+			UserID: userId,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &transaction, nil
+	}
+	if !NoRowsFound(err) {
+		return nil, err
+	}
+
+	exceptions, err := db.GetRecurringTransactionExceptions(ctx, recurringTransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	end := day.AddDate(0, 0, 1)
+	if recurringTransaction.EndDate.Valid && recurringTransaction.EndDate.Time.Before(end) {
+		end = recurringTransaction.EndDate.Time
+	}
+	createParams, err := getRecurringTransactions(getRecurringTransactionsParams{
+		UserID:                 userId,
+		RecurringTransactionId: recurringTransactionId,
+		Description:            recurringTransaction.Description,
+		Amount:                 recurringTransaction.Amount,
+		Type:                   recurringTransaction.Type,
+		StartDate:              recurringTransaction.StartDate,
+		EndDate:                end,
+		RRule:                  recurringTransaction.Rrule,
+		From:                   day,
+		Exceptions:             exceptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(createParams) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if createParams[0].Date.Before(recurringTransaction.MaterializedUntil) {
+		transaction, err := db.GetTransactionByRecurringTransactionIdAndDate(ctx, GetTransactionByRecurringTransactionIdAndDateParams{
+			UserID:                 userId,
+			RecurringTransactionID: recurringTransactionId,
+			Date:                   createParams[0].Date,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &transaction, nil
+	}
+
+	return &Transaction{
+		UserID:                 createParams[0].UserID,
+		RecurringTransactionID: createParams[0].RecurringTransactionID,
+		Description:            createParams[0].Description,
+		Amount:                 createParams[0].Amount,
+		Type:                   createParams[0].Type,
+		Date:                   createParams[0].Date,
+	}, nil
+}
+
+// UpdateOccurrence changes a single occurrence of a series. The occurrence is
+// recorded as an exception, so later changes to the series leave it alone. A
+// projected occurrence is stored in the same database transaction first. The
+// id of the changed transaction is returned.
+func (repository *TransactionRepository) UpdateOccurrence(ctx context.Context, occurrence Transaction, params UpdateTransactionParams) (int32, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	id, err := storeOccurrence(ctx, db, occurrence)
+	if err != nil {
+		return 0, err
+	}
+
+	params.ID = id
+	err = applyTransactionUpdate(ctx, db, params.UserID, id, func(db *Queries) (int64, error) {
+		err := createRecurringException(ctx, db, params.UserID, id)
+		if err != nil {
+			return 0, err
+		}
+
+		return db.UpdateTransaction(ctx, params)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// RemoveOccurrence deletes a single occurrence of a series, recording it as an
+// exception so it is not created again. A projected occurrence is stored in
+// the same database transaction first.
+func (repository *TransactionRepository) RemoveOccurrence(ctx context.Context, occurrence Transaction, version sql.NullInt32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	id, err := storeOccurrence(ctx, db, occurrence)
+	if err != nil {
+		return err
+	}
+
+	userId := occurrence.UserID
+	err = applyTransactionUpdate(ctx, db, userId, id, func(db *Queries) (int64, error) {
+		err := createRecurringException(ctx, db, userId, id)
+		if err != nil {
+			return 0, err
+		}
+
+		return db.DeleteTransaction(ctx, DeleteTransactionParams{
+			ID:      id,
+			UserID:  userId,
+			Version: version,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeOccurrence stores a projected occurrence, one without an id, together
+// with its exception and returns its id. The series is locked, so it fails
+// with ErrVersionConflict when the occurrence was stored in the meantime.
+// Stored occurrences are returned as they are.
+func storeOccurrence(ctx context.Context, db *Queries, occurrence Transaction) (int32, error) {
+	if occurrence.ID != 0 {
+		return occurrence.ID, nil
+	}
+
+	recurringTransaction, err := db.GetRecurringTransactionForUpdate(ctx, occurrence.RecurringTransactionID.Int32)
+	if err != nil {
+		return 0, err
+	}
+	if recurringTransaction.UserID != occurrence.UserID {
+		return 0, sql.ErrNoRows
+	}
+	if occurrence.Date.Before(recurringTransaction.MaterializedUntil) {
+		return 0, ErrVersionConflict
+	}
+	_, err = db.GetRecurringTransactionExceptionOnDay(ctx, GetRecurringTransactionExceptionOnDayParams{
+		RecurringTransactionID: recurringTransaction.ID,
+		Day:                    truncateDay(occurrence.Date),
+	})
+	if err == nil {
+		return 0, ErrVersionConflict
+	}
+	if !NoRowsFound(err) {
+		return 0, err
+	}
+
+	transaction, err := db.CreateTransaction(ctx, CreateTransactionParams{
+		UserID:                 occurrence.UserID,
+		RecurringTransactionID: occurrence.RecurringTransactionID,
+		Amount:                 occurrence.Amount,
+		Description:            occurrence.Description,
+		Type:                   occurrence.Type,
+		Date:                   occurrence.Date,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// The exception keeps materialization from creating the occurrence again
+	err = db.CreateRecurringTransactionException(ctx, CreateRecurringTransactionExceptionParams{
+		RecurringTransactionID: recurringTransaction.ID,
+		Date:                   transaction.Date,
+		TransactionID:          transaction.ID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return transaction.ID, nil
+}
+
+// createRecurringException records the occurrence with its original date,
+// before the occurrence is changed. An occurrence that is already an exception
+// keeps its original date.
+func createRecurringException(ctx context.Context, db *Queries, userId uuid.UUID, id int32) error {
+	transaction, err := db.GetTransactionById(ctx, GetTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+	if !transaction.RecurringTransactionID.Valid {
+		return nil
+	}
+
+	return db.CreateRecurringTransactionException(ctx, CreateRecurringTransactionExceptionParams{
+		RecurringTransactionID: transaction.RecurringTransactionID.Int32,
+		Date:                   transaction.Date,
+		TransactionID:          id,
+	})
+}
+
+// ErrTransactionsUnavailable is returned when transactions can not become part
+// of a series, because they are deleted, already recurring or do not exist.
+var ErrTransactionsUnavailable = errors.New("transactions unavailable")
+
+// DetectRecurring proposes recurring series found in the transactions since
+// the given date that are not part of a series yet.
+func (repository *TransactionRepository) DetectRecurring(ctx context.Context, userId uuid.UUID, since time.Time) ([]recurrence.Proposal, error) {
+	db := New(repository.db)
+	transactions, err := db.GetNonRecurringTransactionsSince(ctx, GetNonRecurringTransactionsSinceParams{
+		UserID: userId,
+		Since:  since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	observations := make([]recurrence.Observation, len(transactions))
+	for idx, transaction := range transactions {
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		observations[idx] = recurrence.Observation{
+			ID:          transaction.ID,
+			Description: transaction.Description,
+			Amount:      amount,
+			Type:        transaction.Type,
+			Date:        transaction.Date,
+		}
+	}
+
+	return recurrence.Detect(observations, time.Now().UTC()), nil
+}
+
+type ConvertToRecurringParams struct {
+	UserID         uuid.UUID
+	TransactionIDs []int32
+	Params         CreateRecurringTransactionParams
+	// NextDate is the first occurrence after the converted transactions
+	NextDate time.Time
+}
+
+// ConvertToRecurring creates a series from existing transactions. The
+// transactions keep their dates and amounts, and the series continues at
+// NextDate.
+func (repository *TransactionRepository) ConvertToRecurring(ctx context.Context, params ConvertToRecurringParams) (*RecurringTransaction, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	before, err := db.GetTransactionsByIds(ctx, GetTransactionsByIdsParams{
+		UserID: params.UserID,
+		Ids:    params.TransactionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recurringTransaction, err := db.CreateRecurringTransaction(ctx, params.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := db.SetTransactionsRecurringTransactionId(ctx, SetTransactionsRecurringTransactionIdParams{
+		UserID:                 params.UserID,
+		RecurringTransactionID: recurringTransaction.ID,
+		Ids:                    params.TransactionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(after) == 0 || len(after) != len(params.TransactionIDs) {
+		return nil, ErrTransactionsUnavailable
+	}
+
+	for _, transaction := range after {
+		if transaction.Date.Before(recurringTransaction.StartDate) || !transaction.Date.Before(params.NextDate) {
+			return nil, ErrTransactionsUnavailable
+		}
+
+		// The transactions seldom fall exactly on the dates of the rule, so they
+		// are exceptions that changes to the whole series leave alone
+		err = db.CreateRecurringTransactionException(ctx, CreateRecurringTransactionExceptionParams{
+			RecurringTransactionID: recurringTransaction.ID,
+			Date:                   transaction.Date,
+			TransactionID:          transaction.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = db.SetRecurringTransactionMaterializedUntil(ctx, SetRecurringTransactionMaterializedUntilParams{
+		ID:                recurringTransaction.ID,
+		MaterializedUntil: params.NextDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	recurringTransaction.MaterializedUntil = params.NextDate
+
+	err = materializeRecurringTransaction(ctx, db, recurringTransaction, repository.horizonEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     params.UserID,
+		EntityType: AuditEntityRecurringTransaction,
+		EntityID:   int32ToString(recurringTransaction.ID),
+		Action:     AuditActionCreate,
+		After:      snapshotRecurringTransaction(recurringTransaction),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range after {
+		idx := slices.IndexFunc(before, func(b Transaction) bool {
+			return b.ID == transaction.ID
+		})
+		err = writeAudit(ctx, db, auditEntry{
+			UserID:     params.UserID,
+			EntityType: AuditEntityTransaction,
+			EntityID:   int32ToString(transaction.ID),
+			Action:     AuditActionUpdate,
+			Before:     snapshotTransaction(before[idx]),
+			After:      snapshotTransaction(transaction),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &recurringTransaction, nil
+}
+
+// Materialize inserts the occurrences of all recurring transactions up to
+// the horizon. Each series is locked and materialized in its own database
+// transaction, so it is not materialized from a stale row.
+func (repository *TransactionRepository) Materialize(ctx context.Context) (int64, error) {
+	horizonEnd := repository.horizonEnd()
+
+	db := New(repository.db)
+	recurringTransactions, err := db.GetUnmaterializedRecurringTransactions(ctx, GetUnmaterializedRecurringTransactionsParams{
+		Until: horizonEnd,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]int32, len(recurringTransactions))
+	for idx, recurringTransaction := range recurringTransactions {
+		ids[idx] = recurringTransaction.ID
+	}
+	allExceptions, err := db.GetRecurringTransactionExceptionsByIds(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	exceptions := make(map[int32][]RecurringTransactionException)
+	for _, exception := range allExceptions {
+		exceptions[exception.RecurringTransactionID] = append(exceptions[exception.RecurringTransactionID], exception)
+	}
+
+	var materialized int64
+	for _, recurringTransaction := range recurringTransactions {
+		ok, err := repository.materialize(ctx, recurringTransaction.ID, exceptions[recurringTransaction.ID], horizonEnd)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			materialized++
+		}
+	}
+
+	return materialized, nil
+}
+
+// materialize locks the series and materializes it from the locked row. It
+// returns false when the series was deleted in the meantime.
+func (repository *TransactionRepository) materialize(ctx context.Context, id int32, exceptions []RecurringTransactionException, until time.Time) (bool, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	recurringTransaction, err := db.GetRecurringTransactionForUpdate(ctx, id)
+	if err != nil {
+		if NoRowsFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = materializeOccurrences(ctx, db, recurringTransaction, exceptions, until)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (repository *TransactionRepository) horizonEnd() time.Time {
+	return time.Now().UTC().Add(repository.horizon)
+}
+
+// materializeRecurringTransaction inserts the occurrences of a series from
+// where it was materialized until, up to until or the end of the series.
+func materializeRecurringTransaction(ctx context.Context, db *Queries, recurringTransaction RecurringTransaction, until time.Time) error {
+	exceptions, err := db.GetRecurringTransactionExceptions(ctx, recurringTransaction.ID)
+	if err != nil {
+		return err
+	}
+
+	return materializeOccurrences(ctx, db, recurringTransaction, exceptions, until)
+}
+
+// materializeOccurrences inserts the occurrences of a series with the given
+// exceptions. Exceptions created after they were loaded are still skipped,
+// because an occurrence is only inserted when there is no exception on its
+// date.
+func materializeOccurrences(ctx context.Context, db *Queries, recurringTransaction RecurringTransaction, exceptions []RecurringTransactionException, until time.Time) error {
+	if recurringTransaction.EndDate.Valid && recurringTransaction.EndDate.Time.Before(until) {
+		until = recurringTransaction.EndDate.Time
+	}
+	if !recurringTransaction.MaterializedUntil.Before(until) {
+		return nil
+	}
+
+	createParams, err := getRecurringTransactions(getRecurringTransactionsParams{
+		UserID:                 recurringTransaction.UserID,
+		RecurringTransactionId: recurringTransaction.ID,
+		Description:            recurringTransaction.Description,
+		Amount:                 recurringTransaction.Amount,
+		Type:                   recurringTransaction.Type,
+		StartDate:              recurringTransaction.StartDate,
+		EndDate:                until,
+		RRule:                  recurringTransaction.Rrule,
+		From:                   recurringTransaction.MaterializedUntil,
+		Exceptions:             exceptions,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, params := range createParams {
+		_, err := db.CreateRecurringOccurrence(ctx, CreateRecurringOccurrenceParams{
+			UserID:                 params.UserID,
+			RecurringTransactionID: params.RecurringTransactionID.Int32,
+			Amount:                 params.Amount,
+			Description:            params.Description,
+			Type:                   params.Type,
+			Date:                   params.Date,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.SetRecurringTransactionMaterializedUntil(ctx, SetRecurringTransactionMaterializedUntilParams{
+		ID:                recurringTransaction.ID,
+		MaterializedUntil: until,
+	})
+}
+
+// getProjectedTransactions generates the occurrences between start and end,
+// inclusive, that are not materialized yet. Projected transactions are not
+// stored and have no id.
+func getProjectedTransactions(ctx context.Context, db *Queries, userId uuid.UUID, start time.Time, end time.Time) ([]FullTransaction, error) {
+	recurringTransactions, err := db.GetUnmaterializedRecurringTransactions(ctx, GetUnmaterializedRecurringTransactionsParams{
+		UserID: uuid.NullUUID{UUID: userId, Valid: true},
+		Until:  end.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var projected []FullTransaction
+	for _, recurringTransaction := range recurringTransactions {
+		from := recurringTransaction.MaterializedUntil
+		if start.After(from) {
+			from = start
+		}
+		until := end.AddDate(0, 0, 1)
+		if recurringTransaction.EndDate.Valid && recurringTransaction.EndDate.Time.Before(until) {
+			until = recurringTransaction.EndDate.Time
+		}
+
+		exceptions, err := db.GetRecurringTransactionExceptions(ctx, recurringTransaction.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		createParams, err := getRecurringTransactions(getRecurringTransactionsParams{
+			UserID:                 userId,
+			RecurringTransactionId: recurringTransaction.ID,
+			Description:            recurringTransaction.Description,
+			Amount:                 recurringTransaction.Amount,
+			Type:                   recurringTransaction.Type,
+			StartDate:              recurringTransaction.StartDate,
+			EndDate:                until,
+			RRule:                  recurringTransaction.Rrule,
+			From:                   from,
+			Exceptions:             exceptions,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, params := range createParams {
+			if params.Date.After(end) {
+				continue
+			}
+
+			projected = append(projected, FullTransaction{
+				UserID:                 userId,
+				RecurringTransactionID: params.RecurringTransactionID,
+				Description:            params.Description,
+				Amount:                 params.Amount,
+				Type:                   params.Type,
+				Date:                   params.Date,
+				StartDate:              sql.NullTime{Time: recurringTransaction.StartDate, Valid: true},
+				EndDate:                recurringTransaction.EndDate,
+				Interval:               sql.NullString{String: recurringTransaction.Interval, Valid: true},
+				DaysInterval:           recurringTransaction.DaysInterval,
+				Rrule:                  sql.NullString{String: recurringTransaction.Rrule, Valid: true},
+				RecurringCreated:       sql.NullTime{Time: recurringTransaction.Created, Valid: true},
+				RecurringUpdated:       sql.NullTime{Time: recurringTransaction.Updated, Valid: true},
+				Tags:                   []string{},
+			})
+		}
+	}
+
+	return projected, nil
+}
+
+// withProjectedTransactions adds the projected occurrences between start and
+// end that match keep to the transactions, ordered by date.
+func withProjectedTransactions(ctx context.Context, db *Queries, transactions []FullTransaction, params GetBetweenDatesParams, keep func(amount float64) bool) ([]FullTransaction, error) {
+	projected, err := getProjectedTransactions(ctx, db, params.UserID, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	if len(projected) == 0 {
+		return transactions, nil
+	}
+
+	for _, transaction := range projected {
+		amount, err := strconv.ParseFloat(transaction.Amount, 64)
+		if err != nil || !keep(amount) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	slices.SortStableFunc(transactions, func(a FullTransaction, b FullTransaction) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return transactions, nil
+}
+
+func (repository *TransactionRepository) GetDeleted(ctx context.Context, userId uuid.UUID) (*[]FullTransaction, error) {
+	db := New(repository.db)
+	transactions, err := db.GetDeletedTransactions(ctx, GetDeletedTransactionsParams{
+		UserID: userId,
+		Limit:  MaxFetchLimit,
+		Offset: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fullTransactions := make([]FullTransaction, len(transactions))
+	for idx, transaction := range transactions {
+		fullTransactions[idx] = transaction.FullTransaction
+	}
+
+	return &fullTransactions, nil
+}
+
+// Restore brings a transaction back from the trash. If the transaction was
+// deleted as part of its recurring series, the whole series is restored.
+func (repository *TransactionRepository) Restore(ctx context.Context, userId uuid.UUID, id int32) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	transaction, err := db.GetDeletedTransactionById(ctx, GetDeletedTransactionByIdParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	if transaction.RecurringTransactionID.Valid {
+		recurringTransaction, err := db.GetDeletedRecurringTransactionById(ctx, GetDeletedRecurringTransactionByIdParams{
+			ID:     transaction.RecurringTransactionID.Int32,
+			UserID: userId,
+		})
+		if err == nil {
+			after, err := db.RestoreRecurringTransaction(ctx, RestoreRecurringTransactionParams{
+				ID:     recurringTransaction.ID,
+				UserID: userId,
+			})
+			if err != nil {
+				return err
+			}
+
+			restored, err := db.RestoreTransactionsByRecurringTransactionId(ctx, RestoreTransactionsByRecurringTransactionIdParams{
+				UserID:                 userId,
+				RecurringTransactionID: recurringTransaction.ID,
+				Deleted:                recurringTransaction.Deleted.Time,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = writeTransactionsRestoredAudit(ctx, db, restored, recurringTransaction.Deleted.Time)
+			if err != nil {
+				return err
+			}
+
+			err = writeAudit(ctx, db, auditEntry{
+				UserID:     userId,
+				EntityType: AuditEntityRecurringTransaction,
+				EntityID:   int32ToString(recurringTransaction.ID),
+				Action:     AuditActionRestore,
+				Before:     snapshotRecurringTransaction(recurringTransaction),
+				After:      snapshotRecurringTransaction(after),
+			})
+			if err != nil {
+				return err
+			}
+
+			return tx.Commit()
+		} else if !NoRowsFound(err) {
+			return err
+		}
+	}
+
+	after, err := db.RestoreTransaction(ctx, RestoreTransactionParams{
+		ID:     id,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, db, auditEntry{
+		UserID:     userId,
+		EntityType: AuditEntityTransaction,
+		EntityID:   int32ToString(id),
+		Action:     AuditActionRestore,
+		Before:     snapshotTransaction(transaction),
+		After:      snapshotTransaction(after),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *TransactionRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+	recurringTransactions, err := db.PurgeDeletedRecurringTransactions(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	for _, recurringTransaction := range recurringTransactions {
+		err = writeAudit(ctx, db, auditEntry{
+			UserID:     recurringTransaction.UserID,
+			EntityType: AuditEntityRecurringTransaction,
+			EntityID:   int32ToString(recurringTransaction.ID),
+			Action:     AuditActionPurge,
+			Before:     snapshotRecurringTransaction(recurringTransaction),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	transactions, err := db.PurgeDeletedTransactions(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	for _, transaction := range transactions {
+		err = writeAudit(ctx, db, auditEntry{
+			UserID:     transaction.UserID,
+			EntityType: AuditEntityTransaction,
+			EntityID:   int32ToString(transaction.ID),
+			Action:     AuditActionPurge,
+			Before:     snapshotTransaction(transaction),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(recurringTransactions) + len(transactions)), tx.Commit()
+}
+
+type BulkFilter struct {
+	StartDate   sql.NullTime
+	EndDate     sql.NullTime
+	Type        sql.NullString
+	Description sql.NullString
+	BudgetID    sql.NullString
+	Income      sql.NullBool
+	Tag         sql.NullString
+}
+
+// IsEmpty reports whether the filter has no criteria and would match every
+// transaction.
+func (filter *BulkFilter) IsEmpty() bool {
+	return !filter.StartDate.Valid && !filter.EndDate.Valid && !filter.Type.Valid &&
+		(!filter.Description.Valid || filter.Description.String == "") &&
+		!filter.BudgetID.Valid && !filter.Income.Valid && !filter.Tag.Valid
+}
+
+type BulkParams struct {
+	UserID    uuid.UUID
+	Operation string
+	// Either Ids or Filter selects the transactions the operation is applied to
+	Ids             []int32
+	Filter          *BulkFilter
+	Type            string
+	BudgetID        string
+	BudgetExpenseID int32
+	Tags            []string
+	// RequireAll fails the operation when a selected id does not exist or a
+	// matched transaction is not changed, instead of skipping it
+	RequireAll bool
+}
+
+type BulkResult struct {
+	Operation string
+	Matched   int
+	Affected  int64
+}
+
+var ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+var ErrEmptyBulkSelection = errors.New("empty bulk selection")
+var ErrEmptyBulkFilter = errors.New("bulk filter without criteria")
+var ErrBulkNotFound = errors.New("bulk selection contains unknown transactions")
+
+// Bulk applies a single operation to a selection of transactions inside one
+// database transaction, so either every selected row is changed or none is.
+func (repository *TransactionRepository) Bulk(ctx context.Context, params BulkParams) (*BulkResult, error) {
+	if !slices.Contains(BulkOperations, params.Operation) {
+		return nil, ErrInvalidBulkOperation
+	}
+	if params.Ids == nil && params.Filter == nil {
+		return nil, ErrEmptyBulkSelection
+	}
+	if params.Ids == nil && params.Filter.IsEmpty() {
+		return nil, ErrEmptyBulkFilter
+	}
+
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	db := New(repository.db).WithTx(tx)
+
+	var ids []int32
+	if params.Ids != nil {
+		ids, err = db.GetTransactionIdsByIds(ctx, GetTransactionIdsByIdsParams{
+			UserID: params.UserID,
+			Ids:    params.Ids,
+		})
+	} else {
+		ids, err = getBulkFilterIds(ctx, db, params.UserID, params.Filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if params.RequireAll && params.Ids != nil && len(ids) != countUnique(params.Ids) {
+		return nil, ErrBulkNotFound
+	}
+
+	result := BulkResult{
+		Operation: params.Operation,
+		Matched:   len(ids),
+	}
+	if len(ids) == 0 {
+		return &result, nil
+	}
+
+	before, err := db.GetTransactionsByIds(ctx, GetTransactionsByIdsParams{
+		UserID: params.UserID,
+		Ids:    ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch params.Operation {
+	case BulkOperationSetType:
+		result.Affected, err = db.BulkUpdateTransactionType(ctx, BulkUpdateTransactionTypeParams{
+			UserID: params.UserID,
+			Ids:    ids,
+			Type:   params.Type,
+		})
+	case BulkOperationAssignBudget:
+		result.Affected, err = db.BulkUpdateTransactionBudgetId(ctx, BulkUpdateTransactionBudgetIdParams{
+			UserID:          params.UserID,
+			Ids:             ids,
+			BudgetID:        params.BudgetID,
+			BudgetExpenseID: params.BudgetExpenseID,
+		})
+	case BulkOperationUnassignBudget:
+		result.Affected, err = db.BulkRemoveTransactionBudgetId(ctx, BulkRemoveTransactionBudgetIdParams{
+			UserID: params.UserID,
+			Ids:    ids,
+		})
+	case BulkOperationAddTags:
+		result.Affected, err = db.AddTransactionTags(ctx, AddTransactionTagsParams{
+			UserID: params.UserID,
+			Ids:    ids,
+			Tags:   params.Tags,
+		})
+	case BulkOperationRemoveTags:
+		result.Affected, err = db.RemoveTransactionTags(ctx, RemoveTransactionTagsParams{
+			UserID: params.UserID,
+			Ids:    ids,
+			Tags:   params.Tags,
+		})
+	case BulkOperationDelete:
+		result.Affected, err = db.BulkDeleteTransactions(ctx, BulkDeleteTransactionsParams{
+			UserID: params.UserID,
+			Ids:    ids,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if params.RequireAll && result.Affected != int64(result.Matched) {
+		return nil, ErrVersionConflict
+	}
+
+	err = writeBulkAudit(ctx, db, params, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, tx.Commit()
+}
+
+func countUnique(ids []int32) int {
+	unique := make(map[int32]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return len(unique)
+}
+
+// getBulkFilterIds pages through all transactions that match the
+// filter, so a bulk operation is never cut off at the fetch limit.
+func getBulkFilterIds(ctx context.Context, db *Queries, userId uuid.UUID, filter *BulkFilter) ([]int32, error) {
+	var ids []int32
+	var afterId int32
+	for {
+		page, err := db.GetTransactionIdsByFilter(ctx, GetTransactionIdsByFilterParams{
+			UserID:      userId,
+			Limit:       MaxFetchLimit,
+			StartDate:   filter.StartDate,
+			EndDate:     filter.EndDate,
+			Type:        filter.Type,
+			Description: filter.Description,
+			BudgetID:    filter.BudgetID,
+			Income:      filter.Income,
+			Tag:         filter.Tag,
+			AfterID:     afterId,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, page...)
+		if len(page) < MaxFetchLimit {
+			return ids, nil
+		}
+		afterId = page[len(page)-1]
+	}
+}
+
+func writeBulkAudit(ctx context.Context, db *Queries, params BulkParams, before []Transaction) error {
+	ids := make([]int32, len(before))
+	for idx, transaction := range before {
+		ids[idx] = transaction.ID
+	}
+
+	after, err := db.GetTransactionsByIds(ctx, GetTransactionsByIdsParams{
+		UserID: params.UserID,
+		Ids:    ids,
+	})
+	if err != nil {
+		return err
+	}
+
+	for idx, transaction := range before {
+		entry := auditEntry{
+			UserID:     params.UserID,
+			EntityType: AuditEntityTransaction,
+			EntityID:   int32ToString(transaction.ID),
+			Action:     AuditActionUpdate,
+			Before:     snapshotTransaction(transaction),
+			After:      snapshotTransaction(after[idx]),
+		}
+
+		switch params.Operation {
+		case BulkOperationAddTags, BulkOperationRemoveTags:
+			entry.Action = AuditActionTag
+			if params.Operation == BulkOperationRemoveTags {
+				entry.Action = AuditActionUntag
+			}
+			entry.Before = nil
+			entry.After = map[string][]string{"tags": params.Tags}
+		case BulkOperationDelete:
+			entry.Action = AuditActionDelete
+		}
+
+		err = writeAudit(ctx, db, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepository) GetTags(ctx context.Context, userId uuid.UUID) (*[]string, error) {
+	db := New(repository.db)
+	tags, err := db.GetTags(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
+
+type getRecurringTransactionsParams struct {
+	UserID                 uuid.UUID
+	RecurringTransactionId int32
+	Description            string
+	Amount                 string
+	Type                   string
+	StartDate              time.Time
+	EndDate                time.Time
+	RRule                  string
+	// From skips the occurrences before it when set
+	From time.Time
+	// Exceptions are occurrences that were changed or deleted on their own
+	Exceptions []RecurringTransactionException
+}
+
+func getRecurringTransactions(params getRecurringTransactionsParams) ([]CreateTransactionParams, error) {
+	rule, err := recurrence.Parse(params.RRule, params.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var createParams []CreateTransactionParams
+	for _, date := range rule.Between(params.StartDate, params.EndDate) {
+		if date.Before(params.From) || isRecurringException(params.Exceptions, date) {
+			continue
+		}
+
+		createParams = append(createParams, CreateTransactionParams{
+			UserID:                 params.UserID,
+			RecurringTransactionID: sql.NullInt32{Valid: true, Int32: params.RecurringTransactionId},
+			Amount:                 params.Amount,
+			Description:            params.Description,
+			Type:                   params.Type,
+			Date:                   date,
+		})
+	}
+
+	return createParams, nil
+}
+
+func isRecurringException(exceptions []RecurringTransactionException, date time.Time) bool {
+	return slices.ContainsFunc(exceptions, func(exception RecurringTransactionException) bool {
+		return exception.Date.Equal(date)
+	})
+}
+
+func anyAmount(amount float64) bool {
+	return true
+}
+
+func isIncome(amount float64) bool {
+	return amount > 0
+}
+
+func isExpense(amount float64) bool {
+	return amount < 0
+}
+
+type TypeAmount struct {
+	Type   string
+	Amount float64
+}
+
+// MonthAmount is the income and expense of a month. Expenses are negative.
+type MonthAmount struct {
+	Month   time.Time
+	Income  float64
+	Expense float64
+}
+
+// MonthTypeAmount is the income and expense of a transaction type in a
+// month. Expenses are negative.
+type MonthTypeAmount struct {
+	Month   time.Time
+	Type    string
+	Income  float64
+	Expense float64
+}
+
+// parseAmount parses a decimal amount from the db, invalid amounts count as 0
+func parseAmount(amount string) float64 {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// DayAmount is the income and expense of a group on a day. Expenses are
+// negative.
+type DayAmount struct {
+	Date    time.Time
+	Key     string
+	Income  float64
+	Expense float64
+}
+
+// Summary grouping
+const (
+	SummaryByType   string = "type"
+	SummaryByTag           = "tag"
+	SummaryByPayee         = "payee"
+	SummaryByBudget        = "budget"
+)
+
+var SummaryGroupings = []string{
+	SummaryByType,
+	SummaryByTag,
+	SummaryByPayee,
+	SummaryByBudget,
+}
+
+func truncateMonth(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func truncateDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var TransactionIntervals = recurrence.Intervals
+
+// Recurrence scope
+const (
+	RecurrenceScopeOccurrence string = "occurrence"
+	RecurrenceScopeFollowing         = "following"
+	RecurrenceScopeSeries            = "series"
+)
+
+var RecurrenceScopes = []string{
+	RecurrenceScopeOccurrence,
+	RecurrenceScopeFollowing,
+	RecurrenceScopeSeries,
 }
 
-// Transaction interval
+// Bulk operation
 const (
-	TransactionIntervalDaily   string = "Daily"
-	TransactionIntervalWeekly         = "Weekly"
-	TransactionIntervalMonthly        = "Monthly"
-	TransactionIntervalOther          = "Other"
+	BulkOperationSetType        string = "setType"
+	BulkOperationAssignBudget          = "assignBudget"
+	BulkOperationUnassignBudget        = "unassignBudget"
+	BulkOperationAddTags               = "addTags"
+	BulkOperationRemoveTags            = "removeTags"
+	BulkOperationDelete                = "delete"
 )
 
-var TransactionIntervals = []string{
-	TransactionIntervalDaily,
-	TransactionIntervalWeekly,
-	TransactionIntervalMonthly,
-	TransactionIntervalOther,
+var BulkOperations = []string{
+	BulkOperationSetType,
+	BulkOperationAssignBudget,
+	BulkOperationUnassignBudget,
+	BulkOperationAddTags,
+	BulkOperationRemoveTags,
+	BulkOperationDelete,
 }
 
 // Income type
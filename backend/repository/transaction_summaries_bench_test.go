@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// The benchmarks compare the yearly summaries per month with the grouped
+// aggregation on a migrated database. They are skipped unless
+// BENCH_DB_CONNECTION_STRING is set, for example:
+//
+//	BENCH_DB_CONNECTION_STRING=postgres://... go test ./repository -run ^$ -bench Year
+
+const benchTransactionCount = 100_000
+
+var benchYear = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func setupBenchTransactions(b *testing.B) (*TransactionRepository, uuid.UUID) {
+	b.Helper()
+
+	connectionString := os.Getenv("BENCH_DB_CONNECTION_STRING")
+	if connectionString == "" {
+		b.Skip("BENCH_DB_CONNECTION_STRING is not set")
+	}
+
+	conn, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		b.Fatalf("error connecting to db: %v", err)
+	}
+	b.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	userId := uuid.New()
+	_, err = New(conn).CreateUser(ctx, CreateUserParams{
+		ID:         userId,
+		Provider:   "bench",
+		ProviderID: userId.String()[:32],
+		Username:   userId.String()[:32],
+		Email:      userId.String() + "@bench",
+	})
+	if err != nil {
+		b.Fatalf("error creating user: %v", err)
+	}
+	// Deleting the user cascades to the transactions
+	b.Cleanup(func() {
+		_, _ = conn.Exec("DELETE FROM users WHERE id = $1", userId)
+	})
+
+	// Spread income and expenses of every type over the year
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO transactions (user_id, description, amount, type, date)
+		SELECT $1, 'Bench ' || n,
+			CASE WHEN n % 5 = 0 THEN (random() * 2000)::DECIMAL(19, 2) ELSE -(random() * 200)::DECIMAL(19, 2) END,
+			CASE WHEN n % 5 = 0 THEN ($2::text[])[1 + n % array_length($2::text[], 1)] ELSE ($3::text[])[1 + n % array_length($3::text[], 1)] END,
+			$4::timestamp + (n % 365) * interval '1 day'
+		FROM generate_series(1, $5::int) AS n`,
+		userId, pq.Array(IncomeTypes), pq.Array(ExpenseTypes), benchYear, benchTransactionCount)
+	if err != nil {
+		b.Fatalf("error seeding transactions: %v", err)
+	}
+
+	return CreateTransactionRepository(conn, 0), userId
+}
+
+func BenchmarkYearSummaryPerMonth(b *testing.B) {
+	repository, userId := setupBenchTransactions(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for month := 0; month < 12; month++ {
+			start := benchYear.AddDate(0, month, 0)
+			_, err := repository.GetAmountsBetweenDates(ctx, GetBetweenDatesParams{
+				UserID: userId,
+				Start:  start,
+				End:    start.AddDate(0, 1, -1),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkYearSummaryAggregated(b *testing.B) {
+	repository, userId := setupBenchTransactions(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := repository.GetMonthlyAmountsBetweenDates(ctx, GetBetweenDatesParams{
+			UserID: userId,
+			Start:  benchYear,
+			End:    benchYear.AddDate(1, 0, -1),
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkYearTypeSummaryPerMonth(b *testing.B) {
+	repository, userId := setupBenchTransactions(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for month := 0; month < 12; month++ {
+			start := benchYear.AddDate(0, month, 0)
+			_, err := repository.GetExpenseAmountsBetweenDates(ctx, GetBetweenDatesParams{
+				UserID: userId,
+				Start:  start,
+				End:    start.AddDate(0, 1, -1),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkYearTypeSummaryAggregated(b *testing.B) {
+	repository, userId := setupBenchTransactions(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := repository.GetMonthlyTypeAmountsBetweenDates(ctx, GetBetweenDatesParams{
+			UserID: userId,
+			Start:  benchYear,
+			End:    benchYear.AddDate(1, 0, -1),
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
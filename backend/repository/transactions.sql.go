@@ -11,20 +11,170 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const addTransactionTags = `-- name: AddTransactionTags :one
+WITH added AS (
+    INSERT INTO transaction_tags (transaction_id, tag)
+    SELECT t.id, tag FROM transactions t, unnest($2::text[]) AS tag
+    WHERE t.user_id = $1 AND t.id = ANY($3::int[])
+    ON CONFLICT DO NOTHING
+    RETURNING transaction_id
+)
+SELECT count(DISTINCT transaction_id) FROM added
+`
+
+type AddTransactionTagsParams struct {
+	UserID uuid.UUID
+	Tags   []string
+	Ids    []int32
+}
+
+func (q *Queries) AddTransactionTags(ctx context.Context, arg AddTransactionTagsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addTransactionTags, arg.UserID, pq.Array(arg.Tags), pq.Array(arg.Ids))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const bulkDeleteTransactions = `-- name: BulkDeleteTransactions :execrows
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY($2::int[]) AND deleted IS NULL
+`
+
+type BulkDeleteTransactionsParams struct {
+	UserID uuid.UUID
+	Ids    []int32
+}
+
+func (q *Queries) BulkDeleteTransactions(ctx context.Context, arg BulkDeleteTransactionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bulkDeleteTransactions, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bulkRemoveTransactionBudgetId = `-- name: BulkRemoveTransactionBudgetId :execrows
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY($2::int[])
+`
+
+type BulkRemoveTransactionBudgetIdParams struct {
+	UserID uuid.UUID
+	Ids    []int32
+}
+
+func (q *Queries) BulkRemoveTransactionBudgetId(ctx context.Context, arg BulkRemoveTransactionBudgetIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bulkRemoveTransactionBudgetId, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bulkUpdateTransactionBudgetId = `-- name: BulkUpdateTransactionBudgetId :execrows
+UPDATE transactions
+SET budget_id = $2::text, budget_expense_id = $3::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE transactions.user_id = $1 AND transactions.id = ANY($4::int[])
+    AND EXISTS (
+        SELECT 1 FROM budget_expenses be JOIN budgets b ON be.budget_id = b.id
+        WHERE be.id = $3::int AND b.id = $2::text AND b.user_id = $1 AND b.deleted IS NULL
+    )
+`
+
+type BulkUpdateTransactionBudgetIdParams struct {
+	UserID          uuid.UUID
+	BudgetID        string
+	BudgetExpenseID int32
+	Ids             []int32
+}
+
+func (q *Queries) BulkUpdateTransactionBudgetId(ctx context.Context, arg BulkUpdateTransactionBudgetIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bulkUpdateTransactionBudgetId,
+		arg.UserID,
+		arg.BudgetID,
+		arg.BudgetExpenseID,
+		pq.Array(arg.Ids),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bulkUpdateTransactionType = `-- name: BulkUpdateTransactionType :execrows
+UPDATE transactions
+SET type = $2::text, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY($3::int[])
+`
+
+type BulkUpdateTransactionTypeParams struct {
+	UserID uuid.UUID
+	Type   string
+	Ids    []int32
+}
+
+func (q *Queries) BulkUpdateTransactionType(ctx context.Context, arg BulkUpdateTransactionTypeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bulkUpdateTransactionType, arg.UserID, arg.Type, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createRecurringOccurrence = `-- name: CreateRecurringOccurrence :execrows
+INSERT INTO transactions (user_id, recurring_transaction_id, amount, description, type, date)
+SELECT $1::uuid, $2::int, CAST($3::text AS DECIMAL(19, 4)), $4::text, $5::text, $6::timestamp
+WHERE NOT EXISTS (
+    SELECT 1 FROM recurring_transaction_exceptions e
+    WHERE e.recurring_transaction_id = $2::int AND e.date = $6::timestamp
+)
+`
+
+type CreateRecurringOccurrenceParams struct {
+	UserID                 uuid.UUID
+	RecurringTransactionID int32
+	Amount                 string
+	Description            string
+	Type                   string
+	Date                   time.Time
+}
+
+func (q *Queries) CreateRecurringOccurrence(ctx context.Context, arg CreateRecurringOccurrenceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createRecurringOccurrence,
+		arg.UserID,
+		arg.RecurringTransactionID,
+		arg.Amount,
+		arg.Description,
+		arg.Type,
+		arg.Date,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createRecurringTransaction = `-- name: CreateRecurringTransaction :one
-INSERT INTO recurring_transactions (user_id, start_date, end_date, interval, days_interval)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, start_date, end_date, interval, days_interval, created, updated
+INSERT INTO recurring_transactions (user_id, start_date, end_date, interval, days_interval, rrule, description, amount, type, materialized_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $2)
+RETURNING id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until
 `
 
 type CreateRecurringTransactionParams struct {
 	UserID       uuid.UUID
 	StartDate    time.Time
-	EndDate      time.Time
+	EndDate      sql.NullTime
 	Interval     string
 	DaysInterval sql.NullInt32
+	Rrule        string
+	Description  string
+	Amount       string
+	Type         string
 }
 
 func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecurringTransactionParams) (RecurringTransaction, error) {
@@ -34,6 +184,10 @@ func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecu
 		arg.EndDate,
 		arg.Interval,
 		arg.DaysInterval,
+		arg.Rrule,
+		arg.Description,
+		arg.Amount,
+		arg.Type,
 	)
 	var i RecurringTransaction
 	err := row.Scan(
@@ -45,14 +199,38 @@ func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecu
 		&i.DaysInterval,
 		&i.Created,
 		&i.Updated,
+		&i.Deleted,
+		&i.Rrule,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.MaterializedUntil,
 	)
 	return i, err
 }
 
+const createRecurringTransactionException = `-- name: CreateRecurringTransactionException :exec
+INSERT INTO recurring_transaction_exceptions (recurring_transaction_id, date, transaction_id)
+SELECT $1::int, $2::timestamp, $3::int
+WHERE NOT EXISTS (SELECT 1 FROM recurring_transaction_exceptions e WHERE e.transaction_id = $3::int)
+ON CONFLICT (recurring_transaction_id, date) DO NOTHING
+`
+
+type CreateRecurringTransactionExceptionParams struct {
+	RecurringTransactionID int32
+	Date                   time.Time
+	TransactionID          int32
+}
+
+func (q *Queries) CreateRecurringTransactionException(ctx context.Context, arg CreateRecurringTransactionExceptionParams) error {
+	_, err := q.db.ExecContext(ctx, createRecurringTransactionException, arg.RecurringTransactionID, arg.Date, arg.TransactionID)
+	return err
+}
+
 const createTransaction = `-- name: CreateTransaction :one
 INSERT INTO transactions (user_id, recurring_transaction_id, amount, description, type, date)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
 type CreateTransactionParams struct {
@@ -86,13 +264,17 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.Date,
 		&i.Created,
 		&i.Updated,
+		&i.Deleted,
+		&i.Version,
 	)
 	return i, err
 }
 
-const deleteRecurringTransaction = `-- name: DeleteRecurringTransaction :exec
-DELETE FROM recurring_transactions 
-WHERE id = $1 AND user_id = $2
+const deleteRecurringTransaction = `-- name: DeleteRecurringTransaction :one
+UPDATE recurring_transactions
+SET deleted = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+RETURNING deleted
 `
 
 type DeleteRecurringTransactionParams struct {
@@ -100,87 +282,112 @@ type DeleteRecurringTransactionParams struct {
 	UserID uuid.UUID
 }
 
-func (q *Queries) DeleteRecurringTransaction(ctx context.Context, arg DeleteRecurringTransactionParams) error {
-	_, err := q.db.ExecContext(ctx, deleteRecurringTransaction, arg.ID, arg.UserID)
-	return err
+func (q *Queries) DeleteRecurringTransaction(ctx context.Context, arg DeleteRecurringTransactionParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, deleteRecurringTransaction, arg.ID, arg.UserID)
+	var deleted sql.NullTime
+	err := row.Scan(&deleted)
+	return deleted, err
 }
 
-const deleteTransaction = `-- name: DeleteTransaction :exec
-DELETE FROM transactions 
-WHERE id = $1 AND user_id = $2
+const deleteRecurringTransactionExceptions = `-- name: DeleteRecurringTransactionExceptions :exec
+DELETE FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1 AND date >= $2::timestamp
 `
 
-type DeleteTransactionParams struct {
-	ID     int32
-	UserID uuid.UUID
+type DeleteRecurringTransactionExceptionsParams struct {
+	RecurringTransactionID int32
+	FromDate               time.Time
 }
 
-func (q *Queries) DeleteTransaction(ctx context.Context, arg DeleteTransactionParams) error {
-	_, err := q.db.ExecContext(ctx, deleteTransaction, arg.ID, arg.UserID)
+func (q *Queries) DeleteRecurringTransactionExceptions(ctx context.Context, arg DeleteRecurringTransactionExceptionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRecurringTransactionExceptions, arg.RecurringTransactionID, arg.FromDate)
 	return err
 }
 
-const deleteTransactionsByRecurringTransactionId = `-- name: DeleteTransactionsByRecurringTransactionId :execrows
-DELETE FROM transactions 
-WHERE recurring_transaction_id = $2::int AND user_id = $1
+const deleteTransaction = `-- name: DeleteTransaction :execrows
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($3::int IS NULL OR version = $3::int)
 `
 
-type DeleteTransactionsByRecurringTransactionIdParams struct {
-	UserID                 uuid.UUID
-	RecurringTransactionID int32
+type DeleteTransactionParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
 }
 
-func (q *Queries) DeleteTransactionsByRecurringTransactionId(ctx context.Context, arg DeleteTransactionsByRecurringTransactionIdParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, deleteTransactionsByRecurringTransactionId, arg.UserID, arg.RecurringTransactionID)
+func (q *Queries) DeleteTransaction(ctx context.Context, arg DeleteTransactionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTransaction, arg.ID, arg.UserID, arg.Version)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-const deleteTransactionsByRecurringTransactionIdAndWhereDate = `-- name: DeleteTransactionsByRecurringTransactionIdAndWhereDate :execrows
-DELETE FROM transactions 
-WHERE recurring_transaction_id = $3::int AND user_id = $1 AND date > $2
+const deleteTransactionsByRecurringTransactionId = `-- name: DeleteTransactionsByRecurringTransactionId :many
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = $2::int AND user_id = $1 AND deleted IS NULL
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type DeleteTransactionsByRecurringTransactionIdAndWhereDateParams struct {
+type DeleteTransactionsByRecurringTransactionIdParams struct {
 	UserID                 uuid.UUID
-	Date                   time.Time
 	RecurringTransactionID int32
 }
 
-func (q *Queries) DeleteTransactionsByRecurringTransactionIdAndWhereDate(ctx context.Context, arg DeleteTransactionsByRecurringTransactionIdAndWhereDateParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, deleteTransactionsByRecurringTransactionIdAndWhereDate, arg.UserID, arg.Date, arg.RecurringTransactionID)
+func (q *Queries) DeleteTransactionsByRecurringTransactionId(ctx context.Context, arg DeleteTransactionsByRecurringTransactionIdParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, deleteTransactionsByRecurringTransactionId, arg.UserID, arg.RecurringTransactionID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return result.RowsAffected()
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getBaseTransactionsBetweenDates = `-- name: GetBaseTransactionsBetweenDates :many
-SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated FROM transactions
-WHERE user_id = $1 AND date >= $4 AND date <= $5
-ORDER BY date
-LIMIT $2
-OFFSET $3
+const deleteTransactionsByRecurringTransactionIdAndWhereDate = `-- name: DeleteTransactionsByRecurringTransactionIdAndWhereDate :many
+UPDATE transactions
+SET deleted = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = $3::int AND user_id = $1 AND date >= $2 AND deleted IS NULL
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type GetBaseTransactionsBetweenDatesParams struct {
-	UserID    uuid.UUID
-	Limit     int32
-	Offset    int32
-	StartDate time.Time
-	EndDate   time.Time
+type DeleteTransactionsByRecurringTransactionIdAndWhereDateParams struct {
+	UserID                 uuid.UUID
+	Date                   time.Time
+	RecurringTransactionID int32
 }
 
-func (q *Queries) GetBaseTransactionsBetweenDates(ctx context.Context, arg GetBaseTransactionsBetweenDatesParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, getBaseTransactionsBetweenDates,
-		arg.UserID,
-		arg.Limit,
-		arg.Offset,
-		arg.StartDate,
-		arg.EndDate,
-	)
+func (q *Queries) DeleteTransactionsByRecurringTransactionIdAndWhereDate(ctx context.Context, arg DeleteTransactionsByRecurringTransactionIdAndWhereDateParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, deleteTransactionsByRecurringTransactionIdAndWhereDate, arg.UserID, arg.Date, arg.RecurringTransactionID)
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +407,8 @@ func (q *Queries) GetBaseTransactionsBetweenDates(ctx context.Context, arg GetBa
 			&i.Date,
 			&i.Created,
 			&i.Updated,
+			&i.Deleted,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -214,32 +423,43 @@ func (q *Queries) GetBaseTransactionsBetweenDates(ctx context.Context, arg GetBa
 	return items, nil
 }
 
-const getExpenseTransactionAmountsBetweenDates = `-- name: GetExpenseTransactionAmountsBetweenDates :many
-SELECT amount, type FROM transactions
-WHERE user_id = $1 AND amount < 0 AND date >= $2 AND date <= $3
+const deleteTransactionsByRecurringTransactionIdSoft = `-- name: DeleteTransactionsByRecurringTransactionIdSoft :many
+UPDATE transactions
+SET deleted = $2::timestamp, version = version + 1
+WHERE recurring_transaction_id = $3::int AND user_id = $1 AND deleted IS NULL
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type GetExpenseTransactionAmountsBetweenDatesParams struct {
-	UserID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
-}
-
-type GetExpenseTransactionAmountsBetweenDatesRow struct {
-	Amount string
-	Type   string
+type DeleteTransactionsByRecurringTransactionIdSoftParams struct {
+	UserID                 uuid.UUID
+	Deleted                time.Time
+	RecurringTransactionID int32
 }
 
-func (q *Queries) GetExpenseTransactionAmountsBetweenDates(ctx context.Context, arg GetExpenseTransactionAmountsBetweenDatesParams) ([]GetExpenseTransactionAmountsBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getExpenseTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+func (q *Queries) DeleteTransactionsByRecurringTransactionIdSoft(ctx context.Context, arg DeleteTransactionsByRecurringTransactionIdSoftParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, deleteTransactionsByRecurringTransactionIdSoft, arg.UserID, arg.Deleted, arg.RecurringTransactionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetExpenseTransactionAmountsBetweenDatesRow
+	var items []Transaction
 	for rows.Next() {
-		var i GetExpenseTransactionAmountsBetweenDatesRow
-		if err := rows.Scan(&i.Amount, &i.Type); err != nil {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -253,41 +473,42 @@ func (q *Queries) GetExpenseTransactionAmountsBetweenDates(ctx context.Context,
 	return items, nil
 }
 
-const getExpenseTransactionsBetweenDates = `-- name: GetExpenseTransactionsBetweenDates :many
-SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.budget_name, full_transaction.budget_expense_name FROM full_transaction
-WHERE user_id = $1 AND amount < 0 AND date >= $4 AND date <= $5
-ORDER BY date
-LIMIT $2
-OFFSET $3
+const endRecurringTransaction = `-- name: EndRecurringTransaction :exec
+UPDATE recurring_transactions
+SET end_date = $3::timestamp, materialized_until = LEAST(materialized_until, $3::timestamp), updated = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
 `
 
-type GetExpenseTransactionsBetweenDatesParams struct {
-	UserID    uuid.UUID
-	Limit     int32
-	Offset    int32
-	StartDate time.Time
-	EndDate   time.Time
+type EndRecurringTransactionParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	EndDate time.Time
 }
 
-type GetExpenseTransactionsBetweenDatesRow struct {
+func (q *Queries) EndRecurringTransaction(ctx context.Context, arg EndRecurringTransactionParams) error {
+	_, err := q.db.ExecContext(ctx, endRecurringTransaction, arg.ID, arg.UserID, arg.EndDate)
+	return err
+}
+
+const getAllTransactions = `-- name: GetAllTransactions :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL
+ORDER BY date, id
+`
+
+type GetAllTransactionsRow struct {
 	FullTransaction FullTransaction
 }
 
-func (q *Queries) GetExpenseTransactionsBetweenDates(ctx context.Context, arg GetExpenseTransactionsBetweenDatesParams) ([]GetExpenseTransactionsBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getExpenseTransactionsBetweenDates,
-		arg.UserID,
-		arg.Limit,
-		arg.Offset,
-		arg.StartDate,
-		arg.EndDate,
-	)
+func (q *Queries) GetAllTransactions(ctx context.Context, userID uuid.UUID) ([]GetAllTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllTransactions, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetExpenseTransactionsBetweenDatesRow
+	var items []GetAllTransactionsRow
 	for rows.Next() {
-		var i GetExpenseTransactionsBetweenDatesRow
+		var i GetAllTransactionsRow
 		if err := rows.Scan(
 			&i.FullTransaction.ID,
 			&i.FullTransaction.UserID,
@@ -300,14 +521,19 @@ func (q *Queries) GetExpenseTransactionsBetweenDates(ctx context.Context, arg Ge
 			&i.FullTransaction.Date,
 			&i.FullTransaction.Created,
 			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
 			&i.FullTransaction.StartDate,
 			&i.FullTransaction.EndDate,
 			&i.FullTransaction.Interval,
 			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
 			&i.FullTransaction.RecurringCreated,
 			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
 			&i.FullTransaction.BudgetName,
 			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -322,32 +548,69 @@ func (q *Queries) GetExpenseTransactionsBetweenDates(ctx context.Context, arg Ge
 	return items, nil
 }
 
-const getIncomeTransactionAmountsBetweenDates = `-- name: GetIncomeTransactionAmountsBetweenDates :many
-SELECT amount, type FROM transactions
-WHERE user_id = $1 AND amount > 0 AND date >= $2 AND date <= $3
+const getBalanceBefore = `-- name: GetBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::text AS balance FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date < $2::timestamp
 `
 
-type GetIncomeTransactionAmountsBetweenDatesParams struct {
+type GetBalanceBeforeParams struct {
+	UserID uuid.UUID
+	Before time.Time
+}
+
+func (q *Queries) GetBalanceBefore(ctx context.Context, arg GetBalanceBeforeParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getBalanceBefore, arg.UserID, arg.Before)
+	var balance string
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const getBaseTransactionsBetweenDates = `-- name: GetBaseTransactionsBetweenDates :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= $4 AND date <= $5
+ORDER BY date
+LIMIT $2
+OFFSET $3
+`
+
+type GetBaseTransactionsBetweenDatesParams struct {
 	UserID    uuid.UUID
+	Limit     int32
+	Offset    int32
 	StartDate time.Time
 	EndDate   time.Time
 }
 
-type GetIncomeTransactionAmountsBetweenDatesRow struct {
-	Amount string
-	Type   string
-}
-
-func (q *Queries) GetIncomeTransactionAmountsBetweenDates(ctx context.Context, arg GetIncomeTransactionAmountsBetweenDatesParams) ([]GetIncomeTransactionAmountsBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getIncomeTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+func (q *Queries) GetBaseTransactionsBetweenDates(ctx context.Context, arg GetBaseTransactionsBetweenDatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getBaseTransactionsBetweenDates,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+		arg.StartDate,
+		arg.EndDate,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetIncomeTransactionAmountsBetweenDatesRow
+	var items []Transaction
 	for rows.Next() {
-		var i GetIncomeTransactionAmountsBetweenDatesRow
-		if err := rows.Scan(&i.Amount, &i.Type); err != nil {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -361,31 +624,94 @@ func (q *Queries) GetIncomeTransactionAmountsBetweenDates(ctx context.Context, a
 	return items, nil
 }
 
-const getIncomeTransactionsBetweenDates = `-- name: GetIncomeTransactionsBetweenDates :many
-SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.budget_name, full_transaction.budget_expense_name FROM full_transaction
-WHERE user_id = $1 AND amount > 0 AND date >= $4 AND date <= $5
-ORDER BY date
-LIMIT $2
-OFFSET $3
+const getDailyTagTransactionAmounts = `-- name: GetDailyTagTransactionAmounts :many
+SELECT
+    date_trunc('day', t.date)::timestamp AS day,
+    COALESCE(tt.tag, '')::text AS key,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.amount > 0), 0)::text AS income,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.amount < 0), 0)::text AS expense
+FROM transactions t
+    LEFT OUTER JOIN transaction_tags tt ON tt.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL AND t.date >= $2 AND t.date <= $3
+GROUP BY day, key
+ORDER BY day, key
 `
 
-type GetIncomeTransactionsBetweenDatesParams struct {
+type GetDailyTagTransactionAmountsParams struct {
 	UserID    uuid.UUID
-	Limit     int32
-	Offset    int32
 	StartDate time.Time
 	EndDate   time.Time
 }
 
-type GetIncomeTransactionsBetweenDatesRow struct {
-	FullTransaction FullTransaction
+type GetDailyTagTransactionAmountsRow struct {
+	Day     time.Time
+	Key     string
+	Income  string
+	Expense string
 }
 
-func (q *Queries) GetIncomeTransactionsBetweenDates(ctx context.Context, arg GetIncomeTransactionsBetweenDatesParams) ([]GetIncomeTransactionsBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getIncomeTransactionsBetweenDates,
+func (q *Queries) GetDailyTagTransactionAmounts(ctx context.Context, arg GetDailyTagTransactionAmountsParams) ([]GetDailyTagTransactionAmountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDailyTagTransactionAmounts, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDailyTagTransactionAmountsRow
+	for rows.Next() {
+		var i GetDailyTagTransactionAmountsRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Key,
+			&i.Income,
+			&i.Expense,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDailyTransactionAmounts = `-- name: GetDailyTransactionAmounts :many
+SELECT
+    date_trunc('day', date)::timestamp AS day,
+    (CASE $2::text
+        WHEN 'payee' THEN description
+        WHEN 'budget' THEN COALESCE(budget_name, '')
+        ELSE type
+    END)::text AS key,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL AND date >= $3 AND date <= $4
+GROUP BY day, key
+ORDER BY day, key
+`
+
+type GetDailyTransactionAmountsParams struct {
+	UserID    uuid.UUID
+	GroupBy   string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetDailyTransactionAmountsRow struct {
+	Day     time.Time
+	Key     string
+	Income  string
+	Expense string
+}
+
+func (q *Queries) GetDailyTransactionAmounts(ctx context.Context, arg GetDailyTransactionAmountsParams) ([]GetDailyTransactionAmountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDailyTransactionAmounts,
 		arg.UserID,
-		arg.Limit,
-		arg.Offset,
+		arg.GroupBy,
 		arg.StartDate,
 		arg.EndDate,
 	)
@@ -393,29 +719,14 @@ func (q *Queries) GetIncomeTransactionsBetweenDates(ctx context.Context, arg Get
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetIncomeTransactionsBetweenDatesRow
+	var items []GetDailyTransactionAmountsRow
 	for rows.Next() {
-		var i GetIncomeTransactionsBetweenDatesRow
+		var i GetDailyTransactionAmountsRow
 		if err := rows.Scan(
-			&i.FullTransaction.ID,
-			&i.FullTransaction.UserID,
-			&i.FullTransaction.BudgetID,
-			&i.FullTransaction.BudgetExpenseID,
-			&i.FullTransaction.RecurringTransactionID,
-			&i.FullTransaction.Description,
-			&i.FullTransaction.Amount,
-			&i.FullTransaction.Type,
-			&i.FullTransaction.Date,
-			&i.FullTransaction.Created,
-			&i.FullTransaction.Updated,
-			&i.FullTransaction.StartDate,
-			&i.FullTransaction.EndDate,
-			&i.FullTransaction.Interval,
-			&i.FullTransaction.DaysInterval,
-			&i.FullTransaction.RecurringCreated,
-			&i.FullTransaction.RecurringUpdated,
-			&i.FullTransaction.BudgetName,
-			&i.FullTransaction.BudgetExpenseName,
+			&i.Day,
+			&i.Key,
+			&i.Income,
+			&i.Expense,
 		); err != nil {
 			return nil, err
 		}
@@ -430,19 +741,19 @@ func (q *Queries) GetIncomeTransactionsBetweenDates(ctx context.Context, arg Get
 	return items, nil
 }
 
-const getRecurringTransactionById = `-- name: GetRecurringTransactionById :one
-SELECT id, user_id, start_date, end_date, interval, days_interval, created, updated FROM recurring_transactions
-WHERE id = $1 AND user_id = $2
+const getDeletedRecurringTransactionById = `-- name: GetDeletedRecurringTransactionById :one
+SELECT id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until FROM recurring_transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL
 LIMIT 1
 `
 
-type GetRecurringTransactionByIdParams struct {
+type GetDeletedRecurringTransactionByIdParams struct {
 	ID     int32
 	UserID uuid.UUID
 }
 
-func (q *Queries) GetRecurringTransactionById(ctx context.Context, arg GetRecurringTransactionByIdParams) (RecurringTransaction, error) {
-	row := q.db.QueryRowContext(ctx, getRecurringTransactionById, arg.ID, arg.UserID)
+func (q *Queries) GetDeletedRecurringTransactionById(ctx context.Context, arg GetDeletedRecurringTransactionByIdParams) (RecurringTransaction, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedRecurringTransactionById, arg.ID, arg.UserID)
 	var i RecurringTransaction
 	err := row.Scan(
 		&i.ID,
@@ -453,34 +764,105 @@ func (q *Queries) GetRecurringTransactionById(ctx context.Context, arg GetRecurr
 		&i.DaysInterval,
 		&i.Created,
 		&i.Updated,
+		&i.Deleted,
+		&i.Rrule,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.MaterializedUntil,
 	)
 	return i, err
 }
 
-const getTransactionAmountsBetweenDates = `-- name: GetTransactionAmountsBetweenDates :many
-SELECT amount FROM transactions
-WHERE user_id = $1 AND date >= $2 AND date <= $3
+const getDeletedTransactionById = `-- name: GetDeletedTransactionById :one
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NOT NULL
+LIMIT 1
 `
 
-type GetTransactionAmountsBetweenDatesParams struct {
-	UserID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
+type GetDeletedTransactionByIdParams struct {
+	ID     int32
+	UserID uuid.UUID
 }
 
-func (q *Queries) GetTransactionAmountsBetweenDates(ctx context.Context, arg GetTransactionAmountsBetweenDatesParams) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+func (q *Queries) GetDeletedTransactionById(ctx context.Context, arg GetDeletedTransactionByIdParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedTransactionById, arg.ID, arg.UserID)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BudgetID,
+		&i.BudgetExpenseID,
+		&i.RecurringTransactionID,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.Date,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getDeletedTransactions = `-- name: GetDeletedTransactions :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE full_transaction.user_id = $1 AND full_transaction.deleted IS NOT NULL
+    AND (full_transaction.recurring_deleted IS NULL OR full_transaction.id = (SELECT MIN(t.id) FROM transactions t WHERE t.recurring_transaction_id = full_transaction.recurring_transaction_id))
+ORDER BY full_transaction.deleted DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetDeletedTransactionsParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetDeletedTransactionsRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetDeletedTransactions(ctx context.Context, arg GetDeletedTransactionsParams) ([]GetDeletedTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedTransactions, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []GetDeletedTransactionsRow
 	for rows.Next() {
-		var amount string
-		if err := rows.Scan(&amount); err != nil {
+		var i GetDeletedTransactionsRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, amount)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -491,45 +873,54 @@ func (q *Queries) GetTransactionAmountsBetweenDates(ctx context.Context, arg Get
 	return items, nil
 }
 
-const getTransactionById = `-- name: GetTransactionById :one
-SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated FROM transactions
-WHERE id = $1 AND user_id = $2
-LIMIT 1
+const getExpenseTransactionAmountsBetweenDates = `-- name: GetExpenseTransactionAmountsBetweenDates :many
+SELECT amount, type FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND amount < 0 AND date >= $2 AND date <= $3
 `
 
-type GetTransactionByIdParams struct {
-	ID     int32
-	UserID uuid.UUID
+type GetExpenseTransactionAmountsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
 }
 
-func (q *Queries) GetTransactionById(ctx context.Context, arg GetTransactionByIdParams) (Transaction, error) {
-	row := q.db.QueryRowContext(ctx, getTransactionById, arg.ID, arg.UserID)
-	var i Transaction
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.BudgetID,
-		&i.BudgetExpenseID,
-		&i.RecurringTransactionID,
-		&i.Description,
-		&i.Amount,
-		&i.Type,
-		&i.Date,
-		&i.Created,
-		&i.Updated,
-	)
-	return i, err
+type GetExpenseTransactionAmountsBetweenDatesRow struct {
+	Amount string
+	Type   string
 }
 
-const getTransactionsBetweenDates = `-- name: GetTransactionsBetweenDates :many
-SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.budget_name, full_transaction.budget_expense_name FROM full_transaction
-WHERE user_id = $1 AND date >= $4 AND date <= $5
+func (q *Queries) GetExpenseTransactionAmountsBetweenDates(ctx context.Context, arg GetExpenseTransactionAmountsBetweenDatesParams) ([]GetExpenseTransactionAmountsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getExpenseTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetExpenseTransactionAmountsBetweenDatesRow
+	for rows.Next() {
+		var i GetExpenseTransactionAmountsBetweenDatesRow
+		if err := rows.Scan(&i.Amount, &i.Type); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpenseTransactionsBetweenDates = `-- name: GetExpenseTransactionsBetweenDates :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL AND amount < 0 AND date >= $4 AND date <= $5
 ORDER BY date
 LIMIT $2
 OFFSET $3
 `
 
-type GetTransactionsBetweenDatesParams struct {
+type GetExpenseTransactionsBetweenDatesParams struct {
 	UserID    uuid.UUID
 	Limit     int32
 	Offset    int32
@@ -537,12 +928,12 @@ type GetTransactionsBetweenDatesParams struct {
 	EndDate   time.Time
 }
 
-type GetTransactionsBetweenDatesRow struct {
+type GetExpenseTransactionsBetweenDatesRow struct {
 	FullTransaction FullTransaction
 }
 
-func (q *Queries) GetTransactionsBetweenDates(ctx context.Context, arg GetTransactionsBetweenDatesParams) ([]GetTransactionsBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTransactionsBetweenDates,
+func (q *Queries) GetExpenseTransactionsBetweenDates(ctx context.Context, arg GetExpenseTransactionsBetweenDatesParams) ([]GetExpenseTransactionsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getExpenseTransactionsBetweenDates,
 		arg.UserID,
 		arg.Limit,
 		arg.Offset,
@@ -553,9 +944,9 @@ func (q *Queries) GetTransactionsBetweenDates(ctx context.Context, arg GetTransa
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetTransactionsBetweenDatesRow
+	var items []GetExpenseTransactionsBetweenDatesRow
 	for rows.Next() {
-		var i GetTransactionsBetweenDatesRow
+		var i GetExpenseTransactionsBetweenDatesRow
 		if err := rows.Scan(
 			&i.FullTransaction.ID,
 			&i.FullTransaction.UserID,
@@ -568,14 +959,1104 @@ func (q *Queries) GetTransactionsBetweenDates(ctx context.Context, arg GetTransa
 			&i.FullTransaction.Date,
 			&i.FullTransaction.Created,
 			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
 			&i.FullTransaction.StartDate,
 			&i.FullTransaction.EndDate,
 			&i.FullTransaction.Interval,
 			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
 			&i.FullTransaction.RecurringCreated,
 			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
 			&i.FullTransaction.BudgetName,
 			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFullTransactionById = `-- name: GetFullTransactionById :one
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1
+`
+
+type GetFullTransactionByIdParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+type GetFullTransactionByIdRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetFullTransactionById(ctx context.Context, arg GetFullTransactionByIdParams) (GetFullTransactionByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getFullTransactionById, arg.ID, arg.UserID)
+	var i GetFullTransactionByIdRow
+	err := row.Scan(
+		&i.FullTransaction.ID,
+		&i.FullTransaction.UserID,
+		&i.FullTransaction.BudgetID,
+		&i.FullTransaction.BudgetExpenseID,
+		&i.FullTransaction.RecurringTransactionID,
+		&i.FullTransaction.Description,
+		&i.FullTransaction.Amount,
+		&i.FullTransaction.Type,
+		&i.FullTransaction.Date,
+		&i.FullTransaction.Created,
+		&i.FullTransaction.Updated,
+		&i.FullTransaction.Deleted,
+		&i.FullTransaction.Version,
+		&i.FullTransaction.StartDate,
+		&i.FullTransaction.EndDate,
+		&i.FullTransaction.Interval,
+		&i.FullTransaction.DaysInterval,
+		&i.FullTransaction.Rrule,
+		&i.FullTransaction.RecurringCreated,
+		&i.FullTransaction.RecurringUpdated,
+		&i.FullTransaction.RecurringDeleted,
+		&i.FullTransaction.BudgetName,
+		&i.FullTransaction.BudgetExpenseName,
+		pq.Array(&i.FullTransaction.Tags),
+	)
+	return i, err
+}
+
+const getIncomeTransactionAmountsBetweenDates = `-- name: GetIncomeTransactionAmountsBetweenDates :many
+SELECT amount, type FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0 AND date >= $2 AND date <= $3
+`
+
+type GetIncomeTransactionAmountsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetIncomeTransactionAmountsBetweenDatesRow struct {
+	Amount string
+	Type   string
+}
+
+func (q *Queries) GetIncomeTransactionAmountsBetweenDates(ctx context.Context, arg GetIncomeTransactionAmountsBetweenDatesParams) ([]GetIncomeTransactionAmountsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIncomeTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIncomeTransactionAmountsBetweenDatesRow
+	for rows.Next() {
+		var i GetIncomeTransactionAmountsBetweenDatesRow
+		if err := rows.Scan(&i.Amount, &i.Type); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIncomeTransactionsBetweenDates = `-- name: GetIncomeTransactionsBetweenDates :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL AND amount > 0 AND date >= $4 AND date <= $5
+ORDER BY date
+LIMIT $2
+OFFSET $3
+`
+
+type GetIncomeTransactionsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	Limit     int32
+	Offset    int32
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetIncomeTransactionsBetweenDatesRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetIncomeTransactionsBetweenDates(ctx context.Context, arg GetIncomeTransactionsBetweenDatesParams) ([]GetIncomeTransactionsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIncomeTransactionsBetweenDates,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIncomeTransactionsBetweenDatesRow
+	for rows.Next() {
+		var i GetIncomeTransactionsBetweenDatesRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMonthlyTransactionAmountsBetweenDates = `-- name: GetMonthlyTransactionAmountsBetweenDates :many
+SELECT
+    date_trunc('month', date)::timestamp AS month,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= $2 AND date <= $3
+GROUP BY month
+ORDER BY month
+`
+
+type GetMonthlyTransactionAmountsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetMonthlyTransactionAmountsBetweenDatesRow struct {
+	Month   time.Time
+	Income  string
+	Expense string
+}
+
+func (q *Queries) GetMonthlyTransactionAmountsBetweenDates(ctx context.Context, arg GetMonthlyTransactionAmountsBetweenDatesParams) ([]GetMonthlyTransactionAmountsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMonthlyTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMonthlyTransactionAmountsBetweenDatesRow
+	for rows.Next() {
+		var i GetMonthlyTransactionAmountsBetweenDatesRow
+		if err := rows.Scan(&i.Month, &i.Income, &i.Expense); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMonthlyTypeTransactionAmountsBetweenDates = `-- name: GetMonthlyTypeTransactionAmountsBetweenDates :many
+SELECT
+    date_trunc('month', date)::timestamp AS month,
+    type,
+    COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::text AS income,
+    COALESCE(SUM(amount) FILTER (WHERE amount < 0), 0)::text AS expense
+FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= $2 AND date <= $3
+GROUP BY month, type
+ORDER BY month, type
+`
+
+type GetMonthlyTypeTransactionAmountsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetMonthlyTypeTransactionAmountsBetweenDatesRow struct {
+	Month   time.Time
+	Type    string
+	Income  string
+	Expense string
+}
+
+func (q *Queries) GetMonthlyTypeTransactionAmountsBetweenDates(ctx context.Context, arg GetMonthlyTypeTransactionAmountsBetweenDatesParams) ([]GetMonthlyTypeTransactionAmountsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMonthlyTypeTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMonthlyTypeTransactionAmountsBetweenDatesRow
+	for rows.Next() {
+		var i GetMonthlyTypeTransactionAmountsBetweenDatesRow
+		if err := rows.Scan(
+			&i.Month,
+			&i.Type,
+			&i.Income,
+			&i.Expense,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNonRecurringTransactionsSince = `-- name: GetNonRecurringTransactionsSince :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND recurring_transaction_id IS NULL AND date >= $2::timestamp
+ORDER BY date
+`
+
+type GetNonRecurringTransactionsSinceParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) GetNonRecurringTransactionsSince(ctx context.Context, arg GetNonRecurringTransactionsSinceParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getNonRecurringTransactionsSince, arg.UserID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecurringTransactionById = `-- name: GetRecurringTransactionById :one
+SELECT id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until FROM recurring_transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1
+`
+
+type GetRecurringTransactionByIdParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetRecurringTransactionById(ctx context.Context, arg GetRecurringTransactionByIdParams) (RecurringTransaction, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringTransactionById, arg.ID, arg.UserID)
+	var i RecurringTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Interval,
+		&i.DaysInterval,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Rrule,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.MaterializedUntil,
+	)
+	return i, err
+}
+
+const getRecurringTransactionExceptionOnDay = `-- name: GetRecurringTransactionExceptionOnDay :one
+SELECT recurring_transaction_id, date, transaction_id, created FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1 AND date >= $2::timestamp AND date < $2::timestamp + INTERVAL '1 day'
+LIMIT 1
+`
+
+type GetRecurringTransactionExceptionOnDayParams struct {
+	RecurringTransactionID int32
+	Day                    time.Time
+}
+
+func (q *Queries) GetRecurringTransactionExceptionOnDay(ctx context.Context, arg GetRecurringTransactionExceptionOnDayParams) (RecurringTransactionException, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringTransactionExceptionOnDay, arg.RecurringTransactionID, arg.Day)
+	var i RecurringTransactionException
+	err := row.Scan(
+		&i.RecurringTransactionID,
+		&i.Date,
+		&i.TransactionID,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getRecurringTransactionExceptions = `-- name: GetRecurringTransactionExceptions :many
+SELECT recurring_transaction_id, date, transaction_id, created FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1
+ORDER BY date
+`
+
+func (q *Queries) GetRecurringTransactionExceptions(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionException, error) {
+	rows, err := q.db.QueryContext(ctx, getRecurringTransactionExceptions, recurringTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringTransactionException
+	for rows.Next() {
+		var i RecurringTransactionException
+		if err := rows.Scan(
+			&i.RecurringTransactionID,
+			&i.Date,
+			&i.TransactionID,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecurringTransactionExceptionsByIds = `-- name: GetRecurringTransactionExceptionsByIds :many
+SELECT recurring_transaction_id, date, transaction_id, created FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = ANY($1::int[])
+ORDER BY recurring_transaction_id, date
+`
+
+func (q *Queries) GetRecurringTransactionExceptionsByIds(ctx context.Context, ids []int32) ([]RecurringTransactionException, error) {
+	rows, err := q.db.QueryContext(ctx, getRecurringTransactionExceptionsByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringTransactionException
+	for rows.Next() {
+		var i RecurringTransactionException
+		if err := rows.Scan(
+			&i.RecurringTransactionID,
+			&i.Date,
+			&i.TransactionID,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecurringTransactionForUpdate = `-- name: GetRecurringTransactionForUpdate :one
+SELECT id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until FROM recurring_transactions
+WHERE id = $1 AND deleted IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetRecurringTransactionForUpdate(ctx context.Context, id int32) (RecurringTransaction, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringTransactionForUpdate, id)
+	var i RecurringTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Interval,
+		&i.DaysInterval,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Rrule,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.MaterializedUntil,
+	)
+	return i, err
+}
+
+const getTags = `-- name: GetTags :many
+SELECT DISTINCT tt.tag FROM transaction_tags tt JOIN transactions t ON tt.transaction_id = t.id
+WHERE t.user_id = $1 AND t.deleted IS NULL
+ORDER BY tt.tag
+`
+
+func (q *Queries) GetTags(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getTags, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionAmountsBetweenDates = `-- name: GetTransactionAmountsBetweenDates :many
+SELECT amount FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND date >= $2 AND date <= $3
+`
+
+type GetTransactionAmountsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) GetTransactionAmountsBetweenDates(ctx context.Context, arg GetTransactionAmountsBetweenDatesParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionAmountsBetweenDates, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var amount string
+		if err := rows.Scan(&amount); err != nil {
+			return nil, err
+		}
+		items = append(items, amount)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionById = `-- name: GetTransactionById :one
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
+LIMIT 1
+`
+
+type GetTransactionByIdParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetTransactionById(ctx context.Context, arg GetTransactionByIdParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionById, arg.ID, arg.UserID)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BudgetID,
+		&i.BudgetExpenseID,
+		&i.RecurringTransactionID,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.Date,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getTransactionByRecurringTransactionIdAndDate = `-- name: GetTransactionByRecurringTransactionIdAndDate :one
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE recurring_transaction_id = $3::int AND user_id = $1 AND date = $2
+LIMIT 1
+`
+
+type GetTransactionByRecurringTransactionIdAndDateParams struct {
+	UserID                 uuid.UUID
+	Date                   time.Time
+	RecurringTransactionID int32
+}
+
+func (q *Queries) GetTransactionByRecurringTransactionIdAndDate(ctx context.Context, arg GetTransactionByRecurringTransactionIdAndDateParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionByRecurringTransactionIdAndDate, arg.UserID, arg.Date, arg.RecurringTransactionID)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BudgetID,
+		&i.BudgetExpenseID,
+		&i.RecurringTransactionID,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.Date,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getTransactionIdsByFilter = `-- name: GetTransactionIdsByFilter :many
+SELECT t.id FROM transactions t
+WHERE t.user_id = $1 AND t.deleted IS NULL
+    AND ($3::timestamp IS NULL OR t.date >= $3::timestamp)
+    AND ($4::timestamp IS NULL OR t.date <= $4::timestamp)
+    AND ($5::text IS NULL OR t.type = $5::text)
+    AND ($6::text IS NULL OR t.description ILIKE '%' || $6::text || '%')
+    AND ($7::text IS NULL OR t.budget_id = $7::text)
+    AND ($8::boolean IS NULL OR (t.amount > 0) = $8::boolean)
+    AND ($9::text IS NULL OR EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag = $9::text))
+    AND t.id > $10::int
+ORDER BY t.id
+LIMIT $2
+`
+
+type GetTransactionIdsByFilterParams struct {
+	UserID      uuid.UUID
+	Limit       int32
+	StartDate   sql.NullTime
+	EndDate     sql.NullTime
+	Type        sql.NullString
+	Description sql.NullString
+	BudgetID    sql.NullString
+	Income      sql.NullBool
+	Tag         sql.NullString
+	AfterID     int32
+}
+
+func (q *Queries) GetTransactionIdsByFilter(ctx context.Context, arg GetTransactionIdsByFilterParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionIdsByFilter,
+		arg.UserID,
+		arg.Limit,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Type,
+		arg.Description,
+		arg.BudgetID,
+		arg.Income,
+		arg.Tag,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionIdsByIds = `-- name: GetTransactionIdsByIds :many
+SELECT id FROM transactions
+WHERE user_id = $1 AND deleted IS NULL AND id = ANY($2::int[])
+ORDER BY id
+`
+
+type GetTransactionIdsByIdsParams struct {
+	UserID uuid.UUID
+	Ids    []int32
+}
+
+func (q *Queries) GetTransactionIdsByIds(ctx context.Context, arg GetTransactionIdsByIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionIdsByIds, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsBetweenDates = `-- name: GetTransactionsBetweenDates :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE user_id = $1 AND deleted IS NULL AND date >= $4 AND date <= $5
+ORDER BY date, id
+LIMIT $2
+OFFSET $3
+`
+
+type GetTransactionsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	Limit     int32
+	Offset    int32
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetTransactionsBetweenDatesRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetTransactionsBetweenDates(ctx context.Context, arg GetTransactionsBetweenDatesParams) ([]GetTransactionsBetweenDatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsBetweenDates,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTransactionsBetweenDatesRow
+	for rows.Next() {
+		var i GetTransactionsBetweenDatesRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByBudgetId = `-- name: GetTransactionsByBudgetId :many
+SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.deleted, full_transaction.version, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.rrule, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.recurring_deleted, full_transaction.budget_name, full_transaction.budget_expense_name, full_transaction.tags FROM full_transaction
+WHERE budget_id = $2::text AND user_id = $1 AND deleted IS NULL
+ORDER BY date
+`
+
+type GetTransactionsByBudgetIdParams struct {
+	UserID   uuid.UUID
+	BudgetID string
+}
+
+type GetTransactionsByBudgetIdRow struct {
+	FullTransaction FullTransaction
+}
+
+func (q *Queries) GetTransactionsByBudgetId(ctx context.Context, arg GetTransactionsByBudgetIdParams) ([]GetTransactionsByBudgetIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsByBudgetId, arg.UserID, arg.BudgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTransactionsByBudgetIdRow
+	for rows.Next() {
+		var i GetTransactionsByBudgetIdRow
+		if err := rows.Scan(
+			&i.FullTransaction.ID,
+			&i.FullTransaction.UserID,
+			&i.FullTransaction.BudgetID,
+			&i.FullTransaction.BudgetExpenseID,
+			&i.FullTransaction.RecurringTransactionID,
+			&i.FullTransaction.Description,
+			&i.FullTransaction.Amount,
+			&i.FullTransaction.Type,
+			&i.FullTransaction.Date,
+			&i.FullTransaction.Created,
+			&i.FullTransaction.Updated,
+			&i.FullTransaction.Deleted,
+			&i.FullTransaction.Version,
+			&i.FullTransaction.StartDate,
+			&i.FullTransaction.EndDate,
+			&i.FullTransaction.Interval,
+			&i.FullTransaction.DaysInterval,
+			&i.FullTransaction.Rrule,
+			&i.FullTransaction.RecurringCreated,
+			&i.FullTransaction.RecurringUpdated,
+			&i.FullTransaction.RecurringDeleted,
+			&i.FullTransaction.BudgetName,
+			&i.FullTransaction.BudgetExpenseName,
+			pq.Array(&i.FullTransaction.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByIds = `-- name: GetTransactionsByIds :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE user_id = $1 AND id = ANY($2::int[])
+ORDER BY id
+`
+
+type GetTransactionsByIdsParams struct {
+	UserID uuid.UUID
+	Ids    []int32
+}
+
+func (q *Queries) GetTransactionsByIds(ctx context.Context, arg GetTransactionsByIdsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsByIds, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByRecurringTransactionId = `-- name: GetTransactionsByRecurringTransactionId :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE recurring_transaction_id = $2::int AND user_id = $1 AND deleted IS NULL
+ORDER BY date
+`
+
+type GetTransactionsByRecurringTransactionIdParams struct {
+	UserID                 uuid.UUID
+	RecurringTransactionID int32
+}
+
+func (q *Queries) GetTransactionsByRecurringTransactionId(ctx context.Context, arg GetTransactionsByRecurringTransactionIdParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsByRecurringTransactionId, arg.UserID, arg.RecurringTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsOutsideBudgetDates = `-- name: GetTransactionsOutsideBudgetDates :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE user_id = $1 AND budget_id = $2::text AND (date < $3 OR date > $4)
+ORDER BY id
+FOR UPDATE
+`
+
+type GetTransactionsOutsideBudgetDatesParams struct {
+	UserID    uuid.UUID
+	BudgetID  string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) GetTransactionsOutsideBudgetDates(ctx context.Context, arg GetTransactionsOutsideBudgetDatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsOutsideBudgetDates,
+		arg.UserID,
+		arg.BudgetID,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnassignedTransactionsBetweenDates = `-- name: GetUnassignedTransactionsBetweenDates :many
+SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version FROM transactions
+WHERE transactions.user_id = $1 AND transactions.deleted IS NULL
+    AND (transactions.budget_id IS NULL OR transactions.budget_id IN (SELECT b.id FROM budgets b WHERE b.deleted IS NOT NULL))
+    AND transactions.date >= $4 AND transactions.date <= $5
+ORDER BY date
+LIMIT $2
+OFFSET $3
+`
+
+type GetUnassignedTransactionsBetweenDatesParams struct {
+	UserID    uuid.UUID
+	Limit     int32
+	Offset    int32
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) GetUnassignedTransactionsBetweenDates(ctx context.Context, arg GetUnassignedTransactionsBetweenDatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getUnassignedTransactionsBetweenDates,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnmaterializedRecurringTransactions = `-- name: GetUnmaterializedRecurringTransactions :many
+SELECT id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until FROM recurring_transactions
+WHERE deleted IS NULL
+    AND ($1::uuid IS NULL OR user_id = $1::uuid)
+    AND materialized_until < $2::timestamp
+    AND (end_date IS NULL OR materialized_until < end_date)
+ORDER BY id
+`
+
+type GetUnmaterializedRecurringTransactionsParams struct {
+	UserID uuid.NullUUID
+	Until  time.Time
+}
+
+func (q *Queries) GetUnmaterializedRecurringTransactions(ctx context.Context, arg GetUnmaterializedRecurringTransactionsParams) ([]RecurringTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, getUnmaterializedRecurringTransactions, arg.UserID, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringTransaction
+	for rows.Next() {
+		var i RecurringTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Interval,
+			&i.DaysInterval,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Rrule,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.MaterializedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -590,50 +2071,55 @@ func (q *Queries) GetTransactionsBetweenDates(ctx context.Context, arg GetTransa
 	return items, nil
 }
 
-const getTransactionsByBudgetId = `-- name: GetTransactionsByBudgetId :many
-SELECT full_transaction.id, full_transaction.user_id, full_transaction.budget_id, full_transaction.budget_expense_id, full_transaction.recurring_transaction_id, full_transaction.description, full_transaction.amount, full_transaction.type, full_transaction.date, full_transaction.created, full_transaction.updated, full_transaction.start_date, full_transaction.end_date, full_transaction.interval, full_transaction.days_interval, full_transaction.recurring_created, full_transaction.recurring_updated, full_transaction.budget_name, full_transaction.budget_expense_name FROM full_transaction
-WHERE budget_id = $2::text AND user_id = $1
-ORDER BY date
+const lockTransactionVersion = `-- name: LockTransactionVersion :one
+SELECT id FROM transactions
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($3::int IS NULL OR version = $3::int)
+FOR UPDATE
 `
 
-type GetTransactionsByBudgetIdParams struct {
-	UserID   uuid.UUID
-	BudgetID string
+type LockTransactionVersionParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
 }
 
-type GetTransactionsByBudgetIdRow struct {
-	FullTransaction FullTransaction
+func (q *Queries) LockTransactionVersion(ctx context.Context, arg LockTransactionVersionParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, lockTransactionVersion, arg.ID, arg.UserID, arg.Version)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
 }
 
-func (q *Queries) GetTransactionsByBudgetId(ctx context.Context, arg GetTransactionsByBudgetIdParams) ([]GetTransactionsByBudgetIdRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTransactionsByBudgetId, arg.UserID, arg.BudgetID)
+const purgeDeletedRecurringTransactions = `-- name: PurgeDeletedRecurringTransactions :many
+DELETE FROM recurring_transactions
+WHERE deleted < $1::timestamp
+RETURNING id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until
+`
+
+func (q *Queries) PurgeDeletedRecurringTransactions(ctx context.Context, before time.Time) ([]RecurringTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedRecurringTransactions, before)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetTransactionsByBudgetIdRow
+	var items []RecurringTransaction
 	for rows.Next() {
-		var i GetTransactionsByBudgetIdRow
+		var i RecurringTransaction
 		if err := rows.Scan(
-			&i.FullTransaction.ID,
-			&i.FullTransaction.UserID,
-			&i.FullTransaction.BudgetID,
-			&i.FullTransaction.BudgetExpenseID,
-			&i.FullTransaction.RecurringTransactionID,
-			&i.FullTransaction.Description,
-			&i.FullTransaction.Amount,
-			&i.FullTransaction.Type,
-			&i.FullTransaction.Date,
-			&i.FullTransaction.Created,
-			&i.FullTransaction.Updated,
-			&i.FullTransaction.StartDate,
-			&i.FullTransaction.EndDate,
-			&i.FullTransaction.Interval,
-			&i.FullTransaction.DaysInterval,
-			&i.FullTransaction.RecurringCreated,
-			&i.FullTransaction.RecurringUpdated,
-			&i.FullTransaction.BudgetName,
-			&i.FullTransaction.BudgetExpenseName,
+			&i.ID,
+			&i.UserID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Interval,
+			&i.DaysInterval,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Rrule,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.MaterializedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -648,19 +2134,14 @@ func (q *Queries) GetTransactionsByBudgetId(ctx context.Context, arg GetTransact
 	return items, nil
 }
 
-const getTransactionsByRecurringTransactionId = `-- name: GetTransactionsByRecurringTransactionId :many
-SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated FROM transactions
-WHERE recurring_transaction_id = $2::int AND user_id = $1
-ORDER BY date
+const purgeDeletedTransactions = `-- name: PurgeDeletedTransactions :many
+DELETE FROM transactions
+WHERE deleted < $1::timestamp
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type GetTransactionsByRecurringTransactionIdParams struct {
-	UserID                 uuid.UUID
-	RecurringTransactionID int32
-}
-
-func (q *Queries) GetTransactionsByRecurringTransactionId(ctx context.Context, arg GetTransactionsByRecurringTransactionIdParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, getTransactionsByRecurringTransactionId, arg.UserID, arg.RecurringTransactionID)
+func (q *Queries) PurgeDeletedTransactions(ctx context.Context, before time.Time) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedTransactions, before)
 	if err != nil {
 		return nil, err
 	}
@@ -680,6 +2161,8 @@ func (q *Queries) GetTransactionsByRecurringTransactionId(ctx context.Context, a
 			&i.Date,
 			&i.Created,
 			&i.Updated,
+			&i.Deleted,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -694,27 +2177,60 @@ func (q *Queries) GetTransactionsByRecurringTransactionId(ctx context.Context, a
 	return items, nil
 }
 
-const getUnassignedTransactionsBetweenDates = `-- name: GetUnassignedTransactionsBetweenDates :many
-SELECT id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated FROM transactions
-WHERE user_id = $1 AND budget_id IS NULL AND budget_expense_id IS NULL AND date >= $4 AND date <= $5
-ORDER BY date
-LIMIT $2
-OFFSET $3
+const removeTransactionBudgetId = `-- name: RemoveTransactionBudgetId :execrows
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($3::int IS NULL OR version = $3::int)
 `
 
-type GetUnassignedTransactionsBetweenDatesParams struct {
+type RemoveTransactionBudgetIdParams struct {
+	ID      int32
+	UserID  uuid.UUID
+	Version sql.NullInt32
+}
+
+func (q *Queries) RemoveTransactionBudgetId(ctx context.Context, arg RemoveTransactionBudgetIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeTransactionBudgetId, arg.ID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeTransactionBudgetIdForBudget = `-- name: RemoveTransactionBudgetIdForBudget :exec
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE budget_id = $2::text AND user_id = $1
+`
+
+type RemoveTransactionBudgetIdForBudgetParams struct {
+	UserID   uuid.UUID
+	BudgetID string
+}
+
+func (q *Queries) RemoveTransactionBudgetIdForBudget(ctx context.Context, arg RemoveTransactionBudgetIdForBudgetParams) error {
+	_, err := q.db.ExecContext(ctx, removeTransactionBudgetIdForBudget, arg.UserID, arg.BudgetID)
+	return err
+}
+
+const removeTransactionBudgetIdOutsideDates = `-- name: RemoveTransactionBudgetIdOutsideDates :many
+UPDATE transactions
+SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND budget_id = $2::text AND (date < $3 OR date > $4)
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
+`
+
+type RemoveTransactionBudgetIdOutsideDatesParams struct {
 	UserID    uuid.UUID
-	Limit     int32
-	Offset    int32
+	BudgetID  string
 	StartDate time.Time
 	EndDate   time.Time
 }
 
-func (q *Queries) GetUnassignedTransactionsBetweenDates(ctx context.Context, arg GetUnassignedTransactionsBetweenDatesParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, getUnassignedTransactionsBetweenDates,
+func (q *Queries) RemoveTransactionBudgetIdOutsideDates(ctx context.Context, arg RemoveTransactionBudgetIdOutsideDatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, removeTransactionBudgetIdOutsideDates,
 		arg.UserID,
-		arg.Limit,
-		arg.Offset,
+		arg.BudgetID,
 		arg.StartDate,
 		arg.EndDate,
 	)
@@ -737,6 +2253,8 @@ func (q *Queries) GetUnassignedTransactionsBetweenDates(ctx context.Context, arg
 			&i.Date,
 			&i.Created,
 			&i.Updated,
+			&i.Deleted,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -751,74 +2269,229 @@ func (q *Queries) GetUnassignedTransactionsBetweenDates(ctx context.Context, arg
 	return items, nil
 }
 
-const removeTransactionBudgetId = `-- name: RemoveTransactionBudgetId :exec
+const removeTransactionTags = `-- name: RemoveTransactionTags :one
+WITH removed AS (
+    DELETE FROM transaction_tags tt
+    USING transactions t
+    WHERE tt.transaction_id = t.id AND t.user_id = $1 AND t.id = ANY($2::int[]) AND tt.tag = ANY($3::text[])
+    RETURNING tt.transaction_id
+)
+SELECT count(DISTINCT transaction_id) FROM removed
+`
+
+type RemoveTransactionTagsParams struct {
+	UserID uuid.UUID
+	Ids    []int32
+	Tags   []string
+}
+
+func (q *Queries) RemoveTransactionTags(ctx context.Context, arg RemoveTransactionTagsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, removeTransactionTags, arg.UserID, pq.Array(arg.Ids), pq.Array(arg.Tags))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const restoreRecurringTransaction = `-- name: RestoreRecurringTransaction :one
+UPDATE recurring_transactions
+SET deleted = NULL, updated = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, start_date, end_date, interval, days_interval, created, updated, deleted, rrule, description, amount, type, materialized_until
+`
+
+type RestoreRecurringTransactionParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) RestoreRecurringTransaction(ctx context.Context, arg RestoreRecurringTransactionParams) (RecurringTransaction, error) {
+	row := q.db.QueryRowContext(ctx, restoreRecurringTransaction, arg.ID, arg.UserID)
+	var i RecurringTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Interval,
+		&i.DaysInterval,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Rrule,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.MaterializedUntil,
+	)
+	return i, err
+}
+
+const restoreTransaction = `-- name: RestoreTransaction :one
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
 WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type RemoveTransactionBudgetIdParams struct {
+type RestoreTransactionParams struct {
 	ID     int32
 	UserID uuid.UUID
 }
 
-func (q *Queries) RemoveTransactionBudgetId(ctx context.Context, arg RemoveTransactionBudgetIdParams) error {
-	_, err := q.db.ExecContext(ctx, removeTransactionBudgetId, arg.ID, arg.UserID)
-	return err
+func (q *Queries) RestoreTransaction(ctx context.Context, arg RestoreTransactionParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, restoreTransaction, arg.ID, arg.UserID)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BudgetID,
+		&i.BudgetExpenseID,
+		&i.RecurringTransactionID,
+		&i.Description,
+		&i.Amount,
+		&i.Type,
+		&i.Date,
+		&i.Created,
+		&i.Updated,
+		&i.Deleted,
+		&i.Version,
+	)
+	return i, err
 }
 
-const removeTransactionBudgetIdForBudget = `-- name: RemoveTransactionBudgetIdForBudget :exec
+const restoreTransactionsByRecurringTransactionId = `-- name: RestoreTransactionsByRecurringTransactionId :many
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
-WHERE budget_id = $2::text AND user_id = $1
+SET deleted = NULL, updated = (now() at time zone 'utc'), version = version + 1
+WHERE recurring_transaction_id = $2::int AND user_id = $1 AND deleted = $3::timestamp
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type RemoveTransactionBudgetIdForBudgetParams struct {
-	UserID   uuid.UUID
-	BudgetID string
+type RestoreTransactionsByRecurringTransactionIdParams struct {
+	UserID                 uuid.UUID
+	RecurringTransactionID int32
+	Deleted                time.Time
 }
 
-func (q *Queries) RemoveTransactionBudgetIdForBudget(ctx context.Context, arg RemoveTransactionBudgetIdForBudgetParams) error {
-	_, err := q.db.ExecContext(ctx, removeTransactionBudgetIdForBudget, arg.UserID, arg.BudgetID)
+func (q *Queries) RestoreTransactionsByRecurringTransactionId(ctx context.Context, arg RestoreTransactionsByRecurringTransactionIdParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, restoreTransactionsByRecurringTransactionId, arg.UserID, arg.RecurringTransactionID, arg.Deleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setRecurringTransactionMaterializedUntil = `-- name: SetRecurringTransactionMaterializedUntil :exec
+UPDATE recurring_transactions
+SET materialized_until = $2
+WHERE id = $1
+`
+
+type SetRecurringTransactionMaterializedUntilParams struct {
+	ID                int32
+	MaterializedUntil time.Time
+}
+
+func (q *Queries) SetRecurringTransactionMaterializedUntil(ctx context.Context, arg SetRecurringTransactionMaterializedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setRecurringTransactionMaterializedUntil, arg.ID, arg.MaterializedUntil)
 	return err
 }
 
-const removeTransactionBudgetIdOutsideDates = `-- name: RemoveTransactionBudgetIdOutsideDates :exec
+const setTransactionsRecurringTransactionId = `-- name: SetTransactionsRecurringTransactionId :many
 UPDATE transactions
-SET budget_id = NULL, budget_expense_id = NULL, updated = (now() at time zone 'utc')
-WHERE user_id = $1 AND budget_id = $2::text AND (date < $3 OR date > $4)
+SET recurring_transaction_id = $2::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE user_id = $1 AND id = ANY($3::int[]) AND recurring_transaction_id IS NULL AND deleted IS NULL
+RETURNING id, user_id, budget_id, budget_expense_id, recurring_transaction_id, description, amount, type, date, created, updated, deleted, version
 `
 
-type RemoveTransactionBudgetIdOutsideDatesParams struct {
-	UserID    uuid.UUID
-	BudgetID  string
-	StartDate time.Time
-	EndDate   time.Time
+type SetTransactionsRecurringTransactionIdParams struct {
+	UserID                 uuid.UUID
+	RecurringTransactionID int32
+	Ids                    []int32
 }
 
-func (q *Queries) RemoveTransactionBudgetIdOutsideDates(ctx context.Context, arg RemoveTransactionBudgetIdOutsideDatesParams) error {
-	_, err := q.db.ExecContext(ctx, removeTransactionBudgetIdOutsideDates,
-		arg.UserID,
-		arg.BudgetID,
-		arg.StartDate,
-		arg.EndDate,
-	)
-	return err
+func (q *Queries) SetTransactionsRecurringTransactionId(ctx context.Context, arg SetTransactionsRecurringTransactionIdParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, setTransactionsRecurringTransactionId, arg.UserID, arg.RecurringTransactionID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BudgetID,
+			&i.BudgetExpenseID,
+			&i.RecurringTransactionID,
+			&i.Description,
+			&i.Amount,
+			&i.Type,
+			&i.Date,
+			&i.Created,
+			&i.Updated,
+			&i.Deleted,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const updateRecurringTransaction = `-- name: UpdateRecurringTransaction :exec
 UPDATE recurring_transactions 
-SET start_date = $3, end_date = $4, interval = $5, days_interval = $6, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2
+SET start_date = $3, end_date = $4, interval = $5, days_interval = $6, rrule = $7, description = $8, amount = $9, type = $10, updated = (now() at time zone 'utc')
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL
 `
 
 type UpdateRecurringTransactionParams struct {
 	ID           int32
 	UserID       uuid.UUID
 	StartDate    time.Time
-	EndDate      time.Time
+	EndDate      sql.NullTime
 	Interval     string
 	DaysInterval sql.NullInt32
+	Rrule        string
+	Description  string
+	Amount       string
+	Type         string
 }
 
 func (q *Queries) UpdateRecurringTransaction(ctx context.Context, arg UpdateRecurringTransactionParams) error {
@@ -829,14 +2502,18 @@ func (q *Queries) UpdateRecurringTransaction(ctx context.Context, arg UpdateRecu
 		arg.EndDate,
 		arg.Interval,
 		arg.DaysInterval,
+		arg.Rrule,
+		arg.Description,
+		arg.Amount,
+		arg.Type,
 	)
 	return err
 }
 
-const updateTransaction = `-- name: UpdateTransaction :exec
+const updateTransaction = `-- name: UpdateTransaction :execrows
 UPDATE transactions
-SET amount = $3, description = $4, type = $5, date = $6, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2
+SET amount = $3, description = $4, type = $5, date = $6, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($7::int IS NULL OR version = $7::int)
 `
 
 type UpdateTransactionParams struct {
@@ -846,24 +2523,29 @@ type UpdateTransactionParams struct {
 	Description string
 	Type        string
 	Date        time.Time
+	Version     sql.NullInt32
 }
 
-func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionParams) error {
-	_, err := q.db.ExecContext(ctx, updateTransaction,
+func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTransaction,
 		arg.ID,
 		arg.UserID,
 		arg.Amount,
 		arg.Description,
 		arg.Type,
 		arg.Date,
+		arg.Version,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const updateTransactionBudgetId = `-- name: UpdateTransactionBudgetId :exec
+const updateTransactionBudgetId = `-- name: UpdateTransactionBudgetId :execrows
 UPDATE transactions
-SET budget_id = $3::text, budget_expense_id = $4::int, updated = (now() at time zone 'utc')
-WHERE id = $1 AND user_id = $2
+SET budget_id = $3::text, budget_expense_id = $4::int, updated = (now() at time zone 'utc'), version = version + 1
+WHERE id = $1 AND user_id = $2 AND deleted IS NULL AND ($5::int IS NULL OR version = $5::int)
 `
 
 type UpdateTransactionBudgetIdParams struct {
@@ -871,14 +2553,19 @@ type UpdateTransactionBudgetIdParams struct {
 	UserID          uuid.UUID
 	BudgetID        string
 	BudgetExpenseID int32
+	Version         sql.NullInt32
 }
 
-func (q *Queries) UpdateTransactionBudgetId(ctx context.Context, arg UpdateTransactionBudgetIdParams) error {
-	_, err := q.db.ExecContext(ctx, updateTransactionBudgetId,
+func (q *Queries) UpdateTransactionBudgetId(ctx context.Context, arg UpdateTransactionBudgetIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTransactionBudgetId,
 		arg.ID,
 		arg.UserID,
 		arg.BudgetID,
 		arg.BudgetExpenseID,
+		arg.Version,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -12,6 +12,7 @@ type IUserRepository interface {
 	GetByProviderId(ctx context.Context, provider string, providerId string) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Add(ctx context.Context, params CreateUserParams) (*User, error)
+	UpdateSettings(ctx context.Context, params UpdateUserSettingsParams) (*User, error)
 }
 
 type UserRepository struct {
@@ -50,3 +51,9 @@ func (repository *UserRepository) Add(ctx context.Context, params CreateUserPara
 	user, err := db.CreateUser(ctx, params)
 	return &user, err
 }
+
+func (repository *UserRepository) UpdateSettings(ctx context.Context, params UpdateUserSettingsParams) (*User, error) {
+	db := New(repository.db)
+	user, err := db.UpdateUserSettings(ctx, params)
+	return &user, err
+}
@@ -14,7 +14,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, provider, provider_id, username, email)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, provider, provider_id, username, email, avatar, created, updated
+RETURNING id, provider, provider_id, username, email, avatar, created, updated, week_start, fiscal_year_start
 `
 
 type CreateUserParams struct {
@@ -43,12 +43,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Avatar,
 		&i.Created,
 		&i.Updated,
+		&i.WeekStart,
+		&i.FiscalYearStart,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, provider, provider_id, username, email, avatar, created, updated FROM users WHERE email = $1
+SELECT id, provider, provider_id, username, email, avatar, created, updated, week_start, fiscal_year_start FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -63,12 +65,14 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Avatar,
 		&i.Created,
 		&i.Updated,
+		&i.WeekStart,
+		&i.FiscalYearStart,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, provider, provider_id, username, email, avatar, created, updated FROM users WHERE id = $1
+SELECT id, provider, provider_id, username, email, avatar, created, updated, week_start, fiscal_year_start FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
@@ -83,12 +87,14 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Avatar,
 		&i.Created,
 		&i.Updated,
+		&i.WeekStart,
+		&i.FiscalYearStart,
 	)
 	return i, err
 }
 
 const getUserByProviderId = `-- name: GetUserByProviderId :one
-SELECT id, provider, provider_id, username, email, avatar, created, updated FROM users WHERE provider = $1 AND provider_id = $2
+SELECT id, provider, provider_id, username, email, avatar, created, updated, week_start, fiscal_year_start FROM users WHERE provider = $1 AND provider_id = $2
 `
 
 type GetUserByProviderIdParams struct {
@@ -108,6 +114,8 @@ func (q *Queries) GetUserByProviderId(ctx context.Context, arg GetUserByProvider
 		&i.Avatar,
 		&i.Created,
 		&i.Updated,
+		&i.WeekStart,
+		&i.FiscalYearStart,
 	)
 	return i, err
 }
@@ -122,3 +130,34 @@ func (q *Queries) GetUserExists(ctx context.Context, id uuid.UUID) (int32, error
 	err := row.Scan(&column_1)
 	return column_1, err
 }
+
+const updateUserSettings = `-- name: UpdateUserSettings :one
+UPDATE users
+SET week_start = $2, fiscal_year_start = $3, updated = (now() at time zone 'utc')
+WHERE id = $1
+RETURNING id, provider, provider_id, username, email, avatar, created, updated, week_start, fiscal_year_start
+`
+
+type UpdateUserSettingsParams struct {
+	ID              uuid.UUID
+	WeekStart       int16
+	FiscalYearStart int16
+}
+
+func (q *Queries) UpdateUserSettings(ctx context.Context, arg UpdateUserSettingsParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserSettings, arg.ID, arg.WeekStart, arg.FiscalYearStart)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.ProviderID,
+		&i.Username,
+		&i.Email,
+		&i.Avatar,
+		&i.Created,
+		&i.Updated,
+		&i.WeekStart,
+		&i.FiscalYearStart,
+	)
+	return i, err
+}
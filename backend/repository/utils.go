@@ -1,10 +1,20 @@
 package repository
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
 
 const DefaultFetchLimit = 25
 const MaxFetchLimit = 1000
 
+// MaxTagLength is the length of the tag column of transaction_tags
+const MaxTagLength = 32
+
+// ErrVersionConflict is returned when a record was changed by someone else
+// after the version the caller based its change on.
+var ErrVersionConflict = errors.New("version conflict")
+
 func NoRowsFound(err error) bool {
 	return strings.Contains(err.Error(), "no rows in result set")
 }
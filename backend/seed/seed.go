@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
+	"github.com/tvgelderen/fiscora/config"
+	"github.com/tvgelderen/fiscora/recurrence"
 	"github.com/tvgelderen/fiscora/repository"
 	"github.com/tvgelderen/fiscora/types"
 )
@@ -16,7 +19,7 @@ var userId uuid.UUID
 
 func Seed(conn *sql.DB) {
 	userRepository := repository.CreateUserRepository(conn)
-	transactionRepository := repository.CreateTransactionRepository(conn)
+	transactionRepository := repository.CreateTransactionRepository(conn, config.Envs.RecurringHorizon)
 	budgetRepository := repository.CreateBudgetRepository(conn)
 
 	log.Info("Seeding repository.")
@@ -32,7 +35,7 @@ func Seed(conn *sql.DB) {
 
 func SeedMyAccount(conn *sql.DB) {
 	userRepository := repository.CreateUserRepository(conn)
-	transactionRepository := repository.CreateTransactionRepository(conn)
+	transactionRepository := repository.CreateTransactionRepository(conn, config.Envs.RecurringHorizon)
 	budgetRepository := repository.CreateBudgetRepository(conn)
 
 	log.Info("Seeding repository.")
@@ -61,17 +64,22 @@ func createDemoUser(userRepository *repository.UserRepository) {
 
 func createTransactions(transactionRepository *repository.TransactionRepository) {
 	for _, recurringTransaction := range recurringTransactions {
-		_ = transactionRepository.AddRecurring(context.Background(), repository.AddRecurringParams{
-			Params: repository.CreateRecurringTransactionParams{
-				UserID:       userId,
-				StartDate:    recurringTransaction.StartDate.Time,
-				EndDate:      recurringTransaction.EndDate.Time,
-				Interval:     recurringTransaction.Interval.String,
-				DaysInterval: recurringTransaction.DaysInterval.NullInt32,
-			},
-			Amount:      recurringTransaction.Amount,
-			Description: recurringTransaction.Description,
-			Type:        recurringTransaction.Type,
+		rrule, err := recurringTransaction.RecurrenceRule()
+		if err != nil {
+			log.Errorf("Error creating recurrence rule: %v", err.Error())
+			continue
+		}
+
+		_ = transactionRepository.AddRecurring(context.Background(), repository.CreateRecurringTransactionParams{
+			UserID:       userId,
+			StartDate:    recurringTransaction.StartDate.Time,
+			EndDate:      recurringTransaction.EndDate.NullTime,
+			Interval:     recurringTransaction.Interval.String,
+			DaysInterval: recurringTransaction.DaysInterval.NullInt32,
+			Rrule:        rrule,
+			Description:  recurringTransaction.Description,
+			Amount:       strconv.FormatFloat(recurringTransaction.Amount, 'f', -1, 64),
+			Type:         recurringTransaction.Type,
 		})
 	}
 
@@ -107,6 +115,7 @@ var budgets = []repository.CreateBudgetParams{
 		Amount:      "2024",
 		StartDate:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
 		EndDate:     time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC),
+		Type:        repository.BudgetTypeMonthly,
 	},
 	{
 		ID:          budgetId2,
@@ -115,6 +124,7 @@ var budgets = []repository.CreateBudgetParams{
 		Amount:      "1250",
 		StartDate:   time.Date(2024, 8, 7, 0, 0, 0, 0, time.UTC),
 		EndDate:     time.Date(2024, 8, 21, 0, 0, 0, 0, time.UTC),
+		Type:        repository.BudgetTypeCustom,
 	},
 }
 
@@ -123,41 +133,51 @@ var budgetExpenses = []repository.CreateBudgetExpenseParams{
 		BudgetID:        budgetId1,
 		Name:            "Groceries",
 		AllocatedAmount: "500",
+		Rollover:        repository.BudgetRolloverUnspent,
+		Categories:      []string{repository.ExpenseTypeGroceries},
 	},
 	{
 		BudgetID:        budgetId1,
 		Name:            "Utilities",
 		AllocatedAmount: "300",
+		Rollover:        repository.BudgetRolloverReset,
+		Categories:      []string{repository.ExpenseTypeUtilities},
 	},
 	{
 		BudgetID:        budgetId1,
 		Name:            "Entertainment",
 		AllocatedAmount: "100",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 	{
 		BudgetID:        budgetId1,
 		Name:            "Savings",
 		AllocatedAmount: "500",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 	{
 		BudgetID:        budgetId2,
 		Name:            "Accomodation",
 		AllocatedAmount: "600",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 	{
 		BudgetID:        budgetId2,
 		Name:            "Transportation",
 		AllocatedAmount: "400",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 	{
 		BudgetID:        budgetId2,
 		Name:            "Activities",
 		AllocatedAmount: "300",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 	{
 		BudgetID:        budgetId2,
 		Name:            "Food",
 		AllocatedAmount: "200",
+		Rollover:        repository.BudgetRolloverReset,
 	},
 }
 
@@ -169,7 +189,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.IncomeTypeSalary,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2025, 1, 25, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Weekly income (Starting May 12, 2024)
@@ -179,7 +199,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.IncomeTypePassive,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2025, 4, 12, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalWeekly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalWeekly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Mortgage (Monthly, starting January 1, 2024)
@@ -189,7 +209,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeMortgage,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 1, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Utilities (Monthly, starting January 1, 2024)
@@ -199,7 +219,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeUtilities,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 1, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Internet (Monthly, starting January 6, 2024)
@@ -209,7 +229,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeUtilities,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 6, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// HOA (Monthly, starting January 1, 2024)
@@ -219,7 +239,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeFixed,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 1, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Health insurance (Monthly, starting January 10, 2024)
@@ -229,7 +249,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeInsurance,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 10, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	// Various subscriptions (Monthly, starting January 10, 2024)
@@ -239,7 +259,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 10, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	{
@@ -248,7 +268,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 15, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	{
@@ -257,7 +277,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 5, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	{
@@ -266,7 +286,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 1, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	{
@@ -275,7 +295,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 1, 15, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 	{
@@ -284,7 +304,7 @@ var recurringTransactions = []types.TransactionForm{
 		Type:         repository.ExpenseTypeSubscriptions,
 		StartDate:    types.NewNullTimeFromTime(time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
 		EndDate:      types.NewNullTimeFromTime(time.Date(2032, 12, 20, 0, 0, 0, 0, time.UTC)),
-		Interval:     types.NewNullStringFromString(repository.TransactionIntervalMonthly),
+		Interval:     types.NewNullStringFromString(recurrence.IntervalMonthly),
 		DaysInterval: types.NewNullIntFromInt(0),
 	},
 }
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type AuditLogReturn struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     string          `json:"action"`
+	ActorID    *string         `json:"actorId"`
+	RequestID  NullString      `json:"requestId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Created    time.Time       `json:"created"`
+}
+
+func ToAuditLogReturns(auditLogs *[]repository.AuditLog) []AuditLogReturn {
+	result := make([]AuditLogReturn, len(*auditLogs))
+	for idx, auditLog := range *auditLogs {
+		result[idx] = ToAuditLogReturn(auditLog)
+	}
+
+	return result
+}
+
+func ToAuditLogReturn(auditLog repository.AuditLog) AuditLogReturn {
+	result := AuditLogReturn{
+		ID:         auditLog.ID,
+		EntityType: auditLog.EntityType,
+		EntityID:   auditLog.EntityID,
+		Action:     auditLog.Action,
+		RequestID:  NewNullString(auditLog.RequestID),
+		Before:     json.RawMessage("null"),
+		After:      json.RawMessage("null"),
+		Created:    auditLog.Created,
+	}
+
+	if auditLog.ActorID.Valid {
+		actorId := auditLog.ActorID.UUID.String()
+		result.ActorID = &actorId
+	}
+	if auditLog.Before.Valid {
+		result.Before = auditLog.Before.RawMessage
+	}
+	if auditLog.After.Valid {
+		result.After = auditLog.After.RawMessage
+	}
+
+	return result
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"database/sql"
+	"math"
 	"strconv"
 	"time"
 
@@ -13,6 +15,8 @@ type BaseBudget struct {
 	Amount      float64   `json:"amount"`
 	StartDate   time.Time `json:"startDate"`
 	EndDate     time.Time `json:"endDate"`
+	Type        string    `json:"type"`
+	ZeroBased   bool      `json:"zeroBased"`
 }
 
 type BudgetCreateRequest struct {
@@ -25,11 +29,20 @@ type BudgetUpdateRequest struct {
 
 type BudgetReturn struct {
 	BaseBudget
-	ID           string                 `json:"id"`
-	Created      time.Time              `json:"created"`
-	Updated      time.Time              `json:"updated"`
-	Expenses     *[]BudgetExpenseReturn `json:"expenses"`
-	Transactions *[]TransactionReturn   `json:"transactions"`
+	ID             string                 `json:"id"`
+	Created        time.Time              `json:"created"`
+	Updated        time.Time              `json:"updated"`
+	Deleted        NullTime               `json:"deleted"`
+	Version        int32                  `json:"version"`
+	SpentAmount    float64                `json:"spentAmount"`
+	Remaining      float64                `json:"remaining"`
+	PercentageUsed float64                `json:"percentageUsed"`
+	Overspent      bool                   `json:"overspent"`
+	Expenses       *[]BudgetExpenseReturn `json:"expenses"`
+	Transactions   *[]TransactionReturn   `json:"transactions"`
+	// UnmatchedTransactions is the spending within the dates of the budget
+	// that counts against none of its expenses
+	UnmatchedTransactions *[]TransactionReturn `json:"unmatchedTransactions"`
 }
 
 type BudgetForm struct {
@@ -38,9 +51,15 @@ type BudgetForm struct {
 }
 
 type BaseBudgetExpense struct {
-	Name            string  `json:"name"`
-	AllocatedAmount float64 `json:"allocatedAmount"`
-	CurrentAmount   float64 `json:"currentAmount"`
+	Name            string   `json:"name"`
+	AllocatedAmount float64  `json:"allocatedAmount"`
+	CurrentAmount   float64  `json:"currentAmount"`
+	Rollover        string   `json:"rollover"`
+	Categories      []string `json:"categories"`
+	Tags            []string `json:"tags"`
+	Payees          []string `json:"payees"`
+	// Thresholds are the percentages of the expense that trigger an alert
+	Thresholds []int32 `json:"thresholds"`
 }
 
 type BudgetExpenseCreateRequest struct {
@@ -53,62 +72,395 @@ type BudgetExpenseUpdateRequest struct {
 
 type BudgetExpenseForm struct {
 	BaseBudgetExpense
-	ID int32 `json:"id"`
+	ID      int32 `json:"id"`
+	Version int32 `json:"version"`
 }
 
+// BudgetExpenseReturn has the amount spent within the dates of the budget as
+// the current amount. The rollover amount was carried over from the period it
+// came from, and counts towards what is remaining.
 type BudgetExpenseReturn struct {
 	BaseBudgetExpense
-	ID int32 `json:"id"`
+	ID             int32                 `json:"id"`
+	Version        int32                 `json:"version"`
+	RolloverAmount float64               `json:"rolloverAmount"`
+	RolloverFrom   *BudgetRolloverSource `json:"rolloverFrom"`
+	Remaining      float64               `json:"remaining"`
+	PercentageUsed float64               `json:"percentageUsed"`
+	Overspent      bool                  `json:"overspent"`
+}
+
+// BudgetRolloverSource is the closed period a rollover amount comes from.
+type BudgetRolloverSource struct {
+	PeriodID  int32     `json:"periodId"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
 }
 
 func ToBudgetReturn(budget *repository.BudgetWithExpenses) BudgetReturn {
 	amount, _ := strconv.ParseFloat(budget.Amount, 64)
 
+	var spent float64
+	available := amount
 	expenses := make([]BudgetExpenseReturn, len(budget.Expenses))
 	for idx, expense := range budget.Expenses {
 		expenses[idx] = ToBudgetExpenseReturn(&expense)
+		spent += expenses[idx].CurrentAmount
+		available += expenses[idx].RolloverAmount
 	}
 
 	return BudgetReturn{
-		ID:           budget.ID,
-		Created:      budget.Created,
-		Updated:      budget.Updated,
-		Expenses:     &expenses,
-		Transactions: nil,
+		ID:             budget.ID,
+		Created:        budget.Created,
+		Updated:        budget.Updated,
+		Deleted:        NewNullTime(budget.Deleted),
+		Version:        budget.Version,
+		SpentAmount:    spent,
+		Remaining:      available - spent,
+		PercentageUsed: percentageUsed(spent, available),
+		Overspent:      spent > available,
+		Expenses:       &expenses,
+		Transactions:   nil,
 		BaseBudget: BaseBudget{
 			Name:        budget.Name,
 			Description: budget.Description,
 			Amount:      amount,
 			StartDate:   budget.StartDate,
 			EndDate:     budget.EndDate,
+			Type:        budget.Type,
+			ZeroBased:   budget.ZeroBased,
 		},
 	}
 }
 
-func ToBudgetExpenseReturn(expense *repository.BudgetExpense) BudgetExpenseReturn {
+func ToBudgetExpenseReturn(expense *repository.FullBudgetExpense) BudgetExpenseReturn {
 	allocatedAmount, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
 	currentAmount, _ := strconv.ParseFloat(expense.CurrentAmount, 64)
+	rolloverAmount, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+	available := allocatedAmount + rolloverAmount
 
 	return BudgetExpenseReturn{
-		ID: expense.ID,
+		ID:             expense.ID,
+		Version:        expense.Version,
+		RolloverAmount: rolloverAmount,
+		RolloverFrom:   toBudgetRolloverSource(expense.RolloverPeriodID, expense.RolloverStartDate, expense.RolloverEndDate),
+		Remaining:      available - currentAmount,
+		PercentageUsed: percentageUsed(currentAmount, available),
+		Overspent:      currentAmount > available,
 		BaseBudgetExpense: BaseBudgetExpense{
 			Name:            expense.Name,
 			AllocatedAmount: allocatedAmount,
 			CurrentAmount:   currentAmount,
+			Rollover:        expense.Rollover,
+			Categories:      expense.Categories,
+			Tags:            expense.Tags,
+			Payees:          expense.Payees,
+			Thresholds:      expense.Thresholds,
 		},
 	}
 }
 
+func toBudgetRolloverSource(periodId sql.NullInt32, startDate sql.NullTime, endDate sql.NullTime) *BudgetRolloverSource {
+	if !periodId.Valid {
+		return nil
+	}
+
+	return &BudgetRolloverSource{
+		PeriodID:  periodId.Int32,
+		StartDate: startDate.Time,
+		EndDate:   endDate.Time,
+	}
+}
+
+// percentageUsed returns how much of the amount is spent, rounded to two
+// decimals. Spending anything from nothing is 100 percent.
+func percentageUsed(spent float64, amount float64) float64 {
+	if amount <= 0 {
+		if spent > 0 {
+			return 100
+		}
+		return 0
+	}
+
+	return math.Round(spent/amount*10000) / 100
+}
+
+// BudgetPeriodReturn is a period of a periodic budget. The current period is
+// the last one and has not closed yet.
+type BudgetPeriodReturn struct {
+	ID             NullInt                     `json:"id"`
+	StartDate      time.Time                   `json:"startDate"`
+	EndDate        time.Time                   `json:"endDate"`
+	Amount         float64                     `json:"amount"`
+	SpentAmount    float64                     `json:"spentAmount"`
+	Remaining      float64                     `json:"remaining"`
+	PercentageUsed float64                     `json:"percentageUsed"`
+	Overspent      bool                        `json:"overspent"`
+	Current        bool                        `json:"current"`
+	Expenses       []BudgetPeriodExpenseReturn `json:"expenses"`
+}
+
+type BudgetPeriodExpenseReturn struct {
+	BudgetExpenseID NullInt               `json:"budgetExpenseId"`
+	Name            string                `json:"name"`
+	AllocatedAmount float64               `json:"allocatedAmount"`
+	SpentAmount     float64               `json:"spentAmount"`
+	Rollover        string                `json:"rollover"`
+	RolloverAmount  float64               `json:"rolloverAmount"`
+	RolloverFrom    *BudgetRolloverSource `json:"rolloverFrom"`
+	Remaining       float64               `json:"remaining"`
+	PercentageUsed  float64               `json:"percentageUsed"`
+	Overspent       bool                  `json:"overspent"`
+}
+
+func ToBudgetPeriodReturns(periods *[]repository.BudgetPeriodWithExpenses, current *repository.BudgetWithExpenses) []BudgetPeriodReturn {
+	result := make([]BudgetPeriodReturn, 0, len(*periods)+1)
+	for _, period := range *periods {
+		amount, _ := strconv.ParseFloat(period.Amount, 64)
+
+		var spent float64
+		available := amount
+		expenses := make([]BudgetPeriodExpenseReturn, len(period.Expenses))
+		for idx, expense := range period.Expenses {
+			allocatedAmount, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+			spentAmount, _ := strconv.ParseFloat(expense.SpentAmount, 64)
+			rolloverAmount, _ := strconv.ParseFloat(expense.RolloverAmount, 64)
+			spent += spentAmount
+			available += rolloverAmount
+
+			expenses[idx] = BudgetPeriodExpenseReturn{
+				BudgetExpenseID: NewNullInt(expense.BudgetExpenseID),
+				Name:            expense.Name,
+				AllocatedAmount: allocatedAmount,
+				SpentAmount:     spentAmount,
+				Rollover:        expense.Rollover,
+				RolloverAmount:  rolloverAmount,
+				RolloverFrom:    toBudgetRolloverSource(expense.RolloverPeriodID, expense.RolloverStartDate, expense.RolloverEndDate),
+				Remaining:       allocatedAmount + rolloverAmount - spentAmount,
+				PercentageUsed:  percentageUsed(spentAmount, allocatedAmount+rolloverAmount),
+				Overspent:       spentAmount > allocatedAmount+rolloverAmount,
+			}
+		}
+
+		result = append(result, BudgetPeriodReturn{
+			ID:             NullInt{NullInt32: sql.NullInt32{Int32: period.ID, Valid: true}},
+			StartDate:      period.StartDate,
+			EndDate:        period.EndDate,
+			Amount:         amount,
+			SpentAmount:    spent,
+			Remaining:      available - spent,
+			PercentageUsed: percentageUsed(spent, available),
+			Overspent:      spent > available,
+			Current:        false,
+			Expenses:       expenses,
+		})
+	}
+
+	budget := ToBudgetReturn(current)
+	expenses := make([]BudgetPeriodExpenseReturn, len(*budget.Expenses))
+	for idx, expense := range *budget.Expenses {
+		expenses[idx] = BudgetPeriodExpenseReturn{
+			BudgetExpenseID: NullInt{NullInt32: sql.NullInt32{Int32: expense.ID, Valid: true}},
+			Name:            expense.Name,
+			AllocatedAmount: expense.AllocatedAmount,
+			SpentAmount:     expense.CurrentAmount,
+			Rollover:        expense.Rollover,
+			RolloverAmount:  expense.RolloverAmount,
+			RolloverFrom:    expense.RolloverFrom,
+			Remaining:       expense.Remaining,
+			PercentageUsed:  expense.PercentageUsed,
+			Overspent:       expense.Overspent,
+		}
+	}
+
+	return append(result, BudgetPeriodReturn{
+		StartDate:      budget.StartDate,
+		EndDate:        budget.EndDate,
+		Amount:         budget.Amount,
+		SpentAmount:    budget.SpentAmount,
+		Remaining:      budget.Remaining,
+		PercentageUsed: budget.PercentageUsed,
+		Overspent:      budget.Overspent,
+		Current:        true,
+		Expenses:       expenses,
+	})
+}
+
 const (
-	BudgetTypeWeekly  string = "Weekly"
-	BudgetTypeMonthly        = "Monthly"
-	BudgetTypeYearly         = "Yearly"
-	BudgetTypeCustom         = "Custom"
+	BudgetTypeWeekly  = repository.BudgetTypeWeekly
+	BudgetTypeMonthly = repository.BudgetTypeMonthly
+	BudgetTypeYearly  = repository.BudgetTypeYearly
+	BudgetTypeCustom  = repository.BudgetTypeCustom
 )
 
-var BudgetTypes = []string{
-	BudgetTypeWeekly,
-	BudgetTypeMonthly,
-	BudgetTypeYearly,
-	BudgetTypeCustom,
+var BudgetTypes = repository.BudgetTypes
+
+type EnvelopeReturn struct {
+	ExpenseID int32   `json:"expenseId"`
+	Name      string  `json:"name"`
+	Assigned  float64 `json:"assigned"`
+	Spent     float64 `json:"spent"`
+	Balance   float64 `json:"balance"`
+	Overspent bool    `json:"overspent"`
+}
+
+type EnvelopeSummaryReturn struct {
+	Income       float64          `json:"income"`
+	Assigned     float64          `json:"assigned"`
+	ToBeBudgeted float64          `json:"toBeBudgeted"`
+	OverAssigned bool             `json:"overAssigned"`
+	Envelopes    []EnvelopeReturn `json:"envelopes"`
+}
+
+// EnvelopeAssignForm assigns money from the pool to an envelope.
+type EnvelopeAssignForm struct {
+	ExpenseID   int32   `json:"expenseId"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// EnvelopeMoveForm moves money between envelopes. Without a source the money
+// is assigned from the pool, and without a destination it goes back to it.
+type EnvelopeMoveForm struct {
+	FromExpenseID NullInt `json:"fromExpenseId"`
+	ToExpenseID   NullInt `json:"toExpenseId"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+}
+
+type EnvelopeLedgerEntryReturn struct {
+	Date          time.Time `json:"date"`
+	Kind          string    `json:"kind"`
+	Description   string    `json:"description"`
+	Amount        float64   `json:"amount"`
+	TransactionID NullInt   `json:"transactionId"`
+	MovementID    NullInt   `json:"movementId"`
+	FromExpenseID NullInt   `json:"fromExpenseId"`
+	ToExpenseID   NullInt   `json:"toExpenseId"`
+	FromBalance   *float64  `json:"fromBalance"`
+	ToBalance     *float64  `json:"toBalance"`
+	ToBeBudgeted  float64   `json:"toBeBudgeted"`
+}
+
+func ToEnvelopeSummaryReturn(summary *repository.EnvelopeSummary) EnvelopeSummaryReturn {
+	envelopes := make([]EnvelopeReturn, len(summary.Envelopes))
+	for idx, envelope := range summary.Envelopes {
+		envelopes[idx] = EnvelopeReturn{
+			ExpenseID: envelope.ExpenseID,
+			Name:      envelope.Name,
+			Assigned:  envelope.Assigned,
+			Spent:     envelope.Spent,
+			Balance:   envelope.Balance,
+			Overspent: envelope.Balance < 0,
+		}
+	}
+
+	return EnvelopeSummaryReturn{
+		Income:       summary.Income,
+		Assigned:     summary.Assigned,
+		ToBeBudgeted: summary.ToBeBudgeted,
+		OverAssigned: summary.ToBeBudgeted < 0,
+		Envelopes:    envelopes,
+	}
+}
+
+func ToEnvelopeLedgerEntryReturns(entries *[]repository.EnvelopeLedgerEntry) []EnvelopeLedgerEntryReturn {
+	result := make([]EnvelopeLedgerEntryReturn, len(*entries))
+	for idx, entry := range *entries {
+		result[idx] = EnvelopeLedgerEntryReturn{
+			Date:          entry.Date,
+			Kind:          entry.Kind,
+			Description:   entry.Description,
+			Amount:        entry.Amount,
+			TransactionID: NewNullInt(entry.TransactionID),
+			MovementID:    NewNullInt(entry.MovementID),
+			FromExpenseID: NewNullInt(entry.FromExpenseID),
+			ToExpenseID:   NewNullInt(entry.ToExpenseID),
+			FromBalance:   nullFloatPtr(entry.FromBalance),
+			ToBalance:     nullFloatPtr(entry.ToBalance),
+			ToBeBudgeted:  entry.ToBeBudgeted,
+		}
+	}
+
+	return result
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+type BudgetTemplateReturn struct {
+	ID          int32                         `json:"id"`
+	Name        string                        `json:"name"`
+	Description string                        `json:"description"`
+	Amount      float64                       `json:"amount"`
+	Type        string                        `json:"type"`
+	ZeroBased   bool                          `json:"zeroBased"`
+	Created     time.Time                     `json:"created"`
+	Expenses    []BudgetTemplateExpenseReturn `json:"expenses"`
+}
+
+type BudgetTemplateExpenseReturn struct {
+	Name            string   `json:"name"`
+	AllocatedAmount float64  `json:"allocatedAmount"`
+	Rollover        string   `json:"rollover"`
+	Categories      []string `json:"categories"`
+	Tags            []string `json:"tags"`
+	Payees          []string `json:"payees"`
+	Thresholds      []int32  `json:"thresholds"`
+}
+
+type BudgetTemplateForm struct {
+	Name string `json:"name"`
+}
+
+// BudgetCopyForm creates a budget from a template or another budget. The name
+// defaults to the name of the template or budget, and the amounts are scaled
+// by the percentage, which defaults to 100.
+type BudgetCopyForm struct {
+	Name       string   `json:"name"`
+	StartDate  NullTime `json:"startDate"`
+	EndDate    NullTime `json:"endDate"`
+	Percentage float64  `json:"percentage"`
+}
+
+func ToBudgetTemplateReturns(templates *[]repository.BudgetTemplateWithExpenses) []BudgetTemplateReturn {
+	result := make([]BudgetTemplateReturn, len(*templates))
+	for idx, template := range *templates {
+		result[idx] = ToBudgetTemplateReturn(&template)
+	}
+
+	return result
+}
+
+func ToBudgetTemplateReturn(template *repository.BudgetTemplateWithExpenses) BudgetTemplateReturn {
+	amount, _ := strconv.ParseFloat(template.Amount, 64)
+
+	expenses := make([]BudgetTemplateExpenseReturn, len(template.Expenses))
+	for idx, expense := range template.Expenses {
+		allocatedAmount, _ := strconv.ParseFloat(expense.AllocatedAmount, 64)
+		expenses[idx] = BudgetTemplateExpenseReturn{
+			Name:            expense.Name,
+			AllocatedAmount: allocatedAmount,
+			Rollover:        expense.Rollover,
+			Categories:      expense.Categories,
+			Tags:            expense.Tags,
+			Payees:          expense.Payees,
+			Thresholds:      expense.Thresholds,
+		}
+	}
+
+	return BudgetTemplateReturn{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		Amount:      amount,
+		Type:        template.Type,
+		ZeroBased:   template.ZeroBased,
+		Created:     template.Created,
+		Expenses:    expenses,
+	}
 }
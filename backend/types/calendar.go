@@ -0,0 +1,70 @@
+package types
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type CalendarReturn struct {
+	StartDate    time.Time           `json:"startDate"`
+	EndDate      time.Time           `json:"endDate"`
+	StartBalance float64             `json:"startBalance"`
+	EndBalance   float64             `json:"endBalance"`
+	Days         []CalendarDayReturn `json:"days"`
+}
+
+type CalendarDayReturn struct {
+	Date         time.Time           `json:"date"`
+	Income       float64             `json:"income"`
+	Expense      float64             `json:"expense"`
+	Balance      float64             `json:"balance"`
+	Transactions []TransactionReturn `json:"transactions"`
+}
+
+type CalendarTokenReturn struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// GetCalendar groups the transactions, ordered by date, per day in UTC. The
+// balance of a day is the balance at the end of that day. Days without
+// transactions are left out.
+func GetCalendar(start time.Time, end time.Time, startBalance float64, transactions []repository.FullTransaction) CalendarReturn {
+	result := CalendarReturn{
+		StartDate:    start,
+		EndDate:      end,
+		StartBalance: startBalance,
+		EndBalance:   startBalance,
+		Days:         []CalendarDayReturn{},
+	}
+
+	balance := startBalance
+	for _, transaction := range transactions {
+		amount, _ := strconv.ParseFloat(transaction.Amount, 64)
+		balance += amount
+
+		date := transaction.Date.UTC()
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if len(result.Days) == 0 || !result.Days[len(result.Days)-1].Date.Equal(date) {
+			result.Days = append(result.Days, CalendarDayReturn{
+				Date:         date,
+				Transactions: []TransactionReturn{},
+			})
+		}
+
+		day := &result.Days[len(result.Days)-1]
+		if amount > 0 {
+			day.Income += amount
+		} else {
+			day.Expense += math.Abs(amount)
+		}
+		day.Balance = balance
+		day.Transactions = append(day.Transactions, ToTransactionReturn(transaction))
+	}
+	result.EndBalance = balance
+
+	return result
+}
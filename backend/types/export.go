@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// ExportReturn is everything of a user that is not in the trash.
+type ExportReturn struct {
+	Exported     time.Time           `json:"exported"`
+	User         User                `json:"user"`
+	Transactions []TransactionReturn `json:"transactions"`
+	Budgets      []BudgetReturn      `json:"budgets"`
+	Goals        []GoalReturn        `json:"goals"`
+}
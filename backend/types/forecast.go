@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+
+	"github.com/tvgelderen/fiscora/forecast"
+)
+
+type ForecastReturn struct {
+	StartDate time.Time                `json:"startDate"`
+	EndDate   time.Time                `json:"endDate"`
+	Estimates []ForecastEstimateReturn `json:"estimates"`
+	Points    []ForecastPointReturn    `json:"points"`
+}
+
+type ForecastEstimateReturn struct {
+	Type        string  `json:"type"`
+	Optimistic  float64 `json:"optimistic"`
+	Expected    float64 `json:"expected"`
+	Pessimistic float64 `json:"pessimistic"`
+}
+
+type ForecastPointReturn struct {
+	Date        time.Time `json:"date"`
+	Optimistic  float64   `json:"optimistic"`
+	Expected    float64   `json:"expected"`
+	Pessimistic float64   `json:"pessimistic"`
+}
+
+func ToForecastReturn(start time.Time, end time.Time, result forecast.Forecast) ForecastReturn {
+	estimates := make([]ForecastEstimateReturn, len(result.Estimates))
+	for idx, estimate := range result.Estimates {
+		estimates[idx] = ForecastEstimateReturn{
+			Type:        estimate.Category,
+			Optimistic:  estimate.Optimistic,
+			Expected:    estimate.Expected,
+			Pessimistic: estimate.Pessimistic,
+		}
+	}
+
+	points := make([]ForecastPointReturn, len(result.Points))
+	for idx, point := range result.Points {
+		points[idx] = ForecastPointReturn{
+			Date:        point.Date,
+			Optimistic:  point.Optimistic,
+			Expected:    point.Expected,
+			Pessimistic: point.Pessimistic,
+		}
+	}
+
+	return ForecastReturn{
+		StartDate: start,
+		EndDate:   end,
+		Estimates: estimates,
+		Points:    points,
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type BaseGoal struct {
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	TargetAmount  float64   `json:"targetAmount"`
+	InitialAmount float64   `json:"initialAmount"`
+	StartDate     time.Time `json:"startDate"`
+	TargetDate    time.Time `json:"targetDate"`
+	// Tags are the tags of the transactions that contribute to the goal
+	Tags []string `json:"tags"`
+	// InvestmentAccountID is the investment account the goal is saved in,
+	// its market value counts as saved
+	InvestmentAccountID NullInt `json:"investmentAccountId"`
+}
+
+type GoalForm struct {
+	BaseGoal
+}
+
+type GoalReturn struct {
+	BaseGoal
+	ID           int32                `json:"id"`
+	Created      time.Time            `json:"created"`
+	Updated      time.Time            `json:"updated"`
+	Version      int32                `json:"version"`
+	Progress     GoalProgressReturn   `json:"progress"`
+	Transactions *[]TransactionReturn `json:"transactions"`
+}
+
+// GoalProgressReturn is the progress of a goal as of today. Expected is what
+// should be saved today to reach the target at a steady pace.
+type GoalProgressReturn struct {
+	Saved           float64 `json:"saved"`
+	Remaining       float64 `json:"remaining"`
+	Percentage      float64 `json:"percentage"`
+	Expected        float64 `json:"expected"`
+	MonthsLeft      int     `json:"monthsLeft"`
+	RequiredMonthly float64 `json:"requiredMonthly"`
+	Status          string  `json:"status"`
+}
+
+func ToGoalReturns(goals *[]repository.GoalWithProgress) []GoalReturn {
+	returnGoals := make([]GoalReturn, len(*goals))
+	for idx, goal := range *goals {
+		returnGoals[idx] = ToGoalReturn(&goal)
+	}
+
+	return returnGoals
+}
+
+func ToGoalReturn(goal *repository.GoalWithProgress) GoalReturn {
+	targetAmount, _ := strconv.ParseFloat(goal.TargetAmount, 64)
+	initialAmount, _ := strconv.ParseFloat(goal.InitialAmount, 64)
+
+	return GoalReturn{
+		ID:      goal.ID,
+		Created: goal.Created,
+		Updated: goal.Updated,
+		Version: goal.Version,
+		Progress: GoalProgressReturn{
+			Saved:           goal.Progress.Saved,
+			Remaining:       goal.Progress.Remaining,
+			Percentage:      goal.Progress.Percentage,
+			Expected:        goal.Progress.Expected,
+			MonthsLeft:      goal.Progress.MonthsLeft,
+			RequiredMonthly: goal.Progress.RequiredMonthly,
+			Status:          goal.Progress.Status,
+		},
+		Transactions: nil,
+		BaseGoal: BaseGoal{
+			Name:                goal.Name,
+			Description:         goal.Description,
+			TargetAmount:        targetAmount,
+			InitialAmount:       initialAmount,
+			StartDate:           goal.StartDate,
+			TargetDate:          goal.TargetDate,
+			Tags:                goal.Tags,
+			InvestmentAccountID: NewNullInt(goal.InvestmentAccountID),
+		},
+	}
+}
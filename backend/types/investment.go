@@ -0,0 +1,178 @@
+package types
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/tvgelderen/fiscora/portfolio"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type BaseInvestmentAccount struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	CostBasisMethod string `json:"costBasisMethod"`
+}
+
+type InvestmentAccountForm struct {
+	BaseInvestmentAccount
+}
+
+// InvestmentAccountReturn is an investment account with its holdings valued
+// at the last known prices. The trades are only included for a single
+// account.
+type InvestmentAccountReturn struct {
+	BaseInvestmentAccount
+	PortfolioReturn
+	ID      int32                    `json:"id"`
+	Created time.Time                `json:"created"`
+	Updated time.Time                `json:"updated"`
+	Version int32                    `json:"version"`
+	Trades  *[]InvestmentTradeReturn `json:"trades"`
+}
+
+type PortfolioReturn struct {
+	CostBasis      float64         `json:"costBasis"`
+	MarketValue    float64         `json:"marketValue"`
+	UnrealizedGain float64         `json:"unrealizedGain"`
+	RealizedGain   float64         `json:"realizedGain"`
+	Dividends      float64         `json:"dividends"`
+	Holdings       []HoldingReturn `json:"holdings"`
+}
+
+type HoldingReturn struct {
+	Symbol         string    `json:"symbol"`
+	Quantity       float64   `json:"quantity"`
+	CostBasis      float64   `json:"costBasis"`
+	Price          float64   `json:"price"`
+	PriceDate      time.Time `json:"priceDate"`
+	MarketValue    float64   `json:"marketValue"`
+	UnrealizedGain float64   `json:"unrealizedGain"`
+	RealizedGain   float64   `json:"realizedGain"`
+	Dividends      float64   `json:"dividends"`
+}
+
+// InvestmentTradeForm buys or sells a quantity at a price per unit, or pays a
+// dividend of the price per unit over the quantity.
+type InvestmentTradeForm struct {
+	Symbol   string    `json:"symbol"`
+	Kind     string    `json:"kind"`
+	Date     time.Time `json:"date"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Fees     float64   `json:"fees"`
+}
+
+type InvestmentTradeReturn struct {
+	InvestmentTradeForm
+	ID int32 `json:"id"`
+}
+
+type InvestmentPriceForm struct {
+	Symbol string    `json:"symbol"`
+	Date   time.Time `json:"date"`
+	Price  float64   `json:"price"`
+}
+
+type InvestmentPriceReturn struct {
+	InvestmentPriceForm
+}
+
+// NetWorthReturn is the balance of the transactions plus the value of the
+// investments minus what is left of the loans.
+type NetWorthReturn struct {
+	Date        time.Time `json:"date"`
+	Cash        float64   `json:"cash"`
+	Investments float64   `json:"investments"`
+	Loans       float64   `json:"loans"`
+	NetWorth    float64   `json:"netWorth"`
+}
+
+func ToInvestmentAccountReturns(accounts *[]repository.InvestmentAccountWithPortfolio) []InvestmentAccountReturn {
+	returnAccounts := make([]InvestmentAccountReturn, len(*accounts))
+	for idx, account := range *accounts {
+		returnAccounts[idx] = ToInvestmentAccountReturn(&account)
+	}
+
+	return returnAccounts
+}
+
+func ToInvestmentAccountReturn(account *repository.InvestmentAccountWithPortfolio) InvestmentAccountReturn {
+	return InvestmentAccountReturn{
+		ID:              account.ID,
+		Created:         account.Created,
+		Updated:         account.Updated,
+		Version:         account.Version,
+		PortfolioReturn: ToPortfolioReturn(account.Portfolio),
+		Trades:          nil,
+		BaseInvestmentAccount: BaseInvestmentAccount{
+			Name:            account.Name,
+			Description:     account.Description,
+			CostBasisMethod: account.CostBasisMethod,
+		},
+	}
+}
+
+func ToPortfolioReturn(value portfolio.Portfolio) PortfolioReturn {
+	holdings := make([]HoldingReturn, len(value.Holdings))
+	for idx, holding := range value.Holdings {
+		holdings[idx] = HoldingReturn{
+			Symbol:         holding.Symbol,
+			Quantity:       holding.Quantity,
+			CostBasis:      holding.CostBasis,
+			Price:          holding.Price,
+			PriceDate:      holding.PriceDate,
+			MarketValue:    holding.MarketValue,
+			UnrealizedGain: holding.UnrealizedGain,
+			RealizedGain:   holding.RealizedGain,
+			Dividends:      holding.Dividends,
+		}
+	}
+
+	return PortfolioReturn{
+		CostBasis:      value.CostBasis,
+		MarketValue:    value.MarketValue,
+		UnrealizedGain: value.UnrealizedGain,
+		RealizedGain:   value.RealizedGain,
+		Dividends:      value.Dividends,
+		Holdings:       holdings,
+	}
+}
+
+func ToInvestmentTradeReturns(trades []repository.InvestmentTrade) []InvestmentTradeReturn {
+	returnTrades := make([]InvestmentTradeReturn, len(trades))
+	for idx, trade := range trades {
+		quantity, _ := strconv.ParseFloat(trade.Quantity, 64)
+		price, _ := strconv.ParseFloat(trade.Price, 64)
+		fees, _ := strconv.ParseFloat(trade.Fees, 64)
+		returnTrades[idx] = InvestmentTradeReturn{
+			ID: trade.ID,
+			InvestmentTradeForm: InvestmentTradeForm{
+				Symbol:   trade.Symbol,
+				Kind:     trade.Kind,
+				Date:     trade.Date,
+				Quantity: quantity,
+				Price:    price,
+				Fees:     fees,
+			},
+		}
+	}
+
+	return returnTrades
+}
+
+func ToInvestmentPriceReturns(prices *[]repository.InvestmentPrice) []InvestmentPriceReturn {
+	returnPrices := make([]InvestmentPriceReturn, len(*prices))
+	for idx, price := range *prices {
+		value, _ := strconv.ParseFloat(price.Price, 64)
+		returnPrices[idx] = InvestmentPriceReturn{
+			InvestmentPriceForm: InvestmentPriceForm{
+				Symbol: price.Symbol,
+				Date:   price.Date,
+				Price:  value,
+			},
+		}
+	}
+
+	return returnPrices
+}
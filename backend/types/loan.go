@@ -0,0 +1,190 @@
+package types
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/tvgelderen/fiscora/amortization"
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type BaseLoan struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Principal   float64 `json:"principal"`
+	// InterestRate is the yearly interest rate in percent
+	InterestRate float64 `json:"interestRate"`
+	// Term is the number of monthly payments
+	Term     int32  `json:"term"`
+	Schedule string `json:"schedule"`
+	// Type is the transaction type of the principal payments, interest is
+	// booked as Interest
+	Type             string    `json:"type"`
+	FirstPaymentDate time.Time `json:"firstPaymentDate"`
+}
+
+type LoanForm struct {
+	BaseLoan
+}
+
+// LoanReturn is a loan with the balance left after the payments that are due
+// up to today. The payments are only included for a single loan.
+type LoanReturn struct {
+	BaseLoan
+	ID            int32                 `json:"id"`
+	Created       time.Time             `json:"created"`
+	Updated       time.Time             `json:"updated"`
+	Version       int32                 `json:"version"`
+	Balance       float64               `json:"balance"`
+	TotalInterest float64               `json:"totalInterest"`
+	TotalPaid     float64               `json:"totalPaid"`
+	PayoffDate    NullTime              `json:"payoffDate"`
+	NextPayment   *LoanPaymentReturn    `json:"nextPayment"`
+	Repayments    []LoanRepaymentReturn `json:"repayments"`
+	Payments      *[]LoanPaymentReturn  `json:"payments"`
+}
+
+// LoanPaymentReturn is a row of the amortization table. A posted payment was
+// booked as transactions.
+type LoanPaymentReturn struct {
+	Number    int       `json:"number"`
+	Date      time.Time `json:"date"`
+	Principal float64   `json:"principal"`
+	Interest  float64   `json:"interest"`
+	Extra     float64   `json:"extra"`
+	Total     float64   `json:"total"`
+	Balance   float64   `json:"balance"`
+	Posted    bool      `json:"posted"`
+}
+
+type LoanRepaymentReturn struct {
+	ID            int32     `json:"id"`
+	TransactionID NullInt   `json:"transactionId"`
+	Date          time.Time `json:"date"`
+	Amount        float64   `json:"amount"`
+}
+
+type LoanRepaymentForm struct {
+	Date   time.Time `json:"date"`
+	Amount float64   `json:"amount"`
+}
+
+// LoanSimulationForm are extra repayments on top of the ones that were made,
+// as a monthly amount with each coming payment and as single repayments.
+type LoanSimulationForm struct {
+	MonthlyExtra float64             `json:"monthlyExtra"`
+	Repayments   []LoanRepaymentForm `json:"repayments"`
+}
+
+type LoanScheduleSummaryReturn struct {
+	TotalInterest float64  `json:"totalInterest"`
+	TotalPaid     float64  `json:"totalPaid"`
+	PayoffDate    NullTime `json:"payoffDate"`
+	Payments      int      `json:"payments"`
+}
+
+// LoanSimulationReturn compares the schedule of a loan with the schedule with
+// the simulated extra repayments.
+type LoanSimulationReturn struct {
+	Current       LoanScheduleSummaryReturn `json:"current"`
+	Simulated     LoanScheduleSummaryReturn `json:"simulated"`
+	InterestSaved float64                   `json:"interestSaved"`
+	PaymentsSaved int                       `json:"paymentsSaved"`
+	Payments      []LoanPaymentReturn       `json:"payments"`
+}
+
+func ToLoanReturns(loans *[]repository.LoanWithSchedule) []LoanReturn {
+	returnLoans := make([]LoanReturn, len(*loans))
+	for idx, loan := range *loans {
+		returnLoans[idx] = ToLoanReturn(&loan)
+	}
+
+	return returnLoans
+}
+
+func ToLoanReturn(loan *repository.LoanWithSchedule) LoanReturn {
+	principal, _ := strconv.ParseFloat(loan.Principal, 64)
+	interestRate, _ := strconv.ParseFloat(loan.InterestRate, 64)
+
+	repayments := make([]LoanRepaymentReturn, len(loan.Repayments))
+	for idx, repayment := range loan.Repayments {
+		amount, _ := strconv.ParseFloat(repayment.Amount, 64)
+		repayments[idx] = LoanRepaymentReturn{
+			ID:            repayment.ID,
+			TransactionID: NewNullInt(repayment.TransactionID),
+			Date:          repayment.Date,
+			Amount:        amount,
+		}
+	}
+
+	var posted int32
+	if len(loan.Payments) != 0 {
+		posted = loan.Payments[len(loan.Payments)-1].Number
+	}
+	payments := ToLoanPaymentReturns(loan.Amortization, posted)
+
+	now := time.Now().UTC()
+	var nextPayment *LoanPaymentReturn
+	for idx := range payments {
+		if payments[idx].Date.After(now) {
+			nextPayment = &payments[idx]
+			break
+		}
+	}
+
+	summary := ToLoanScheduleSummaryReturn(loan.Amortization)
+
+	return LoanReturn{
+		ID:            loan.ID,
+		Created:       loan.Created,
+		Updated:       loan.Updated,
+		Version:       loan.Version,
+		Balance:       loan.Balance,
+		TotalInterest: summary.TotalInterest,
+		TotalPaid:     summary.TotalPaid,
+		PayoffDate:    summary.PayoffDate,
+		NextPayment:   nextPayment,
+		Repayments:    repayments,
+		Payments:      nil,
+		BaseLoan: BaseLoan{
+			Name:             loan.Name,
+			Description:      loan.Description,
+			Principal:        principal,
+			InterestRate:     interestRate,
+			Term:             loan.Term,
+			Schedule:         loan.Schedule,
+			Type:             loan.Type,
+			FirstPaymentDate: loan.FirstPaymentDate,
+		},
+	}
+}
+
+// ToLoanPaymentReturns converts a schedule to an amortization table, in which
+// the payments up to the last posted one are marked as posted.
+func ToLoanPaymentReturns(schedule amortization.Schedule, posted int32) []LoanPaymentReturn {
+	payments := make([]LoanPaymentReturn, len(schedule.Payments))
+	for idx, payment := range schedule.Payments {
+		payments[idx] = LoanPaymentReturn{
+			Number:    payment.Number,
+			Date:      payment.Date,
+			Principal: payment.Principal,
+			Interest:  payment.Interest,
+			Extra:     payment.Extra,
+			Total:     payment.Total,
+			Balance:   payment.Balance,
+			Posted:    int32(payment.Number) <= posted,
+		}
+	}
+
+	return payments
+}
+
+func ToLoanScheduleSummaryReturn(schedule amortization.Schedule) LoanScheduleSummaryReturn {
+	return LoanScheduleSummaryReturn{
+		TotalInterest: schedule.TotalInterest,
+		TotalPaid:     schedule.TotalPaid,
+		PayoffDate:    NewNullTime(sql.NullTime{Time: schedule.PayoffDate, Valid: !schedule.PayoffDate.IsZero()}),
+		Payments:      len(schedule.Payments),
+	}
+}
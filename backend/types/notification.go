@@ -0,0 +1,30 @@
+package types
+
+import (
+	"time"
+
+	"github.com/tvgelderen/fiscora/repository"
+)
+
+type NotificationReturn struct {
+	ID      int32     `json:"id"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Read    NullTime  `json:"read"`
+	Created time.Time `json:"created"`
+}
+
+func ToNotificationReturns(notifications *[]repository.Notification) []NotificationReturn {
+	result := make([]NotificationReturn, len(*notifications))
+	for idx, notification := range *notifications {
+		result[idx] = NotificationReturn{
+			ID:      notification.ID,
+			Title:   notification.Title,
+			Message: notification.Message,
+			Read:    NewNullTime(notification.Read),
+			Created: notification.Created,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,183 @@
+package types
+
+import (
+	"time"
+
+	"github.com/tvgelderen/fiscora/report"
+)
+
+type VarianceReportReturn struct {
+	StartDate time.Time              `json:"startDate"`
+	EndDate   time.Time              `json:"endDate"`
+	Periods   []BudgetVarianceReturn `json:"periods"`
+}
+
+// VarianceSummaryReturn compares the allocated amount with the actual
+// spending. A negative projected variance is the overspending at the end of
+// the period at the current pace.
+type VarianceSummaryReturn struct {
+	Allocated         float64               `json:"allocated"`
+	Actual            float64               `json:"actual"`
+	Variance          float64               `json:"variance"`
+	Projected         float64               `json:"projected"`
+	ProjectedVariance float64               `json:"projectedVariance"`
+	BurnDown          []BurnDownPointReturn `json:"burnDown"`
+}
+
+type BurnDownPointReturn struct {
+	Date      time.Time `json:"date"`
+	Actual    float64   `json:"actual"`
+	Ideal     float64   `json:"ideal"`
+	Projected bool      `json:"projected"`
+}
+
+// BudgetVarianceReturn is the variance of a budget in one of its periods.
+type BudgetVarianceReturn struct {
+	VarianceSummaryReturn
+	BudgetID   string                  `json:"budgetId"`
+	BudgetName string                  `json:"budgetName"`
+	PeriodID   NullInt                 `json:"periodId"`
+	StartDate  time.Time               `json:"startDate"`
+	EndDate    time.Time               `json:"endDate"`
+	Current    bool                    `json:"current"`
+	Expenses   []ExpenseVarianceReturn `json:"expenses"`
+}
+
+type ExpenseVarianceReturn struct {
+	VarianceSummaryReturn
+	BudgetExpenseID NullInt `json:"budgetExpenseId"`
+	Name            string  `json:"name"`
+}
+
+func ToVarianceSummaryReturn(summary report.Summary) VarianceSummaryReturn {
+	points := make([]BurnDownPointReturn, len(summary.BurnDown))
+	for idx, point := range summary.BurnDown {
+		points[idx] = BurnDownPointReturn{
+			Date:      point.Date,
+			Actual:    point.Actual,
+			Ideal:     point.Ideal,
+			Projected: point.Projected,
+		}
+	}
+
+	return VarianceSummaryReturn{
+		Allocated:         summary.Allocated,
+		Actual:            summary.Actual,
+		Variance:          summary.Variance,
+		Projected:         summary.Projected,
+		ProjectedVariance: summary.ProjectedVariance,
+		BurnDown:          points,
+	}
+}
+
+// SummaryReportReturn is the income, expense and net result per bucket of the
+// period, split per group. Expenses are positive.
+type SummaryReportReturn struct {
+	SummaryTotalsReturn
+	From    time.Time             `json:"from"`
+	To      time.Time             `json:"to"`
+	GroupBy string                `json:"groupBy"`
+	By      string                `json:"by"`
+	Buckets []SummaryBucketReturn `json:"buckets"`
+}
+
+type SummaryTotalsReturn struct {
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Net     float64 `json:"net"`
+}
+
+type SummaryBucketReturn struct {
+	SummaryTotalsReturn
+	Start  time.Time            `json:"start"`
+	End    time.Time            `json:"end"`
+	Groups []SummaryGroupReturn `json:"groups"`
+}
+
+type SummaryGroupReturn struct {
+	SummaryTotalsReturn
+	Key string `json:"key"`
+}
+
+func ToSummaryBucketReturns(buckets []report.Bucket) []SummaryBucketReturn {
+	returns := make([]SummaryBucketReturn, len(buckets))
+	for idx, bucket := range buckets {
+		groups := make([]SummaryGroupReturn, len(bucket.Groups))
+		for groupIdx, group := range bucket.Groups {
+			groups[groupIdx] = SummaryGroupReturn{
+				SummaryTotalsReturn: ToSummaryTotalsReturn(group.Totals),
+				Key:                 group.Key,
+			}
+		}
+
+		returns[idx] = SummaryBucketReturn{
+			SummaryTotalsReturn: ToSummaryTotalsReturn(bucket.Totals),
+			Start:               bucket.Start,
+			End:                 bucket.End,
+			Groups:              groups,
+		}
+	}
+
+	return returns
+}
+
+func ToSummaryTotalsReturn(totals report.Totals) SummaryTotalsReturn {
+	return SummaryTotalsReturn{
+		Income:  totals.Income,
+		Expense: totals.Expense,
+		Net:     totals.Net,
+	}
+}
+
+// ComparisonReportReturn compares a month with the same month last year and
+// with the average of the twelve months before it, in total and per category.
+type ComparisonReportReturn struct {
+	Month      time.Time          `json:"month"`
+	Income     bool               `json:"income"`
+	Total      ComparisonReturn   `json:"total"`
+	Categories []ComparisonReturn `json:"categories"`
+}
+
+type ComparisonReturn struct {
+	Category        string      `json:"category"`
+	Current         float64     `json:"current"`
+	LastYear        DeltaReturn `json:"lastYear"`
+	TrailingAverage DeltaReturn `json:"trailingAverage"`
+}
+
+// DeltaReturn is the change from the base amount. The percentage is only
+// comparable when there is a base amount to compare with.
+type DeltaReturn struct {
+	Base        float64 `json:"base"`
+	Difference  float64 `json:"difference"`
+	Percentage  float64 `json:"percentage"`
+	Comparable  bool    `json:"comparable"`
+	Significant bool    `json:"significant"`
+}
+
+func ToComparisonReturn(comparison report.Comparison) ComparisonReturn {
+	return ComparisonReturn{
+		Category:        comparison.Key,
+		Current:         comparison.Current,
+		LastYear:        ToDeltaReturn(comparison.LastYear),
+		TrailingAverage: ToDeltaReturn(comparison.TrailingAverage),
+	}
+}
+
+func ToComparisonReturns(comparisons []report.Comparison) []ComparisonReturn {
+	returns := make([]ComparisonReturn, len(comparisons))
+	for idx, comparison := range comparisons {
+		returns[idx] = ToComparisonReturn(comparison)
+	}
+	return returns
+}
+
+func ToDeltaReturn(delta report.Delta) DeltaReturn {
+	return DeltaReturn{
+		Base:        delta.Base,
+		Difference:  delta.Difference,
+		Percentage:  delta.Percentage,
+		Comparable:  delta.Comparable,
+		Significant: delta.Significant,
+	}
+}
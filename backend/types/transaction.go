@@ -1,10 +1,12 @@
 package types
 
 import (
+	"database/sql"
 	"math"
 	"strconv"
 	"time"
 
+	"github.com/tvgelderen/fiscora/recurrence"
 	"github.com/tvgelderen/fiscora/repository"
 )
 
@@ -17,6 +19,22 @@ type TransactionForm struct {
 	EndDate      NullTime   `json:"endDate"`
 	Interval     NullString `json:"interval"`
 	DaysInterval NullInt    `json:"daysInterval"`
+	RRule        NullString `json:"rrule"`
+}
+
+// RecurrenceRule returns the recurrence rule of a recurring transaction. A
+// custom interval comes with its own rule, the other intervals are presets.
+func (form TransactionForm) RecurrenceRule() (string, error) {
+	if form.Interval.String != recurrence.IntervalCustom {
+		return recurrence.FromInterval(form.Interval.String, form.DaysInterval.Int32, form.StartDate.Time)
+	}
+
+	rule, err := recurrence.Parse(form.RRule.String, form.StartDate.Time)
+	if err != nil {
+		return "", err
+	}
+
+	return rule.String(), nil
 }
 
 type TransactionReturn struct {
@@ -27,8 +45,12 @@ type TransactionReturn struct {
 	Date        time.Time             `json:"date"`
 	Created     time.Time             `json:"created"`
 	Updated     time.Time             `json:"updated"`
+	Deleted     NullTime              `json:"deleted"`
+	Version     int32                 `json:"version"`
+	Projected   bool                  `json:"projected"`
 	Recurring   *TransactionRecurring `json:"recurring"`
 	Budget      *TransactionBudget    `json:"budget"`
+	Tags        []string              `json:"tags"`
 }
 
 type TransactionRecurring struct {
@@ -37,12 +59,63 @@ type TransactionRecurring struct {
 	EndDate      NullTime   `json:"endDate"`
 	Interval     NullString `json:"interval"`
 	DaysInterval NullInt    `json:"daysInterval"`
+	RRule        NullString `json:"rrule"`
+}
+
+type RecurringProposalReturn struct {
+	Description    string    `json:"description"`
+	Amount         float64   `json:"amount"`
+	Type           string    `json:"type"`
+	Interval       string    `json:"interval"`
+	RRule          string    `json:"rrule"`
+	StartDate      time.Time `json:"startDate"`
+	LastDate       time.Time `json:"lastDate"`
+	NextDate       time.Time `json:"nextDate"`
+	Confidence     float64   `json:"confidence"`
+	TransactionIDs []int32   `json:"transactionIds"`
+}
+
+// RecurringProposalForm accepts a proposal, possibly changed by the user. The
+// series starts at the start date and continues at the next date.
+type RecurringProposalForm struct {
+	TransactionForm
+	NextDate       NullTime `json:"nextDate"`
+	TransactionIDs []int32  `json:"transactionIds"`
 }
 
+// TransactionBudget is the budget a transaction counts against. A matched
+// transaction is not assigned to the budget, but matches one of its expenses.
 type TransactionBudget struct {
 	ID          NullString `json:"id"`
 	Name        NullString `json:"name"`
 	ExpenseName NullString `json:"expenseName"`
+	Matched     bool       `json:"matched"`
+}
+
+type TransactionBulkFilter struct {
+	StartDate   NullTime   `json:"startDate"`
+	EndDate     NullTime   `json:"endDate"`
+	Type        NullString `json:"type"`
+	Description NullString `json:"description"`
+	BudgetID    NullString `json:"budgetId"`
+	Income      *bool      `json:"income"`
+	Tag         NullString `json:"tag"`
+}
+
+type TransactionBulkForm struct {
+	Operation       string                 `json:"operation"`
+	Ids             []int32                `json:"ids"`
+	Filter          *TransactionBulkFilter `json:"filter"`
+	Type            string                 `json:"type"`
+	BudgetID        string                 `json:"budgetId"`
+	BudgetExpenseID int32                  `json:"budgetExpenseId"`
+	Tags            []string               `json:"tags"`
+}
+
+type TransactionBulkReturn struct {
+	Operation string `json:"operation"`
+	Matched   int    `json:"matched"`
+	Affected  int64  `json:"affected"`
 }
 
 type MonthInfoReturn struct {
@@ -75,6 +148,9 @@ func ToBaseTransactionReturn(transaction repository.Transaction) TransactionRetu
 		Date:        transaction.Date,
 		Created:     transaction.Created,
 		Updated:     transaction.Updated,
+		Deleted:     NewNullTime(transaction.Deleted),
+		Version:     transaction.Version,
+		Projected:   transaction.ID == 0,
 		Recurring:   nil,
 		Budget:      nil,
 	}
@@ -100,8 +176,12 @@ func ToTransactionReturn(transaction repository.FullTransaction) TransactionRetu
 		Amount:      amount,
 		Type:        transaction.Type,
 		Date:        transaction.Date,
+		Deleted:     NewNullTime(transaction.Deleted),
+		Version:     transaction.Version,
+		Projected:   transaction.ID == 0,
 		Recurring:   nil,
 		Budget:      nil,
+		Tags:        transaction.Tags,
 	}
 
 	if transaction.RecurringTransactionID.Valid {
@@ -113,6 +193,7 @@ func ToTransactionReturn(transaction repository.FullTransaction) TransactionRetu
 			EndDate:      NewNullTime(transaction.EndDate),
 			Interval:     NewNullString(transaction.Interval),
 			DaysInterval: NewNullInt(transaction.DaysInterval),
+			RRule:        NewNullString(transaction.Rrule),
 		}
 	} else {
 		result.Created = transaction.Created
@@ -130,6 +211,68 @@ func ToTransactionReturn(transaction repository.FullTransaction) TransactionRetu
 	return result
 }
 
+func ToBudgetMatchedTransactionReturns(transactions *[]repository.BudgetMatchedTransaction, budgetId string, budgetName string) []TransactionReturn {
+	result := make([]TransactionReturn, len(*transactions))
+	for idx, transaction := range *transactions {
+		result[idx] = ToTransactionReturn(transaction.FullTransaction)
+		result[idx].Budget = &TransactionBudget{
+			ID:          NewNullStringFromString(budgetId),
+			Name:        NewNullStringFromString(budgetName),
+			ExpenseName: NewNullStringFromString(transaction.ExpenseName),
+			Matched:     true,
+		}
+	}
+
+	return result
+}
+
+func ToRecurringProposalReturns(proposals []recurrence.Proposal) []RecurringProposalReturn {
+	result := make([]RecurringProposalReturn, len(proposals))
+	for idx, proposal := range proposals {
+		result[idx] = RecurringProposalReturn{
+			Description:    proposal.Description,
+			Amount:         proposal.Amount,
+			Type:           proposal.Type,
+			Interval:       proposal.Interval,
+			RRule:          proposal.RRule,
+			StartDate:      proposal.StartDate,
+			LastDate:       proposal.LastDate,
+			NextDate:       proposal.NextDate,
+			Confidence:     proposal.Confidence,
+			TransactionIDs: proposal.TransactionIDs,
+		}
+	}
+
+	return result
+}
+
+func ToBulkParams(form *TransactionBulkForm) repository.BulkParams {
+	params := repository.BulkParams{
+		Operation:       form.Operation,
+		Ids:             form.Ids,
+		Type:            form.Type,
+		BudgetID:        form.BudgetID,
+		BudgetExpenseID: form.BudgetExpenseID,
+		Tags:            form.Tags,
+	}
+
+	if form.Filter != nil {
+		params.Filter = &repository.BulkFilter{
+			StartDate:   form.Filter.StartDate.NullTime,
+			EndDate:     form.Filter.EndDate.NullTime,
+			Type:        form.Filter.Type.NullString,
+			Description: form.Filter.Description.NullString,
+			BudgetID:    form.Filter.BudgetID.NullString,
+			Tag:         form.Filter.Tag.NullString,
+		}
+		if form.Filter.Income != nil {
+			params.Filter.Income = sql.NullBool{Bool: *form.Filter.Income, Valid: true}
+		}
+	}
+
+	return params
+}
+
 func GetMonthInfo(amounts *[]float64) MonthInfoReturn {
 	var income float64 = 0
 	var expense float64 = 0
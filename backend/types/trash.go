@@ -0,0 +1,6 @@
+package types
+
+type TrashReturn struct {
+	Transactions *[]TransactionReturn `json:"transactions"`
+	Budgets      []BudgetReturn       `json:"budgets"`
+}
@@ -3,12 +3,29 @@ package types
 import "github.com/tvgelderen/fiscora/repository"
 
 type User struct {
+	UserSettings
 	Email    string `json:"email"`
 	Username string `json:"username"`
 }
 
+// UserSettings is how the user divides time in reports. The week start is a
+// day of the week from 0 (Sunday) to 6 (Saturday) and the fiscal year start a
+// month from 1 (January) to 12 (December).
+type UserSettings struct {
+	WeekStart       int `json:"weekStart"`
+	FiscalYearStart int `json:"fiscalYearStart"`
+}
+
+type UserSettingsForm struct {
+	UserSettings
+}
+
 func ToUser(user *repository.User) User {
 	return User{
+		UserSettings: UserSettings{
+			WeekStart:       int(user.WeekStart),
+			FiscalYearStart: int(user.FiscalYearStart),
+		},
 		Username: user.Username,
 		Email:    user.Email,
 	}